@@ -157,7 +157,7 @@ func runAgentCreate(cmd *cobra.Command, args []string) error {
 	mcpEndpoint := deriveMCPEndpoint(cfg.Endpoint)
 
 	// Resolve org slug for sandbox name namespacing
-	apiClient, err := client.New(cfg)
+	apiClient, err := newClient(cfg)
 	if err != nil {
 		return fmt.Errorf("creating API client: %w", err)
 	}
@@ -414,7 +414,7 @@ func runAgentCreateFromBlueprint(cmd *cobra.Command, name, sandboxName, blueprin
 
 	// Register agent in the server and mark as running
 	fmt.Print("  Registering agent... ")
-	apiClient, err := client.New(cfg)
+	apiClient, err := newClient(cfg)
 	if err == nil {
 		agentInput := map[string]interface{}{
 			"name":             name,
@@ -548,7 +548,7 @@ func runAgentStop(cmd *cobra.Command, args []string) error {
 	// Update agent status in the server
 	cfg, cfgErr := config.Load()
 	if cfgErr == nil && cfg.Endpoint != "" && cfg.APIKey != "" {
-		apiClient, clientErr := client.New(cfg)
+		apiClient, clientErr := newClient(cfg)
 		if clientErr == nil {
 			existing, lookupErr := apiClient.GetAgentBySandboxName(name)
 			if lookupErr == nil && existing != nil {
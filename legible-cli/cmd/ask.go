@@ -3,11 +3,13 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+	"github.com/Kubeworkz/legible/legible-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -24,25 +26,60 @@ Examples:
   legible ask "What are the top 10 customers by revenue?"
   legible ask "How many orders were placed last month?" --sample-size 100
   legible ask "Show me revenue by region" --json
-  legible ask "Tell me about sales trends" --thread-id abc123`,
-	Args: cobra.ExactArgs(1),
+  legible ask "Tell me about sales trends" --thread-id abc123
+  legible ask "Revenue by region" --format csv > revenue.csv
+  legible ask --interactive
+
+With --format or --output, the result rows are written like run-sql
+(table, csv, tsv, ndjson, markdown, parquet, arrow); the SQL and summary
+then go to stderr so stdout carries only the rows.
+
+With --interactive, questions are read from a prompt and every turn stays
+in the same thread, so follow-up questions keep their context. Type /help
+at the prompt for commands that run, chart or save the last answer.
+
+Transient gateway errors are retried. Pass --request-id to let the server
+de-duplicate the question, which also allows it to be retried safely.`,
+	Args: cobra.RangeArgs(0, 1),
 	RunE: runAsk,
 }
 
 var (
-	askSampleSize int
-	askLanguage   string
-	askThreadID   string
+	askSampleSize  int
+	askLanguage    string
+	askThreadID    string
+	askRequestID   string
+	askFormat      string
+	askOutput      string
+	askInteractive bool
 )
 
 func init() {
 	askCmd.Flags().IntVar(&askSampleSize, "sample-size", 0, "Max rows for data/summary (default: server decides)")
 	askCmd.Flags().StringVar(&askLanguage, "language", "", "Language for AI responses (e.g., English, 中文)")
 	askCmd.Flags().StringVar(&askThreadID, "thread-id", "", "Thread ID for conversation context")
+	askCmd.Flags().StringVar(&askRequestID, "request-id", "", "Idempotency key sent as X-Request-ID (enables retries)")
+	askCmd.Flags().StringVar(&askFormat, "format", "", "Also output result rows: "+strings.Join(output.Formats, ", "))
+	askCmd.Flags().StringVarP(&askOutput, "output", "o", "", "Write result rows to a file")
+	askCmd.Flags().BoolVarP(&askInteractive, "interactive", "i", false, "Ask follow-up questions in one thread")
 	rootCmd.AddCommand(askCmd)
 }
 
 func runAsk(cmd *cobra.Command, args []string) error {
+	if askInteractive {
+		if jsonOutput || askFormat != "" || askOutput != "" {
+			return fmt.Errorf("--interactive cannot be combined with --json, --format or --output")
+		}
+		var question string
+		if len(args) > 0 {
+			question = args[0]
+		}
+		return runConversation(cmd, askThreadID, question)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("a question is required (or use --interactive)")
+	}
+
 	c, cfg, err := newClientFromConfig()
 	if err != nil {
 		return err
@@ -51,6 +88,18 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no project selected — run: legible project use <id>")
 	}
 
+	var out *resultOutput
+	if !jsonOutput && (askFormat != "" || askOutput != "") {
+		format := askFormat
+		if format == "" {
+			format = output.Table
+		}
+		out, err = newResultOutput(format, askFormat != "", askOutput)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Ask endpoint can take up to 3 minutes
 	c.SetTimeout(4 * time.Minute)
 
@@ -68,23 +117,19 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Thinking...\n")
 	}
 
-	result, err := c.Ask(req)
-	if err != nil {
-		return err
+	ctx := cmd.Context()
+	if askRequestID != "" {
+		ctx = client.WithRequestID(ctx, askRequestID)
 	}
-
-	// Check for errors in the response
-	if result.Error != "" {
-		if jsonOutput {
+	result, err := c.AskContext(ctx, req)
+	if err != nil {
+		// Error payloads come back with the result; show it as-is in JSON mode.
+		if jsonOutput && result != nil {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
-			return enc.Encode(result)
+			enc.Encode(result)
 		}
-		code := result.Code
-		if code == "" {
-			code = "ERROR"
-		}
-		return fmt.Errorf("[%s] %s", code, result.Error)
+		return err
 	}
 
 	if jsonOutput {
@@ -102,19 +147,60 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Normal SQL result with summary
+	// Normal SQL result with summary. When rows go to stdout, everything
+	// else goes to stderr.
+	text := io.Writer(os.Stdout)
+	if out != nil && out.toStdout() {
+		text = os.Stderr
+	}
+	printAnswer(text, result)
+	if result.ThreadID != "" {
+		fmt.Fprintf(os.Stderr, "\nThread: %s\n", result.ThreadID)
+	}
+
+	if out != nil && result.SQL != "" {
+		return writeAskRows(cmd, c, result, out)
+	}
+	return nil
+}
+
+// printAnswer prints the SQL and summary of an answer to w, with the
+// section headers on stderr.
+func printAnswer(w io.Writer, result *client.AskResult) {
 	if result.SQL != "" {
 		fmt.Fprintf(os.Stderr, "\n--- SQL ---\n")
-		fmt.Println(formatSQL(result.SQL))
+		fmt.Fprintln(w, formatSQL(result.SQL))
 	}
 	if result.Summary != "" {
 		fmt.Fprintf(os.Stderr, "\n--- Summary ---\n")
-		fmt.Println(result.Summary)
+		fmt.Fprintln(w, result.Summary)
 	}
-	if result.ThreadID != "" {
-		fmt.Fprintf(os.Stderr, "\nThread: %s\n", result.ThreadID)
+}
+
+// writeAskRows writes the rows behind an answer. The ask API usually
+// returns only SQL and a summary, so the SQL is run to fetch them.
+func writeAskRows(cmd *cobra.Command, c *client.Client, result *client.AskResult, out *resultOutput) error {
+	cols, records := result.Columns, result.Records
+	if cols == nil {
+		rows, err := c.RunSQLContext(cmd.Context(), &client.RunSQLRequest{
+			SQL:      result.SQL,
+			ThreadID: result.ThreadID,
+		})
+		if err != nil {
+			return fmt.Errorf("fetching result rows: %w", err)
+		}
+		cols, records = rows.Columns, rows.Records
 	}
 
+	if out.toStdout() {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err := out.writeResult(cols, records); err != nil {
+		return err
+	}
+	if !out.toStdout() {
+		fmt.Fprintf(os.Stderr, "\n%d row(s) written to %s\n", len(records), out.path)
+	}
 	return nil
 }
 
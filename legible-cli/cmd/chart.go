@@ -1,14 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/Kubeworkz/legible/legible-cli/internal/chart"
 	"github.com/Kubeworkz/legible/legible-cli/internal/client"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// defaultChartSampleSize is the number of rows a chart is generated and
+// rendered from.
+const defaultChartSampleSize = 10000
+
 var chartCmd = &cobra.Command{
 	Use:   "chart",
 	Short: "Generate a Vega-Lite chart specification from a question and SQL",
@@ -20,17 +31,32 @@ a Vega-Lite JSON spec suitable for rendering with any Vega-compatible library.
 
 Requires both --question and --sql flags.
 
+With --render, the query results are embedded into the spec and the chart
+is drawn locally instead of printing the spec:
+
+  svg       SVG image (bar, line, area, point and arc charts)
+  png       PNG image (same chart types as svg)
+  html      Self-contained HTML page rendered with vega-embed (any chart)
+  terminal  Unicode preview of bar and line charts
+
+When --out is given without --render, the format is inferred from the file
+extension (.svg, .png, .html). A .json file receives the spec with its data.
+
 Examples:
   legible chart --question "Monthly order trends" --sql "SELECT date_trunc('month', order_date) as month, count(*) FROM orders GROUP BY 1"
-  legible chart -q "Revenue by product category" -s "SELECT category, sum(price) FROM products GROUP BY 1" --json`,
+  legible chart -q "Revenue by product category" -s "SELECT category, sum(price) FROM products GROUP BY 1" --json
+  legible chart -q "Revenue by product category" -s "SELECT category, sum(price) FROM products GROUP BY 1" --out revenue.svg
+  legible chart -q "Monthly order trends" -s "SELECT ..." --render terminal`,
 	RunE: runChart,
 }
 
 func init() {
 	chartCmd.Flags().StringP("question", "q", "", "The natural language question (required)")
 	chartCmd.Flags().StringP("sql", "s", "", "The SQL query to visualize (required)")
-	chartCmd.Flags().Int("sample-size", 10000, "Max rows to sample for chart generation")
+	chartCmd.Flags().Int("sample-size", defaultChartSampleSize, "Max rows to sample for chart generation")
 	chartCmd.Flags().String("thread-id", "", "Optional thread ID for context")
+	chartCmd.Flags().String("render", "", "Render the chart with its data: svg, png, html or terminal")
+	chartCmd.Flags().String("out", "", "Write the rendered chart to a file (format inferred from the extension)")
 	chartCmd.MarkFlagRequired("question")
 	chartCmd.MarkFlagRequired("sql")
 	rootCmd.AddCommand(chartCmd)
@@ -41,6 +67,18 @@ func runChart(cmd *cobra.Command, args []string) error {
 	sql, _ := cmd.Flags().GetString("sql")
 	sampleSize, _ := cmd.Flags().GetInt("sample-size")
 	threadID, _ := cmd.Flags().GetString("thread-id")
+	render, _ := cmd.Flags().GetString("render")
+	out, _ := cmd.Flags().GetString("out")
+
+	if render != "" || out != "" {
+		if jsonOutput {
+			return fmt.Errorf("--render and --out cannot be combined with --json")
+		}
+		var err error
+		if render, err = chartFormat(render, out); err != nil {
+			return err
+		}
+	}
 
 	c, _, err := newClientFromConfig()
 	if err != nil {
@@ -55,12 +93,26 @@ func runChart(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Fprintf(os.Stderr, "Generating chart...")
-	result, err := c.GenerateChart(req)
+	result, err := c.GenerateChartContext(cmd.Context(), req)
 	fmt.Fprintf(os.Stderr, "\r                     \r")
 	if err != nil {
 		return err
 	}
 
+	if render != "" {
+		if result.VegaSpec == nil {
+			return fmt.Errorf("no chart spec generated")
+		}
+		rows := &client.RunSQLRequest{SQL: sql, ThreadID: threadID, Limit: sampleSize}
+		if err := renderChart(cmd.Context(), c, result.VegaSpec, question, rows, render, out); err != nil {
+			return err
+		}
+		if result.ThreadID != "" {
+			fmt.Fprintf(os.Stderr, "Thread ID: %s\n", result.ThreadID)
+		}
+		return nil
+	}
+
 	if jsonOutput {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -84,3 +136,109 @@ func runChart(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// Chart render formats. chartJSON writes the spec with its data embedded.
+const (
+	chartSVG      = "svg"
+	chartPNG      = "png"
+	chartHTML     = "html"
+	chartTerminal = "terminal"
+	chartJSON     = "json"
+)
+
+var chartRenders = []string{chartSVG, chartPNG, chartHTML, chartTerminal}
+
+// chartFormat validates --render, or infers it from the --out extension.
+func chartFormat(render, path string) (string, error) {
+	if render == "" {
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".svg":
+			render = chartSVG
+		case ".png":
+			render = chartPNG
+		case ".html", ".htm":
+			render = chartHTML
+		case ".json":
+			render = chartJSON
+		default:
+			return "", fmt.Errorf("cannot infer the chart format from %q — use --render %s", path, strings.Join(chartRenders, "|"))
+		}
+	} else if !slices.Contains(chartRenders, render) {
+		return "", fmt.Errorf("unknown render format %q (valid: %s)", render, strings.Join(chartRenders, ", "))
+	}
+
+	if render == chartTerminal && path != "" {
+		return "", fmt.Errorf("--render terminal writes to stdout — drop --out")
+	}
+	if render == chartPNG && path == "" && term.IsTerminal(int(os.Stdout.Fd())) {
+		return "", fmt.Errorf("png output is binary — use --out <file> or redirect stdout")
+	}
+	return render, nil
+}
+
+// renderChart runs the query behind a chart, embeds its rows in spec and
+// renders it as format to path, or to stdout when path is empty.
+func renderChart(ctx context.Context, c *client.Client, spec interface{}, title string, rows *client.RunSQLRequest, format, path string) error {
+	fmt.Fprintf(os.Stderr, "Running query...")
+	result, err := c.RunSQLContext(ctx, rows)
+	fmt.Fprintf(os.Stderr, "\r                     \r")
+	if err != nil {
+		return err
+	}
+
+	var render func(w io.Writer) error
+	switch format {
+	case chartHTML, chartJSON:
+		withData, err := chart.WithData(spec, result.Records)
+		if err != nil {
+			return err
+		}
+		if format == chartJSON {
+			render = func(w io.Writer) error {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(withData)
+			}
+			break
+		}
+		if !chart.Vendored() {
+			return chart.ErrNotVendored
+		}
+		render = func(w io.Writer) error { return chart.RenderHTML(w, title, withData) }
+	default:
+		s, err := chart.Parse(spec)
+		if err != nil {
+			return err
+		}
+		switch format {
+		case chartSVG:
+			render = func(w io.Writer) error { return chart.RenderSVG(w, s, result.Records) }
+		case chartPNG:
+			render = func(w io.Writer) error { return chart.RenderPNG(w, s, result.Records) }
+		case chartTerminal:
+			width, _, err := term.GetSize(int(os.Stdout.Fd()))
+			if err != nil {
+				width = 80
+			}
+			render = func(w io.Writer) error { return chart.RenderTerminal(w, s, result.Records, width) }
+		}
+	}
+
+	if path == "" {
+		return render(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "Chart written to %s\n", path)
+	return nil
+}
@@ -19,7 +19,9 @@ var configGetCmd = &cobra.Command{
 	Short: "Display configuration values",
 	Long: `Show all config values, or a specific key.
 
-Keys: endpoint, api-key, project-id
+Keys: endpoint, api-key, project-id, profile, credential-store, credential-helper
+
+Values are read from the active profile (see: legible config profile).
 
 Examples:
   legible config get              Show all values
@@ -33,12 +35,29 @@ var configSetCmd = &cobra.Command{
 	Short: "Set a configuration value",
 	Long: `Set a configuration key to the given value.
 
-Keys: endpoint, api-key, project-id
+Keys: endpoint, api-key, project-id, credential-store, credential-helper
+
+API keys are never written to config.yaml in clear text. credential-store
+selects where they go:
+  auto       OS keyring if available, otherwise the encrypted file (default)
+  keyring    macOS Keychain or Secret Service (secret-tool) on Linux
+  file       ~/.legible/credentials.enc, AES-encrypted with a passphrase
+             (prompted, or read from LEGIBLE_PASSPHRASE)
+  plaintext  config.yaml, for throwaway environments such as sandboxes
+
+credential-helper runs an external command instead, like git's credential
+helpers: "<helper> get|store|erase" with "profile=<name>" on stdin; "get"
+prints "api_key=<key>". Set it to "" to go back to credential-store.
+
+Changing either setting moves the stored API keys of all profiles to the
+new store and removes them from the old one.
 
 Examples:
   legible config set endpoint https://legible.example.com
   legible config set api-key osk-abc123...
-  legible config set project-id 1`,
+  legible config set project-id 1
+  legible config set credential-store file
+  legible config set credential-helper "pass-legible"`,
 	Args: cobra.ExactArgs(2),
 	RunE: runConfigSet,
 }
@@ -78,14 +97,17 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 		return enc.Encode(display)
 	}
 
-	fmt.Println("Current configuration (~/.legible/config.yaml):")
+	fmt.Printf("Current configuration (~/.legible/config.yaml, profile %q):\n", cfg.Profile)
 	fmt.Println()
-	for _, key := range []string{"endpoint", "api_key", "project_id"} {
+	for _, key := range []string{"endpoint", "api_key", "project_id", "credential_store"} {
 		val := display[key]
 		if val == "" {
 			val = "(not set)"
 		}
-		fmt.Printf("  %-12s %s\n", key+":", val)
+		fmt.Printf("  %-18s %s\n", key+":", val)
+	}
+	if err := cfg.CredentialError(); err != nil {
+		fmt.Fprintf(os.Stderr, "\nWarning: %v\n", err)
 	}
 	return nil
 }
@@ -100,6 +122,9 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 	if err := cfg.Set(key, value); err != nil {
 		return err
 	}
+	if key == "api-key" || key == "api_key" {
+		value = config.MaskAPIKey(value)
+	}
 
 	if err := cfg.Save(); err != nil {
 		return fmt.Errorf("saving config: %w", err)
@@ -112,6 +137,6 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		return enc.Encode(out)
 	}
 
-	fmt.Printf("Set %s = %s\n", key, value)
+	fmt.Printf("Set %s = %s (profile %q)\n", key, value, cfg.Profile)
 	return nil
 }
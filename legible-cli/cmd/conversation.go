@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+	"github.com/Kubeworkz/legible/legible-cli/internal/config"
+	"github.com/Kubeworkz/legible/legible-cli/internal/output"
+	"github.com/chzyer/readline"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// conversationHelp lists the slash commands of "ask --interactive".
+const conversationHelp = `Type a question, or one of:
+  /sql               Run the last answer's SQL and show the rows
+  /chart [file]      Chart the last answer (.svg, .png, .html or .json file)
+  /save-view <name>  Save the last answer's SQL as a view
+  /save-pair         Save the last question and SQL as a SQL pair
+  /new               Start a new thread
+  /thread            Show the thread ID
+  /help              Show this help
+  /quit              Quit (or press Ctrl+D)`
+
+// conversation is an "ask --interactive" session. Every question is asked
+// in the same thread until /new.
+type conversation struct {
+	c        *client.Client
+	rl       *readline.Instance
+	threadID string
+
+	// The last answered question and its SQL, for the slash commands.
+	question string
+	sql      string
+}
+
+// runConversation starts an interactive ask session in threadID (a new
+// thread when empty), asking question first if it is not empty.
+func runConversation(cmd *cobra.Command, threadID, question string) error {
+	c, cfg, err := newClientFromConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.ProjectID == "" {
+		return fmt.Errorf("no project selected — run: legible project use <id>")
+	}
+
+	// Ask endpoint can take up to 3 minutes
+	c.SetTimeout(4 * time.Minute)
+
+	rlCfg := &readline.Config{
+		Prompt:            "ask> ",
+		InterruptPrompt:   "^C",
+		EOFPrompt:         "/quit",
+		HistorySearchFold: true,
+	}
+	if dir, err := config.Dir(); err == nil && os.MkdirAll(dir, 0700) == nil {
+		rlCfg.HistoryFile = filepath.Join(dir, "ask_history")
+	}
+	rl, err := readline.NewEx(rlCfg)
+	if err != nil {
+		return fmt.Errorf("starting prompt: %w", err)
+	}
+	defer rl.Close()
+
+	// While the session runs, Ctrl+C cancels the running turn only.
+	noop := func() {}
+	interruptHandler.Store(&noop)
+	defer interruptHandler.Store(nil)
+
+	conv := &conversation{c: c, rl: rl, threadID: threadID}
+	ctx := cmd.Context()
+
+	if threadID != "" {
+		fmt.Printf("Resuming thread %s. Type /help for commands.\n", threadID)
+	} else {
+		fmt.Println("Ask a question. Type /help for commands.")
+	}
+	if question != "" {
+		fmt.Printf("ask> %s\n", question)
+		conv.ask(ctx, question)
+	}
+
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "/") {
+			if quit := conv.command(ctx, line); quit {
+				break
+			}
+			continue
+		}
+		conv.ask(ctx, line)
+	}
+
+	if conv.threadID != "" {
+		fmt.Fprintf(os.Stderr, "Thread: %s (resume with: legible thread resume %s)\n", conv.threadID, conv.threadID)
+	}
+	return nil
+}
+
+// ask asks a question in the session thread and prints the answer.
+func (conv *conversation) ask(ctx context.Context, question string) {
+	turnCtx, done := interruptible(ctx)
+	defer done()
+
+	req := &client.AskRequest{
+		Question: question,
+		ThreadID: conv.threadID,
+		Language: askLanguage,
+	}
+	if askSampleSize > 0 {
+		req.SampleSize = askSampleSize
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Thinking...")
+	result, err := conv.c.AskContext(turnCtx, req)
+	spinner.Stop()
+	if result != nil && result.ThreadID != "" {
+		conv.threadID = result.ThreadID
+	}
+	if err != nil {
+		reportError(turnCtx, err)
+		return
+	}
+
+	if result.Type == "NON_SQL_QUERY" {
+		fmt.Println(result.Explanation)
+		fmt.Println()
+		return
+	}
+	printAnswer(os.Stdout, result)
+	fmt.Println()
+	if result.SQL != "" {
+		conv.question, conv.sql = question, result.SQL
+	}
+}
+
+// command runs a slash command and reports whether the session should end.
+func (conv *conversation) command(ctx context.Context, line string) (quit bool) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/quit", "/exit", "/q":
+		return true
+	case "/help", "/?":
+		fmt.Println(conversationHelp)
+	case "/new":
+		conv.threadID, conv.question, conv.sql = "", "", ""
+		fmt.Println("Started a new thread.")
+	case "/thread":
+		if conv.threadID == "" {
+			fmt.Println("No thread yet — it starts with the first question.")
+		} else {
+			fmt.Println(conv.threadID)
+		}
+	case "/sql", "/chart", "/save-view", "/save-pair":
+		if conv.sql == "" {
+			pterm.Error.Println("No SQL answer yet — ask a question first.")
+			break
+		}
+		turnCtx, done := interruptible(ctx)
+		defer done()
+		var err error
+		switch name {
+		case "/sql":
+			err = conv.runSQL(turnCtx)
+		case "/chart":
+			err = conv.chart(turnCtx, arg)
+		case "/save-view":
+			err = conv.saveView(turnCtx, arg)
+		case "/save-pair":
+			err = conv.savePair(turnCtx)
+		}
+		if err != nil {
+			reportError(turnCtx, err)
+		}
+	default:
+		pterm.Error.Printfln("unknown command %s — type /help for commands", name)
+	}
+	return false
+}
+
+// runSQL runs the last answer's SQL and prints the rows as a table.
+func (conv *conversation) runSQL(ctx context.Context) error {
+	result, err := conv.c.RunSQLContext(ctx, &client.RunSQLRequest{SQL: conv.sql, ThreadID: conv.threadID})
+	if err != nil {
+		return err
+	}
+	if len(result.Columns) == 0 {
+		fmt.Println("No columns returned.")
+		return nil
+	}
+	if err := printRecords(output.Table, result.Columns, result.Records); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "(%d row(s))\n\n", len(result.Records))
+	return nil
+}
+
+// chart generates a chart for the last answer and previews it in the
+// terminal, or renders it to path in the format of its extension.
+func (conv *conversation) chart(ctx context.Context, path string) error {
+	format := chartTerminal
+	if path != "" {
+		var err error
+		if format, err = chartFormat("", path); err != nil {
+			return err
+		}
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Generating chart...")
+	result, err := conv.c.GenerateChartContext(ctx, &client.GenerateChartRequest{
+		Question: conv.question,
+		SQL:      conv.sql,
+		ThreadID: conv.threadID,
+	})
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+	if result.VegaSpec == nil {
+		fmt.Println("(no chart spec generated)")
+		return nil
+	}
+
+	rows := &client.RunSQLRequest{SQL: conv.sql, ThreadID: conv.threadID, Limit: defaultChartSampleSize}
+	if err := renderChart(ctx, conv.c, result.VegaSpec, conv.question, rows, format, path); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+// saveView saves the last answer as a view. Views are created from a
+// thread response: the thread's response with the answer's SQL, or, when
+// there is none, the response of a new thread recording the question and SQL.
+func (conv *conversation) saveView(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("usage: /save-view <name>")
+	}
+	responseID, err := conv.viewResponseID(ctx)
+	if err != nil {
+		return err
+	}
+	view, err := conv.c.CreateViewContext(ctx, name, responseID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created view %q (ID: %d)\n", view.Name, view.ID)
+	return nil
+}
+
+// viewResponseID returns the ID of the thread response to create a view of
+// the last answer from, creating a thread only when the conversation's
+// thread has no response with the answer's SQL.
+func (conv *conversation) viewResponseID(ctx context.Context) (int, error) {
+	if threadID, err := strconv.Atoi(conv.threadID); err == nil {
+		detail, err := conv.c.GetThreadContext(ctx, threadID)
+		if err != nil {
+			return 0, err
+		}
+		for i := len(detail.Responses) - 1; i >= 0; i-- {
+			if detail.Responses[i].SQL == conv.sql {
+				return detail.Responses[i].ID, nil
+			}
+		}
+	}
+
+	thread, err := conv.c.CreateThreadContext(ctx, conv.question, conv.sql)
+	if err != nil {
+		return 0, err
+	}
+	detail, err := conv.c.GetThreadContext(ctx, thread.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(detail.Responses) == 0 {
+		return 0, fmt.Errorf("thread %d has no response to create a view from", thread.ID)
+	}
+	return detail.Responses[0].ID, nil
+}
+
+// savePair saves the last question and SQL as a SQL pair.
+func (conv *conversation) savePair(ctx context.Context) error {
+	pair, err := conv.c.CreateSqlPairContext(ctx, &client.CreateSqlPairRequest{
+		Question: conv.question,
+		SQL:      conv.sql,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created SQL pair %d\n", pair.ID)
+	return nil
+}
+
+// printThreadHistory prints the questions already asked in an ask thread,
+// oldest first.
+func printThreadHistory(ctx context.Context, c *client.Client, threadID string) error {
+	page, err := c.GetApiHistoryContext(ctx, &client.ApiHistoryFilter{ApiType: "ASK", ThreadID: threadID}, 0, 50)
+	if err != nil {
+		return err
+	}
+	if len(page.Items) == 0 {
+		return fmt.Errorf("no questions found in thread %s", threadID)
+	}
+
+	// History is newest first.
+	for i := len(page.Items) - 1; i >= 0; i-- {
+		item := page.Items[i]
+		req, _ := item.RequestPayload.(map[string]interface{})
+		question, _ := req["question"].(string)
+		if question == "" {
+			continue
+		}
+		fmt.Printf("Q: %s\n", question)
+		if resp, ok := item.ResponsePayload.(map[string]interface{}); ok {
+			if sql, _ := resp["sql"].(string); sql != "" {
+				fmt.Printf("SQL:\n%s\n", indentSQL(sql))
+			}
+		}
+		fmt.Println()
+	}
+	return nil
+}
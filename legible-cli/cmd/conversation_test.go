@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+func TestViewResponseID(t *testing.T) {
+	created := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+		switch {
+		case strings.Contains(req.Query, "createThread"):
+			created++
+			w.Write([]byte(`{"data": {"createThread": {"id": 9}}}`)) //nolint:errcheck
+		case req.Variables["threadId"] == float64(9):
+			w.Write([]byte(`{"data": {"thread": {"id": 9, "responses": [{"id": 90, "sql": "SELECT 2"}]}}}`)) //nolint:errcheck
+		default:
+			w.Write([]byte(`{"data": {"thread": {"id": 7, "responses": [{"id": 70, "sql": "SELECT 1"}, {"id": 71, "sql": "SELECT 2"}]}}}`)) //nolint:errcheck
+		}
+	}))
+	defer srv.Close()
+	conv := &conversation{c: client.NewWithOverrides(srv.URL, "osk-test"), threadID: "7", sql: "SELECT 1"}
+
+	// The conversation's thread has a response with the SQL
+	if id, err := conv.viewResponseID(context.Background()); err != nil || id != 70 || created != 0 {
+		t.Errorf("viewResponseID() = %d, %v with %d threads created, want 70 and none created", id, err, created)
+	}
+
+	// Without a thread, one is created
+	conv.threadID, conv.sql = "", "SELECT 2"
+	if id, err := conv.viewResponseID(context.Background()); err != nil || id != 90 || created != 1 {
+		t.Errorf("viewResponseID() = %d, %v with %d threads created, want 90 and one created", id, err, created)
+	}
+}
@@ -1,19 +1,27 @@
 package cmd
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
 	"github.com/Kubeworkz/legible/legible-cli/internal/client"
 	"github.com/Kubeworkz/legible/legible-cli/internal/config"
 	dbtfilter "github.com/Kubeworkz/legible/legible-cli/internal/dbt"
 	"github.com/Kubeworkz/legible/legible-cli/internal/legibleconfig"
+	"github.com/Kubeworkz/legible/legible-cli/internal/mdlsync"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var dbtCmd = &cobra.Command{
@@ -32,10 +40,23 @@ Requires:
   - catalog.json in target/ (run 'dbt docs generate')
   - manifest.json in target/ (run 'dbt build')
 
+Models are filtered by name with --include and --exclude, which take
+regular expressions, and by dbt node selectors with --select and
+--exclude-select, which take the syntax of dbt's own --select and --exclude
+(e.g. "tag:finance", "path:models/legacy", "+orders"). --exclude stays a
+regular expression so that it pairs with --include and keeps the meaning
+of the exclude filters already saved in .legibleconfig files. A model must
+pass both kinds of filter.
+
 Examples:
   legible dbt create --path /path/to/dbt-project
   legible dbt create --path . --name "My Analytics"
-  legible dbt create --path . --include "marts_.*" --dry-run`,
+  legible dbt create --path . --include "marts_.*" --dry-run
+  legible dbt create --path . --select "tag:finance +orders" --exclude-select "path:models/legacy"
+  legible dbt create --path . --include-sources --include-snapshots
+  legible dbt create --path . --infer-relations
+  legible dbt create --path . --infer-relations --min-confidence 0.8
+  legible dbt create --path . --name Finance --target-project finance --select "tag:finance"`,
 	RunE: runDbtCreate,
 }
 
@@ -46,16 +67,86 @@ var dbtUpdateCmd = &cobra.Command{
 changes from your dbt models. Reads the project ID from .legibleconfig
 in the dbt project directory.
 
-WARNING: This replaces the current MDL in your project with the updated
-dbt models. Any manual changes made in the UI will be overwritten.
+Changes are merged using the dbt MDL of the last sync, stored in
+.legible-snapshot.json next to .legibleconfig: descriptions, display names,
+calculated fields and relationships edited in the UI are kept unless dbt
+changed them too. Such conflicts keep the UI change unless --prefer-dbt is
+given, and fail the update with --fail-on-conflict. --overwrite replaces
+the project's models as older versions did.
+
+The update asks for confirmation unless --yes is given; with --json,
+which cannot prompt, --yes or --dry-run is required.
+
+A .legibleconfig can link further projects as named targets, each with
+its own filters and dbt settings: --target-project updates one of them,
+and --all updates every linked project with a combined report. With
+--all, each project is converted and merged with its own settings in
+.legibleconfig, so the flags for those settings (--profile, --target,
+--include-*, --metadata-mapping, --prefer-dbt and the filters) are not
+accepted.
+
+--include, --exclude, --select and --exclude-select replace the filters
+saved in .legibleconfig; the new filters are saved when the update is
+applied and only previewed with --dry-run. Give an empty value to remove
+a filter.
 
 Examples:
   legible dbt update --path /path/to/dbt-project
   legible dbt update --path . --yes
-  legible dbt update --path . --include "marts_.*" --dry-run`,
+  legible dbt update --path . --include "marts_.*" --dry-run
+  legible dbt update --path . --select "tag:finance" --exclude-select ""
+  legible dbt update --path . --prefer-dbt
+  legible dbt update --path . --target-project finance
+  legible dbt update --path . --all --yes`,
 	RunE: runDbtUpdate,
 }
 
+var dbtWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Sync a linked project whenever dbt artifacts change",
+	Long: `Watch target/manifest.json, catalog.json and semantic_manifest.json of
+a dbt project linked by .legibleconfig, and run 'legible dbt update' each
+time dbt writes them. Changes are merged as by update, without
+confirmation, and the project is only deployed when its models change.
+Watch never prompts: conflicts with changes made in the UI keep the UI
+change, or dbt's with --prefer-dbt, and --fail-on-conflict skips syncs
+with conflicts until they are resolved.
+
+Syncs once on start, then runs until interrupted with Ctrl+C. As with
+update, --all syncs each project with its own settings in .legibleconfig.
+
+Examples:
+  legible dbt watch --path .
+  legible dbt watch --path . --debounce 10s
+  legible dbt watch --path . --prefer-dbt
+  legible dbt watch --path . --all`,
+	RunE: runDbtWatch,
+}
+
+var dbtExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a project as dbt YAML",
+	Long: `Write a Legible project back to dbt YAML, so that descriptions,
+relationships, calculated fields and metrics authored in Legible can be
+kept in the dbt project:
+
+  schema.yml            model and column descriptions and labels, unique and
+                        not_null tests for primary keys, relationships and
+                        accepted_values tests
+  semantic_models.yml   MetricFlow semantic models with calculated fields as
+                        dimensions, and metrics
+
+Projects do not store enums or metrics, so accepted_values tests and
+metrics are only exported from an MDL file given with --mdl. Anything with
+no dbt equivalent is listed as skipped.
+
+Examples:
+  legible dbt export --out ./models/legible
+  legible dbt export --project 42 --out ./models/legible --force
+  legible dbt export --mdl legible-mdl.json --out ./models/legible`,
+	RunE: runDbtExport,
+}
+
 func init() {
 	// dbt create flags
 	dbtCreateCmd.Flags().String("path", ".", "Path to the dbt project root directory")
@@ -64,21 +155,67 @@ func init() {
 	dbtCreateCmd.Flags().String("target", "", "dbt target/output to use")
 	dbtCreateCmd.Flags().String("include", "", "Regex pattern to include matching models")
 	dbtCreateCmd.Flags().String("exclude", "", "Regex pattern to exclude matching models")
+	dbtCreateCmd.Flags().String("select", "", "dbt selector of models to include (e.g. \"tag:finance +orders\")")
+	dbtCreateCmd.Flags().String("exclude-select", "", "dbt selector of models to exclude (e.g. \"path:models/legacy\")")
 	dbtCreateCmd.Flags().Bool("dry-run", false, "Preview models without creating the project")
 	dbtCreateCmd.Flags().Bool("include-staging-models", false, "Include staging/intermediate models")
+	dbtCreateCmd.Flags().Bool("include-sources", false, "Include dbt sources as models")
+	dbtCreateCmd.Flags().Bool("include-seeds", false, "Include dbt seeds as models")
+	dbtCreateCmd.Flags().Bool("include-snapshots", false, "Include dbt snapshots as models")
+	dbtCreateCmd.Flags().String("metadata-mapping", "", "YAML file mapping dbt meta, config, tags and docs into model properties")
+	dbtCreateCmd.Flags().Bool("infer-relations", false, "Infer relationships that have no dbt relationships test, and review them")
+	dbtCreateCmd.Flags().Float64("min-confidence", 0, "Add the inferred relationships with at least this confidence (0-1) without reviewing them")
+	dbtCreateCmd.Flags().String("target-project", "", "Link the new project as a named target of .legibleconfig (e.g. finance)")
+	dbtCreateCmd.Flags().String("vars", "", "YAML dictionary of values for var() in profiles.yml, like dbt's --vars")
 
 	// dbt update flags
 	dbtUpdateCmd.Flags().String("path", ".", "Path to the dbt project root directory")
 	dbtUpdateCmd.Flags().String("profile", "", "dbt profile name to use")
 	dbtUpdateCmd.Flags().String("target", "", "dbt target/output to use")
-	dbtUpdateCmd.Flags().String("include", "", "Regex pattern to include (dry-run only)")
-	dbtUpdateCmd.Flags().String("exclude", "", "Regex pattern to exclude (dry-run only)")
+	dbtUpdateCmd.Flags().String("include", "", "Regex pattern to include matching models, replacing the saved one (\"\" removes it)")
+	dbtUpdateCmd.Flags().String("exclude", "", "Regex pattern to exclude matching models, replacing the saved one (\"\" removes it)")
+	dbtUpdateCmd.Flags().String("select", "", "dbt selector of models to include, replacing the saved one (\"\" removes it)")
+	dbtUpdateCmd.Flags().String("exclude-select", "", "dbt selector of models to exclude, replacing the saved one (\"\" removes it)")
 	dbtUpdateCmd.Flags().Bool("dry-run", false, "Preview changes without applying")
 	dbtUpdateCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
 	dbtUpdateCmd.Flags().Bool("include-staging-models", false, "Include staging/intermediate models")
+	dbtUpdateCmd.Flags().Bool("include-sources", false, "Include dbt sources as models")
+	dbtUpdateCmd.Flags().Bool("include-seeds", false, "Include dbt seeds as models")
+	dbtUpdateCmd.Flags().Bool("include-snapshots", false, "Include dbt snapshots as models")
+	dbtUpdateCmd.Flags().String("vars", "", "YAML dictionary of values for var() in profiles.yml, like dbt's --vars")
+	dbtUpdateCmd.Flags().String("metadata-mapping", "", "YAML file mapping dbt meta, config, tags and docs into model properties (default: the one in .legibleconfig)")
+	dbtUpdateCmd.Flags().Bool("prefer-dbt", false, "Resolve conflicts with changes made in the UI in favour of dbt")
+	dbtUpdateCmd.Flags().Bool("fail-on-conflict", false, "Fail instead of applying an update that conflicts with changes made in the UI")
+	dbtUpdateCmd.Flags().Bool("overwrite", false, "Replace the project's models instead of merging, discarding changes made in the UI")
+	dbtUpdateCmd.Flags().String("target-project", "", "Named target of .legibleconfig to update (default: the top-level project)")
+	dbtUpdateCmd.Flags().Bool("all", false, "Update every project linked in .legibleconfig, with a combined report")
+
+	// dbt watch flags
+	dbtWatchCmd.Flags().String("path", ".", "Path to the dbt project root directory")
+	dbtWatchCmd.Flags().String("profile", "", "dbt profile name to use")
+	dbtWatchCmd.Flags().String("target", "", "dbt target/output to use")
+	dbtWatchCmd.Flags().Bool("include-staging-models", false, "Include staging/intermediate models")
+	dbtWatchCmd.Flags().Bool("include-sources", false, "Include dbt sources as models")
+	dbtWatchCmd.Flags().Bool("include-seeds", false, "Include dbt seeds as models")
+	dbtWatchCmd.Flags().Bool("include-snapshots", false, "Include dbt snapshots as models")
+	dbtWatchCmd.Flags().String("vars", "", "YAML dictionary of values for var() in profiles.yml, like dbt's --vars")
+	dbtWatchCmd.Flags().String("metadata-mapping", "", "YAML file mapping dbt meta, config, tags and docs into model properties (default: the one in .legibleconfig)")
+	dbtWatchCmd.Flags().Bool("prefer-dbt", false, "Resolve conflicts with changes made in the UI in favour of dbt")
+	dbtWatchCmd.Flags().Bool("fail-on-conflict", false, "Skip syncs that conflict with changes made in the UI, until they are resolved")
+	dbtWatchCmd.Flags().Duration("debounce", 2*time.Second, "How long artifacts must stay unchanged before syncing")
+	dbtWatchCmd.Flags().String("target-project", "", "Named target of .legibleconfig to sync (default: the top-level project)")
+	dbtWatchCmd.Flags().Bool("all", false, "Sync every project linked in .legibleconfig")
+
+	// dbt export flags
+	dbtExportCmd.Flags().String("project", "", "Project ID to export (default: the current project)")
+	dbtExportCmd.Flags().String("mdl", "", "Export an MDL file instead of a project")
+	dbtExportCmd.Flags().String("out", "models/legible", "Directory to write the YAML files to")
+	dbtExportCmd.Flags().Bool("force", false, "Overwrite existing files")
 
 	dbtCmd.AddCommand(dbtCreateCmd)
 	dbtCmd.AddCommand(dbtUpdateCmd)
+	dbtCmd.AddCommand(dbtWatchCmd)
+	dbtCmd.AddCommand(dbtExportCmd)
 	rootCmd.AddCommand(dbtCmd)
 }
 
@@ -89,12 +226,50 @@ func runDbtCreate(cmd *cobra.Command, args []string) error {
 	target, _ := cmd.Flags().GetString("target")
 	include, _ := cmd.Flags().GetString("include")
 	exclude, _ := cmd.Flags().GetString("exclude")
+	selectExpr, _ := cmd.Flags().GetString("select")
+	excludeSelect, _ := cmd.Flags().GetString("exclude-select")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	includeStagingModels, _ := cmd.Flags().GetBool("include-staging-models")
+	includeSources, _ := cmd.Flags().GetBool("include-sources")
+	includeSeeds, _ := cmd.Flags().GetBool("include-seeds")
+	includeSnapshots, _ := cmd.Flags().GetBool("include-snapshots")
+	metadataMappingPath, _ := cmd.Flags().GetString("metadata-mapping")
+	inferRelations, _ := cmd.Flags().GetBool("infer-relations")
+	minConfidence, _ := cmd.Flags().GetFloat64("min-confidence")
+	minConfidenceSet := cmd.Flags().Changed("min-confidence")
+	targetProject, _ := cmd.Flags().GetString("target-project")
+	varsFlag, _ := cmd.Flags().GetString("vars")
+	vars, err := parseDbtVars(varsFlag)
+	if err != nil {
+		return err
+	}
+	if minConfidenceSet {
+		if !inferRelations {
+			return fmt.Errorf("--min-confidence needs --infer-relations")
+		}
+		if minConfidence < 0 || minConfidence > 1 {
+			return fmt.Errorf("--min-confidence must be between 0 and 1, got %g", minConfidence)
+		}
+	}
+	// JSON output cannot be mixed with the review of inferred relationships
+	if jsonOutput && inferRelations && !dryRun && !minConfidenceSet {
+		return fmt.Errorf("--json cannot review inferred relationships — add --min-confidence to add them by confidence (0 adds all), or --dry-run to preview them")
+	}
 
-	// Check if already linked
+	// Check if already linked. A named target can be added to an existing
+	// .legibleconfig, as long as it is new.
+	root := &legibleconfig.Config{}
 	if legibleconfig.Exists(path) {
-		return fmt.Errorf("this dbt project is already linked (found .legibleconfig) — use 'legible dbt update' instead")
+		if targetProject == "" {
+			return fmt.Errorf("this dbt project is already linked (found .legibleconfig) — use 'legible dbt update' instead, or --target-project to link another project")
+		}
+		var err error
+		if root, err = legibleconfig.Load(path); err != nil {
+			return err
+		}
+		if _, ok := root.Targets[targetProject]; ok {
+			return fmt.Errorf("target %q is already linked — use 'legible dbt update --target-project %s' instead", targetProject, targetProject)
+		}
 	}
 
 	// Validate dbt project
@@ -102,6 +277,14 @@ func runDbtCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not a valid dbt project: %s (missing dbt_project.yml)", path)
 	}
 
+	var metadata *dbt.MetadataMapping
+	if metadataMappingPath != "" {
+		var err error
+		if metadata, err = dbt.LoadMetadataMapping(metadataMappingPath); err != nil {
+			return err
+		}
+	}
+
 	// Use a temp directory for converter output
 	tmpDir, err := os.MkdirTemp("", "legible-dbt-*")
 	if err != nil {
@@ -110,14 +293,20 @@ func runDbtCreate(cmd *cobra.Command, args []string) error {
 	defer os.RemoveAll(tmpDir)
 
 	// Convert dbt project to Legible MDL + data source JSON
-	fmt.Println("Converting dbt project...")
-	_, err = dbt.ConvertDbtProjectCore(dbt.ConvertOptions{
+	fmt.Fprintln(progressOut(), "Converting dbt project...")
+	result, err := dbt.ConvertDbtProjectCore(dbt.ConvertOptions{
 		ProjectPath:          path,
 		OutputDir:            tmpDir,
 		ProfileName:          profile,
 		Target:               target,
 		RequireCatalog:       true,
 		IncludeStagingModels: includeStagingModels,
+		IncludeSources:       includeSources,
+		IncludeSeeds:         includeSeeds,
+		IncludeSnapshots:     includeSnapshots,
+		Metadata:             metadata,
+		InferRelations:       inferRelations,
+		Vars:                 vars,
 	})
 	if err != nil {
 		return fmt.Errorf("dbt conversion failed: %w", err)
@@ -131,19 +320,39 @@ func runDbtCreate(cmd *cobra.Command, args []string) error {
 
 	// Apply include/exclude filters
 	f := dbtfilter.NewFilterSingle(include, exclude)
-	mdl.Models = applyModelFilter(mdl.Models, f)
+	f.Selects, f.ExcludeSelects = nonEmpty(selectExpr), nonEmpty(excludeSelect)
+	mdl.Models, err = applyModelFilter(path, mdl.Models, f, result.ModelNodeIDs)
+	if err != nil {
+		return err
+	}
 	if len(mdl.Models) == 0 {
 		return fmt.Errorf("no models matched the filter criteria")
 	}
+	printValidationIssues(dbt.ValidateManifest(mdl), result.Validation)
+
+	var inferred []dbt.InferredRelationship
+	if inferRelations {
+		inferred = inferredForModels(result.InferredRelationships, mdl.Models)
+	}
 
 	// Print summary
-	printCreateSummary(mdl)
+	printCreateSummary(mdl, inferred)
 
 	if dryRun {
-		fmt.Println("\nDry run — no project created.")
+		fmt.Fprintln(progressOut(), "\nDry run — no project created.")
 		return nil
 	}
 
+	// Review the inferred relationships before anything is created, unless
+	// they are added by confidence
+	var accepted []dbt.Relationship
+	if minConfidenceSet {
+		accepted = acceptInferredRelations(inferred, minConfidence)
+	} else if accepted, err = reviewInferredRelations(os.Stdin, inferred); err != nil {
+		return err
+	}
+	mdl.Relationships = append(mdl.Relationships, accepted...)
+
 	// Read data source JSON (optional — converter may not have generated it)
 	var dsInput *client.SaveDataSourceInput
 	dsPath := filepath.Join(tmpDir, "legible-datasource.json")
@@ -165,271 +374,866 @@ func runDbtCreate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
 
-	// 1. Create project
-	fmt.Printf("\nCreating project %q... ", name)
-	project, err := c.CreateProject(name)
+	project, err := createProjectFromMDL(c, name, dsInput, mdl)
 	if err != nil {
-		fmt.Println("FAILED")
 		return err
 	}
-	fmt.Printf("OK (ID: %d)\n", project.ID)
-
-	// Switch client context to the new project
 	projectIDStr := strconv.Itoa(project.ID)
-	c.SetProjectID(projectIDStr)
 
-	// 2. Save data source (if available)
-	if dsInput != nil {
-		fmt.Printf("Configuring %s data source... ", dsInput.Type)
-		_, err = c.SaveDataSource(dsInput)
-		if err != nil {
-			fmt.Println("FAILED")
-			return fmt.Errorf("saving data source: %w", err)
+	// Save .legibleconfig
+	var includes, excludes []string
+	if include != "" {
+		includes = []string{include}
+	}
+	if exclude != "" {
+		excludes = []string{exclude}
+	}
+	wcfg := legibleconfig.NewConfig(projectIDStr, includes, excludes)
+	wcfg.Filter.Select, wcfg.Filter.ExcludeSelect = f.Selects, f.ExcludeSelects
+	for _, r := range accepted {
+		wcfg.InferredRelations = append(wcfg.InferredRelations, r.Name)
+	}
+	if metadataMappingPath != "" {
+		if wcfg.MetadataMapping, err = projectRelativePath(path, metadataMappingPath); err != nil {
+			return err
+		}
+	}
+	wcfg.Profile, wcfg.DbtTarget, wcfg.IncludeStagingModels = profile, target, includeStagingModels
+	wcfg.IncludeSources, wcfg.IncludeSeeds, wcfg.IncludeSnapshots = includeSources, includeSeeds, includeSnapshots
+	if targetProject != "" {
+		if root.Targets == nil {
+			root.Targets = make(map[string]*legibleconfig.Config)
 		}
-		fmt.Println("OK")
+		root.Targets[targetProject] = wcfg
+	} else {
+		root = wcfg
+	}
+	if err := legibleconfig.Save(path, root); err != nil {
+		return fmt.Errorf("saving .legibleconfig: %w", err)
+	}
+	if err := legibleconfig.SaveSnapshot(path, targetProject, mdl); err != nil {
+		return err
 	}
 
-	// 3. Save tables as models
-	tableNames := make([]string, len(mdl.Models))
-	for i, m := range mdl.Models {
-		tableNames[i] = m.Name
+	fmt.Fprintf(progressOut(), "\nProject created successfully!\n")
+	fmt.Fprintf(progressOut(), "  Project ID: %d\n", project.ID)
+	fmt.Fprintf(progressOut(), "  Models: %d\n", len(mdl.Models))
+	fmt.Fprintf(progressOut(), "  Config: %s/.legibleconfig\n", path)
+	return nil
+}
+
+func runDbtUpdate(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("path")
+	targetProject, _ := cmd.Flags().GetString("target-project")
+	all, _ := cmd.Flags().GetBool("all")
+	var u dbtUpdateFlags
+	u.dryRun, _ = cmd.Flags().GetBool("dry-run")
+	u.yes, _ = cmd.Flags().GetBool("yes")
+	u.overwrite, _ = cmd.Flags().GetBool("overwrite")
+	u.preferDBT, _ = cmd.Flags().GetBool("prefer-dbt")
+	u.failOnConflict, _ = cmd.Flags().GetBool("fail-on-conflict")
+	u.metadataMappingChanged = cmd.Flags().Changed("metadata-mapping")
+	opts, err := dbtSyncOptionsFromFlags(cmd)
+	if err != nil {
+		return err
 	}
-	fmt.Printf("Importing %d models... ", len(tableNames))
-	if err := c.SaveTables(tableNames); err != nil {
-		fmt.Println("FAILED")
-		return fmt.Errorf("saving tables: %w", err)
+	if u.preferDBT && u.failOnConflict {
+		return fmt.Errorf("--prefer-dbt and --fail-on-conflict cannot be used together")
+	}
+	// JSON output cannot be mixed with a confirmation prompt
+	if jsonOutput && !u.yes && !u.dryRun {
+		return fmt.Errorf("--json cannot prompt for confirmation — add --yes to apply the update, or --dry-run to preview it")
 	}
-	fmt.Println("OK")
 
-	// 4. Save relationships (needs model/column IDs, so must come after SaveTables)
-	if len(mdl.Relationships) > 0 {
-		fmt.Printf("Saving %d relationships... ", len(mdl.Relationships))
-		relInputs := toMDLRelations(mdl.Relationships)
-		resolved, unresolved, err := c.ResolveRelations(relInputs)
+	filterChanged := false
+	for _, flag := range dbtFilterFlags {
+		filterChanged = filterChanged || cmd.Flags().Changed(flag)
+	}
+	if all {
+		if err := checkAllFlags(cmd); err != nil {
+			return err
+		}
+	}
+
+	// Load .legibleconfig
+	wcfg, err := legibleconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	names, err := selectTargets(wcfg, targetProject, all)
+	if err != nil {
+		return err
+	}
+
+	// Given filters replace the saved ones, which are saved on update and
+	// only previewed with --dry-run
+	if filterChanged {
+		target, err := wcfg.Target(names[0])
 		if err != nil {
-			fmt.Println("FAILED")
-			return fmt.Errorf("resolving relations: %w", err)
+			return err
 		}
-		if len(unresolved) > 0 {
-			fmt.Printf("(%d unresolved) ", len(unresolved))
-			for _, u := range unresolved {
-				fmt.Printf("\n  ⚠ %s", u)
-			}
-			fmt.Println()
+		setFilterFlags(cmd, &target.Filter)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	c, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !all {
+		_, err := updateTarget(c, path, wcfg, names[0], opts, u)
+		return err
+	}
+
+	// Update every target, carrying on past failures, and report on all of them
+	u.combined = true
+	var reports []dbtTargetReport
+	failed := 0
+	for _, name := range names {
+		if !jsonOutput {
+			fmt.Printf("\n=== %s ===\n", targetLabel(name))
 		}
-		if len(resolved) > 0 {
-			if err := c.SaveRelations(resolved); err != nil {
-				fmt.Println("FAILED")
-				return fmt.Errorf("saving relations: %w", err)
+		report, err := updateTarget(c, path, wcfg, name, opts, u)
+		if err != nil {
+			failed++
+			report.Status, report.Error = "failed", err.Error()
+			if !jsonOutput {
+				fmt.Printf("✗ %s: %v\n", targetLabel(name), err)
 			}
-			fmt.Printf("OK (%d saved)\n", len(resolved))
+		}
+		reports = append(reports, *report)
+	}
+	printTargetReports(reports)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d targets failed to update", failed, len(names))
+	}
+	return nil
+}
+
+// dbtUpdateFlags are the flags of dbt update that apply to each target.
+type dbtUpdateFlags struct {
+	dryRun                 bool
+	yes                    bool
+	overwrite              bool
+	preferDBT              bool
+	failOnConflict         bool
+	metadataMappingChanged bool
+	// combined is set when several targets are updated and reported together.
+	combined bool
+}
+
+// dbtTargetReport is the outcome of updating one target, for the combined
+// report of dbt update --all.
+type dbtTargetReport struct {
+	Target    string           `json:"target"`
+	ProjectID string           `json:"projectId"`
+	Status    string           `json:"status"`
+	Models    int              `json:"models"`
+	Changes   []mdlsync.Change `json:"changes"`
+	Conflicts int              `json:"conflicts"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// selectTargets returns the names of the targets to sync: the named one
+// ("" for the top-level project) or, with all, every one in .legibleconfig.
+func selectTargets(wcfg *legibleconfig.Config, targetProject string, all bool) ([]string, error) {
+	if all {
+		if targetProject != "" {
+			return nil, fmt.Errorf("--target-project and --all cannot be used together")
+		}
+		names := wcfg.AllTargets()
+		if len(names) == 0 {
+			return nil, fmt.Errorf(".legibleconfig links no projects")
+		}
+		return names, nil
+	}
+	if _, err := wcfg.Target(targetProject); err != nil {
+		return nil, err
+	}
+	return []string{targetProject}, nil
+}
+
+// dbtTargetSettingFlags are the flags of dbt update and watch for settings
+// that each target of .legibleconfig has of its own.
+var dbtTargetSettingFlags = []string{
+	"profile", "target", "include-staging-models", "include-sources", "include-seeds",
+	"include-snapshots", "metadata-mapping", "prefer-dbt",
+}
+
+// checkAllFlags rejects the flags for settings and filters of a target
+// with --all, which syncs every target with its own.
+func checkAllFlags(cmd *cobra.Command) error {
+	for _, flag := range slices.Concat(dbtTargetSettingFlags, dbtFilterFlags) {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s cannot be used with --all, which syncs each project with its own settings in .legibleconfig — set it there, or use --target-project", flag)
+		}
+	}
+	return nil
+}
+
+// targetLabel names a target in output; the top-level project is "default".
+func targetLabel(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+// progressOut is where the dbt commands report their progress: stdout, or
+// stderr with --json so that stdout holds only the JSON output.
+func progressOut() io.Writer {
+	if jsonOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// updateTarget re-converts the dbt project for one target of .legibleconfig
+// and merges it into the target's project, after showing the diff and
+// asking for confirmation.
+func updateTarget(c *client.Client, path string, wcfg *legibleconfig.Config, name string, opts dbtSyncOptions, u dbtUpdateFlags) (*dbtTargetReport, error) {
+	report := &dbtTargetReport{Target: targetLabel(name), Changes: []mdlsync.Change{}}
+	target, err := wcfg.Target(name)
+	if err != nil {
+		return report, err
+	}
+	report.ProjectID = target.WrenProject.ID
+
+	fmt.Fprintf(progressOut(), "Linked project ID: %s\n", target.WrenProject.ID)
+	if target.WrenProject.LastSynced != "" {
+		fmt.Fprintf(progressOut(), "Last synced: %s\n", target.WrenProject.LastSynced)
+	}
+
+	mdl, err := convertLinkedProject(path, target, opts)
+	if err != nil {
+		return report, err
+	}
+
+	c.SetProjectID(target.WrenProject.ID)
+	plan, err := mergeLinkedProject(c, path, name, mdl, u.overwrite, u.preferDBT || target.PreferDBT)
+	if err != nil {
+		return report, err
+	}
+	report.Models = len(plan.merge.Manifest.Models)
+	report.Changes = nonNilChanges(plan.merge.Changes)
+	report.Conflicts = len(plan.merge.Conflicts)
+
+	// Show diff: compare the current project with the models it will have.
+	// The combined report replaces it in JSON output.
+	if !u.combined || !jsonOutput {
+		showModelDiff(plan.server, plan.merge)
+	}
+
+	if u.dryRun {
+		report.Status = "dry-run"
+		if !jsonOutput {
+			fmt.Println("\nDry run — no changes applied.")
+		}
+		return report, nil
+	}
+	if u.failOnConflict {
+		if err := checkConflicts(plan.merge); err != nil {
+			return report, err
+		}
+	}
+
+	// Confirmation prompt
+	if !u.yes {
+		if u.overwrite {
+			fmt.Println("\n⚠ WARNING: This will replace the current MDL. Manual UI changes will be overwritten.")
+		}
+		if name != "" {
+			fmt.Printf("Proceed with update of %s? [y/N] ", name)
 		} else {
-			fmt.Println("SKIPPED (none resolved)")
+			fmt.Print("Proceed with update? [y/N] ")
+		}
+		var answer string
+		fmt.Scanln(&answer)
+		if answer != "y" && answer != "Y" {
+			fmt.Println("Aborted.")
+			report.Status = "skipped"
+			return report, nil
 		}
 	}
 
-	// 5. Update model metadata (descriptions from dbt)
-	syncModelMetadata(c, mdl)
+	if err := applyLinkedProject(c, plan, mdl, u.overwrite); err != nil {
+		return report, err
+	}
 
-	// 6. Deploy
-	fmt.Print("Deploying... ")
-	if _, err := c.Deploy(false); err != nil {
-		fmt.Println("FAILED")
-		return fmt.Errorf("deploying: %w", err)
+	// Update last_synced, and remember a newly given metadata mapping
+	if u.metadataMappingChanged {
+		if target.MetadataMapping, err = projectRelativePath(path, opts.MetadataMapping); err != nil {
+			return report, err
+		}
+	}
+	if err := recordSync(path, wcfg, name, mdl); err != nil {
+		return report, err
 	}
-	fmt.Println("OK")
+	report.Status = "updated"
 
-	// 7. Save .legibleconfig
-	var includes, excludes []string
-	if include != "" {
-		includes = []string{include}
+	fmt.Fprintf(progressOut(), "\nUpdate complete. Models: %d\n", len(plan.merge.Manifest.Models))
+	return report, nil
+}
+
+// printTargetReports prints the combined report of dbt update --all.
+func printTargetReports(reports []dbtTargetReport) {
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(map[string]interface{}{"targets": reports}) //nolint:errcheck
+		return
 	}
-	if exclude != "" {
-		excludes = []string{exclude}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tPROJECT\tSTATUS\tMODELS\tCHANGES\tCONFLICTS")
+	counts := make(map[string]int)
+	for _, r := range reports {
+		counts[r.Status]++
+		if r.Status == "failed" && r.Models == 0 {
+			fmt.Fprintf(w, "%s\t%s\t%s\t-\t-\t-\n", r.Target, r.ProjectID, r.Status)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\n", r.Target, r.ProjectID, r.Status, r.Models, len(r.Changes), r.Conflicts)
 	}
-	wcfg := legibleconfig.NewConfig(projectIDStr, includes, excludes)
-	if err := legibleconfig.Save(path, wcfg); err != nil {
-		return fmt.Errorf("saving .legibleconfig: %w", err)
+	w.Flush()
+	for _, r := range reports {
+		if r.Error != "" {
+			fmt.Printf("  ⚠ %s: %s\n", r.Target, r.Error)
+		}
 	}
 
-	fmt.Printf("\nProject created successfully!\n")
-	fmt.Printf("  Project ID: %d\n", project.ID)
-	fmt.Printf("  Models: %d\n", len(mdl.Models))
-	fmt.Printf("  Config: %s/.legibleconfig\n", path)
-	return nil
+	fmt.Printf("\nTargets: %d updated, %d dry-run, %d skipped, %d failed (%d total)\n",
+		counts["updated"], counts["dry-run"], counts["skipped"], counts["failed"], len(reports))
 }
 
-func runDbtUpdate(cmd *cobra.Command, args []string) error {
+func runDbtWatch(cmd *cobra.Command, args []string) error {
 	path, _ := cmd.Flags().GetString("path")
-	profile, _ := cmd.Flags().GetString("profile")
-	target, _ := cmd.Flags().GetString("target")
-	include, _ := cmd.Flags().GetString("include")
-	exclude, _ := cmd.Flags().GetString("exclude")
-	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	yes, _ := cmd.Flags().GetBool("yes")
-	includeStagingModels, _ := cmd.Flags().GetBool("include-staging-models")
+	preferDBT, _ := cmd.Flags().GetBool("prefer-dbt")
+	failOnConflict, _ := cmd.Flags().GetBool("fail-on-conflict")
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	targetProject, _ := cmd.Flags().GetString("target-project")
+	all, _ := cmd.Flags().GetBool("all")
+	opts, err := dbtSyncOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if preferDBT && failOnConflict {
+		return fmt.Errorf("--prefer-dbt and --fail-on-conflict cannot be used together")
+	}
+	if all {
+		if err := checkAllFlags(cmd); err != nil {
+			return err
+		}
+	}
+
+	// Load .legibleconfig
+	wcfg, err := legibleconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	names, err := selectTargets(wcfg, targetProject, all)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	c, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	syncNow := func() {
+		for _, name := range names {
+			if all {
+				watchLog("Syncing %s...", targetLabel(name))
+			}
+			if err := syncLinkedProject(c, path, name, opts, preferDBT, failOnConflict); err != nil {
+				watchLog("Sync failed: %v", err)
+			}
+		}
+	}
+
+	syncNow()
+
+	targetDir := filepath.Join(path, "target")
+	watchLog("Watching %s for dbt artifact changes (Ctrl+C to stop)...", targetDir)
+	err = dbtfilter.WatchArtifacts(cmd.Context(), targetDir, debounce, func(changed []string) {
+		watchLog("Changed: %s", strings.Join(changed, ", "))
+		syncNow()
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("Stopped watching.")
+	return nil
+}
 
-	// Filter flags can only be used with --dry-run
-	if (include != "" || exclude != "") && !dryRun {
-		return fmt.Errorf("--include and --exclude can only be used with --dry-run on update; edit .legibleconfig to change filters permanently")
+// syncLinkedProject re-converts a linked dbt project and merges it into
+// the target's project, applying and deploying the result only if anything
+// changed. It never prompts: conflicts are resolved as given by preferDBT,
+// or fail the sync with failOnConflict.
+func syncLinkedProject(c *client.Client, path, name string, opts dbtSyncOptions, preferDBT, failOnConflict bool) error {
+	// Reload .legibleconfig, whose filters may have been edited
+	wcfg, err := legibleconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	target, err := wcfg.Target(name)
+	if err != nil {
+		return err
+	}
+
+	mdl, err := convertLinkedProject(path, target, opts)
+	if err != nil {
+		return err
+	}
+	c.SetProjectID(target.WrenProject.ID)
+	plan, err := mergeLinkedProject(c, path, name, mdl, false, preferDBT || target.PreferDBT)
+	if err != nil {
+		return err
+	}
+	if len(plan.merge.Changes) == 0 {
+		watchLog("No changes to sync to project %s.", target.WrenProject.ID)
+		return nil
+	}
+
+	showModelDiff(plan.server, plan.merge)
+	if failOnConflict {
+		if err := checkConflicts(plan.merge); err != nil {
+			return err
+		}
+	}
+	if err := applyLinkedProject(c, plan, mdl, false); err != nil {
+		return err
+	}
+	if err := recordSync(path, wcfg, name, mdl); err != nil {
+		return err
+	}
+	watchLog("Synced %d changes to project %s. Models: %d", len(plan.merge.Changes), target.WrenProject.ID, len(plan.merge.Manifest.Models))
+	return nil
+}
+
+// watchLog prints a timestamped line of dbt watch output.
+func watchLog(format string, args ...interface{}) {
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+func runDbtExport(cmd *cobra.Command, args []string) error {
+	projectID, _ := cmd.Flags().GetString("project")
+	mdlPath, _ := cmd.Flags().GetString("mdl")
+	out, _ := cmd.Flags().GetString("out")
+	force, _ := cmd.Flags().GetBool("force")
+
+	if projectID != "" && mdlPath != "" {
+		return fmt.Errorf("--project and --mdl cannot be used together")
+	}
+
+	var mdl *dbt.LegibleMDLManifest
+	if mdlPath != "" {
+		var err error
+		if mdl, err = readDbtMDL(mdlPath); err != nil {
+			return err
+		}
+	} else {
+		c, cfg, err := newClientFromConfig()
+		if err != nil {
+			return err
+		}
+		if projectID == "" {
+			projectID = cfg.ProjectID
+		}
+		if projectID == "" {
+			return fmt.Errorf("no project selected — use --project or run: legible project use <id>")
+		}
+		c.SetProjectID(projectID)
+
+		models, err := c.ListModels()
+		if err != nil {
+			return fmt.Errorf("fetching project models: %w", err)
+		}
+		relations, err := c.ListRelations()
+		if err != nil {
+			return fmt.Errorf("fetching project relationships: %w", err)
+		}
+		mdl = mdlsync.FromServer(models, relations)
+	}
+
+	export, err := dbt.ExportDbtYAML(mdl)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"schema.yml", export.Schema},
+		{"semantic_models.yml", export.Semantic},
+	}
+	var written []string
+	for _, f := range files {
+		if f.data == nil {
+			continue
+		}
+		path := filepath.Join(out, f.name)
+		if _, err := os.Stat(path); err == nil && !force {
+			return fmt.Errorf("%s already exists — use --force to overwrite it", path)
+		}
+		written = append(written, path)
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", out, err)
+	}
+	for _, f := range files {
+		if f.data == nil {
+			continue
+		}
+		data := append([]byte("# Generated by legible dbt export.\n"), f.data...)
+		if err := os.WriteFile(filepath.Join(out, f.name), data, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+
+	if jsonOutput {
+		skipped := export.Skipped
+		if skipped == nil {
+			skipped = []string{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{
+			"files":          written,
+			"models":         export.Models,
+			"tests":          export.Tests,
+			"semanticModels": export.SemanticModels,
+			"metrics":        export.Metrics,
+			"skipped":        skipped,
+		})
+	}
+
+	for _, path := range written {
+		fmt.Printf("Wrote %s\n", path)
+	}
+	fmt.Printf("\nExported %d models, %d tests, %d semantic models and %d metrics.\n",
+		export.Models, export.Tests, export.SemanticModels, export.Metrics)
+	if len(export.Skipped) > 0 {
+		fmt.Printf("\nSkipped (no dbt equivalent):\n")
+		for _, s := range export.Skipped {
+			fmt.Printf("  ⚠ %s\n", s)
+		}
+	}
+	return nil
+}
+
+// dbtSyncOptions are the options of re-converting a linked dbt project.
+type dbtSyncOptions struct {
+	Profile              string
+	Target               string
+	IncludeStagingModels bool
+	IncludeSources       bool
+	IncludeSeeds         bool
+	IncludeSnapshots     bool
+	// MetadataMapping defaults to the one saved in .legibleconfig.
+	MetadataMapping string
+	Vars            map[string]interface{}
+}
+
+// dbtFilterFlags are the model filter flags of dbt create and update.
+var dbtFilterFlags = []string{"include", "exclude", "select", "exclude-select"}
+
+// setFilterFlags replaces the saved filters with the filter flags that are
+// given. An empty value removes the filter.
+func setFilterFlags(cmd *cobra.Command, filter *legibleconfig.Filter) {
+	lists := map[string]*[]string{
+		"include":        &filter.Include,
+		"exclude":        &filter.Exclude,
+		"select":         &filter.Select,
+		"exclude-select": &filter.ExcludeSelect,
+	}
+	for _, flag := range dbtFilterFlags {
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+		value, _ := cmd.Flags().GetString(flag)
+		*lists[flag] = nonEmpty(value)
 	}
+}
 
-	// Load .legibleconfig
-	wcfg, err := legibleconfig.Load(path)
-	if err != nil {
-		return err
+// dbtSyncOptionsFromFlags reads the conversion flags shared by dbt update and watch.
+func dbtSyncOptionsFromFlags(cmd *cobra.Command) (dbtSyncOptions, error) {
+	var opts dbtSyncOptions
+	opts.Profile, _ = cmd.Flags().GetString("profile")
+	opts.Target, _ = cmd.Flags().GetString("target")
+	opts.IncludeStagingModels, _ = cmd.Flags().GetBool("include-staging-models")
+	opts.IncludeSources, _ = cmd.Flags().GetBool("include-sources")
+	opts.IncludeSeeds, _ = cmd.Flags().GetBool("include-seeds")
+	opts.IncludeSnapshots, _ = cmd.Flags().GetBool("include-snapshots")
+	opts.MetadataMapping, _ = cmd.Flags().GetString("metadata-mapping")
+	vars, _ := cmd.Flags().GetString("vars")
+	var err error
+	opts.Vars, err = parseDbtVars(vars)
+	return opts, err
+}
+
+// parseDbtVars parses the --vars flag, a YAML dictionary like dbt's own --vars.
+func parseDbtVars(s string) (map[string]interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var vars map[string]interface{}
+	if err := yaml.Unmarshal([]byte(s), &vars); err != nil {
+		return nil, fmt.Errorf("--vars must be a YAML dictionary: %w", err)
 	}
+	return vars, nil
+}
 
-	fmt.Printf("Linked project ID: %s\n", wcfg.WrenProject.ID)
-	if wcfg.WrenProject.LastSynced != "" {
-		fmt.Printf("Last synced: %s\n", wcfg.WrenProject.LastSynced)
+// linkedConvertOptions returns the conversion options of a target of
+// .legibleconfig: the given flags, defaulting to the target's settings.
+func linkedConvertOptions(wcfg *legibleconfig.Config, opts dbtSyncOptions) dbt.ConvertOptions {
+	return dbt.ConvertOptions{
+		ProfileName:          cmp.Or(opts.Profile, wcfg.Profile),
+		Target:               cmp.Or(opts.Target, wcfg.DbtTarget),
+		RequireCatalog:       true,
+		IncludeStagingModels: opts.IncludeStagingModels || wcfg.IncludeStagingModels,
+		IncludeSources:       opts.IncludeSources || wcfg.IncludeSources,
+		IncludeSeeds:         opts.IncludeSeeds || wcfg.IncludeSeeds,
+		IncludeSnapshots:     opts.IncludeSnapshots || wcfg.IncludeSnapshots,
+		InferRelations:       len(wcfg.InferredRelations) > 0,
+		Vars:                 opts.Vars,
 	}
+}
 
+// convertLinkedProject re-converts a dbt project for a target of
+// .legibleconfig, with the target's dbt profile and settings, keeping the
+// inferred relationships accepted on create and applying the saved model
+// filters.
+func convertLinkedProject(path string, wcfg *legibleconfig.Config, opts dbtSyncOptions) (*dbt.LegibleMDLManifest, error) {
 	// Validate dbt project
 	if !dbt.IsDbtProjectValid(path) {
-		return fmt.Errorf("not a valid dbt project: %s (missing dbt_project.yml)", path)
+		return nil, fmt.Errorf("not a valid dbt project: %s (missing dbt_project.yml)", path)
+	}
+
+	// Use the saved metadata mapping unless one is given
+	metadataMappingPath := opts.MetadataMapping
+	if metadataMappingPath == "" && wcfg.MetadataMapping != "" {
+		metadataMappingPath = wcfg.MetadataMapping
+		if !filepath.IsAbs(metadataMappingPath) {
+			metadataMappingPath = filepath.Join(path, metadataMappingPath)
+		}
+	}
+	var metadata *dbt.MetadataMapping
+	if metadataMappingPath != "" {
+		var err error
+		if metadata, err = dbt.LoadMetadataMapping(metadataMappingPath); err != nil {
+			return nil, err
+		}
 	}
 
 	// Use a temp dir for converter output
 	tmpDir, err := os.MkdirTemp("", "legible-dbt-*")
 	if err != nil {
-		return fmt.Errorf("creating temp directory: %w", err)
+		return nil, fmt.Errorf("creating temp directory: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
 	// Re-convert dbt project
-	fmt.Println("Converting dbt project...")
-	_, err = dbt.ConvertDbtProjectCore(dbt.ConvertOptions{
-		ProjectPath:          path,
-		OutputDir:            tmpDir,
-		ProfileName:          profile,
-		Target:               target,
-		RequireCatalog:       true,
-		IncludeStagingModels: includeStagingModels,
-	})
+	fmt.Fprintln(progressOut(), "Converting dbt project...")
+	convertOpts := linkedConvertOptions(wcfg, opts)
+	convertOpts.ProjectPath, convertOpts.OutputDir, convertOpts.Metadata = path, tmpDir, metadata
+	result, err := dbt.ConvertDbtProjectCore(convertOpts)
 	if err != nil {
-		return fmt.Errorf("dbt conversion failed: %w", err)
+		return nil, fmt.Errorf("dbt conversion failed: %w", err)
 	}
 
 	// Read the generated MDL
 	mdl, err := readDbtMDL(filepath.Join(tmpDir, "legible-mdl.json"))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Use saved filters unless overridden for dry-run
-	activeIncludes := wcfg.Filter.Include
-	activeExcludes := wcfg.Filter.Exclude
-	if include != "" {
-		activeIncludes = []string{include}
+	// Keep the inferred relationships that were accepted on create, if they are still inferred
+	accepted := make(map[string]bool, len(wcfg.InferredRelations))
+	for _, name := range wcfg.InferredRelations {
+		accepted[name] = true
 	}
-	if exclude != "" {
-		activeExcludes = []string{exclude}
+	for _, r := range result.InferredRelationships {
+		if accepted[r.Name] {
+			mdl.Relationships = append(mdl.Relationships, r.Relationship)
+		}
+	}
+
+	// Apply the saved filters
+	f := dbtfilter.NewFilter(wcfg.Filter.Include, wcfg.Filter.Exclude)
+	f.Selects, f.ExcludeSelects = wcfg.Filter.Select, wcfg.Filter.ExcludeSelect
+	mdl.Models, err = applyModelFilter(path, mdl.Models, f, result.ModelNodeIDs)
+	if err != nil {
+		return nil, err
 	}
-	f := dbtfilter.NewFilter(activeIncludes, activeExcludes)
-	mdl.Models = applyModelFilter(mdl.Models, f)
 	if len(mdl.Models) == 0 {
-		return fmt.Errorf("no models matched the filter criteria")
+		return nil, fmt.Errorf("no models matched the filter criteria")
 	}
+	printValidationIssues(dbt.ValidateManifest(mdl), result.Validation)
+	return mdl, nil
+}
 
-	// --- API calls (needed early for diff) ---
-	cfg, err := config.Load()
+// linkedProjectSync is a dbt MDL merged into the current state of its project.
+type linkedProjectSync struct {
+	server          *dbt.LegibleMDLManifest
+	serverModels    []client.Model
+	serverRelations []client.Relation
+	merge           *mdlsync.MergeResult
+}
+
+// mergeLinkedProject fetches the project's models and merges the dbt MDL
+// into them, keeping the changes made in the UI since the last sync, unless
+// they are to be overwritten.
+func mergeLinkedProject(c *client.Client, path, target string, mdl *dbt.LegibleMDLManifest, overwrite, preferDBT bool) (*linkedProjectSync, error) {
+	serverModels, err := c.ListModels()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return nil, fmt.Errorf("fetching project models: %w", err)
+	}
+	serverRelations, err := c.ListRelations()
+	if err != nil {
+		return nil, fmt.Errorf("fetching project relationships: %w", err)
 	}
-	c, err := client.New(cfg)
+	plan := &linkedProjectSync{
+		server:          mdlsync.FromServer(serverModels, serverRelations),
+		serverModels:    serverModels,
+		serverRelations: serverRelations,
+	}
+	if overwrite {
+		plan.merge = &mdlsync.MergeResult{Manifest: mdl, Changes: mdlsync.Compare(plan.server, mdl)}
+		return plan, nil
+	}
+	snapshot, err := legibleconfig.LoadSnapshot(path, target)
 	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil && !jsonOutput {
+		fmt.Printf("No %s from the last sync: dbt values replace the project's, and calculated fields and relationships added in the UI are kept.\n", legibleconfig.SnapshotFile(target))
+	}
+	plan.merge = mdlsync.Merge(snapshot, plan.server, mdl, preferDBT)
+	return plan, nil
+}
+
+// checkConflicts fails a merge that has conflicts with changes made in the
+// UI, for --fail-on-conflict.
+func checkConflicts(merge *mdlsync.MergeResult) error {
+	if len(merge.Conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d conflicts with changes made in the UI — nothing applied; resolve them in the UI or dbt, or update with --prefer-dbt", len(merge.Conflicts))
+}
+
+// applyLinkedProject applies a merge, or replaces the project's models with
+// the dbt MDL when overwriting, and deploys the project.
+func applyLinkedProject(c *client.Client, plan *linkedProjectSync, mdl *dbt.LegibleMDLManifest, overwrite bool) error {
+	if overwrite {
+		if err := replaceModels(c, mdl); err != nil {
+			return err
+		}
+	} else if err := applyMerge(c, plan.merge, plan.serverModels, plan.serverRelations); err != nil {
 		return err
 	}
-	c.SetProjectID(wcfg.WrenProject.ID)
 
-	// Show diff: compare incoming dbt models with current server models
-	showModelDiff(c, mdl)
+	// Deploy
+	fmt.Fprint(progressOut(), "Deploying... ")
+	if _, err := c.Deploy(false); err != nil {
+		fmt.Fprintln(progressOut(), "FAILED")
+		return fmt.Errorf("deploying: %w", err)
+	}
+	fmt.Fprintln(progressOut(), "OK")
+	return nil
+}
 
-	if dryRun {
-		fmt.Println("\nDry run — no changes applied.")
-		return nil
+// recordSync updates the target's last_synced in .legibleconfig and saves
+// the dbt MDL as the snapshot its next update merges from.
+func recordSync(path string, wcfg *legibleconfig.Config, name string, mdl *dbt.LegibleMDLManifest) error {
+	target, err := wcfg.Target(name)
+	if err != nil {
+		return err
+	}
+	target.TouchSynced()
+	if err := legibleconfig.Save(path, wcfg); err != nil {
+		return fmt.Errorf("saving .legibleconfig: %w", err)
 	}
+	return legibleconfig.SaveSnapshot(path, name, mdl)
+}
 
-	// Confirmation prompt
-	if !yes {
-		fmt.Println("\n⚠ WARNING: This will replace the current MDL. Manual UI changes will be overwritten.")
-		fmt.Print("Proceed with update? [y/N] ")
-		var answer string
-		fmt.Scanln(&answer)
-		if answer != "y" && answer != "Y" {
-			fmt.Println("Aborted.")
-			return nil
+// --- helpers ---
+
+// createProjectFromMDL creates a project with the MDL's models and
+// relationships: it creates the project, configures its data source if one
+// is given, imports the models' tables, saves the relationships and the
+// models' metadata, and deploys. The client is switched to the new project.
+func createProjectFromMDL(c *client.Client, name string, dsInput *client.SaveDataSourceInput, mdl *dbt.LegibleMDLManifest) (*client.Project, error) {
+	// 1. Create project
+	fmt.Fprintf(progressOut(), "\nCreating project %q... ", name)
+	project, err := c.CreateProject(name)
+	if err != nil {
+		fmt.Fprintln(progressOut(), "FAILED")
+		return nil, err
+	}
+	fmt.Fprintf(progressOut(), "OK (ID: %d)\n", project.ID)
+
+	// Switch client context to the new project
+	projectIDStr := strconv.Itoa(project.ID)
+	c.SetProjectID(projectIDStr)
+
+	// 2. Save data source (if available)
+	if dsInput != nil {
+		fmt.Fprintf(progressOut(), "Configuring %s data source... ", dsInput.Type)
+		_, err = c.SaveDataSource(dsInput)
+		if err != nil {
+			fmt.Fprintln(progressOut(), "FAILED")
+			return nil, fmt.Errorf("saving data source: %w", err)
 		}
+		fmt.Fprintln(progressOut(), "OK")
 	}
 
-	// Save tables
+	// 3. Save tables as models
 	tableNames := make([]string, len(mdl.Models))
 	for i, m := range mdl.Models {
 		tableNames[i] = m.Name
 	}
-	fmt.Printf("Updating %d models... ", len(tableNames))
+	fmt.Fprintf(progressOut(), "Importing %d models... ", len(tableNames))
 	if err := c.SaveTables(tableNames); err != nil {
-		fmt.Println("FAILED")
-		return fmt.Errorf("saving tables: %w", err)
+		fmt.Fprintln(progressOut(), "FAILED")
+		return nil, fmt.Errorf("saving tables: %w", err)
 	}
-	fmt.Println("OK")
+	fmt.Fprintln(progressOut(), "OK")
 
-	// Save relationships
-	if len(mdl.Relationships) > 0 {
-		fmt.Printf("Saving %d relationships... ", len(mdl.Relationships))
-		relInputs := toMDLRelations(mdl.Relationships)
-		resolved, unresolved, err := c.ResolveRelations(relInputs)
-		if err != nil {
-			fmt.Println("FAILED")
-			return fmt.Errorf("resolving relations: %w", err)
-		}
-		if len(unresolved) > 0 {
-			fmt.Printf("(%d unresolved) ", len(unresolved))
-			for _, u := range unresolved {
-				fmt.Printf("\n  ⚠ %s", u)
-			}
-			fmt.Println()
-		}
-		if len(resolved) > 0 {
-			if err := c.SaveRelations(resolved); err != nil {
-				fmt.Println("FAILED")
-				return fmt.Errorf("saving relations: %w", err)
-			}
-			fmt.Printf("OK (%d saved)\n", len(resolved))
-		} else {
-			fmt.Println("SKIPPED (none resolved)")
-		}
+	// 4. Save relationships (needs model/column IDs, so must come after SaveTables)
+	if err := saveRelationships(c, mdl.Relationships); err != nil {
+		return nil, err
 	}
 
-	// Update model metadata (descriptions from dbt)
+	// 5. Update model metadata (display names and descriptions)
 	syncModelMetadata(c, mdl)
 
-	// Deploy
-	fmt.Print("Deploying... ")
+	// 6. Deploy
+	fmt.Fprint(progressOut(), "Deploying... ")
 	if _, err := c.Deploy(false); err != nil {
-		fmt.Println("FAILED")
-		return fmt.Errorf("deploying: %w", err)
-	}
-	fmt.Println("OK")
-
-	// Update last_synced
-	wcfg.TouchSynced()
-	if err := legibleconfig.Save(path, wcfg); err != nil {
-		return fmt.Errorf("saving .legibleconfig: %w", err)
+		fmt.Fprintln(progressOut(), "FAILED")
+		return nil, fmt.Errorf("deploying: %w", err)
 	}
-
-	fmt.Printf("\nUpdate complete. Models: %d\n", len(mdl.Models))
-	return nil
+	fmt.Fprintln(progressOut(), "OK")
+	return project, nil
 }
 
-// --- helpers ---
-
 // readDbtMDL reads and parses the legible-mdl.json output from the converter.
 func readDbtMDL(path string) (*dbt.LegibleMDLManifest, error) {
 	data, err := os.ReadFile(path)
@@ -453,21 +1257,79 @@ func readDbtDataSource(path string) (*client.SaveDataSourceInput, error) {
 	if err := json.Unmarshal(data, &ds); err != nil {
 		return nil, fmt.Errorf("parsing data source file: %w", err)
 	}
+	if property := unresolvedProperty(ds.Properties); property != "" {
+		return nil, fmt.Errorf("data source property %q is an unresolved template; it must hold the value to upload", property)
+	}
 	return &ds, nil
 }
 
-// applyModelFilter filters LegibleModel slices using a ModelFilter.
-func applyModelFilter(models []dbt.LegibleModel, f *dbtfilter.ModelFilter) []dbt.LegibleModel {
+// unresolvedProperty returns the name of a data source property that still
+// holds a Jinja expression such as {{ env_var('DBT_ENV_SECRET_PASSWORD') }},
+// or "" if there is none.
+func unresolvedProperty(properties map[string]interface{}) string {
+	for name, value := range properties {
+		switch typed := value.(type) {
+		case string:
+			if strings.Contains(typed, "{{") && strings.Contains(typed, "}}") {
+				return name
+			}
+		case map[string]interface{}:
+			if nested := unresolvedProperty(typed); nested != "" {
+				return name + "." + nested
+			}
+		}
+	}
+	return ""
+}
+
+// applyModelFilter filters LegibleModel slices using a ModelFilter. dbt
+// selectors are evaluated against the project's target/manifest.json, and
+// matched to models by the dbt node each model was converted from.
+func applyModelFilter(projectPath string, models []dbt.LegibleModel, f *dbtfilter.ModelFilter, nodeIDs map[string]string) ([]dbt.LegibleModel, error) {
 	if f.IsEmpty() {
-		return models
+		return models, nil
+	}
+	if f.HasSelectors() {
+		manifest, err := dbtfilter.LoadManifest(filepath.Join(projectPath, "target", "manifest.json"))
+		if err != nil {
+			return nil, err
+		}
+		if err := f.Resolve(manifest); err != nil {
+			return nil, err
+		}
 	}
 	var result []dbt.LegibleModel
 	for _, m := range models {
-		if f.Match(m.Name) {
+		if f.MatchNode(m.Name, nodeIDs[m.Name]) {
 			result = append(result, m)
 		}
 	}
-	return result
+	return result, nil
+}
+
+// projectRelativePath returns a file's path relative to the dbt project, for .legibleconfig.
+func projectRelativePath(projectPath, file string) (string, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", projectPath, err)
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", file, err)
+	}
+	rel, err := filepath.Rel(absProject, absFile)
+	if err != nil {
+		return absFile, nil
+	}
+	return rel, nil
+}
+
+// nonEmpty wraps a flag value in a slice, or returns nil if it is empty.
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
 }
 
 // toMDLRelations converts dbt Relationship structs to client MDLRelation structs.
@@ -484,8 +1346,9 @@ func toMDLRelations(rels []dbt.Relationship) []client.MDLRelation {
 	return out
 }
 
-// syncModelMetadata pushes model/column descriptions from the dbt MDL to the API.
-// It matches models by name and updates descriptions found in Properties["description"].
+// syncModelMetadata pushes model/column display names and descriptions from the dbt MDL to the API.
+// It matches models by name and updates descriptions found in Properties["description"],
+// and display names from Properties["displayName"] for models and DisplayName for columns.
 func syncModelMetadata(c *client.Client, mdl *dbt.LegibleMDLManifest) {
 	// Build a lookup of dbt models by name
 	dbtModelByName := make(map[string]*dbt.LegibleModel, len(mdl.Models))
@@ -496,7 +1359,7 @@ func syncModelMetadata(c *client.Client, mdl *dbt.LegibleMDLManifest) {
 	// Fetch the server-side models (with IDs)
 	serverModels, err := c.ListModels()
 	if err != nil {
-		fmt.Printf("  ⚠ Could not fetch models for metadata sync: %v\n", err)
+		fmt.Fprintf(progressOut(), "  ⚠ Could not fetch models for metadata sync: %v\n", err)
 		return
 	}
 
@@ -510,25 +1373,30 @@ func syncModelMetadata(c *client.Client, mdl *dbt.LegibleMDLManifest) {
 		input := &client.UpdateModelMetadataInput{}
 		hasUpdates := false
 
-		// Model description
+		// Model display name and description
+		if displayName := dm.Properties["displayName"]; displayName != "" {
+			input.DisplayName = displayName
+			hasUpdates = true
+		}
 		if desc, ok := dm.Properties["description"]; ok && desc != "" {
 			input.Description = desc
 			hasUpdates = true
 		}
 
-		// Column descriptions
-		colDescByName := make(map[string]string)
-		for _, col := range dm.Columns {
-			if desc, ok := col.Properties["description"]; ok && desc != "" {
-				colDescByName[col.Name] = desc
+		// Column display names and descriptions
+		colByName := make(map[string]*dbt.LegibleColumn)
+		for i, col := range dm.Columns {
+			if col.DisplayName != "" || col.Properties["description"] != "" {
+				colByName[col.Name] = &dm.Columns[i]
 			}
 		}
-		if len(colDescByName) > 0 {
+		if len(colByName) > 0 {
 			for _, f := range sm.Fields {
-				if desc, ok := colDescByName[f.ReferenceName]; ok {
+				if col, ok := colByName[f.ReferenceName]; ok {
 					input.Columns = append(input.Columns, client.UpdateColumnMetadataInput{
 						ID:          f.ID,
-						Description: desc,
+						DisplayName: col.DisplayName,
+						Description: col.Properties["description"],
 					})
 				}
 			}
@@ -539,7 +1407,7 @@ func syncModelMetadata(c *client.Client, mdl *dbt.LegibleMDLManifest) {
 
 		if hasUpdates {
 			if err := c.UpdateModelMetadata(sm.ID, input); err != nil {
-				fmt.Printf("  ⚠ Failed to update metadata for %s: %v\n", sm.ReferenceName, err)
+				fmt.Fprintf(progressOut(), "  ⚠ Failed to update metadata for %s: %v\n", sm.ReferenceName, err)
 			} else {
 				updated++
 			}
@@ -547,13 +1415,79 @@ func syncModelMetadata(c *client.Client, mdl *dbt.LegibleMDLManifest) {
 	}
 
 	if updated > 0 {
-		fmt.Printf("Updated metadata for %d models.\n", updated)
+		fmt.Fprintf(progressOut(), "Updated metadata for %d models.\n", updated)
+	}
+}
+
+// inferredForModels returns the inferred relationships between models that are imported.
+func inferredForModels(inferred []dbt.InferredRelationship, models []dbt.LegibleModel) []dbt.InferredRelationship {
+	imported := make(map[string]bool, len(models))
+	for _, m := range models {
+		imported[m.Name] = true
 	}
+	out := []dbt.InferredRelationship{}
+	for _, r := range inferred {
+		if len(r.Models) == 2 && imported[r.Models[0]] && imported[r.Models[1]] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// reviewInferredRelations asks whether to add each inferred relationship,
+// reading the answers from in, and returns the accepted ones. It fails when
+// in runs out of answers rather than rejecting the rest.
+func reviewInferredRelations(in io.Reader, inferred []dbt.InferredRelationship) ([]dbt.Relationship, error) {
+	if len(inferred) == 0 {
+		return nil, nil
+	}
+	out := progressOut()
+	fmt.Fprintf(out, "\nReview %d inferred relationships:\n", len(inferred))
+	var accepted []dbt.Relationship
+	acceptAll := false
+	for i, r := range inferred {
+		if !acceptAll {
+			fmt.Fprintf(out, "\n[%d/%d] %s (%s, confidence %.2f)\n", i+1, len(inferred), r.Name, r.JoinType, r.Confidence)
+			fmt.Fprintf(out, "  %s\n  %s\n", r.Condition, r.Reason)
+			fmt.Fprint(out, "Add this relationship? [y/N/a=all remaining/q=none remaining] ")
+			var answer string
+			if _, err := fmt.Fscanln(in, &answer); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				fmt.Fprintln(out)
+				return nil, fmt.Errorf("no answer to review the inferred relationships — use --min-confidence to add them without reviewing")
+			}
+			switch answer {
+			case "y", "Y":
+			case "a", "A":
+				acceptAll = true
+			case "q", "Q":
+				fmt.Fprintf(out, "Accepted %d inferred relationships.\n", len(accepted))
+				return accepted, nil
+			default:
+				continue
+			}
+		}
+		accepted = append(accepted, r.Relationship)
+	}
+	fmt.Fprintf(out, "Accepted %d inferred relationships.\n", len(accepted))
+	return accepted, nil
+}
+
+// acceptInferredRelations returns the inferred relationships with at least
+// the given confidence, for --min-confidence.
+func acceptInferredRelations(inferred []dbt.InferredRelationship, minConfidence float64) []dbt.Relationship {
+	var accepted []dbt.Relationship
+	for _, r := range inferred {
+		if r.Confidence >= minConfidence {
+			accepted = append(accepted, r.Relationship)
+		}
+	}
+	fmt.Fprintf(progressOut(), "Accepted %d of %d inferred relationships with confidence %.2f or more.\n", len(accepted), len(inferred), minConfidence)
+	return accepted
 }
 
-// printCreateSummary prints a table of models to sync (for create dry-run or fallback).
-// Supports --json output.
-func printCreateSummary(mdl *dbt.LegibleMDLManifest) {
+// printCreateSummary prints a table of models to sync (for create dry-run or fallback),
+// and the inferred relationships to review, if any. Supports --json output.
+func printCreateSummary(mdl *dbt.LegibleMDLManifest, inferred []dbt.InferredRelationship) {
 	type modelEntry struct {
 		Name    string `json:"name"`
 		Columns int    `json:"columns"`
@@ -572,6 +1506,9 @@ func printCreateSummary(mdl *dbt.LegibleMDLManifest) {
 				"total": len(entries),
 			},
 		}
+		if inferred != nil {
+			result["inferredRelationships"] = inferred
+		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		enc.Encode(result) //nolint:errcheck
@@ -590,79 +1527,86 @@ func printCreateSummary(mdl *dbt.LegibleMDLManifest) {
 	if len(mdl.Relationships) > 0 {
 		fmt.Printf("Relationships: %d\n", len(mdl.Relationships))
 	}
-}
-
-// showModelDiff fetches current server models and compares with the incoming dbt models,
-// printing a table with status (+/-/unchanged) per model. Supports --json output.
-func showModelDiff(c *client.Client, mdl *dbt.LegibleMDLManifest) {
-	serverModels, err := c.ListModels()
-	if err != nil {
-		// Non-fatal: fall back to plain create-style summary
-		printCreateSummary(mdl)
-		return
-	}
 
-	// Build sets
-	serverSet := make(map[string]*client.Model, len(serverModels))
-	for i := range serverModels {
-		serverSet[serverModels[i].ReferenceName] = &serverModels[i]
-	}
-	incomingSet := make(map[string]*dbt.LegibleModel, len(mdl.Models))
-	for i := range mdl.Models {
-		incomingSet[mdl.Models[i].Name] = &mdl.Models[i]
+	if inferred != nil {
+		fmt.Printf("\nInferred relationships: %d\n", len(inferred))
+		if len(inferred) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CONFIDENCE\tRELATIONSHIP\tJOIN TYPE\tREASON")
+			for _, r := range inferred {
+				fmt.Fprintf(w, "%.2f\t%s\t%s\t%s\n", r.Confidence, r.Name, r.JoinType, r.Reason)
+			}
+			w.Flush()
+		}
 	}
+}
 
+// showModelDiff compares the project's current models with the ones it will
+// have after the update, printing a table with status (+/-/~/unchanged) per
+// model, the column, calculated field and relationship changes, the UI
+// changes that are kept and any conflicts. Supports --json output.
+func showModelDiff(server *dbt.LegibleMDLManifest, merge *mdlsync.MergeResult) {
 	type diffEntry struct {
 		Name    string `json:"name"`
 		Status  string `json:"status"`
 		Columns int    `json:"columns"`
 	}
 
+	changed := make(map[string]bool)
+	for _, ch := range merge.Changes {
+		if ch.Model != "" {
+			changed[ch.Model] = true
+		}
+	}
+	serverSet := make(map[string]bool, len(server.Models))
+	for _, m := range server.Models {
+		serverSet[m.Name] = true
+	}
+	targetSet := make(map[string]bool, len(merge.Manifest.Models))
+	for _, m := range merge.Manifest.Models {
+		targetSet[m.Name] = true
+	}
+
 	var entries []diffEntry
-	for _, m := range mdl.Models {
-		status := "add"
-		if serverSet[m.Name] != nil {
-			status = "unchanged"
-		}
-		entries = append(entries, diffEntry{
-			Name:    m.Name,
-			Status:  status,
-			Columns: len(m.Columns),
-		})
+	for _, m := range merge.Manifest.Models {
+		status := "unchanged"
+		switch {
+		case !serverSet[m.Name]:
+			status = "add"
+		case changed[m.Name]:
+			status = "change"
+		}
+		entries = append(entries, diffEntry{Name: m.Name, Status: status, Columns: len(m.Columns)})
 	}
-	for _, sm := range serverModels {
-		if incomingSet[sm.ReferenceName] == nil {
-			entries = append(entries, diffEntry{
-				Name:    sm.ReferenceName,
-				Status:  "remove",
-				Columns: len(sm.Fields),
-			})
+	for _, m := range server.Models {
+		if !targetSet[m.Name] {
+			entries = append(entries, diffEntry{Name: m.Name, Status: "remove", Columns: len(m.Columns)})
 		}
 	}
 
-	var addCount, removeCount, unchangedCount int
+	counts := make(map[string]int)
 	for _, e := range entries {
-		switch e.Status {
-		case "add":
-			addCount++
-		case "remove":
-			removeCount++
-		case "unchanged":
-			unchangedCount++
-		}
+		counts[e.Status]++
 	}
 
 	if jsonOutput {
 		result := map[string]interface{}{
 			"models":        entries,
-			"relationships": len(mdl.Relationships),
+			"relationships": len(merge.Manifest.Relationships),
+			"changes":       nonNilChanges(merge.Changes),
+			"kept":          nonNilChanges(merge.Kept),
+			"conflicts":     merge.Conflicts,
 			"summary": map[string]int{
-				"add":       addCount,
-				"remove":    removeCount,
-				"unchanged": unchangedCount,
+				"add":       counts["add"],
+				"remove":    counts["remove"],
+				"change":    counts["change"],
+				"unchanged": counts["unchanged"],
 				"total":     len(entries),
 			},
 		}
+		if merge.Conflicts == nil {
+			result["conflicts"] = []mdlsync.Conflict{}
+		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		enc.Encode(result) //nolint:errcheck
@@ -679,13 +1623,225 @@ func showModelDiff(c *client.Client, mdl *dbt.LegibleMDLManifest) {
 			marker = "+"
 		case "remove":
 			marker = "-"
+		case "change":
+			marker = "~"
 		}
 		fmt.Fprintf(w, "%s\t%s\t%d\n", marker, e.Name, e.Columns)
 	}
 	w.Flush()
 
-	fmt.Printf("\nSummary: %d added, %d removed, %d unchanged (%d total)\n", addCount, removeCount, unchangedCount, len(entries))
-	if len(mdl.Relationships) > 0 {
-		fmt.Printf("Relationships: %d\n", len(mdl.Relationships))
+	printChanges("\nChanges:", merge.Changes)
+	printChanges("\nKept from the UI:", merge.Kept)
+	if len(merge.Conflicts) > 0 {
+		fmt.Println("\nConflicts (changed in both the UI and dbt):")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tOBJECT\tFIELD\tUI\tDBT\tKEPT")
+		for _, c := range merge.Conflicts {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Kind, c.Object(), c.Field, c.Server, c.DBT, c.Kept)
+		}
+		w.Flush()
+	}
+
+	fmt.Printf("\nSummary: %d added, %d removed, %d changed, %d unchanged (%d total)\n",
+		counts["add"], counts["remove"], counts["change"], counts["unchanged"], len(entries))
+	if len(merge.Manifest.Relationships) > 0 {
+		fmt.Printf("Relationships: %d\n", len(merge.Manifest.Relationships))
+	}
+}
+
+// printChanges prints a table of changes under a title, if there are any.
+func printChanges(title string, changes []mdlsync.Change) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Println(title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tKIND\tOBJECT\tDETAIL")
+	for _, ch := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ch.Action, ch.Kind, ch.Object(), ch.Detail())
+	}
+	w.Flush()
+}
+
+func nonNilChanges(changes []mdlsync.Change) []mdlsync.Change {
+	if changes == nil {
+		return []mdlsync.Change{}
+	}
+	return changes
+}
+
+// replaceModels replaces the project's models and relationships with the dbt
+// ones, discarding changes made in the UI.
+func replaceModels(c *client.Client, mdl *dbt.LegibleMDLManifest) error {
+	// Save tables
+	tableNames := make([]string, len(mdl.Models))
+	for i, m := range mdl.Models {
+		tableNames[i] = m.Name
+	}
+	fmt.Fprintf(progressOut(), "Updating %d models... ", len(tableNames))
+	if err := c.SaveTables(tableNames); err != nil {
+		fmt.Fprintln(progressOut(), "FAILED")
+		return fmt.Errorf("saving tables: %w", err)
+	}
+	fmt.Fprintln(progressOut(), "OK")
+
+	// Save relationships
+	if err := saveRelationships(c, mdl.Relationships); err != nil {
+		return err
+	}
+
+	// Update model metadata (descriptions from dbt)
+	syncModelMetadata(c, mdl)
+	return nil
+}
+
+// applyMerge applies the changes of a merge to the project model by model,
+// so that the calculated fields and relationships of the models it keeps
+// are not recreated.
+func applyMerge(c *client.Client, merge *mdlsync.MergeResult, serverModels []client.Model, serverRelations []client.Relation) error {
+	modelByName := make(map[string]*client.Model, len(serverModels))
+	for i := range serverModels {
+		modelByName[serverModels[i].ReferenceName] = &serverModels[i]
+	}
+	targetByName := make(map[string]*dbt.LegibleModel, len(merge.Manifest.Models))
+	for i := range merge.Manifest.Models {
+		targetByName[merge.Manifest.Models[i].Name] = &merge.Manifest.Models[i]
+	}
+	relationByKey := make(map[string]*client.Relation, len(serverRelations))
+	for i := range serverRelations {
+		relationByKey[mdlsync.RelationshipKey(mdlsync.ServerRelationship(serverRelations[i]))] = &serverRelations[i]
+	}
+	targetRelations := make(map[string]dbt.Relationship, len(merge.Manifest.Relationships))
+	for _, r := range merge.Manifest.Relationships {
+		targetRelations[mdlsync.RelationshipKey(r)] = r
+	}
+
+	var added, removed, updated []string
+	var addedRelations []dbt.Relationship
+	updatedModels := make(map[string]bool)
+	for _, ch := range merge.Changes {
+		switch ch.Kind {
+		case mdlsync.KindModel:
+			switch {
+			case ch.Action == mdlsync.ActionAdd:
+				added = append(added, ch.Model)
+			case ch.Action == mdlsync.ActionRemove:
+				removed = append(removed, ch.Model)
+			case ch.Field == "primaryKey" && !updatedModels[ch.Model]:
+				updatedModels[ch.Model] = true
+				updated = append(updated, ch.Model)
+			}
+		case mdlsync.KindColumn:
+			if ch.Action != mdlsync.ActionChange && !updatedModels[ch.Model] {
+				updatedModels[ch.Model] = true
+				updated = append(updated, ch.Model)
+			}
+		case mdlsync.KindRelationship:
+			rel := relationByKey[ch.Name]
+			switch ch.Action {
+			case mdlsync.ActionAdd:
+				addedRelations = append(addedRelations, targetRelations[ch.Name])
+			case mdlsync.ActionRemove:
+				fmt.Fprintf(progressOut(), "Removing relationship %s... ", ch.Name)
+				if err := c.DeleteRelation(rel.RelationID); err != nil {
+					fmt.Fprintln(progressOut(), "FAILED")
+					return err
+				}
+				fmt.Fprintln(progressOut(), "OK")
+			case mdlsync.ActionChange:
+				joinType := mdlsync.OrientJoinType(mdlsync.ServerRelationship(*rel), ch.To)
+				fmt.Fprintf(progressOut(), "Changing relationship %s to %s... ", ch.Name, joinType)
+				if err := c.UpdateRelation(rel.RelationID, joinType); err != nil {
+					fmt.Fprintln(progressOut(), "FAILED")
+					return err
+				}
+				fmt.Fprintln(progressOut(), "OK")
+			}
+		}
+		// Calculated fields are never recreated, so there is nothing to do for them.
+	}
+
+	for _, name := range removed {
+		fmt.Fprintf(progressOut(), "Removing model %s... ", name)
+		if err := c.DeleteModel(modelByName[name].ID); err != nil {
+			fmt.Fprintln(progressOut(), "FAILED")
+			return err
+		}
+		fmt.Fprintln(progressOut(), "OK")
+	}
+	for _, name := range added {
+		model := targetByName[name]
+		fmt.Fprintf(progressOut(), "Adding model %s... ", name)
+		if err := c.CreateModel(&client.CreateModelInput{
+			SourceTableName: name,
+			Fields:          physicalColumns(model),
+			PrimaryKey:      model.PrimaryKey,
+		}); err != nil {
+			fmt.Fprintln(progressOut(), "FAILED")
+			return err
+		}
+		fmt.Fprintln(progressOut(), "OK")
+	}
+	for _, name := range updated {
+		model := targetByName[name]
+		fmt.Fprintf(progressOut(), "Updating columns of %s... ", name)
+		if err := c.UpdateModel(modelByName[name].ID, &client.UpdateModelInput{
+			Fields:     physicalColumns(model),
+			PrimaryKey: model.PrimaryKey,
+		}); err != nil {
+			fmt.Fprintln(progressOut(), "FAILED")
+			return err
+		}
+		fmt.Fprintln(progressOut(), "OK")
+	}
+
+	// Relationships need the IDs of added models and columns, so they come last
+	if err := saveRelationships(c, addedRelations); err != nil {
+		return err
+	}
+
+	// Update model metadata (merged descriptions and display names)
+	syncModelMetadata(c, merge.Manifest)
+	return nil
+}
+
+// saveRelationships resolves relationships to model and column IDs and saves them.
+func saveRelationships(c *client.Client, rels []dbt.Relationship) error {
+	if len(rels) == 0 {
+		return nil
+	}
+	fmt.Fprintf(progressOut(), "Saving %d relationships... ", len(rels))
+	resolved, unresolved, err := c.ResolveRelations(toMDLRelations(rels))
+	if err != nil {
+		fmt.Fprintln(progressOut(), "FAILED")
+		return fmt.Errorf("resolving relations: %w", err)
+	}
+	if len(unresolved) > 0 {
+		fmt.Fprintf(progressOut(), "(%d unresolved) ", len(unresolved))
+		for _, u := range unresolved {
+			fmt.Fprintf(progressOut(), "\n  ⚠ %s", u)
+		}
+		fmt.Fprintln(progressOut())
+	}
+	if len(resolved) == 0 {
+		fmt.Fprintln(progressOut(), "SKIPPED (none resolved)")
+		return nil
+	}
+	if err := c.SaveRelations(resolved); err != nil {
+		fmt.Fprintln(progressOut(), "FAILED")
+		return fmt.Errorf("saving relations: %w", err)
+	}
+	fmt.Fprintf(progressOut(), "OK (%d saved)\n", len(resolved))
+	return nil
+}
+
+// physicalColumns returns the names of a model's columns that are not calculated.
+func physicalColumns(model *dbt.LegibleModel) []string {
+	var names []string
+	for _, col := range model.Columns {
+		if !col.IsCalculated {
+			names = append(names, col.Name)
+		}
 	}
+	return names
 }
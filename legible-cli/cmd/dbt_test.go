@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/legibleconfig"
+	"github.com/Kubeworkz/legible/legible-cli/internal/mdlsync"
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+	"github.com/spf13/cobra"
+)
+
+func TestLinkedConvertOptions(t *testing.T) {
+	// dbt create --include-sources --target-project finance saves the flag
+	// in the target of .legibleconfig
+	dir := t.TempDir()
+	created := legibleconfig.NewConfig("42", nil, nil)
+	created.Profile, created.IncludeSources = "analytics", true
+	root := &legibleconfig.Config{Targets: map[string]*legibleconfig.Config{"finance": created}}
+	if err := legibleconfig.Save(dir, root); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	wcfg, err := legibleconfig.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	finance, err := wcfg.Target("finance")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dbt update without --include-sources still includes them
+	if err := dbtUpdateCmd.ParseFlags(nil); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := dbtSyncOptionsFromFlags(dbtUpdateCmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := linkedConvertOptions(finance, opts)
+	if !got.IncludeSources || got.IncludeSeeds || got.IncludeSnapshots || got.IncludeStagingModels {
+		t.Errorf("include sources, seeds, snapshots, staging = %v, %v, %v, %v, want true, false, false, false",
+			got.IncludeSources, got.IncludeSeeds, got.IncludeSnapshots, got.IncludeStagingModels)
+	}
+	if got.ProfileName != "analytics" {
+		t.Errorf("ProfileName = %q, want analytics", got.ProfileName)
+	}
+
+	// Flags add to the saved settings
+	opts.IncludeSeeds, opts.Profile = true, "other"
+	got = linkedConvertOptions(finance, opts)
+	if !got.IncludeSources || !got.IncludeSeeds || got.ProfileName != "other" {
+		t.Errorf("with flags: include sources, seeds = %v, %v, profile %q, want true, true, other",
+			got.IncludeSources, got.IncludeSeeds, got.ProfileName)
+	}
+}
+
+func TestSetFilterFlags(t *testing.T) {
+	filter := legibleconfig.Filter{
+		Include:       []string{"marts_.*"},
+		Exclude:       []string{"tmp_.*"},
+		Select:        []string{"tag:finance"},
+		ExcludeSelect: []string{"path:models/legacy"},
+	}
+	cmd := &cobra.Command{}
+	for _, flag := range dbtFilterFlags {
+		cmd.Flags().String(flag, "", "")
+	}
+	if err := cmd.ParseFlags([]string{"--select", "tag:marketing +orders", "--exclude-select", ""}); err != nil {
+		t.Fatal(err)
+	}
+	setFilterFlags(cmd, &filter)
+
+	want := legibleconfig.Filter{
+		Include: []string{"marts_.*"},
+		Exclude: []string{"tmp_.*"},
+		Select:  []string{"tag:marketing +orders"},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %+v, want %+v", filter, want)
+	}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	if err := checkConflicts(&mdlsync.MergeResult{}); err != nil {
+		t.Errorf("checkConflicts() without conflicts = %v", err)
+	}
+	merge := &mdlsync.MergeResult{Conflicts: []mdlsync.Conflict{{}, {}}}
+	if err := checkConflicts(merge); err == nil || !strings.Contains(err.Error(), "2 conflicts") {
+		t.Errorf("checkConflicts() = %v, want an error about 2 conflicts", err)
+	}
+}
+
+func TestCheckAllFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{nil, ""},
+		{[]string{"--yes", "--vars", "env: prod"}, ""},
+		{[]string{"--include-sources"}, "--include-sources"},
+		{[]string{"--metadata-mapping", "mapping.yml"}, "--metadata-mapping"},
+		{[]string{"--prefer-dbt"}, "--prefer-dbt"},
+		{[]string{"--select", "tag:finance"}, "--select"},
+	}
+	for _, tt := range tests {
+		cmd := &cobra.Command{}
+		for _, flag := range slices.Concat(dbtTargetSettingFlags, dbtFilterFlags, []string{"vars"}) {
+			if strings.HasPrefix(flag, "include-") || flag == "prefer-dbt" {
+				cmd.Flags().Bool(flag, false, "")
+			} else {
+				cmd.Flags().String(flag, "", "")
+			}
+		}
+		cmd.Flags().Bool("yes", false, "")
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		err := checkAllFlags(cmd)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("checkAllFlags(%v) = %v", tt.args, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("checkAllFlags(%v) = %v, want an error about %s", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReadDbtDataSource(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "legible-datasource.json")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	ds, err := readDbtDataSource(write(`{"type": "postgres", "properties": {"host": "db", "password": "secret"}}`))
+	if err != nil || ds.Type != "postgres" || ds.Properties["password"] != "secret" {
+		t.Errorf("readDbtDataSource() = %+v, %v", ds, err)
+	}
+
+	// References to secrets are never uploaded
+	_, err = readDbtDataSource(write(`{"type": "databricks", "properties": {"auth": {"accessToken": "{{ env_var('DBT_ENV_SECRET_TOKEN') }}"}}}`))
+	if err == nil || !strings.Contains(err.Error(), "auth.accessToken") {
+		t.Errorf("readDbtDataSource() with a reference = %v, want an error about auth.accessToken", err)
+	}
+}
+
+func TestProgressOut(t *testing.T) {
+	defer func(json bool) { jsonOutput = json }(jsonOutput)
+	jsonOutput = false
+	if progressOut() != os.Stdout {
+		t.Error("progressOut() is not stdout without --json")
+	}
+	// --json keeps stdout for the JSON report
+	jsonOutput = true
+	if progressOut() != os.Stderr {
+		t.Error("progressOut() is not stderr with --json")
+	}
+}
+
+func TestReviewInferredRelations(t *testing.T) {
+	inferred := []dbt.InferredRelationship{
+		{Relationship: dbt.Relationship{Name: "a"}, Confidence: 0.9},
+		{Relationship: dbt.Relationship{Name: "b"}, Confidence: 0.5},
+		{Relationship: dbt.Relationship{Name: "c"}, Confidence: 0.8},
+	}
+	names := func(rels []dbt.Relationship) []string {
+		var out []string
+		for _, r := range rels {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+
+	// An empty answer rejects, "a" accepts the rest
+	accepted, err := reviewInferredRelations(strings.NewReader("y\n\na\n"), inferred)
+	if err != nil || !slices.Equal(names(accepted), []string{"a", "c"}) {
+		t.Errorf("reviewInferredRelations() = %v, %v, want [a c]", names(accepted), err)
+	}
+
+	// Running out of answers fails instead of rejecting the rest
+	if _, err := reviewInferredRelations(strings.NewReader("y\n"), inferred); err == nil {
+		t.Error("reviewInferredRelations() with closed input = nil error")
+	}
+
+	if got := names(acceptInferredRelations(inferred, 0.8)); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("acceptInferredRelations(0.8) = %v, want [a c]", got)
+	}
+	if got := acceptInferredRelations(inferred, 0); len(got) != 3 {
+		t.Errorf("acceptInferredRelations(0) = %d relationships, want 3", len(got))
+	}
+}
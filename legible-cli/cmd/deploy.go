@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/Kubeworkz/legible/legible-cli/internal/client"
 	"github.com/Kubeworkz/legible/legible-cli/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -46,7 +45,7 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no project selected — run: legible project use <id>")
 	}
 
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
@@ -77,7 +76,7 @@ func runDeployStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/eval"
+	"github.com/spf13/cobra"
+)
+
+// errBelowMinAccuracy is returned by "eval run" when the execution match
+// rate is below --min-accuracy; it exits with ExitEvalFailed.
+var errBelowMinAccuracy = errors.New("execution match rate is below --min-accuracy")
+
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Measure text-to-SQL accuracy",
+	Long: `Evaluate how accurately the project answers a suite of questions.
+
+Each question is sent through SQL generation, and both the generated SQL
+and the suite's expected SQL are executed. The result sets are compared
+ignoring row order, with a tolerance for numbers.`,
+}
+
+var evalRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run an evaluation suite",
+	Long: `Run an evaluation suite and report exact-match, execution-match and
+error rates, with a diff for every question that did not match.
+
+A suite is a YAML file:
+
+  name: ecommerce
+  questions:
+    - id: total-revenue
+      question: What is the total revenue?
+      sql: SELECT SUM(price) FROM orders
+
+Use --sql-pairs instead to evaluate the project's SQL pairs.
+
+Exact match compares the generated and expected SQL after normalizing case
+and whitespace. Execution match compares their result sets: columns are
+matched by name when both queries return the same names, and by position
+otherwise.
+
+Write --junit and --report files for CI, and set --min-accuracy to fail the
+run (exit code 7) when the execution match rate drops below it.
+
+Examples:
+  legible eval run --suite questions.yaml
+  legible eval run --sql-pairs --concurrency 8
+  legible eval run --suite questions.yaml --junit eval.xml --min-accuracy 0.9`,
+	RunE: runEvalRun,
+}
+
+func init() {
+	evalRunCmd.Flags().String("suite", "", "Suite file (YAML)")
+	evalRunCmd.Flags().Bool("sql-pairs", false, "Use the project's SQL pairs as the suite")
+	evalRunCmd.Flags().Int("concurrency", eval.DefaultConcurrency, "Questions to evaluate at once")
+	evalRunCmd.Flags().Float64("tolerance", eval.DefaultTolerance, "Relative tolerance when comparing numbers")
+	evalRunCmd.Flags().Int("limit", eval.DefaultLimit, "Max rows fetched per query")
+	evalRunCmd.Flags().String("language", "", "Language for SQL generation")
+	evalRunCmd.Flags().String("junit", "", "Write a JUnit XML report to this file")
+	evalRunCmd.Flags().String("report", "", "Write a JSON report to this file")
+	evalRunCmd.Flags().Float64("min-accuracy", 0, "Fail if the execution match rate (0-1) is below this")
+
+	evalCmd.AddCommand(evalRunCmd)
+	rootCmd.AddCommand(evalCmd)
+}
+
+func runEvalRun(cmd *cobra.Command, args []string) error {
+	suitePath, _ := cmd.Flags().GetString("suite")
+	useSqlPairs, _ := cmd.Flags().GetBool("sql-pairs")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	tolerance, _ := cmd.Flags().GetFloat64("tolerance")
+	limit, _ := cmd.Flags().GetInt("limit")
+	language, _ := cmd.Flags().GetString("language")
+	junitPath, _ := cmd.Flags().GetString("junit")
+	reportPath, _ := cmd.Flags().GetString("report")
+	minAccuracy, _ := cmd.Flags().GetFloat64("min-accuracy")
+
+	if (suitePath == "") == !useSqlPairs {
+		return fmt.Errorf("specify exactly one of --suite or --sql-pairs")
+	}
+	if minAccuracy < 0 || minAccuracy > 1 {
+		return fmt.Errorf("--min-accuracy must be between 0 and 1")
+	}
+
+	c, cfg, err := newClientFromConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.ProjectID == "" {
+		return fmt.Errorf("no project selected — run: legible project use <id>")
+	}
+	// Generate SQL can take up to 3 minutes
+	c.SetTimeout(4 * time.Minute)
+
+	ctx := cmd.Context()
+	var suite *eval.Suite
+	if useSqlPairs {
+		pairs, err := c.ListSqlPairsContext(ctx)
+		if err != nil {
+			return err
+		}
+		if suite, err = eval.SuiteFromSqlPairs("sql-pairs", pairs); err != nil {
+			return fmt.Errorf("SQL pairs: %w", err)
+		}
+	} else if suite, err = eval.LoadSuite(suitePath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Evaluating %d question(s) from %s...\n", len(suite.Cases), suite.Name)
+	done := 0
+	opts := eval.Options{Concurrency: concurrency, Tolerance: tolerance, Limit: limit, Language: language}
+	report, err := eval.Run(ctx, c, suite, opts, func(r eval.CaseResult) {
+		done++
+		fmt.Fprintf(os.Stderr, "[%d/%d] %-5s %s (%.1fs)\n", done, len(suite.Cases), strings.ToUpper(string(r.Status)), r.ID, r.Seconds)
+	})
+	if err != nil {
+		return err
+	}
+
+	if junitPath != "" {
+		if err := writeReportFile(junitPath, func(f *os.File) error { return eval.WriteJUnit(f, report) }); err != nil {
+			return err
+		}
+	}
+	if reportPath != "" {
+		if err := writeReportFile(reportPath, func(f *os.File) error { return encodeReport(f, report) }); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput {
+		if err := encodeReport(os.Stdout, report); err != nil {
+			return err
+		}
+	} else {
+		printEvalReport(report)
+	}
+
+	if report.Summary.ExecutionMatchRate < minAccuracy {
+		return fmt.Errorf("%w: %.1f%% < %.1f%%", errBelowMinAccuracy,
+			report.Summary.ExecutionMatchRate*100, minAccuracy*100)
+	}
+	return nil
+}
+
+func encodeReport(w io.Writer, report *eval.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// writeReportFile creates path and writes a report to it.
+func writeReportFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "Report written to %s\n", path)
+	return nil
+}
+
+// printEvalReport prints every question that did not match, then the
+// summary.
+func printEvalReport(report *eval.Report) {
+	for _, r := range report.Cases {
+		if r.Status == eval.StatusPass {
+			continue
+		}
+		fmt.Printf("\n%s %s: %s\n", strings.ToUpper(string(r.Status)), r.ID, r.Question)
+		fmt.Printf("  Expected SQL:  %s\n", oneLine(r.ExpectedSQL))
+		if r.GeneratedSQL != "" {
+			fmt.Printf("  Generated SQL: %s\n", oneLine(r.GeneratedSQL))
+		}
+		if r.Error != "" {
+			fmt.Printf("  Error: %s\n", r.Error)
+		}
+		if r.Diff != "" {
+			fmt.Printf("  Rows: %d expected, %d generated\n", r.ExpectedRows, r.GeneratedRows)
+			for _, line := range strings.Split(r.Diff, "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+		if r.Truncated {
+			fmt.Println("  Note: results were truncated at --limit rows")
+		}
+	}
+
+	s := report.Summary
+	pct := func(n int) string {
+		if s.Total == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", float64(n)/float64(s.Total)*100)
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Questions:\t%d\n", s.Total)
+	fmt.Fprintf(w, "Exact match:\t%d\t%s\n", s.ExactMatch, pct(s.ExactMatch))
+	fmt.Fprintf(w, "Execution match:\t%d\t%s\n", s.ExecutionMatch, pct(s.ExecutionMatch))
+	fmt.Fprintf(w, "Errors:\t%d\t%s\n", s.Errors, pct(s.Errors))
+	fmt.Fprintf(w, "Duration:\t%.1fs\n", report.Seconds)
+	w.Flush()
+}
+
+// oneLine collapses whitespace so SQL fits on one line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+// Exit codes returned by the CLI, so scripts can tell failures apart.
+// Documented in docs-site/docs/guides/cli.md.
+const (
+	ExitOK          = 0
+	ExitError       = 1   // any other failure
+	ExitAuth        = 2   // missing, invalid or unauthorized API key
+	ExitNotDeployed = 3   // the project has no deployed models
+	ExitSQL         = 4   // the SQL failed to parse, plan or execute
+	ExitTimeout     = 5   // the request timed out
+	ExitNonSQL      = 6   // the question could not be answered with SQL
+	ExitEvalFailed  = 7   // eval run fell below --min-accuracy
+	ExitInterrupted = 130 // cancelled with Ctrl+C
+)
+
+// exitCode maps an error returned by a command to the process exit code.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, context.Canceled) {
+		return ExitInterrupted
+	}
+	if errors.Is(err, errBelowMinAccuracy) {
+		return ExitEvalFailed
+	}
+	switch client.KindOf(err) {
+	case client.KindAuth:
+		return ExitAuth
+	case client.KindNotDeployed:
+		return ExitNotDeployed
+	case client.KindSQL:
+		return ExitSQL
+	case client.KindTimeout:
+		return ExitTimeout
+	case client.KindNonSQL:
+		return ExitNonSQL
+	}
+	return ExitError
+}
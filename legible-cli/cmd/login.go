@@ -18,9 +18,15 @@ var loginCmd = &cobra.Command{
 	Long: `Interactively configure your Legible server endpoint and API key.
 The credentials are validated against the server before saving.
 
+Credentials are saved to the active profile; use --profile to log in
+to a different server without touching your other profiles. The API key
+is kept in the OS keyring when available, otherwise in an encrypted file
+(~/.legible/credentials.enc) protected by a passphrase.
+
 You can also set values non-interactively:
   legible config set endpoint https://legible.example.com
-  legible config set api-key osk-abc123...`,
+  legible config set api-key osk-abc123...
+  legible --profile prod login --endpoint https://legible.example.com --api-key osk-...`,
 	RunE: runLogin,
 }
 
@@ -70,7 +76,7 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	// Validate
 	fmt.Print("Validating credentials... ")
 	c := client.NewWithOverrides(endpoint, apiKey)
-	info, err := c.ValidateConnection()
+	info, err := c.ValidateConnectionContext(cmd.Context())
 	if err != nil {
 		fmt.Println("FAILED")
 		return fmt.Errorf("validation failed: %w", err)
@@ -83,13 +89,16 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	if err := cfg.Save(); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
+	storeName, _ := cfg.Get("credential-store")
 
 	if jsonOutput {
 		out := map[string]interface{}{
 			"status":   "authenticated",
+			"profile":  cfg.Profile,
 			"endpoint": endpoint,
 			"user":     info.UserEmail,
 			"org":      info.OrgName,
+			"store":    storeName,
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -101,6 +110,7 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	if info.OrgName != "" {
 		fmt.Printf("  Organization: %s\n", info.OrgName)
 	}
-	fmt.Printf("  Config saved to: ~/.legible/config.yaml\n")
+	fmt.Printf("  Config saved to: ~/.legible/config.yaml (profile %q)\n", cfg.Profile)
+	fmt.Printf("  API key stored in: %s\n", storeName)
 	return nil
 }
@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/config"
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+	"github.com/spf13/cobra"
+)
+
+var mdlCmd = &cobra.Command{
+	Use:   "mdl",
+	Short: "Work with MDL files",
+}
+
+var mdlValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Validate an MDL file",
+	Long: `Validate an MDL file against the MDL JSON schema and check its
+references: duplicate model, relationship, view, metric and enum names,
+relationship conditions on missing columns, primary keys, and columns that
+use undefined enums or relationships.
+
+Relationships and metrics over models that are not in the file are
+reported as warnings. The command exits with status 1 if there are errors.
+
+Examples:
+  legible mdl validate legible-mdl.json
+  legible mdl validate legible-mdl.json --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMDLValidate,
+}
+
+var mdlImportCmd = &cobra.Command{
+	Use:   "import <path>",
+	Short: "Create a project from a LookML, Cube or MetricFlow project",
+	Long: `Convert a LookML, Cube or MetricFlow project into MDL and create a
+Legible project from it, the same way 'legible dbt create' does for a dbt
+project.
+
+Views, cubes and semantic models become models, with their dimensions as
+columns. Joins and entities become relationships, measures and metrics
+become metrics, and derived tables and cubes over a query become views.
+Whatever has no MDL equivalent is listed as not imported.
+
+<path> is the project directory. For MetricFlow it can also be a
+semantic_manifest.json, or a YAML file with semantic models and metrics.
+
+The project reads its tables from the data source given with --data-source,
+a JSON file with the data source type and properties, which is required
+unless --dry-run is given. The metrics and views
+are not created in the project; use --out to keep them in an MDL file.
+
+Examples:
+  legible mdl import --from lookml ./looker --data-source warehouse.json
+  legible mdl import --from cube ./model --dry-run
+  legible mdl import --from metricflow ./jaffle_shop --out legible-mdl.json --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMDLImport,
+}
+
+func init() {
+	mdlImportCmd.Flags().String("from", "", "Format of the project: "+strings.Join(dbt.ImportFormats, ", "))
+	mdlImportCmd.Flags().String("name", "", "Display name for the new project (default: the project directory's name)")
+	mdlImportCmd.Flags().String("data-source", "", "JSON file with the data source of the new project (required unless --dry-run)")
+	mdlImportCmd.Flags().String("out", "", "Write the converted MDL to this file")
+	mdlImportCmd.Flags().Bool("force", false, "Overwrite the --out file if it exists")
+	mdlImportCmd.Flags().Bool("dry-run", false, "Preview the conversion without creating the project")
+	mdlImportCmd.MarkFlagRequired("from")
+
+	mdlCmd.AddCommand(mdlValidateCmd)
+	mdlCmd.AddCommand(mdlImportCmd)
+	rootCmd.AddCommand(mdlCmd)
+}
+
+func runMDLValidate(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading MDL file: %w", err)
+	}
+	report, err := dbt.ValidateMDL(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if jsonOutput {
+		if err := json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
+			"valid":    !report.HasErrors(),
+			"errors":   report.Errors(),
+			"warnings": report.Warnings(),
+			"issues":   report.Issues,
+		}); err != nil {
+			return err
+		}
+	} else if len(report.Issues) == 0 {
+		fmt.Printf("%s is valid\n", path)
+	} else {
+		writeValidationIssues(os.Stdout, report.Issues)
+		fmt.Printf("\n%d error(s), %d warning(s)\n", report.Errors(), report.Warnings())
+	}
+
+	if report.HasErrors() {
+		return fmt.Errorf("%s has %d validation error(s)", path, report.Errors())
+	}
+	return nil
+}
+
+func runMDLImport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	from, _ := cmd.Flags().GetString("from")
+	name, _ := cmd.Flags().GetString("name")
+	dataSourcePath, _ := cmd.Flags().GetString("data-source")
+	out, _ := cmd.Flags().GetString("out")
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	// The project's tables are read from its data source, so one is needed
+	// before anything is created
+	if dataSourcePath == "" && !dryRun {
+		return fmt.Errorf("--data-source is required to create a project, which reads its tables from it; use --dry-run to only convert")
+	}
+
+	if !jsonOutput {
+		fmt.Printf("Converting %s project...\n", from)
+	}
+	imp, err := dbt.ImportMDL(from, path)
+	if err != nil {
+		return fmt.Errorf("%s import failed: %w", from, err)
+	}
+	mdl := imp.Manifest
+	printValidationIssues(dbt.ValidateManifest(mdl), nil)
+	printImportSummary(imp)
+
+	if out != "" {
+		if _, err := os.Stat(out); err == nil && !force {
+			return fmt.Errorf("%s already exists — use --force to overwrite it", out)
+		}
+		data, err := json.MarshalIndent(mdl, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding MDL: %w", err)
+		}
+		if err := os.WriteFile(out, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", out, err)
+		}
+		if !jsonOutput {
+			fmt.Printf("\nWrote %s\n", out)
+		}
+	}
+
+	if dryRun {
+		if !jsonOutput {
+			fmt.Println("\nDry run — no project created.")
+		}
+		return nil
+	}
+	if len(mdl.Models) == 0 {
+		return fmt.Errorf("no models to create a project from")
+	}
+
+	dsInput, err := readDbtDataSource(dataSourcePath)
+	if err != nil {
+		return err
+	}
+
+	// Default project name from the project directory
+	if name == "" {
+		absPath, _ := filepath.Abs(path)
+		if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+			absPath = filepath.Dir(absPath)
+		}
+		name = filepath.Base(absPath)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	c, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	nameModelsAfterTables(mdl)
+	project, err := createProjectFromMDL(c, name, dsInput, mdl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nProject created successfully!\n")
+	fmt.Printf("  Project ID: %d\n", project.ID)
+	fmt.Printf("  Models: %d\n", len(mdl.Models))
+	if len(mdl.Metrics) > 0 || len(mdl.Views) > 0 {
+		fmt.Printf("  Not created: %d metrics, %d views (use --out to keep them in an MDL file)\n", len(mdl.Metrics), len(mdl.Views))
+	}
+	return nil
+}
+
+// printImportSummary prints the models, relationships, metrics and views of
+// an imported MDL, and what could not be imported. Supports --json output.
+func printImportSummary(imp *dbt.MDLImport) {
+	mdl := imp.Manifest
+	if jsonOutput {
+		type modelEntry struct {
+			Name    string `json:"name"`
+			Columns int    `json:"columns"`
+		}
+		models := make([]modelEntry, len(mdl.Models))
+		for i, m := range mdl.Models {
+			models[i] = modelEntry{Name: m.Name, Columns: len(m.Columns)}
+		}
+		unmapped := imp.Unmapped
+		if unmapped == nil {
+			unmapped = []string{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(map[string]interface{}{ //nolint:errcheck
+			"models":        models,
+			"relationships": len(mdl.Relationships),
+			"metrics":       len(mdl.Metrics),
+			"views":         len(mdl.Views),
+			"unmapped":      unmapped,
+		})
+		return
+	}
+
+	printCreateSummary(mdl, nil)
+	if len(mdl.Metrics) > 0 {
+		fmt.Printf("Metrics: %d\n", len(mdl.Metrics))
+	}
+	if len(mdl.Views) > 0 {
+		fmt.Printf("Views: %d\n", len(mdl.Views))
+	}
+	if len(imp.Unmapped) > 0 {
+		fmt.Printf("\nNot imported (%d):\n", len(imp.Unmapped))
+		for _, u := range imp.Unmapped {
+			fmt.Printf("  ⚠ %s\n", u)
+		}
+	}
+}
+
+// nameModelsAfterTables renames the models that are named differently from
+// their tables, as a project creates its models from the data source's
+// tables by name, and updates the relationships and metrics that use them.
+// A model keeps its name if another model already has its table's name.
+func nameModelsAfterTables(mdl *dbt.LegibleMDLManifest) {
+	names := make(map[string]bool, len(mdl.Models))
+	for _, m := range mdl.Models {
+		names[m.Name] = true
+	}
+	renamed := make(map[string]string)
+	for i := range mdl.Models {
+		m := &mdl.Models[i]
+		table := m.TableReference.Table
+		if table == "" || table == m.Name || names[table] {
+			continue
+		}
+		if !jsonOutput {
+			fmt.Printf("Model %s is over table %s, and is created as %s\n", m.Name, table, table)
+		}
+		names[table] = true
+		renamed[m.Name] = table
+		m.Name = table
+	}
+	if len(renamed) == 0 {
+		return
+	}
+
+	for i := range mdl.Relationships {
+		rel := &mdl.Relationships[i]
+		for j, model := range rel.Models {
+			if table, ok := renamed[model]; ok {
+				rel.Models[j] = table
+				rel.Condition = renameConditionModel(rel.Condition, model, table)
+			}
+		}
+	}
+	for i := range mdl.Metrics {
+		for j, model := range mdl.Metrics[i].Models {
+			if table, ok := renamed[model]; ok {
+				mdl.Metrics[i].Models[j] = table
+			}
+		}
+	}
+}
+
+// renameConditionModel renames the model qualifying columns in a
+// relationship condition, quoted ("orders".id) or not (orders.id), leaving
+// string literals alone.
+func renameConditionModel(condition, from, to string) string {
+	isIdent := func(r byte) bool {
+		return r == '_' || r == '$' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= 0x80
+	}
+	replacement := to
+	for i := 0; i < len(to); i++ {
+		if !isIdent(to[i]) || i == 0 && to[i] >= '0' && to[i] <= '9' {
+			replacement = `"` + strings.ReplaceAll(to, `"`, `""`) + `"`
+			break
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(condition); {
+		switch c := condition[i]; {
+		case c == '\'':
+			// String literals are copied unchanged
+			j := quotedEnd(condition, i)
+			b.WriteString(condition[i:j])
+			i = j
+		case c == '"':
+			j := quotedEnd(condition, i)
+			name := strings.ReplaceAll(strings.TrimSuffix(condition[i+1:j], `"`), `""`, `"`)
+			if name == from && j < len(condition) && condition[j] == '.' && (i == 0 || condition[i-1] != '.') {
+				b.WriteString(`"` + strings.ReplaceAll(to, `"`, `""`) + `"`)
+			} else {
+				b.WriteString(condition[i:j])
+			}
+			i = j
+		case isIdent(c):
+			j := i
+			for j < len(condition) && isIdent(condition[j]) {
+				j++
+			}
+			if condition[i:j] == from && j < len(condition) && condition[j] == '.' && (i == 0 || condition[i-1] != '.') {
+				b.WriteString(replacement)
+			} else {
+				b.WriteString(condition[i:j])
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// quotedEnd returns the end of the quoted string or identifier starting at
+// i, where a doubled quote is an escaped one.
+func quotedEnd(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		if s[j] != quote {
+			continue
+		}
+		if j+1 < len(s) && s[j+1] == quote {
+			j++
+			continue
+		}
+		return j + 1
+	}
+	return len(s)
+}
+
+// printValidationIssues prints the issues found in an MDL to stderr, so they
+// do not mix with JSON output, leaving out the ones already reported while
+// converting it.
+func printValidationIssues(report, reported *dbt.ValidationReport) {
+	seen := make(map[string]bool)
+	if reported != nil {
+		for _, issue := range reported.Issues {
+			seen[issue.Severity+"\x00"+issue.Message] = true
+		}
+	}
+	var issues []dbt.ValidationIssue
+	for _, issue := range report.Issues {
+		if !seen[issue.Severity+"\x00"+issue.Message] {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "\nMDL validation:")
+	writeValidationIssues(os.Stderr, issues)
+}
+
+func writeValidationIssues(out io.Writer, issues []dbt.ValidationIssue) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tPATH\tMESSAGE")
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", issue.Severity, issue.Path, issue.Message)
+	}
+	w.Flush()
+}
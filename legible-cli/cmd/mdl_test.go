@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+)
+
+func TestRenameConditionModel(t *testing.T) {
+	tests := []struct {
+		condition, from, to, want string
+	}{
+		{`"orders".customer_id = "customers".id`, "orders", "fct_orders", `"fct_orders".customer_id = "customers".id`},
+		{`orders.customer_id = customers.id`, "orders", "fct_orders", `fct_orders.customer_id = customers.id`},
+		{`ORDERS.id = orders_items.order_id`, "orders", "fct_orders", `ORDERS.id = orders_items.order_id`},
+		{`orders.status = 'orders.status' AND x.orders.id = 1`, "orders", "fct_orders", `fct_orders.status = 'orders.status' AND x.orders.id = 1`},
+		{`orders.id = items.order_id`, "orders", "Order Facts", `"Order Facts".id = items.order_id`},
+		{`"say ""hi""".id = orders.note`, `say "hi"`, "greetings", `"greetings".id = orders.note`},
+	}
+	for _, tt := range tests {
+		if got := renameConditionModel(tt.condition, tt.from, tt.to); got != tt.want {
+			t.Errorf("renameConditionModel(%q, %q, %q) = %q, want %q", tt.condition, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestNameModelsAfterTables(t *testing.T) {
+	mdl := &dbt.LegibleMDLManifest{
+		Models: []dbt.LegibleModel{
+			{Name: "orders", TableReference: dbt.TableReference{Table: "fct_orders"}},
+			{Name: "customers", TableReference: dbt.TableReference{Table: "customers"}},
+		},
+		Relationships: []dbt.Relationship{{
+			Name:      "orders_customers",
+			Models:    []string{"orders", "customers"},
+			Condition: "orders.customer_id = customers.id",
+		}},
+	}
+	nameModelsAfterTables(mdl)
+	if mdl.Models[0].Name != "fct_orders" {
+		t.Errorf("model name = %q, want fct_orders", mdl.Models[0].Name)
+	}
+	rel := mdl.Relationships[0]
+	if rel.Models[0] != "fct_orders" || rel.Condition != "fct_orders.customer_id = customers.id" {
+		t.Errorf("relationship = %v, %q", rel.Models, rel.Condition)
+	}
+}
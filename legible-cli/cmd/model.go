@@ -57,12 +57,23 @@ func init() {
 	rootCmd.AddCommand(modelCmd)
 }
 
+// newClient creates an API client for cfg bound to the command context,
+// so Ctrl+C cancels in-flight requests.
+func newClient(cfg *config.Config) (*client.Client, error) {
+	c, err := client.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.SetContext(rootCmd.Context())
+	return c, nil
+}
+
 func newClientFromConfig() (*client.Client, *config.Config, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, nil, fmt.Errorf("loading config: %w", err)
 	}
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -152,6 +163,13 @@ func runModelDescribe(cmd *cobra.Command, args []string) error {
 		return enc.Encode(model)
 	}
 
+	printModelDetail(model)
+	return nil
+}
+
+// printModelDetail prints a model's header, fields, calculated fields and
+// relationships.
+func printModelDetail(model *client.DetailedModel) {
 	// Header
 	fmt.Printf("Model: %s\n", model.DisplayName)
 	fmt.Printf("Reference: %s\n", model.ReferenceName)
@@ -223,8 +241,6 @@ func runModelDescribe(cmd *cobra.Command, args []string) error {
 		}
 		w.Flush()
 	}
-
-	return nil
 }
 
 func runModelFields(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+	"github.com/Kubeworkz/legible/legible-cli/internal/output"
+	"golang.org/x/term"
+)
+
+// resultOutput is where a command writes query rows: stdout or --output FILE,
+// in --format (inferred from the file extension when not given).
+type resultOutput struct {
+	format string
+	path   string
+	w      io.Writer
+	file   *os.File
+}
+
+// newResultOutput validates --format/--output before the query runs. The
+// format is inferred from the file extension unless formatSet, and the file
+// is only created once there are rows to write.
+func newResultOutput(format string, formatSet bool, path string) (*resultOutput, error) {
+	if !formatSet && path != "" {
+		if inferred := output.FormatFromPath(path); inferred != "" {
+			format = inferred
+		}
+	}
+	if _, err := output.New(format, io.Discard, nil); err != nil {
+		return nil, err
+	}
+
+	if path == "" && output.IsBinary(format) && term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("%s output is binary — use --output <file> or redirect stdout", format)
+	}
+	return &resultOutput{format: format, path: path, w: os.Stdout}, nil
+}
+
+// toStdout reports whether rows go to stdout, so other output must not.
+func (o *resultOutput) toStdout() bool {
+	return o.path == ""
+}
+
+// writeResult writes all rows of a result and closes the destination.
+func (o *resultOutput) writeResult(cols []client.RunSQLColumn, records []map[string]interface{}) error {
+	w, err := o.newWriter(cols)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(records); err != nil {
+		w.Close()
+		o.close()
+		return fmt.Errorf("writing %s: %w", o.format, err)
+	}
+	if err := w.Close(); err != nil {
+		o.close()
+		return fmt.Errorf("writing %s: %w", o.format, err)
+	}
+	return o.close()
+}
+
+// writePages writes each page as it arrives and closes the destination.
+// The writer is created from the first page's columns. progress, if not
+// nil, is called with the running row count after each page.
+func (o *resultOutput) writePages(pages iter.Seq2[*client.RunSQLResult, error], progress func(rows int)) (int, error) {
+	var w output.Writer
+	rows := 0
+	fail := func(err error) (int, error) {
+		if w != nil {
+			w.Close()
+		}
+		o.close()
+		return rows, err
+	}
+
+	for page, err := range pages {
+		if err != nil {
+			return fail(err)
+		}
+		if w == nil {
+			if w, err = o.newWriter(page.Columns); err != nil {
+				return rows, err
+			}
+		}
+		if err := w.Write(page.Records); err != nil {
+			return fail(fmt.Errorf("writing %s: %w", o.format, err))
+		}
+		rows += len(page.Records)
+		if progress != nil {
+			progress(rows)
+		}
+	}
+
+	if w == nil {
+		return rows, o.close()
+	}
+	if err := w.Close(); err != nil {
+		return fail(fmt.Errorf("writing %s: %w", o.format, err))
+	}
+	return rows, o.close()
+}
+
+func (o *resultOutput) newWriter(cols []client.RunSQLColumn) (output.Writer, error) {
+	if o.path != "" && o.file == nil {
+		f, err := os.Create(o.path)
+		if err != nil {
+			return nil, fmt.Errorf("creating output file: %w", err)
+		}
+		o.w, o.file = f, f
+	}
+	w, err := output.New(o.format, o.w, cols)
+	if err != nil {
+		o.close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// close closes the output file, if any.
+func (o *resultOutput) close() error {
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	if err != nil {
+		return fmt.Errorf("closing output file: %w", err)
+	}
+	return nil
+}
+
+// printRecords writes rows to stdout in a text format.
+func printRecords(format string, cols []client.RunSQLColumn, records []map[string]interface{}) error {
+	w, err := output.New(format, os.Stdout, cols)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(records); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/config"
+	"golang.org/x/term"
+)
+
+// promptPassphrase asks for the credentials file passphrase on the terminal,
+// falling back to LEGIBLE_PASSPHRASE when set or when stdin is not a terminal.
+func promptPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(config.EnvPassphrase); p != "" {
+		return p, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no passphrase for encrypted credentials — set %s", config.EnvPassphrase)
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase for ~/.legible/credentials.enc: ")
+	p, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	if len(p) == 0 {
+		return "", fmt.Errorf("passphrase is required")
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading passphrase: %w", err)
+		}
+		if string(again) != string(p) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return string(p), nil
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configProfileCmd = &cobra.Command{
+	Use:     "profile",
+	Aliases: []string{"profiles"},
+	Short:   "Manage named connection profiles",
+	Long: `Profiles let you keep several endpoint/API key/project combinations
+(e.g. staging, prod, customer tenants) and switch between them.
+
+The active profile is chosen by, in order: the --profile flag, the
+LEGIBLE_PROFILE environment variable, the current profile saved with
+"legible config profile use", or "default".
+
+Examples:
+  legible config profile list
+  legible config profile add prod --endpoint https://legible.example.com --api-key osk-...
+  legible config profile use prod
+  legible --profile staging project list
+  legible config profile remove staging`,
+}
+
+var configProfileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List connection profiles",
+	RunE:    runConfigProfileList,
+}
+
+var configProfileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Create or update a connection profile",
+	Long: `Create a new profile, or update the given fields of an existing one.
+Use "legible --profile <name> login" to set credentials interactively.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigProfileAdd,
+}
+
+var configProfileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the default connection profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigProfileUse,
+}
+
+var configProfileRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a connection profile",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runConfigProfileRemove,
+}
+
+func init() {
+	configProfileAddCmd.Flags().String("endpoint", "", "Server endpoint URL")
+	configProfileAddCmd.Flags().String("api-key", "", "API key")
+	configProfileAddCmd.Flags().String("project-id", "", "Project ID")
+	configProfileAddCmd.Flags().Bool("use", false, "Make this the default profile")
+
+	configProfileCmd.AddCommand(configProfileListCmd)
+	configProfileCmd.AddCommand(configProfileAddCmd)
+	configProfileCmd.AddCommand(configProfileUseCmd)
+	configProfileCmd.AddCommand(configProfileRemoveCmd)
+	configCmd.AddCommand(configProfileCmd)
+}
+
+func runConfigProfileList(cmd *cobra.Command, args []string) error {
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	active := f.ActiveProfile()
+
+	type profileEntry struct {
+		Name      string `json:"name"`
+		Active    bool   `json:"active"`
+		Endpoint  string `json:"endpoint,omitempty"`
+		APIKey    string `json:"api_key,omitempty"`
+		ProjectID string `json:"project_id,omitempty"`
+	}
+
+	var entries []profileEntry
+	for _, name := range f.ProfileNames() {
+		p := f.Profiles[name]
+		entries = append(entries, profileEntry{
+			Name:      name,
+			Active:    name == active,
+			Endpoint:  p.Endpoint,
+			APIKey:    config.MaskAPIKey(p.APIKey),
+			ProjectID: p.ProjectID,
+		})
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No profiles configured. Run: legible login")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tENDPOINT\tPROJECT\tAPI KEY")
+	for _, e := range entries {
+		name := e.Name
+		if e.Active {
+			name += " *"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, dashIfEmpty(e.Endpoint), dashIfEmpty(e.ProjectID), dashIfEmpty(e.APIKey))
+	}
+	w.Flush()
+	return nil
+}
+
+func runConfigProfileAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	endpoint, _ := cmd.Flags().GetString("endpoint")
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	projectID, _ := cmd.Flags().GetString("project-id")
+	use, _ := cmd.Flags().GetBool("use")
+
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	p, exists := f.Profiles[name]
+	if !exists {
+		p = &config.Profile{}
+		f.Profiles[name] = p
+	}
+	if endpoint != "" {
+		p.Endpoint = endpoint
+	}
+	if projectID != "" {
+		p.ProjectID = projectID
+	}
+	if use {
+		f.CurrentProfile = name
+	}
+	if apiKey != "" {
+		if err := f.SetAPIKey(name, apiKey); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Save(); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{"status": "saved", "profile": name, "current": use})
+	}
+
+	if exists {
+		fmt.Printf("Updated profile %q\n", name)
+	} else {
+		fmt.Printf("Created profile %q\n", name)
+	}
+	if use {
+		fmt.Printf("Switched to profile %q\n", name)
+	}
+	if apiKey == "" && !exists {
+		fmt.Printf("Set credentials with: legible --profile %s login\n", name)
+	}
+	return nil
+}
+
+func runConfigProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if _, ok := f.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found — create it with: legible config profile add %s", name, name)
+	}
+
+	f.CurrentProfile = name
+	if err := f.Save(); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]string{"status": "switched", "profile": name})
+	}
+
+	fmt.Printf("Switched to profile %q\n", name)
+	return nil
+}
+
+func runConfigProfileRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if _, ok := f.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	if err := f.DeleteAPIKey(name); err != nil {
+		return fmt.Errorf("removing stored API key: %w", err)
+	}
+	delete(f.Profiles, name)
+	if f.CurrentProfile == name {
+		f.CurrentProfile = ""
+	}
+	if err := f.Save(); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]string{"status": "removed", "profile": name})
+	}
+
+	fmt.Printf("Removed profile %q\n", name)
+	return nil
+}
+
+// dashIfEmpty returns "-" for empty strings, for table display.
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
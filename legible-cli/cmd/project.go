@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"text/tabwriter"
 
-	"github.com/Kubeworkz/legible/legible-cli/internal/client"
 	"github.com/Kubeworkz/legible/legible-cli/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -101,7 +100,7 @@ func runProjectList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
@@ -159,7 +158,7 @@ func runProjectUse(cmd *cobra.Command, args []string) error {
 	}
 
 	// Verify project exists by fetching it
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
@@ -208,7 +207,7 @@ func runProjectCurrent(cmd *cobra.Command, args []string) error {
 	id, _ := strconv.Atoi(cfg.ProjectID)
 
 	// Try to fetch project info for a richer display
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err == nil {
 		if project, err := c.GetProject(id); err == nil {
 			if jsonOutput {
@@ -243,7 +242,7 @@ func runProjectInfo(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
@@ -312,7 +311,7 @@ func runProjectUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
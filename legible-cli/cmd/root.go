@@ -1,13 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 
+	"github.com/Kubeworkz/legible/legible-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
-var jsonOutput bool
+var (
+	jsonOutput  bool
+	profileFlag string
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "legible",
@@ -18,19 +26,63 @@ Configure your connection:
   legible login                    Interactive setup
   legible config set endpoint URL  Set server endpoint
   legible config set api-key KEY   Set API key
-  legible whoami                   Verify authentication`,
+  legible whoami                   Verify authentication
+
+Switch between servers with named profiles:
+  legible config profile add prod  Create a profile
+  legible --profile prod ask ...   Use a profile for one command
+  legible config profile use prod  Make a profile the default
+
+LEGIBLE_PROFILE, LEGIBLE_ENDPOINT, LEGIBLE_API_KEY and LEGIBLE_PROJECT_ID
+override the stored configuration. API keys are kept in the OS keyring or
+an encrypted file (see: legible config set credential-store).
+
+Exit codes: 0 success, 1 error, 2 authentication failed, 3 no deployment,
+4 SQL error, 5 timeout, 6 question not answerable with SQL, 130 interrupted.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output results as JSON")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Connection profile to use (default: current profile)")
+	cobra.OnInitialize(func() {
+		config.SetProfileOverride(profileFlag)
+		config.PassphraseFunc = promptPassphrase
+	})
 }
 
-// Execute runs the root command.
+// interruptHandler, when set, receives Ctrl+C instead of it cancelling the
+// command. The shell uses it to cancel only the running statement.
+var interruptHandler atomic.Pointer[func()]
+
+// Execute runs the root command. The first Ctrl+C cancels the command's
+// context so in-flight requests stop cleanly; a second one exits at once.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
-		os.Exit(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range sigs {
+			if h := interruptHandler.Load(); h != nil && sig == os.Interrupt {
+				(*h)()
+				continue
+			}
+			cancel()
+			signal.Stop(sigs)
+			return
+		}
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		code := exitCode(err)
+		if code == ExitInterrupted {
+			fmt.Fprintln(os.Stderr, "Interrupted")
+		} else {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(code)
 	}
 }
@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+	"github.com/Kubeworkz/legible/legible-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -19,10 +19,28 @@ var runSQLCmd = &cobra.Command{
 The SQL must be valid Legible SQL (using model/view names from the semantic layer).
 Use 'legible sql' to generate SQL from a natural language question first.
 
+Use --format to write rows as table, csv, tsv, ndjson, markdown, parquet or
+arrow, and --output to write them to a file (the format is inferred from
+the file extension when --format is not given). Values follow the column
+types: nulls are empty in CSV/TSV and null in NDJSON, numbers keep their
+exact digits, and dates/timestamps are ISO 8601. --json prints the raw API
+response instead.
+
+With --all, the query is fetched page by page (--page-size rows at a time)
+and each page is written as it arrives, so large results can be exported
+without raising the server's row limit. Pages are fetched with LIMIT and
+OFFSET, so the query must end with an ORDER BY on a unique key, which keeps
+pages from overlapping, and must leave the row count to --limit. The table
+format has to hold all rows to align them; prefer csv, ndjson or parquet
+for large exports.
+
 Examples:
   legible run-sql "SELECT * FROM customers LIMIT 10"
   legible run-sql "SELECT count(*) FROM orders" --limit 100
-  legible run-sql "SELECT region, sum(revenue) FROM sales GROUP BY region" --json`,
+  legible run-sql "SELECT region, sum(revenue) FROM sales GROUP BY region" --json
+  legible run-sql "SELECT * FROM orders" --format csv > orders.csv
+  legible run-sql "SELECT * FROM orders" --output orders.parquet
+  legible run-sql "SELECT * FROM orders ORDER BY id" --all -o orders.csv`,
 	Args: cobra.ExactArgs(1),
 	RunE: runRunSQL,
 }
@@ -30,11 +48,19 @@ Examples:
 var (
 	runSQLLimit    int
 	runSQLThreadID string
+	runSQLFormat   string
+	runSQLOutput   string
+	runSQLAll      bool
+	runSQLPageSize int
 )
 
 func init() {
 	runSQLCmd.Flags().IntVar(&runSQLLimit, "limit", 0, "Max rows to return (default: server decides, typically 1000)")
 	runSQLCmd.Flags().StringVar(&runSQLThreadID, "thread-id", "", "Thread ID for conversation context")
+	runSQLCmd.Flags().StringVar(&runSQLFormat, "format", output.Table, "Output format: "+strings.Join(output.Formats, ", "))
+	runSQLCmd.Flags().StringVarP(&runSQLOutput, "output", "o", "", "Write rows to a file instead of stdout")
+	runSQLCmd.Flags().BoolVar(&runSQLAll, "all", false, "Fetch all rows page by page, streaming them to the output")
+	runSQLCmd.Flags().IntVar(&runSQLPageSize, "page-size", client.DefaultPageSize, "Rows per page with --all")
 	rootCmd.AddCommand(runSQLCmd)
 }
 
@@ -47,6 +73,18 @@ func runRunSQL(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no project selected — run: legible project use <id>")
 	}
 
+	if runSQLAll && jsonOutput {
+		return fmt.Errorf("--all cannot be combined with --json — use --format ndjson")
+	}
+
+	var out *resultOutput
+	if !jsonOutput {
+		out, err = newResultOutput(runSQLFormat, cmd.Flags().Changed("format"), runSQLOutput)
+		if err != nil {
+			return err
+		}
+	}
+
 	sqlQuery := args[0]
 	req := &client.RunSQLRequest{
 		SQL:      sqlQuery,
@@ -56,23 +94,19 @@ func runRunSQL(cmd *cobra.Command, args []string) error {
 		req.Limit = runSQLLimit
 	}
 
-	result, err := c.RunSQL(req)
-	if err != nil {
-		return err
+	if runSQLAll {
+		return runSQLAllPages(cmd, c, req, out)
 	}
 
-	// Check for error in response
-	if result.Error != "" {
-		if jsonOutput {
+	result, err := c.RunSQL(req)
+	if err != nil {
+		// Error payloads come back with the result; show it as-is in JSON mode.
+		if jsonOutput && result != nil {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
-			return enc.Encode(result)
+			enc.Encode(result)
 		}
-		code := result.Code
-		if code == "" {
-			code = "ERROR"
-		}
-		return fmt.Errorf("[%s] %s", code, result.Error)
+		return err
 	}
 
 	if jsonOutput {
@@ -81,90 +115,53 @@ func runRunSQL(cmd *cobra.Command, args []string) error {
 		return enc.Encode(result)
 	}
 
-	// Print result as a table
 	if len(result.Columns) == 0 {
 		fmt.Println("Query executed successfully (no columns returned).")
 		return nil
 	}
 
-	if len(result.Records) == 0 {
+	if len(result.Records) == 0 && out.format == output.Table && out.toStdout() {
 		fmt.Println("No rows returned.")
 		fmt.Fprintf(os.Stderr, "Columns: %s\n", columnNames(result.Columns))
 		return nil
 	}
 
-	printResultTable(result)
+	if err := out.writeResult(result.Columns, result.Records); err != nil {
+		return err
+	}
 
 	fmt.Fprintf(os.Stderr, "\n%d row(s) returned", len(result.Records))
 	if result.TotalRows > len(result.Records) {
 		fmt.Fprintf(os.Stderr, " (of %d total)", result.TotalRows)
 	}
+	if !out.toStdout() {
+		fmt.Fprintf(os.Stderr, ", written to %s", out.path)
+	}
 	fmt.Fprintln(os.Stderr)
 
 	return nil
 }
 
-// printResultTable renders query results as an aligned ASCII table.
-func printResultTable(result *client.RunSQLResult) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-
-	// Header
-	headers := make([]string, len(result.Columns))
-	for i, col := range result.Columns {
-		headers[i] = col.Name
-	}
-	fmt.Fprintln(w, strings.Join(headers, "\t"))
-
-	// Separator
-	seps := make([]string, len(result.Columns))
-	for i, h := range headers {
-		seps[i] = strings.Repeat("-", max(len(h), 4))
-	}
-	fmt.Fprintln(w, strings.Join(seps, "\t"))
-
-	// Rows
-	for _, record := range result.Records {
-		vals := make([]string, len(result.Columns))
-		for i, col := range result.Columns {
-			val, ok := record[col.Name]
-			if !ok || val == nil {
-				vals[i] = "NULL"
-			} else {
-				vals[i] = formatValue(val)
-			}
+// runSQLAllPages streams every page of the query to out.
+func runSQLAllPages(cmd *cobra.Command, c *client.Client, req *client.RunSQLRequest, out *resultOutput) error {
+	rows, err := out.writePages(c.RunSQLPages(cmd.Context(), req, runSQLPageSize), func(rows int) {
+		if !out.toStdout() {
+			fmt.Fprintf(os.Stderr, "\r%d row(s) fetched", rows)
+		}
+	})
+	if err != nil {
+		if !out.toStdout() && rows > 0 {
+			fmt.Fprintln(os.Stderr)
 		}
-		fmt.Fprintln(w, strings.Join(vals, "\t"))
+		return err
 	}
 
-	w.Flush()
-}
-
-// formatValue converts an interface{} to a display string.
-func formatValue(v interface{}) string {
-	switch val := v.(type) {
-	case string:
-		return val
-	case float64:
-		// JSON numbers are float64; display as int if no fractional part
-		if val == float64(int64(val)) {
-			return fmt.Sprintf("%d", int64(val))
-		}
-		return fmt.Sprintf("%g", val)
-	case bool:
-		if val {
-			return "true"
-		}
-		return "false"
-	case nil:
-		return "NULL"
-	default:
-		// For complex types, marshal to JSON
-		b, err := json.Marshal(val)
-		if err != nil {
-			return fmt.Sprintf("%v", val)
-		}
-		return string(b)
+	fmt.Fprintf(os.Stderr, "\r%d row(s) returned", rows)
+	if !out.toStdout() {
+		fmt.Fprintf(os.Stderr, ", written to %s", out.path)
 	}
+	fmt.Fprintln(os.Stderr)
+	return nil
 }
 
 // columnNames returns a comma-separated list of column names.
@@ -175,10 +172,3 @@ func columnNames(cols []client.RunSQLColumn) string {
 	}
 	return strings.Join(names, ", ")
 }
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,522 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+	"github.com/Kubeworkz/legible/legible-cli/internal/config"
+	"github.com/Kubeworkz/legible/legible-cli/internal/output"
+	"github.com/chzyer/readline"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// shellHelp lists the backslash commands; it is part of the command's help
+// and printed by \?.
+const shellHelp = `Shell commands:
+  \d                 List models
+  \d <model>         Describe a model
+  \ask <question>    Generate SQL for a question and edit it before running
+  \format [format]   Show or set the result format (table, csv, tsv, ndjson, markdown)
+  \thread [new]      Show the session thread, or start a new one
+  \?                 Show help
+  \q                 Quit`
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Interactive SQL shell with schema-aware completion",
+	Long: `Start an interactive shell that runs Legible SQL against the current project.
+
+Statements end with a semicolon and may span several lines. Tab completes
+model and column names (type "model." to complete that model's columns).
+All statements in a session share one thread, and history is kept in
+~/.legible/history.
+
+` + shellHelp + `
+
+Ctrl+C cancels the running statement or clears the current input;
+Ctrl+D quits.`,
+	Args: cobra.NoArgs,
+	RunE: runShell,
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
+
+const (
+	shellPrompt         = "legible> "
+	shellContinuePrompt = "      -> "
+)
+
+// shellCommands are the backslash commands, for completion.
+var shellCommands = []string{`\d`, `\ask`, `\format`, `\thread`, `\?`, `\q`}
+
+// sqlShell holds the state of an interactive session.
+type sqlShell struct {
+	c        *client.Client
+	rl       *readline.Instance
+	threadID string
+	format   string
+
+	models  []client.Model
+	columns []string // every column name, sorted and de-duplicated
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	c, cfg, err := newClientFromConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.ProjectID == "" {
+		return fmt.Errorf("no project selected — run: legible project use <id>")
+	}
+
+	// \ask can take up to 3 minutes
+	c.SetTimeout(4 * time.Minute)
+
+	ctx := cmd.Context()
+	sh := &sqlShell{c: c, format: output.Table}
+	if err := sh.loadModels(ctx); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		pterm.Warning.Printfln("Could not load models for completion: %v", err)
+	}
+
+	rlCfg := &readline.Config{
+		Prompt:                 shellPrompt,
+		AutoComplete:           sh,
+		InterruptPrompt:        "^C",
+		EOFPrompt:              `\q`,
+		HistorySearchFold:      true,
+		DisableAutoSaveHistory: true,
+	}
+	if dir, err := config.Dir(); err == nil && os.MkdirAll(dir, 0700) == nil {
+		rlCfg.HistoryFile = filepath.Join(dir, "history")
+	}
+	sh.rl, err = readline.NewEx(rlCfg)
+	if err != nil {
+		return fmt.Errorf("starting shell: %w", err)
+	}
+	defer sh.rl.Close()
+
+	// While the shell runs, Ctrl+C cancels the running statement only.
+	noop := func() {}
+	interruptHandler.Store(&noop)
+	defer interruptHandler.Store(nil)
+
+	fmt.Printf("Connected to project %s. Type \\? for help, \\q to quit.\n", cfg.ProjectID)
+
+	var buf statementBuffer
+	for {
+		line, err := sh.rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			buf.reset()
+			sh.rl.SetPrompt(shellPrompt)
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		switch kind, text := buf.add(line); kind {
+		case shellQuit:
+			return nil
+		case shellMeta:
+			sh.rl.SaveHistory(text)
+			if quit := sh.command(ctx, text); quit {
+				return nil
+			}
+		case shellPartial:
+			sh.rl.SetPrompt(shellContinuePrompt)
+		case shellStatement:
+			sh.rl.SetPrompt(shellPrompt)
+			sh.rl.SaveHistory(text)
+			sh.runSQL(ctx, strings.TrimRight(text, "; \t\n"))
+		}
+	}
+}
+
+// shellLineKind is what a line of input amounts to.
+type shellLineKind int
+
+const (
+	shellBlank     shellLineKind = iota // nothing to do
+	shellMeta                           // a backslash command
+	shellQuit                           // exit or quit
+	shellPartial                        // a statement that continues on the next line
+	shellStatement                      // the end of a statement
+)
+
+// statementBuffer collects the lines of a statement until one ends with a
+// semicolon. Backslash commands, exit and quit are only recognized on the
+// first line of a statement.
+type statementBuffer struct {
+	lines []string
+}
+
+// add reads a line of input. It returns the backslash command for
+// shellMeta, and the whole statement for shellStatement.
+func (b *statementBuffer) add(line string) (shellLineKind, string) {
+	trimmed := strings.TrimSpace(line)
+	if len(b.lines) == 0 {
+		switch {
+		case trimmed == "":
+			return shellBlank, ""
+		case strings.HasPrefix(trimmed, `\`):
+			return shellMeta, trimmed
+		case trimmed == "exit" || trimmed == "quit":
+			return shellQuit, ""
+		}
+	}
+
+	b.lines = append(b.lines, line)
+	if !strings.HasSuffix(trimmed, ";") {
+		return shellPartial, ""
+	}
+	stmt := strings.TrimSpace(strings.Join(b.lines, "\n"))
+	b.reset()
+	return shellStatement, stmt
+}
+
+// reset discards the lines of an unfinished statement.
+func (b *statementBuffer) reset() {
+	b.lines = nil
+}
+
+// loadModels fetches the models used for completion and \d.
+func (sh *sqlShell) loadModels(ctx context.Context) error {
+	models, err := sh.c.ListModelsContext(ctx)
+	if err != nil {
+		return err
+	}
+	sh.models = models
+	sh.columns = columnNamesOf(models)
+	return nil
+}
+
+// columnNamesOf returns the column names of all models, sorted and
+// de-duplicated.
+func columnNamesOf(models []client.Model) []string {
+	var columns []string
+	for _, m := range models {
+		for _, f := range slices.Concat(m.Fields, m.CalculatedFields) {
+			columns = append(columns, f.ReferenceName)
+		}
+	}
+	slices.Sort(columns)
+	return slices.Compact(columns)
+}
+
+// interruptible returns a context that Ctrl+C cancels, and a function to
+// call once the statement is done. It is for interactive loops that keep
+// running after a statement is interrupted.
+func interruptible(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	handler := func() { cancel() }
+	interruptHandler.Store(&handler)
+	return ctx, func() {
+		noop := func() {}
+		interruptHandler.Store(&noop)
+		cancel()
+	}
+}
+
+// reportError prints a statement error, or "Cancelled" after Ctrl+C.
+func reportError(ctx context.Context, err error) {
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		pterm.Warning.Println("Cancelled")
+		return
+	}
+	pterm.Error.Println(err)
+}
+
+// runSQL runs a statement in the session thread and prints its rows.
+func (sh *sqlShell) runSQL(ctx context.Context, sql string) {
+	stmtCtx, done := interruptible(ctx)
+	defer done()
+
+	start := time.Now()
+	result, err := sh.c.RunSQLContext(stmtCtx, &client.RunSQLRequest{SQL: sql, ThreadID: sh.threadID})
+	if err != nil {
+		reportError(stmtCtx, err)
+		return
+	}
+	if sh.threadID == "" {
+		sh.threadID = result.ThreadID
+	}
+
+	if len(result.Columns) == 0 {
+		fmt.Println("OK")
+		return
+	}
+	if err := printRecords(sh.format, result.Columns, result.Records); err != nil {
+		pterm.Error.Println(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "(%d row(s) in %s)\n\n", len(result.Records), time.Since(start).Round(time.Millisecond))
+}
+
+// command runs a backslash command and reports whether the shell should quit.
+func (sh *sqlShell) command(ctx context.Context, line string) (quit bool) {
+	name, arg := parseShellCommand(line)
+	switch name {
+	case `\q`, `\quit`:
+		return true
+	case `\?`, `\h`, `\help`:
+		fmt.Println(shellHelp)
+	case `\d`:
+		if arg == "" {
+			sh.listModels()
+		} else {
+			sh.describe(ctx, arg)
+		}
+	case `\ask`:
+		if arg == "" {
+			pterm.Error.Println(`usage: \ask <question>`)
+			break
+		}
+		sh.ask(ctx, arg)
+	case `\format`:
+		switch {
+		case arg == "":
+			fmt.Println(sh.format)
+		default:
+			if err := checkShellFormat(arg); err != nil {
+				pterm.Error.Println(err)
+				break
+			}
+			sh.format = arg
+		}
+	case `\thread`:
+		switch arg {
+		case "":
+			if sh.threadID == "" {
+				fmt.Println("No thread yet — it starts with the first statement.")
+			} else {
+				fmt.Println(sh.threadID)
+			}
+		case "new":
+			sh.threadID = ""
+			fmt.Println("The next statement starts a new thread.")
+		default:
+			pterm.Error.Println(`usage: \thread [new]`)
+		}
+	default:
+		pterm.Error.Printfln(`unknown command %s — type \? for help`, name)
+	}
+	return false
+}
+
+// parseShellCommand splits a backslash command into its name and argument.
+func parseShellCommand(line string) (name, arg string) {
+	name, arg, _ = strings.Cut(strings.TrimSpace(line), " ")
+	return name, strings.TrimSpace(arg)
+}
+
+// checkShellFormat reports whether results can be printed in a format.
+func checkShellFormat(format string) error {
+	if output.IsBinary(format) {
+		return fmt.Errorf("%s output is binary — use legible run-sql --output instead", format)
+	}
+	_, err := output.New(format, io.Discard, nil)
+	return err
+}
+
+// listModels prints the models loaded for completion.
+func (sh *sqlShell) listModels() {
+	if len(sh.models) == 0 {
+		fmt.Println("No models found.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSOURCE TABLE\tFIELDS")
+	for _, m := range sh.models {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\n", m.ID, m.ReferenceName, m.SourceTableName, len(m.Fields)+len(m.CalculatedFields))
+	}
+	w.Flush()
+}
+
+// describe prints a model, found by ID, reference name or display name.
+func (sh *sqlShell) describe(ctx context.Context, name string) {
+	id, err := strconv.Atoi(name)
+	if err != nil {
+		id = 0
+		for _, m := range sh.models {
+			if strings.EqualFold(m.ReferenceName, name) || strings.EqualFold(m.DisplayName, name) {
+				id = m.ID
+				break
+			}
+		}
+		if id == 0 {
+			pterm.Error.Printfln("model %q not found", name)
+			return
+		}
+	}
+
+	stmtCtx, done := interruptible(ctx)
+	defer done()
+	model, err := sh.c.GetModelContext(stmtCtx, id)
+	if err != nil {
+		reportError(stmtCtx, err)
+		return
+	}
+	printModelDetail(model)
+	fmt.Println()
+}
+
+// ask generates SQL for a question and puts it on the next input line so it
+// can be edited before it runs.
+func (sh *sqlShell) ask(ctx context.Context, question string) {
+	stmtCtx, done := interruptible(ctx)
+	defer done()
+
+	spinner, _ := pterm.DefaultSpinner.Start("Generating SQL...")
+	result, err := sh.c.GenerateSQLContext(stmtCtx, &client.GenerateSQLRequest{
+		Question: question,
+		ThreadID: sh.threadID,
+	})
+	spinner.Stop()
+	if err != nil {
+		reportError(stmtCtx, err)
+		return
+	}
+	if sh.threadID == "" {
+		sh.threadID = result.ThreadID
+	}
+
+	fmt.Println(formatSQL(result.SQL))
+	fmt.Fprintln(os.Stderr, "Edit the statement below and press Enter to run it, or Ctrl+C to discard it.")
+	sh.rl.WriteStdin([]byte(singleLineSQL(result.SQL) + ";"))
+}
+
+// singleLineSQL puts a statement on one line for editing: runs of
+// whitespace become one space, and -- comments become /* */ comments so
+// they do not swallow the rest of the line. String literals and quoted
+// identifiers are kept as they are.
+func singleLineSQL(sql string) string {
+	sql = strings.TrimRight(strings.TrimSpace(sql), ";")
+	var b strings.Builder
+	space := false
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+			continue
+		case b.Len() > 0 && space:
+			b.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case c == '\'' || c == '"':
+			j := quotedEnd(sql, i)
+			b.WriteString(sql[i:j])
+			i = j
+		case strings.HasPrefix(sql[i:], "--"):
+			j := strings.IndexByte(sql[i:], '\n')
+			if j < 0 {
+				j = len(sql) - i
+			}
+			comment := strings.TrimSpace(strings.ReplaceAll(sql[i+2:i+j], "*/", "* /"))
+			b.WriteString("/* " + comment + " */")
+			i += j
+		case strings.HasPrefix(sql[i:], "/*"):
+			j := strings.Index(sql[i+2:], "*/")
+			if j < 0 {
+				j = len(sql)
+			} else {
+				j += i + 4
+			}
+			b.WriteString(strings.Join(strings.Fields(sql[i:j]), " "))
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return strings.TrimRight(strings.TrimSpace(b.String()), ";")
+}
+
+// Do implements readline.AutoCompleter.
+func (sh *sqlShell) Do(line []rune, pos int) ([][]rune, int) {
+	return completeShell(line, pos, sh.models, sh.columns)
+}
+
+// completeShell completes the word before pos: a backslash command, a model
+// name after \d, a column of a model after "model.", or else a model or
+// column name. It returns the completions' remaining runes and the length
+// of the word, as readline expects.
+func completeShell(line []rune, pos int, models []client.Model, columns []string) ([][]rune, int) {
+	start := pos
+	for start > 0 && isIdentRune(line[start-1]) {
+		start--
+	}
+	word := string(line[start:pos])
+	before := strings.TrimSpace(string(line[:start]))
+
+	var candidates []string
+	switch {
+	case start > 0 && line[start-1] == '\\' && strings.TrimSpace(string(line[:start-1])) == "":
+		word = `\` + word
+		candidates = shellCommands
+	case before == `\d`:
+		candidates = modelNames(models)
+	case strings.HasPrefix(before, `\`):
+		return nil, 0
+	case strings.Contains(word, "."):
+		model, _, _ := strings.Cut(word, ".")
+		for _, m := range models {
+			if m.ReferenceName != model {
+				continue
+			}
+			for _, f := range slices.Concat(m.Fields, m.CalculatedFields) {
+				candidates = append(candidates, model+"."+f.ReferenceName)
+			}
+		}
+	default:
+		candidates = append(modelNames(models), columns...)
+	}
+
+	var out [][]rune
+	for _, cand := range candidates {
+		if strings.HasPrefix(cand, word) && cand != word {
+			out = append(out, []rune(cand[len(word):]))
+		}
+	}
+	return out, len([]rune(word))
+}
+
+func modelNames(models []client.Model) []string {
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m.ReferenceName
+	}
+	return names
+}
+
+// isIdentRune reports whether r can be part of a (possibly qualified)
+// identifier being completed.
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
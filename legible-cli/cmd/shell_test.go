@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+func TestStatementBuffer(t *testing.T) {
+	type step struct {
+		line     string
+		wantKind shellLineKind
+		wantText string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"blank line", []step{{"   ", shellBlank, ""}}},
+		{"single line", []step{{"SELECT 1;", shellStatement, "SELECT 1;"}}},
+		{"multi-line", []step{
+			{"SELECT id", shellPartial, ""},
+			{"  FROM orders", shellPartial, ""},
+			{"  WHERE id > 1;  ", shellStatement, "SELECT id\n  FROM orders\n  WHERE id > 1;"},
+		}},
+		{"blank line inside a statement", []step{
+			{"SELECT id", shellPartial, ""},
+			{"", shellPartial, ""},
+			{"FROM orders;", shellStatement, "SELECT id\n\nFROM orders;"},
+		}},
+		{"meta command", []step{{`  \d orders `, shellMeta, `\d orders`}}},
+		{"quit", []step{{"exit", shellQuit, ""}, {" quit ", shellQuit, ""}}},
+		{"commands only start a statement", []step{
+			{"SELECT", shellPartial, ""},
+			{`\d`, shellPartial, ""},
+			{"quit;", shellStatement, "SELECT\n\\d\nquit;"},
+		}},
+		{"buffer is empty after a statement", []step{
+			{"SELECT 1;", shellStatement, "SELECT 1;"},
+			{`\q`, shellMeta, `\q`},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf statementBuffer
+			for i, s := range tt.steps {
+				kind, text := buf.add(s.line)
+				if kind != s.wantKind || text != s.wantText {
+					t.Errorf("step %d: add(%q) = %d, %q, want %d, %q", i, s.line, kind, text, s.wantKind, s.wantText)
+				}
+			}
+		})
+	}
+}
+
+func TestStatementBufferReset(t *testing.T) {
+	var buf statementBuffer
+	buf.add("SELECT id")
+	buf.reset()
+	if kind, text := buf.add("SELECT 2;"); kind != shellStatement || text != "SELECT 2;" {
+		t.Errorf("add after reset = %d, %q", kind, text)
+	}
+}
+
+func TestParseShellCommand(t *testing.T) {
+	tests := []struct {
+		line, name, arg string
+	}{
+		{`\q`, `\q`, ""},
+		{`\d  orders `, `\d`, "orders"},
+		{`\ask how many orders per month?`, `\ask`, "how many orders per month?"},
+		{` \format csv`, `\format`, "csv"},
+	}
+	for _, tt := range tests {
+		name, arg := parseShellCommand(tt.line)
+		if name != tt.name || arg != tt.arg {
+			t.Errorf("parseShellCommand(%q) = %q, %q, want %q, %q", tt.line, name, arg, tt.name, tt.arg)
+		}
+	}
+}
+
+func TestCheckShellFormat(t *testing.T) {
+	for _, format := range []string{"table", "csv", "ndjson"} {
+		if err := checkShellFormat(format); err != nil {
+			t.Errorf("checkShellFormat(%q) = %v", format, err)
+		}
+	}
+	if err := checkShellFormat("parquet"); err == nil || !strings.Contains(err.Error(), "binary") {
+		t.Errorf("checkShellFormat(parquet) = %v, want a binary format error", err)
+	}
+	if err := checkShellFormat("yaml"); err == nil {
+		t.Error("checkShellFormat(yaml) should fail")
+	}
+}
+
+func TestCompleteShell(t *testing.T) {
+	models := []client.Model{
+		{ReferenceName: "orders", Fields: []client.Field{{ReferenceName: "id"}, {ReferenceName: "order_date"}},
+			CalculatedFields: []client.Field{{ReferenceName: "order_total"}}},
+		{ReferenceName: "customers", Fields: []client.Field{{ReferenceName: "id"}, {ReferenceName: "name"}}},
+	}
+	columns := columnNamesOf(models)
+	if want := []string{"id", "name", "order_date", "order_total"}; !reflect.DeepEqual(columns, want) {
+		t.Fatalf("columnNamesOf() = %v, want %v", columns, want)
+	}
+
+	tests := []struct {
+		name    string
+		line    string
+		want    []string
+		wantLen int
+	}{
+		{"backslash command", `\f`, []string{"ormat"}, 2},
+		{"all backslash commands", `\`, []string{"d", "ask", "format", "thread", "?", "q"}, 1},
+		{"model after \\d", `\d cu`, []string{"stomers"}, 2},
+		{"nothing after other commands", `\ask ord`, nil, 0},
+		{"models and columns", "SELECT * FROM ord", []string{"ers", "er_date", "er_total"}, 3},
+		{"columns of a model", "SELECT orders.order_", []string{"date", "total"}, 13},
+		{"unknown model", "SELECT products.", nil, 9},
+		{"complete word", "SELECT name", nil, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := []rune(tt.line)
+			out, n := completeShell(line, len(line), models, columns)
+			var got []string
+			for _, r := range out {
+				got = append(got, string(r))
+			}
+			if !reflect.DeepEqual(got, tt.want) || n != tt.wantLen {
+				t.Errorf("completeShell(%q) = %q, %d, want %q, %d", tt.line, got, n, tt.want, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestSingleLineSQL(t *testing.T) {
+	tests := []struct {
+		sql, want string
+	}{
+		{"SELECT id\n  FROM orders\n;", "SELECT id FROM orders"},
+		{"SELECT 'a  b\n c' AS s,\n\t\"my  col\"\nFROM t", "SELECT 'a  b\n c' AS s, \"my  col\" FROM t"},
+		{"SELECT id -- the key\nFROM orders -- all of them", "SELECT id /* the key */ FROM orders /* all of them */"},
+		{"SELECT '--not a comment', 'it''s' FROM t", "SELECT '--not a comment', 'it''s' FROM t"},
+		{"SELECT /* keep\n   this */ 1 -- a */ b\n", "SELECT /* keep this */ 1 /* a * / b */"},
+	}
+	for _, tt := range tests {
+		if got := singleLineSQL(tt.sql); got != tt.want {
+			t.Errorf("singleLineSQL(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
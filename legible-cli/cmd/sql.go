@@ -65,20 +65,13 @@ func runSQL(cmd *cobra.Command, args []string) error {
 
 	result, err := c.GenerateSQL(req)
 	if err != nil {
-		return err
-	}
-
-	// Non-SQL query or error
-	if result.Code != "" {
-		if jsonOutput {
+		// Non-SQL queries come back with the result; show it as-is in JSON mode.
+		if jsonOutput && result != nil {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
-			return enc.Encode(result)
+			enc.Encode(result)
 		}
-		if result.Error != "" {
-			return fmt.Errorf("[%s] %s", result.Code, result.Error)
-		}
-		return fmt.Errorf("[%s] query could not be converted to SQL", result.Code)
+		return err
 	}
 
 	if jsonOutput {
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
@@ -60,11 +61,27 @@ Examples:
 	RunE: runThreadDelete,
 }
 
+var threadResumeCmd = &cobra.Command{
+	Use:   "resume <thread-id>",
+	Short: "Continue a legible ask conversation",
+	Long: `Show the questions already asked in a thread and continue it
+interactively, as with "legible ask --interactive".
+
+The thread ID is the one printed by "legible ask" (Thread: ...), not the
+numeric ID shown by "legible thread list".
+
+Examples:
+  legible thread resume 3f1c2a9e-8b7d-4e2f-9a61-0c5d7e8f9a10`,
+	Args: cobra.ExactArgs(1),
+	RunE: runThreadResume,
+}
+
 func init() {
 	threadCmd.AddCommand(threadListCmd)
 	threadCmd.AddCommand(threadShowCmd)
 	threadCmd.AddCommand(threadRenameCmd)
 	threadCmd.AddCommand(threadDeleteCmd)
+	threadCmd.AddCommand(threadResumeCmd)
 	rootCmd.AddCommand(threadCmd)
 }
 
@@ -201,6 +218,28 @@ func runThreadDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runThreadResume(cmd *cobra.Command, args []string) error {
+	if jsonOutput {
+		return fmt.Errorf("thread resume is interactive and does not support --json")
+	}
+
+	c, cfg, err := newClientFromConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.ProjectID == "" {
+		return fmt.Errorf("no project selected — run: legible project use <id>")
+	}
+
+	if err := printThreadHistory(cmd.Context(), c, args[0]); err != nil {
+		if cmd.Context().Err() != nil {
+			return err
+		}
+		pterm.Warning.Printfln("Could not load thread history: %v", err)
+	}
+	return runConversation(cmd, args[0], "")
+}
+
 // indentSQL adds a 2-space indent to each line of SQL for display.
 func indentSQL(sql string) string {
 	lines := strings.Split(sql, "\n")
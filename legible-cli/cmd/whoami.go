@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/Kubeworkz/legible/legible-cli/internal/client"
 	"github.com/Kubeworkz/legible/legible-cli/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -28,7 +27,7 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	c, err := client.New(cfg)
+	c, err := newClient(cfg)
 	if err != nil {
 		return err
 	}
@@ -44,6 +43,7 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		return enc.Encode(info)
 	}
 
+	fmt.Printf("Profile:      %s\n", cfg.Profile)
 	fmt.Printf("Endpoint:     %s\n", cfg.Endpoint)
 	fmt.Printf("User:         %s\n", info.UserEmail)
 	if info.UserName != "" {
@@ -0,0 +1,268 @@
+package chart
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, spec string) *Spec {
+	t.Helper()
+	s, err := Parse(json.RawMessage(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return s
+}
+
+const barSpec = `{
+	"title": "Orders by status",
+	"mark": {"type": "bar"},
+	"encoding": {
+		"x": {"field": "status", "type": "nominal"},
+		"y": {"field": "orders", "type": "quantitative", "title": "Orders"}
+	}
+}`
+
+var barRecords = []map[string]interface{}{
+	{"status": "shipped", "orders": json.Number("120")},
+	{"status": "pending", "orders": json.Number("30")},
+	{"status": "returned", "orders": 7.0},
+}
+
+func TestParse(t *testing.T) {
+	s := mustParse(t, barSpec)
+	if s.Title != "Orders by status" || s.Mark != MarkBar {
+		t.Errorf("title, mark = %q, %q", s.Title, s.Mark)
+	}
+	if s.X.Field != "status" || s.Y.Label() != "Orders" {
+		t.Errorf("x = %+v, y = %+v", s.X, s.Y)
+	}
+	if !s.Stacked {
+		t.Error("bars should stack by default")
+	}
+
+	grouped := mustParse(t, `{"mark": "bar", "encoding": {
+		"x": {"field": "month", "type": "ordinal"},
+		"y": {"field": "sales", "type": "quantitative"},
+		"xOffset": {"field": "region"},
+		"color": {"field": "region"}}}`)
+	if grouped.Stacked {
+		t.Error("xOffset bars should not stack")
+	}
+
+	folded := mustParse(t, `{"mark": "line",
+		"transform": [{"fold": ["a", "b"], "as": ["metric", "value"]}],
+		"encoding": {"x": {"field": "day", "type": "temporal"}, "y": {"field": "value", "type": "quantitative"}}}`)
+	if len(folded.Folds) != 1 || folded.Folds[0].As != [2]string{"metric", "value"} {
+		t.Errorf("folds = %+v", folded.Folds)
+	}
+
+	for _, bad := range []string{
+		`{"layer": [{"mark": "bar"}]}`,
+		`{"mark": "rect", "encoding": {}}`,
+		`{"mark": "bar", "encoding": {"x": {"field": "a"}}}`,
+		`{"mark": "bar", "transform": [{"filter": "datum.a > 1"}], "encoding": {"x": {"field": "a"}, "y": {"field": "b"}}}`,
+	} {
+		if _, err := Parse(json.RawMessage(bad)); err == nil {
+			t.Errorf("Parse(%s) should fail", bad)
+		}
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderSVG(&buf, mustParse(t, barSpec), barRecords); err != nil {
+		t.Fatalf("RenderSVG: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "<svg ") || !strings.HasSuffix(out, "</svg>\n") {
+		t.Fatalf("not an SVG document:\n%s", out)
+	}
+	// Background plus one rect per bar.
+	if n := strings.Count(out, "<rect "); n != 4 {
+		t.Errorf("got %d rects, want 4", n)
+	}
+	for _, want := range []string{">shipped<", ">Orders by status<", ">Orders<"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG missing %q", want)
+		}
+	}
+}
+
+func TestNiceDomain(t *testing.T) {
+	lo, hi, ticks := niceDomain(3, 97, 5)
+	if lo != 0 || hi != 100 || !reflect.DeepEqual(ticks, []float64{0, 20, 40, 60, 80, 100}) {
+		t.Errorf("niceDomain(3, 97) = %v, %v, %v", lo, hi, ticks)
+	}
+
+	// Steps too small to change values this large used to loop forever
+	for _, d := range [][2]float64{{1e17, 1e17 + 16}, {1e18, 1e18 + 128}, {-1e18 - 128, -1e18}} {
+		lo, hi, ticks := niceDomain(d[0], d[1], 5)
+		if lo > d[0] || hi < d[1] || len(ticks) < 2 || len(ticks) > 21 {
+			t.Errorf("niceDomain(%v, %v) = %v, %v, %d ticks", d[0], d[1], lo, hi, len(ticks))
+		}
+	}
+	var buf bytes.Buffer
+	records := []map[string]interface{}{{"status": "a", "orders": 1e17}, {"status": "b", "orders": 1e17 + 16}}
+	if err := RenderSVG(&buf, mustParse(t, barSpec), records); err != nil {
+		t.Errorf("RenderSVG with large values: %v", err)
+	}
+}
+
+func TestRenderSVGMarks(t *testing.T) {
+	records := []map[string]interface{}{
+		{"day": "2024-01-01", "region": "EU", "sales": 10.0},
+		{"day": "2024-01-02", "region": "EU", "sales": 14.0},
+		{"day": "2024-01-01", "region": "US", "sales": 20.0},
+		{"day": "2024-01-02", "region": "US", "sales": 12.0},
+	}
+	for _, mark := range []string{"line", "area", "point"} {
+		spec := `{"mark": "` + mark + `", "encoding": {
+			"x": {"field": "day", "type": "temporal"},
+			"y": {"field": "sales", "type": "quantitative"},
+			"color": {"field": "region", "type": "nominal"}}}`
+		var buf bytes.Buffer
+		if err := RenderSVG(&buf, mustParse(t, spec), records); err != nil {
+			t.Fatalf("%s: %v", mark, err)
+		}
+		if !strings.Contains(buf.String(), ">US<") {
+			t.Errorf("%s: legend missing", mark)
+		}
+	}
+
+	pie := mustParse(t, `{"mark": "arc", "encoding": {
+		"theta": {"field": "orders", "type": "quantitative"},
+		"color": {"field": "status", "type": "nominal"}}}`)
+	var buf bytes.Buffer
+	if err := RenderSVG(&buf, pie, barRecords); err != nil {
+		t.Fatalf("arc: %v", err)
+	}
+	if n := strings.Count(buf.String(), "<polygon "); n != 3 {
+		t.Errorf("arc: got %d slices, want 3", n)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	if err := RenderSVG(&bytes.Buffer{}, mustParse(t, barSpec), nil); err == nil {
+		t.Error("expected an error for no data")
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderPNG(&buf, mustParse(t, barSpec), barRecords); err != nil {
+		t.Fatalf("RenderPNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decoding PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() < defaultWidth || b.Dy() < defaultHeight {
+		t.Errorf("image is %v", b)
+	}
+}
+
+func TestRenderTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderTerminal(&buf, mustParse(t, barSpec), barRecords, 60); err != nil {
+		t.Fatalf("RenderTerminal: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "Orders by status" {
+		t.Errorf("title = %q", lines[0])
+	}
+	// Categories sort alphabetically, like the SVG axis.
+	bars := lines[2:]
+	if len(bars) != 3 || !strings.HasPrefix(bars[0], "pending ") || !strings.HasSuffix(bars[1], "7") {
+		t.Fatalf("bars:\n%s", strings.Join(bars, "\n"))
+	}
+	if !strings.Contains(bars[2], strings.Repeat("█", 40)) {
+		t.Errorf("largest bar should span the width: %q", bars[2])
+	}
+
+	line := mustParse(t, `{"mark": "line", "encoding": {
+		"x": {"field": "n", "type": "quantitative"},
+		"y": {"field": "v", "type": "quantitative"}}}`)
+	buf.Reset()
+	err := RenderTerminal(&buf, line, []map[string]interface{}{{"n": 1.0, "v": 1.0}, {"n": 2.0, "v": 8.0}}, 40)
+	if err != nil {
+		t.Fatalf("RenderTerminal: %v", err)
+	}
+	if rows := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"); len(rows) != columnRows+1 {
+		t.Errorf("got %d rows:\n%s", len(rows), buf.String())
+	}
+}
+
+func TestWithData(t *testing.T) {
+	spec := map[string]interface{}{"mark": "bar", "data": map[string]interface{}{"values": []interface{}{}}}
+	got, err := WithData(spec, barRecords)
+	if err != nil {
+		t.Fatalf("WithData: %v", err)
+	}
+	values := got["data"].(map[string]interface{})["values"].([]map[string]interface{})
+	if len(values) != 3 {
+		t.Errorf("got %d values", len(values))
+	}
+	if len(spec["data"].(map[string]interface{})["values"].([]interface{})) != 0 {
+		t.Error("WithData modified its input")
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	spec, _ := WithData(map[string]interface{}{"mark": "bar"}, []map[string]interface{}{{"a": "</script><b>"}})
+	var buf bytes.Buffer
+	err := RenderHTML(&buf, "Sales & <more>", spec)
+	if errors.Is(err, ErrNotVendored) {
+		t.Fatal("the Vega bundles are not committed in assets/; run make vendor-vega VEGA_UPDATE=1 and commit them")
+	}
+	if err != nil {
+		t.Fatalf("RenderHTML: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "<title>Sales &amp; &lt;more&gt;</title>") {
+		t.Error("title not escaped")
+	}
+	if strings.Contains(out, "</script><b>") {
+		t.Error("data can close the script tag")
+	}
+	if !strings.Contains(out, "vegaEmbed(") {
+		t.Error("missing vegaEmbed call")
+	}
+	if strings.Contains(out, "<script src=") {
+		t.Error("page loads a script from the network")
+	}
+}
+
+// TestVendoredChecksums checks the embedded bundles against the checksums
+// recorded when they were fetched.
+func TestVendoredChecksums(t *testing.T) {
+	sums, err := assets.ReadFile("assets/SHA256SUMS")
+	if err != nil {
+		t.Fatal("assets/SHA256SUMS is not committed; run make vendor-vega VEGA_UPDATE=1 and commit it with the bundles")
+	}
+	want := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(sums)), "\n") {
+		sum, file, ok := strings.Cut(line, "  ")
+		if !ok {
+			t.Fatalf("malformed SHA256SUMS line %q", line)
+		}
+		want[file] = sum
+	}
+	for _, file := range scripts {
+		js, err := assets.ReadFile("assets/" + file)
+		if err != nil {
+			t.Errorf("%s is not vendored: %v", file, err)
+			continue
+		}
+		if got := fmt.Sprintf("%x", sha256.Sum256(js)); got != want[file] {
+			t.Errorf("%s sha256 = %s, want %s", file, got, want[file])
+		}
+	}
+}
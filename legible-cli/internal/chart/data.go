@@ -0,0 +1,391 @@
+package chart
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frame is a chart's data, reduced to what the renderers draw: values by
+// category along one axis, split into color series.
+type frame struct {
+	mark string
+
+	// cat is the category (domain) channel and val the value channel. For
+	// horizontal bars cat is y and val is x; for arcs cat is color and val
+	// is theta.
+	cat, val   *Channel
+	horizontal bool
+
+	// discrete is true when categories are placed on a band scale;
+	// otherwise catNum positions them on a linear (or time) scale.
+	discrete bool
+	temporal bool
+
+	categories []string // discrete categories, in axis order
+	series     []string // color series, in order; nil when not split
+
+	// colorByCategory is true when the color encoding is the category
+	// itself, so each category gets its own color.
+	colorByCategory bool
+
+	// stackMode is the spec's stacking; see Spec.Stacked.
+	stackMode bool
+
+	points []datum
+}
+
+// datum is one (aggregated) value.
+type datum struct {
+	cat    string
+	catNum float64
+	series string
+	value  float64
+}
+
+// newFrame applies the spec's transforms and encodings to records.
+func newFrame(s *Spec, records []map[string]interface{}) (*frame, error) {
+	for _, f := range s.Folds {
+		records = applyFold(records, f)
+	}
+
+	f := &frame{mark: s.Mark, stackMode: s.Stacked}
+	switch {
+	case s.Mark == MarkArc:
+		f.cat, f.val = s.Color, s.Theta
+		f.discrete, f.colorByCategory = true, true
+	case s.Mark == MarkBar && s.X.Type == Quantitative && s.Y.Type != Quantitative:
+		f.cat, f.val, f.horizontal = s.Y, s.X, true
+	default:
+		f.cat, f.val = s.X, s.Y
+	}
+	if f.cat != nil {
+		f.temporal = f.cat.Type == Temporal
+		f.discrete = f.discrete || f.cat.Discrete() || s.Mark == MarkBar
+	} else {
+		f.discrete = true
+	}
+
+	var seriesCh *Channel
+	if s.Mark != MarkArc && s.Color != nil {
+		if f.cat != nil && s.Color.Field == f.cat.Field {
+			f.colorByCategory = true
+		} else {
+			seriesCh = s.Color
+		}
+	}
+	if seriesCh == nil && s.XOffset != nil && s.Mark == MarkBar {
+		seriesCh = s.XOffset
+	}
+
+	type key struct{ cat, series string }
+	var raw []datum
+	for _, rec := range records {
+		d := datum{}
+		if f.cat != nil {
+			v := rec[f.cat.Field]
+			if v == nil {
+				continue
+			}
+			if f.temporal {
+				t, ok := parseTime(v)
+				if !ok {
+					return nil, fmt.Errorf("field %q: invalid date %v", f.cat.Field, v)
+				}
+				t = truncateTime(t, f.cat.TimeUnit)
+				d.catNum = float64(t.Unix())
+				d.cat = formatTime(t, f.cat.TimeUnit)
+			} else {
+				d.cat = text(v)
+				if !f.discrete {
+					n, ok := toFloat(v)
+					if !ok {
+						return nil, fmt.Errorf("field %q: %v is not a number", f.cat.Field, v)
+					}
+					d.catNum = n
+				}
+			}
+		}
+
+		if f.val.Aggregate == "count" {
+			d.value = 1
+		} else {
+			n, ok := toFloat(rec[f.val.Field])
+			if !ok {
+				continue
+			}
+			d.value = n
+		}
+		if seriesCh != nil {
+			d.series = text(rec[seriesCh.Field])
+		}
+		raw = append(raw, d)
+	}
+
+	if s.Mark == MarkPoint && f.val.Aggregate == "" {
+		f.points = raw
+	} else {
+		groups := map[key][]datum{}
+		var order []key
+		for _, d := range raw {
+			k := key{d.cat, d.series}
+			if _, ok := groups[k]; !ok {
+				order = append(order, k)
+			}
+			groups[k] = append(groups[k], d)
+		}
+		for _, k := range order {
+			g := groups[k]
+			values := make([]float64, len(g))
+			for i, d := range g {
+				values[i] = d.value
+			}
+			d := g[0]
+			d.value = aggregate(f.val.Aggregate, values)
+			f.points = append(f.points, d)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, d := range f.points {
+		if f.discrete && !seen["c\x00"+d.cat] {
+			seen["c\x00"+d.cat] = true
+			f.categories = append(f.categories, d.cat)
+		}
+		if seriesCh != nil && !seen["s\x00"+d.series] {
+			seen["s\x00"+d.series] = true
+			f.series = append(f.series, d.series)
+		}
+	}
+	if f.discrete && s.Mark != MarkArc {
+		f.sortCategories()
+	}
+	if !f.discrete {
+		slices.SortStableFunc(f.points, func(a, b datum) int { return cmp.Compare(a.catNum, b.catNum) })
+	}
+	return f, nil
+}
+
+// sortCategories orders discrete categories like Vega-Lite: times
+// chronologically, numbers numerically, and text alphabetically.
+func (f *frame) sortCategories() {
+	if f.temporal {
+		pos := map[string]float64{}
+		for _, d := range f.points {
+			pos[d.cat] = d.catNum
+		}
+		slices.SortStableFunc(f.categories, func(a, b string) int { return cmp.Compare(pos[a], pos[b]) })
+		return
+	}
+	numeric := true
+	for _, c := range f.categories {
+		if _, err := strconv.ParseFloat(c, 64); err != nil {
+			numeric = false
+			break
+		}
+	}
+	slices.SortStableFunc(f.categories, func(a, b string) int {
+		if numeric {
+			x, _ := strconv.ParseFloat(a, 64)
+			y, _ := strconv.ParseFloat(b, 64)
+			return cmp.Compare(x, y)
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+// value returns the value of a category in a series, and whether it has one.
+func (f *frame) value(cat, series string) (float64, bool) {
+	for _, d := range f.points {
+		if d.cat == cat && d.series == series {
+			return d.value, true
+		}
+	}
+	return 0, false
+}
+
+// seriesPoints returns the points of one series, in category order.
+func (f *frame) seriesPoints(series string) []datum {
+	var out []datum
+	if f.discrete {
+		for _, c := range f.categories {
+			if v, ok := f.value(c, series); ok {
+				out = append(out, datum{cat: c, series: series, value: v})
+			}
+		}
+		return out
+	}
+	for _, d := range f.points {
+		if d.series == series {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// seriesNames returns the series to draw; a single unnamed one when the
+// data isn't split by color.
+func (f *frame) seriesNames() []string {
+	if len(f.series) == 0 {
+		return []string{""}
+	}
+	return f.series
+}
+
+// applyFold expands each record into one record per folded field.
+func applyFold(records []map[string]interface{}, f Fold) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(records)*len(f.Fields))
+	for _, rec := range records {
+		for _, field := range f.Fields {
+			r := make(map[string]interface{}, len(rec)+2)
+			for k, v := range rec {
+				r[k] = v
+			}
+			r[f.As[0]] = field
+			r[f.As[1]] = rec[field]
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// aggregate combines the values of one category. Without an aggregate,
+// duplicates are summed, which is how stacked marks would draw them.
+func aggregate(op string, values []float64) float64 {
+	switch op {
+	case "count":
+		return float64(len(values))
+	case "mean", "average":
+		return sum(values) / float64(len(values))
+	case "min":
+		return slices.Min(values)
+	case "max":
+		return slices.Max(values)
+	case "median":
+		s := slices.Clone(values)
+		slices.Sort(s)
+		if len(s)%2 == 1 {
+			return s[len(s)/2]
+		}
+		return (s[len(s)/2-1] + s[len(s)/2]) / 2
+	}
+	return sum(values)
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// toFloat converts a JSON value to a number.
+func toFloat(v interface{}) (float64, bool) {
+	var f float64
+	var err error
+	switch val := v.(type) {
+	case json.Number:
+		f, err = val.Float64()
+	case float64:
+		f = val
+	case int:
+		f = float64(val)
+	case int64:
+		f = float64(val)
+	case string:
+		f, err = strconv.ParseFloat(strings.TrimSpace(val), 64)
+	case bool:
+		if val {
+			f = 1
+		}
+	default:
+		return 0, false
+	}
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// text renders a value as a label.
+func text(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// parseTime parses a date, a timestamp, or epoch milliseconds.
+func parseTime(v interface{}) (time.Time, bool) {
+	if s, ok := v.(string); ok {
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC(), true
+			}
+		}
+		return time.Time{}, false
+	}
+	if ms, ok := toFloat(v); ok {
+		return time.UnixMilli(int64(ms)).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// truncateTime applies a Vega-Lite time unit.
+func truncateTime(t time.Time, unit string) time.Time {
+	switch strings.TrimPrefix(unit, "utc") {
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case "yearquarter":
+		return time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+	case "yearmonth":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "yearweek":
+		d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return d.AddDate(0, 0, -int(d.Weekday()))
+	case "yearmonthdate", "date":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// formatTime labels a time for its time unit.
+func formatTime(t time.Time, unit string) string {
+	switch strings.TrimPrefix(unit, "utc") {
+	case "year":
+		return t.Format("2006")
+	case "yearquarter":
+		return fmt.Sprintf("%d Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	case "yearmonth":
+		return t.Format("Jan 2006")
+	case "yearweek", "yearmonthdate", "date":
+		return t.Format("2006-01-02")
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,78 @@
+package chart
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Versions of the vendored libraries, kept in step with legible-ui. The
+// bundles are committed in assets/ with their checksums in
+// assets/SHA256SUMS; the Makefile's vendor-vega target fetches them.
+const (
+	VegaVersion      = "6.2.0"
+	VegaLiteVersion  = "6.2.0"
+	VegaEmbedVersion = "6.29.0"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// scripts are the vendored bundles in load order.
+var scripts = []string{"vega.min.js", "vega-lite.min.js", "vega-embed.min.js"}
+
+// ErrNotVendored is returned by RenderHTML when the Vega bundles are
+// missing from assets/ at build time.
+var ErrNotVendored = errors.New("this build does not include the Vega libraries — run make vendor-vega and rebuild")
+
+// Vendored reports whether the Vega bundles were embedded at build time.
+func Vendored() bool {
+	for _, file := range scripts {
+		if _, err := assets.ReadFile("assets/" + file); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderHTML writes a standalone HTML page that draws spec with
+// vega-embed, with the Vega libraries inlined so that it works offline.
+// The spec should already carry its data (see WithData).
+func RenderHTML(w io.Writer, title string, spec map[string]interface{}) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("encoding spec: %w", err)
+	}
+	if title == "" {
+		title = "Chart"
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	for _, file := range scripts {
+		js, err := assets.ReadFile("assets/" + file)
+		if err != nil {
+			return ErrNotVendored
+		}
+		b.WriteString("<script>\n")
+		b.WriteString(escapeScript(string(js)))
+		b.WriteString("\n</script>\n")
+	}
+	b.WriteString("</head>\n<body>\n<div id=\"chart\"></div>\n<script>\n")
+	fmt.Fprintf(&b, "vegaEmbed(\"#chart\", %s, {actions: {export: true, source: false, compiled: false, editor: false}});\n",
+		escapeScript(string(data)))
+	b.WriteString("</script>\n</body>\n</html>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// escapeScript keeps inline script content from closing its <script> tag.
+func escapeScript(s string) string {
+	return strings.ReplaceAll(s, "</script", `<\/script`)
+}
@@ -0,0 +1,138 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// RenderPNG draws the chart for records as a PNG image.
+func RenderPNG(w io.Writer, s *Spec, records []map[string]interface{}) error {
+	f, lay, err := prepare(s, records)
+	if err != nil {
+		return err
+	}
+	c := newRasterCanvas(int(lay.width), int(lay.height))
+	draw(c, s, f, lay)
+	return png.Encode(w, c.img)
+}
+
+// rasterCanvas draws onto an RGBA image with an anti-aliasing rasterizer.
+// Text uses a fixed-size bitmap font, so sizes are approximate.
+type rasterCanvas struct {
+	img *image.RGBA
+}
+
+func newRasterCanvas(w, h int) *rasterCanvas {
+	return &rasterCanvas{img: image.NewRGBA(image.Rect(0, 0, w, h))}
+}
+
+// fill rasterizes a closed polygon in color col.
+func (c *rasterCanvas) fill(pts []point, col color.Color) {
+	if len(pts) < 3 {
+		return
+	}
+	b := c.img.Bounds()
+	r := vector.NewRasterizer(b.Dx(), b.Dy())
+	r.MoveTo(float32(pts[0].x), float32(pts[0].y))
+	for _, p := range pts[1:] {
+		r.LineTo(float32(p.x), float32(p.y))
+	}
+	r.ClosePath()
+	r.Draw(c.img, b, image.NewUniform(col), image.Point{})
+}
+
+func (c *rasterCanvas) rect(x, y, w, h float64, fill string) {
+	c.fill([]point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}, parseColor(fill, 1))
+}
+
+// segment draws a line segment as a quad of the given width.
+func (c *rasterCanvas) segment(a, b point, col color.Color, width float64) {
+	dx, dy := b.x-a.x, b.y-a.y
+	n := math.Hypot(dx, dy)
+	if n == 0 {
+		return
+	}
+	ox, oy := -dy/n*width/2, dx/n*width/2
+	c.fill([]point{{a.x + ox, a.y + oy}, {b.x + ox, b.y + oy}, {b.x - ox, b.y - oy}, {a.x - ox, a.y - oy}}, col)
+}
+
+func (c *rasterCanvas) line(x1, y1, x2, y2 float64, stroke string, width float64) {
+	c.segment(point{x1, y1}, point{x2, y2}, parseColor(stroke, 1), width)
+}
+
+func (c *rasterCanvas) polyline(pts []point, stroke string, width float64) {
+	col := parseColor(stroke, 1)
+	for i := 1; i < len(pts); i++ {
+		c.segment(pts[i-1], pts[i], col, width)
+		if i < len(pts)-1 {
+			// Round joins.
+			c.fill(circlePoints(pts[i].x, pts[i].y, width/2), col)
+		}
+	}
+	if len(pts) == 1 {
+		c.fill(circlePoints(pts[0].x, pts[0].y, width), col)
+	}
+}
+
+func (c *rasterCanvas) polygon(pts []point, fill string, opacity float64) {
+	c.fill(pts, parseColor(fill, opacity))
+}
+
+func (c *rasterCanvas) circle(x, y, r float64, fill string, opacity float64) {
+	c.fill(circlePoints(x, y, r), parseColor(fill, opacity))
+}
+
+func (c *rasterCanvas) text(x, y float64, s string, size float64, anchor, fill string, bold bool) {
+	d := font.Drawer{Dst: c.img, Src: image.NewUniform(parseColor(fill, 1)), Face: basicfont.Face7x13}
+	width := d.MeasureString(s).Round()
+	switch anchor {
+	case "middle":
+		x -= float64(width) / 2
+	case "end":
+		x -= float64(width)
+	}
+	d.Dot = fixed.P(int(math.Round(x)), int(math.Round(y)))
+	d.DrawString(s)
+	if bold {
+		d.Dot = fixed.P(int(math.Round(x))+1, int(math.Round(y)))
+		d.DrawString(s)
+	}
+}
+
+func circlePoints(x, y, r float64) []point {
+	const n = 24
+	pts := make([]point, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / n
+		pts[i] = point{x + r*math.Cos(a), y + r*math.Sin(a)}
+	}
+	return pts
+}
+
+// parseColor reads a "#rrggbb" color.
+func parseColor(hex string, opacity float64) color.Color {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.Black
+	}
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.Black
+	}
+	a := opacity * 255
+	// Premultiplied alpha.
+	return color.RGBA{
+		R: uint8(float64(v>>16&0xff) * opacity),
+		G: uint8(float64(v>>8&0xff) * opacity),
+		B: uint8(float64(v&0xff) * opacity),
+		A: uint8(a),
+	}
+}
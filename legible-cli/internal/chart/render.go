@@ -0,0 +1,429 @@
+package chart
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// Default plot size, used when the spec doesn't set width and height.
+const (
+	defaultWidth  = 560
+	defaultHeight = 320
+)
+
+// palette is Vega's default categorical color scheme (tableau10).
+var palette = []string{
+	"#4c78a8", "#f58518", "#e45756", "#72b7b2", "#54a24b",
+	"#eeca3b", "#b279a2", "#ff9da6", "#9d755d", "#bab0ac",
+}
+
+const (
+	axisColor  = "#888888"
+	gridColor  = "#dddddd"
+	labelColor = "#333333"
+	labelSize  = 11.0
+	titleSize  = 14.0
+)
+
+type point struct{ x, y float64 }
+
+// canvas is a drawing surface. Coordinates are pixels from the top left;
+// text is positioned by its baseline.
+type canvas interface {
+	rect(x, y, w, h float64, fill string)
+	line(x1, y1, x2, y2 float64, stroke string, width float64)
+	polyline(pts []point, stroke string, width float64)
+	polygon(pts []point, fill string, opacity float64)
+	circle(x, y, r float64, fill string, opacity float64)
+	text(x, y float64, s string, size float64, anchor string, fill string, bold bool)
+}
+
+// textWidth estimates the width of s in pixels.
+func textWidth(s string, size float64) float64 {
+	return float64(utf8.RuneCountInString(s)) * size * 0.6
+}
+
+// layout is the position of the plot area and legend within the image.
+type layout struct {
+	width, height float64 // whole image
+	left, top     float64 // plot origin
+	plotW, plotH  float64
+	legendX       float64
+}
+
+// legendEntries returns the labels shown in the legend.
+func (f *frame) legendEntries() []string {
+	switch {
+	case f.mark == MarkArc:
+		if f.cat == nil {
+			return nil
+		}
+		return f.categories
+	case f.colorByCategory:
+		return nil // the category axis already names the colors
+	case len(f.series) > 1 || (len(f.series) == 1 && f.series[0] != ""):
+		return f.series
+	}
+	return nil
+}
+
+// seriesColor returns the color of series i (or category i).
+func seriesColor(i int) string {
+	return palette[i%len(palette)]
+}
+
+// draw renders the chart onto c.
+func draw(c canvas, s *Spec, f *frame, lay layout) {
+	c.rect(0, 0, lay.width, lay.height, "#ffffff")
+	if s.Title != "" {
+		c.text(lay.width/2, 22, s.Title, titleSize, "middle", labelColor, true)
+	}
+
+	if f.mark == MarkArc {
+		drawArc(c, f, lay)
+	} else {
+		drawCartesian(c, f, lay)
+	}
+
+	if entries := f.legendEntries(); len(entries) > 0 {
+		y := lay.top + 4
+		if f.mark != MarkArc {
+			if title := legendTitle(s); title != "" {
+				c.text(lay.legendX, y+8, title, labelSize, "start", labelColor, true)
+				y += 18
+			}
+		}
+		for i, e := range entries {
+			c.rect(lay.legendX, y, 10, 10, seriesColor(i))
+			c.text(lay.legendX+16, y+9, e, labelSize, "start", labelColor, false)
+			y += 16
+		}
+	}
+}
+
+func legendTitle(s *Spec) string {
+	switch {
+	case s.Color != nil:
+		return s.Color.Label()
+	case s.XOffset != nil:
+		return s.XOffset.Label()
+	}
+	return ""
+}
+
+// newLayout sizes the image around the plot area.
+func newLayout(s *Spec, f *frame) layout {
+	lay := layout{plotW: defaultWidth, plotH: defaultHeight, top: 20, left: 20}
+	if s.Width > 0 {
+		lay.plotW = float64(s.Width)
+	}
+	if s.Height > 0 {
+		lay.plotH = float64(s.Height)
+	}
+	if s.Title != "" {
+		lay.top += 24
+	}
+
+	bottom := 20.0
+	if f.mark != MarkArc {
+		// Room for tick labels and the axis titles.
+		left := 0.0
+		if f.horizontal {
+			for _, c := range f.categories {
+				left = math.Max(left, textWidth(c, labelSize))
+			}
+		} else {
+			left = textWidth("-000.00k", labelSize)
+		}
+		lay.left += math.Min(left, 160) + 24
+		bottom += 40
+	}
+
+	right := 20.0
+	if entries := f.legendEntries(); len(entries) > 0 {
+		w := textWidth(legendTitle(s), labelSize)
+		for _, e := range entries {
+			w = math.Max(w, textWidth(e, labelSize)+16)
+		}
+		lay.legendX = lay.left + lay.plotW + 20
+		right += 20 + w
+		lay.plotH = math.Max(lay.plotH, float64(len(entries))*16+8)
+	}
+
+	lay.width = math.Ceil(lay.left + lay.plotW + right)
+	lay.height = math.Ceil(lay.top + lay.plotH + bottom)
+	return lay
+}
+
+// valueExtent returns the range of values the value axis must show,
+// including zero and stacked totals.
+func (f *frame) valueExtent(stacked bool) (float64, float64) {
+	lo, hi := 0.0, 0.0
+	if stacked {
+		pos, neg := map[string]float64{}, map[string]float64{}
+		for _, d := range f.points {
+			k := f.stackKey(d)
+			if d.value >= 0 {
+				pos[k] += d.value
+				hi = math.Max(hi, pos[k])
+			} else {
+				neg[k] += d.value
+				lo = math.Min(lo, neg[k])
+			}
+		}
+		return lo, hi
+	}
+	for _, d := range f.points {
+		lo, hi = math.Min(lo, d.value), math.Max(hi, d.value)
+	}
+	return lo, hi
+}
+
+// stackKey identifies the position a datum stacks at.
+func (f *frame) stackKey(d datum) string {
+	if f.discrete {
+		return d.cat
+	}
+	return text(d.catNum)
+}
+
+func drawCartesian(c canvas, f *frame, lay layout) {
+	stacked := f.stacked()
+	vlo, vhi := f.valueExtent(stacked)
+	vlo, vhi, vticks := niceDomain(vlo, vhi, 5)
+
+	x0, x1 := lay.left, lay.left+lay.plotW
+	y0, y1 := lay.top+lay.plotH, lay.top // y grows downwards
+
+	// Value axis.
+	var vs linear
+	if f.horizontal {
+		vs = linear{vlo, vhi, x0, x1}
+	} else {
+		vs = linear{vlo, vhi, y0, y1}
+	}
+	for _, t := range vticks {
+		p := vs.at(t)
+		if f.horizontal {
+			c.line(p, y1, p, y0, gridColor, 1)
+			c.text(p, y0+16, formatNumber(t), labelSize, "middle", labelColor, false)
+		} else {
+			c.line(x0, p, x1, p, gridColor, 1)
+			c.text(x0-6, p+4, formatNumber(t), labelSize, "end", labelColor, false)
+		}
+	}
+
+	// Category axis.
+	bs := band{len(f.categories), x0, x1}
+	if f.horizontal {
+		bs = band{len(f.categories), y1, y0}
+	}
+	var cs linear
+	if f.discrete {
+		every := labelStride(f.categories, bs.step(), f.horizontal)
+		for i, cat := range f.categories {
+			if i%every != 0 {
+				continue
+			}
+			if f.horizontal {
+				c.text(x0-6, bs.center(i)+4, cat, labelSize, "end", labelColor, false)
+			} else {
+				c.text(bs.center(i), y0+16, cat, labelSize, "middle", labelColor, false)
+			}
+		}
+	} else {
+		lo, hi := f.points[0].catNum, f.points[len(f.points)-1].catNum
+		var ticks []float64
+		var labels []string
+		if f.temporal {
+			ticks, labels = timeTicks(lo, hi, 5)
+		} else {
+			lo, hi, ticks = niceDomain(lo, hi, 5)
+			for _, t := range ticks {
+				labels = append(labels, formatNumber(t))
+			}
+		}
+		cs = linear{lo, hi, x0, x1}
+		for i, t := range ticks {
+			c.text(cs.at(t), y0+16, labels[i], labelSize, "middle", labelColor, false)
+		}
+	}
+
+	c.line(x0, y0, x1, y0, axisColor, 1)
+	c.line(x0, y0, x0, y1, axisColor, 1)
+
+	// Axis titles.
+	xTitle, yTitle := f.cat.Label(), f.val.Label()
+	if f.horizontal {
+		xTitle, yTitle = yTitle, xTitle
+	}
+	c.text((x0+x1)/2, y0+36, xTitle, labelSize, "middle", labelColor, true)
+	c.text(4, y1-8, yTitle, labelSize, "start", labelColor, true)
+
+	// Position of a datum along the category axis.
+	catPos := func(d datum, i int) float64 {
+		if f.discrete {
+			return bs.center(i)
+		}
+		return cs.at(d.catNum)
+	}
+	catIndex := map[string]int{}
+	for i, cat := range f.categories {
+		catIndex[cat] = i
+	}
+
+	series := f.seriesNames()
+	switch f.mark {
+	case MarkBar:
+		drawBars(c, f, bs, vs, stacked, series)
+	case MarkLine, MarkArea:
+		base := vs.at(math.Max(vlo, math.Min(0, vhi)))
+		stack := map[string]float64{}
+		for si, sr := range series {
+			pts := f.seriesPoints(sr)
+			line := make([]point, 0, len(pts))
+			var lower []point
+			for _, d := range pts {
+				pos := catPos(d, catIndex[d.cat])
+				key := f.stackKey(d)
+				below := 0.0
+				if stacked && f.mark == MarkArea {
+					below = stack[key]
+					stack[key] += d.value
+				}
+				line = append(line, f.orient(pos, vs.at(below+d.value)))
+				if stacked && f.mark == MarkArea {
+					lower = append(lower, f.orient(pos, vs.at(below)))
+				} else {
+					lower = append(lower, f.orient(pos, base))
+				}
+			}
+			color := seriesColor(si)
+			if f.mark == MarkArea && len(line) > 0 {
+				poly := append([]point{}, line...)
+				for i := len(lower) - 1; i >= 0; i-- {
+					poly = append(poly, lower[i])
+				}
+				opacity := 0.7
+				if stacked {
+					opacity = 0.9
+				}
+				c.polygon(poly, color, opacity)
+			}
+			c.polyline(line, color, 2)
+		}
+	case MarkPoint:
+		seriesIndex := map[string]int{}
+		for i, sr := range series {
+			seriesIndex[sr] = i
+		}
+		for _, d := range f.points {
+			p := f.orient(catPos(d, catIndex[d.cat]), vs.at(d.value))
+			color := seriesColor(seriesIndex[d.series])
+			if f.colorByCategory {
+				color = seriesColor(catIndex[d.cat])
+			}
+			c.circle(p.x, p.y, 3.5, color, 0.8)
+		}
+	}
+}
+
+// stacked reports whether series are stacked on top of each other.
+func (f *frame) stacked() bool {
+	return f.stackMode && len(f.series) > 1 && (f.mark == MarkBar || f.mark == MarkArea)
+}
+
+// orient turns a (category position, value position) pair into a point.
+func (f *frame) orient(catPos, valPos float64) point {
+	if f.horizontal {
+		return point{valPos, catPos}
+	}
+	return point{catPos, valPos}
+}
+
+func drawBars(c canvas, f *frame, bs band, vs linear, stacked bool, series []string) {
+	zero := vs.at(0)
+	for i, cat := range f.categories {
+		pos, neg := 0.0, 0.0
+		for si, sr := range series {
+			v, ok := f.value(cat, sr)
+			if !ok {
+				continue
+			}
+			start, width := bs.start(i), bs.width()
+			from := 0.0
+			if stacked {
+				if v >= 0 {
+					from, pos = pos, pos+v
+				} else {
+					from, neg = neg, neg+v
+				}
+			} else if len(series) > 1 {
+				width /= float64(len(series))
+				start += float64(si) * width
+			}
+			color := seriesColor(si)
+			if f.colorByCategory {
+				color = seriesColor(i)
+			}
+
+			a, b := vs.at(from), vs.at(from+v)
+			if !stacked {
+				a = zero
+			}
+			lo, hi := math.Min(a, b), math.Max(a, b)
+			if f.horizontal {
+				c.rect(lo, start, hi-lo, width, color)
+			} else {
+				c.rect(start, lo, width, hi-lo, color)
+			}
+		}
+	}
+}
+
+// labelStride returns n such that labeling every nth category keeps the
+// labels from overlapping.
+func labelStride(cats []string, step float64, horizontal bool) int {
+	need := labelSize + 2
+	if !horizontal {
+		for _, c := range cats {
+			need = math.Max(need, textWidth(c, labelSize)+6)
+		}
+	}
+	if step <= 0 {
+		return 1
+	}
+	return max(1, int(math.Ceil(need/step)))
+}
+
+func drawArc(c canvas, f *frame, lay layout) {
+	total := 0.0
+	for _, d := range f.points {
+		if d.value > 0 {
+			total += d.value
+		}
+	}
+	if total == 0 {
+		return
+	}
+	cx, cy := lay.left+lay.plotW/2, lay.top+lay.plotH/2
+	r := math.Min(lay.plotW, lay.plotH) / 2
+
+	// Vega-Lite starts at 12 o'clock and goes clockwise.
+	angle := -math.Pi / 2
+	for i, cat := range f.categories {
+		v, _ := f.value(cat, "")
+		if v <= 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		pts := []point{{cx, cy}}
+		steps := max(2, int(math.Ceil(sweep/(math.Pi/90))))
+		for s := 0; s <= steps; s++ {
+			a := angle + sweep*float64(s)/float64(steps)
+			pts = append(pts, point{cx + r*math.Cos(a), cy + r*math.Sin(a)})
+		}
+		c.polygon(pts, seriesColor(i), 1)
+		angle += sweep
+	}
+}
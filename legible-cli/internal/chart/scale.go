@@ -0,0 +1,178 @@
+package chart
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// linear maps a numeric domain onto a pixel range.
+type linear struct {
+	d0, d1 float64
+	r0, r1 float64
+}
+
+func (s linear) at(v float64) float64 {
+	if s.d1 == s.d0 {
+		return (s.r0 + s.r1) / 2
+	}
+	return s.r0 + (v-s.d0)/(s.d1-s.d0)*(s.r1-s.r0)
+}
+
+// band places discrete categories in equal bands across a pixel range.
+type band struct {
+	n      int
+	r0, r1 float64
+}
+
+// step is the width of one band, including padding.
+func (b band) step() float64 {
+	if b.n == 0 {
+		return 0
+	}
+	return (b.r1 - b.r0) / float64(b.n)
+}
+
+// start is where the bar of category i begins; width is the bar width.
+func (b band) start(i int) float64 { return b.r0 + float64(i)*b.step() + b.step()*0.1 }
+func (b band) width() float64      { return b.step() * 0.8 }
+func (b band) center(i int) float64 {
+	return b.r0 + (float64(i)+0.5)*b.step()
+}
+
+// niceDomain extends [lo, hi] to round numbers and returns about n ticks.
+func niceDomain(lo, hi float64, n int) (float64, float64, []float64) {
+	if lo == hi {
+		if lo == 0 {
+			hi = 1
+		} else {
+			lo, hi = math.Min(0, lo), math.Max(0, hi)
+		}
+	}
+	step := niceStep((hi - lo) / float64(n))
+	// A step too small to change the bounds, as for large values close
+	// together, cannot divide the domain
+	if math.IsNaN(step) || math.IsInf(step, 0) || lo+step == lo || hi-step == hi {
+		return lo, hi, []float64{lo, hi}
+	}
+	lo = math.Floor(lo/step) * step
+	hi = math.Ceil(hi/step) * step
+	count := min(int(math.Round((hi-lo)/step)), 4*n)
+	ticks := make([]float64, 0, count+1)
+	for i := 0; i <= count; i++ {
+		ticks = append(ticks, math.Round((lo+float64(i)*step)/step)*step)
+	}
+	return lo, hi, ticks
+}
+
+// niceStep rounds a raw step to 1, 2 or 5 times a power of ten.
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / mag; {
+	case f <= 1:
+		return mag
+	case f <= 2:
+		return 2 * mag
+	case f <= 5:
+		return 5 * mag
+	}
+	return 10 * mag
+}
+
+// formatNumber labels an axis tick or value compactly (1.5k, 2M).
+func formatNumber(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e9:
+		return trimFloat(v/1e9) + "B"
+	case abs >= 1e6:
+		return trimFloat(v/1e6) + "M"
+	case abs >= 1e4:
+		return trimFloat(v/1e3) + "k"
+	}
+	return trimFloat(v)
+}
+
+func trimFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// timeInterval is a tick spacing for time axes: a number of months, or of
+// seconds when months is zero.
+type timeInterval struct {
+	months  int
+	seconds int64
+	layout  string
+}
+
+var timeIntervals = []timeInterval{
+	{0, 3600, "Jan 2 15:04"},
+	{0, 3 * 3600, "Jan 2 15:04"},
+	{0, 6 * 3600, "Jan 2 15:04"},
+	{0, 12 * 3600, "Jan 2 15:04"},
+	{0, 24 * 3600, "Jan 2"},
+	{0, 2 * 24 * 3600, "Jan 2"},
+	{0, 7 * 24 * 3600, "Jan 2"},
+	{1, 0, "Jan 2006"},
+	{3, 0, "Jan 2006"},
+	{6, 0, "Jan 2006"},
+	{12, 0, "2006"},
+	{24, 0, "2006"},
+	{60, 0, "2006"},
+	{120, 0, "2006"},
+	{600, 0, "2006"},
+	{1200, 0, "2006"},
+}
+
+// floor returns the last tick at or before t.
+func (iv timeInterval) floor(t time.Time) time.Time {
+	switch {
+	case iv.months >= 12:
+		years := iv.months / 12
+		return time.Date(t.Year()-t.Year()%years, 1, 1, 0, 0, 0, 0, time.UTC)
+	case iv.months > 0:
+		m := int(t.Month()) - 1
+		return time.Date(t.Year(), time.Month(m-m%iv.months+1), 1, 0, 0, 0, 0, time.UTC)
+	case iv.seconds == 7*24*3600:
+		// Weeks start on Sunday.
+		d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return d.AddDate(0, 0, -int(d.Weekday()))
+	}
+	sec := t.Unix()
+	return time.Unix(sec-sec%iv.seconds, 0).UTC()
+}
+
+func (iv timeInterval) next(t time.Time) time.Time {
+	if iv.months > 0 {
+		return t.AddDate(0, iv.months, 0)
+	}
+	return t.Add(time.Duration(iv.seconds) * time.Second)
+}
+
+// timeTicks returns at most n+1 tick positions (in Unix seconds) on round
+// calendar boundaries within [lo, hi], with their labels.
+func timeTicks(lo, hi float64, n int) ([]float64, []string) {
+	start, end := time.Unix(int64(lo), 0).UTC(), time.Unix(int64(hi), 0).UTC()
+	for i, iv := range timeIntervals {
+		var ticks []float64
+		var labels []string
+		t := iv.floor(start)
+		if t.Before(start) {
+			t = iv.next(t)
+		}
+		for ; !t.After(end); t = iv.next(t) {
+			ticks = append(ticks, float64(t.Unix()))
+			labels = append(labels, t.Format(iv.layout))
+		}
+		if len(ticks) <= n+1 || i == len(timeIntervals)-1 {
+			if len(ticks) == 0 {
+				return []float64{lo}, []string{start.Format(iv.layout)}
+			}
+			return ticks, labels
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,270 @@
+// Package chart renders Vega-Lite chart specifications, as produced by the
+// chart generation API, without a browser: to SVG and PNG for the common
+// single-view charts (bar, line, area, point and arc marks), to a
+// self-contained HTML page for anything else, and as a Unicode preview in
+// the terminal.
+package chart
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Mark types understood by the local renderers.
+const (
+	MarkBar   = "bar"
+	MarkLine  = "line"
+	MarkArea  = "area"
+	MarkPoint = "point"
+	MarkArc   = "arc"
+)
+
+// Encoding types.
+const (
+	Quantitative = "quantitative"
+	Nominal      = "nominal"
+	Ordinal      = "ordinal"
+	Temporal     = "temporal"
+)
+
+// Spec is the part of a single-view Vega-Lite specification that the local
+// renderers draw.
+type Spec struct {
+	Title  string
+	Mark   string
+	Width  int
+	Height int
+
+	X, Y    *Channel
+	Color   *Channel
+	Theta   *Channel
+	XOffset *Channel
+
+	// Stacked reports whether bars and areas with a color encoding are
+	// stacked (the Vega-Lite default) rather than grouped or overlaid.
+	Stacked bool
+
+	// Folds are "fold" transforms, applied to the records before drawing.
+	Folds []Fold
+}
+
+// Channel is an encoding channel bound to a field.
+type Channel struct {
+	Field     string `json:"field"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Aggregate string `json:"aggregate"`
+	TimeUnit  string `json:"timeUnit"`
+}
+
+// Label returns the channel's title, or its field name.
+func (c *Channel) Label() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return c.Field
+}
+
+// Discrete reports whether the channel is nominal or ordinal.
+func (c *Channel) Discrete() bool {
+	return c.Type == Nominal || c.Type == Ordinal
+}
+
+// Fold is a Vega-Lite fold transform: each record becomes one record per
+// field, with the field name in As[0] and its value in As[1].
+type Fold struct {
+	Fields []string
+	As     [2]string
+}
+
+// rawSpec mirrors the JSON of the supported subset.
+type rawSpec struct {
+	Title     json.RawMessage            `json:"title"`
+	Mark      json.RawMessage            `json:"mark"`
+	Width     json.RawMessage            `json:"width"`
+	Height    json.RawMessage            `json:"height"`
+	Encoding  map[string]json.RawMessage `json:"encoding"`
+	Transform []map[string]interface{}   `json:"transform"`
+
+	Layer   json.RawMessage `json:"layer"`
+	Concat  json.RawMessage `json:"concat"`
+	HConcat json.RawMessage `json:"hconcat"`
+	VConcat json.RawMessage `json:"vconcat"`
+	Facet   json.RawMessage `json:"facet"`
+	Repeat  json.RawMessage `json:"repeat"`
+}
+
+// Parse reads a Vega-Lite specification (a decoded JSON object or raw
+// JSON). It returns an error for specs the local renderers can't draw,
+// such as layered, faceted or concatenated views; those can still be
+// rendered to HTML.
+func Parse(spec interface{}) (*Spec, error) {
+	data, ok := spec.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(spec); err != nil {
+			return nil, fmt.Errorf("encoding spec: %w", err)
+		}
+	}
+	var raw rawSpec
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing spec: %w", err)
+	}
+	for name, v := range map[string]json.RawMessage{
+		"layer": raw.Layer, "concat": raw.Concat, "hconcat": raw.HConcat,
+		"vconcat": raw.VConcat, "facet": raw.Facet, "repeat": raw.Repeat,
+	} {
+		if len(v) > 0 && string(v) != "null" {
+			return nil, fmt.Errorf("%s specs are not supported — render to html instead", name)
+		}
+	}
+
+	s := &Spec{
+		Title:  stringOrText(raw.Title),
+		Mark:   markType(raw.Mark),
+		Width:  intOr(raw.Width, 0),
+		Height: intOr(raw.Height, 0),
+	}
+	switch s.Mark {
+	case "circle", "square":
+		s.Mark = MarkPoint
+	case MarkBar, MarkLine, MarkArea, MarkPoint, MarkArc:
+	case "":
+		return nil, fmt.Errorf("spec has no mark")
+	default:
+		return nil, fmt.Errorf("%q marks are not supported — render to html instead", s.Mark)
+	}
+
+	var err error
+	channels := map[string]**Channel{"x": &s.X, "y": &s.Y, "color": &s.Color, "theta": &s.Theta, "xOffset": &s.XOffset}
+	for name, dst := range channels {
+		if *dst, err = parseChannel(raw.Encoding[name]); err != nil {
+			return nil, fmt.Errorf("encoding.%s: %w", name, err)
+		}
+	}
+
+	s.Stacked = s.XOffset == nil
+	for _, name := range []string{"x", "y"} {
+		var st struct {
+			Stack json.RawMessage `json:"stack"`
+		}
+		json.Unmarshal(raw.Encoding[name], &st)
+		if string(st.Stack) == "null" || string(st.Stack) == "false" {
+			s.Stacked = false
+		}
+	}
+
+	for _, t := range raw.Transform {
+		fields, ok := t["fold"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("only fold transforms are supported — render to html instead")
+		}
+		f := Fold{As: [2]string{"key", "value"}}
+		for _, v := range fields {
+			name, _ := v.(string)
+			f.Fields = append(f.Fields, name)
+		}
+		if as, ok := t["as"].([]interface{}); ok && len(as) == 2 {
+			f.As[0], _ = as[0].(string)
+			f.As[1], _ = as[1].(string)
+		}
+		s.Folds = append(s.Folds, f)
+	}
+
+	if s.Mark == MarkArc {
+		if s.Theta == nil {
+			return nil, fmt.Errorf("arc mark needs a theta encoding")
+		}
+	} else if s.X == nil || s.Y == nil {
+		return nil, fmt.Errorf("%s mark needs x and y encodings", s.Mark)
+	}
+	return s, nil
+}
+
+func parseChannel(data json.RawMessage) (*Channel, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var c Channel
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.Field == "" && c.Aggregate != "count" {
+		// Constant values and other field-less encodings carry no data.
+		return nil, nil
+	}
+	if c.Type == "" {
+		c.Type = Nominal
+	}
+	return &c, nil
+}
+
+// markType reads "mark": "bar" or "mark": {"type": "bar"}.
+func markType(data json.RawMessage) string {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		return name
+	}
+	var obj struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(data, &obj)
+	return obj.Type
+}
+
+// stringOrText reads a title given as a string or as {"text": ...}.
+func stringOrText(data json.RawMessage) string {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s
+	}
+	var obj struct {
+		Text interface{} `json:"text"`
+	}
+	json.Unmarshal(data, &obj)
+	switch t := obj.Text.(type) {
+	case string:
+		return t
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, p := range t {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
+
+func intOr(data json.RawMessage, def int) int {
+	var n float64
+	if json.Unmarshal(data, &n) == nil && n > 0 {
+		return int(n)
+	}
+	return def
+}
+
+// WithData returns a copy of spec with records as its inline data values,
+// replacing the (usually empty) sample data the API returns.
+func WithData(spec interface{}, records []map[string]interface{}) (map[string]interface{}, error) {
+	var obj map[string]interface{}
+	switch v := spec.(type) {
+	case map[string]interface{}:
+		obj = maps.Clone(v)
+	default:
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return nil, fmt.Errorf("encoding spec: %w", err)
+		}
+		if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+			return nil, fmt.Errorf("spec is not a JSON object")
+		}
+	}
+	if records == nil {
+		records = []map[string]interface{}{}
+	}
+	obj["data"] = map[string]interface{}{"values": records}
+	delete(obj, "datasets")
+	return obj, nil
+}
@@ -0,0 +1,86 @@
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// prepare parses the records into a frame and lays out the image.
+func prepare(s *Spec, records []map[string]interface{}) (*frame, layout, error) {
+	f, err := newFrame(s, records)
+	if err != nil {
+		return nil, layout{}, err
+	}
+	if len(f.points) == 0 {
+		return nil, layout{}, fmt.Errorf("no data to chart")
+	}
+	return f, newLayout(s, f), nil
+}
+
+// RenderSVG draws the chart for records as an SVG document.
+func RenderSVG(w io.Writer, s *Spec, records []map[string]interface{}) error {
+	f, lay, err := prepare(s, records)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="sans-serif">`+"\n",
+		trimFloat(lay.width), trimFloat(lay.height), trimFloat(lay.width), trimFloat(lay.height))
+	draw(&svgCanvas{w: bw}, s, f, lay)
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// svgCanvas writes shapes as SVG elements.
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill string) {
+	fmt.Fprintf(c.w, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n", trimFloat(x), trimFloat(y), trimFloat(w), trimFloat(h), fill)
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2 float64, stroke string, width float64) {
+	fmt.Fprintf(c.w, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s"/>`+"\n",
+		trimFloat(x1), trimFloat(y1), trimFloat(x2), trimFloat(y2), stroke, trimFloat(width))
+}
+
+func (c *svgCanvas) polyline(pts []point, stroke string, width float64) {
+	fmt.Fprintf(c.w, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round"/>`+"\n",
+		points(pts), stroke, trimFloat(width))
+}
+
+func (c *svgCanvas) polygon(pts []point, fill string, opacity float64) {
+	fmt.Fprintf(c.w, `<polygon points="%s" fill="%s"%s/>`+"\n", points(pts), fill, opacityAttr(opacity))
+}
+
+func (c *svgCanvas) circle(x, y, r float64, fill string, opacity float64) {
+	fmt.Fprintf(c.w, `<circle cx="%s" cy="%s" r="%s" fill="%s"%s/>`+"\n", trimFloat(x), trimFloat(y), trimFloat(r), fill, opacityAttr(opacity))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, size float64, anchor, fill string, bold bool) {
+	weight := ""
+	if bold {
+		weight = ` font-weight="bold"`
+	}
+	fmt.Fprintf(c.w, `<text x="%s" y="%s" font-size="%s" text-anchor="%s" fill="%s"%s>%s</text>`+"\n",
+		trimFloat(x), trimFloat(y), trimFloat(size), anchor, fill, weight, html.EscapeString(s))
+}
+
+func opacityAttr(opacity float64) string {
+	if opacity >= 1 {
+		return ""
+	}
+	return ` fill-opacity="` + trimFloat(opacity) + `"`
+}
+
+func points(pts []point) string {
+	parts := make([]string, len(pts))
+	for i, p := range pts {
+		parts[i] = trimFloat(p.x) + "," + trimFloat(p.y)
+	}
+	return strings.Join(parts, " ")
+}
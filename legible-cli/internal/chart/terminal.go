@@ -0,0 +1,199 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Partial blocks, in eighths: horizontal for bars, vertical for columns.
+var (
+	hBlocks = []rune("▏▎▍▌▋▊▉█")
+	vBlocks = []rune("▁▂▃▄▅▆▇█")
+)
+
+// columnRows is the height of a terminal line chart, in rows.
+const columnRows = 8
+
+// RenderTerminal draws a text preview of the chart, width columns wide.
+// Bar and arc charts become horizontal bars; line, area and point charts
+// become a column chart per series.
+func RenderTerminal(w io.Writer, s *Spec, records []map[string]interface{}, width int) error {
+	f, err := newFrame(s, records)
+	if err != nil {
+		return err
+	}
+	if len(f.points) == 0 {
+		return fmt.Errorf("no data to chart")
+	}
+	if width <= 0 {
+		width = 80
+	}
+
+	var b strings.Builder
+	if s.Title != "" {
+		b.WriteString(s.Title + "\n\n")
+	}
+	if f.mark == MarkBar || f.mark == MarkArc {
+		termBars(&b, f, width)
+	} else {
+		termColumns(&b, f, width)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func termBars(b *strings.Builder, f *frame, width int) {
+	type row struct {
+		label string
+		value float64
+	}
+	var rows []row
+	series := f.seriesNames()
+	for _, cat := range f.categories {
+		for _, sr := range series {
+			v, ok := f.value(cat, sr)
+			if !ok {
+				continue
+			}
+			label := cat
+			if sr != "" {
+				label += " · " + sr
+			}
+			rows = append(rows, row{label, v})
+		}
+	}
+
+	total, peak := 0.0, 0.0
+	labelW, valueW := 0, 0
+	values := make([]string, len(rows))
+	for _, r := range rows {
+		total += math.Max(r.value, 0)
+		peak = math.Max(peak, math.Abs(r.value))
+		labelW = max(labelW, utf8.RuneCountInString(r.label))
+	}
+	labelW = min(labelW, 24)
+	for i, r := range rows {
+		values[i] = formatNumber(r.value)
+		if f.mark == MarkArc && total > 0 {
+			values[i] += fmt.Sprintf(" (%.1f%%)", math.Max(r.value, 0)/total*100)
+		}
+		valueW = max(valueW, utf8.RuneCountInString(values[i]))
+	}
+
+	barW := max(width-labelW-valueW-4, 10)
+	for i, r := range rows {
+		fmt.Fprintf(b, "%s │%s %s\n", padRight(truncate(r.label, labelW), labelW), bar(math.Abs(r.value), peak, barW), values[i])
+	}
+}
+
+// bar draws a horizontal bar of v/peak times width columns, padded to width.
+func bar(v, peak float64, width int) string {
+	eighths := 0
+	if peak > 0 {
+		eighths = int(math.Round(v / peak * float64(width*8)))
+	}
+	s := strings.Repeat("█", eighths/8)
+	if rem := eighths % 8; rem > 0 {
+		s += string(hBlocks[rem-1])
+	}
+	return padRight(s, width)
+}
+
+func termColumns(b *strings.Builder, f *frame, width int) {
+	series := f.seriesNames()
+	lo, hi := 0.0, 0.0
+	for _, d := range f.points {
+		lo, hi = math.Min(lo, d.value), math.Max(hi, d.value)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	top, bottom := formatNumber(hi), formatNumber(lo)
+	gutter := max(utf8.RuneCountInString(top), utf8.RuneCountInString(bottom))
+	plotW := max(width-gutter-2, 10)
+
+	for si, sr := range series {
+		pts := f.seriesPoints(sr)
+		if len(pts) == 0 {
+			continue
+		}
+		if si > 0 {
+			b.WriteString("\n")
+		}
+		if sr != "" {
+			b.WriteString(sr + "\n")
+		}
+
+		values := resample(pts, plotW)
+		colW := max(1, min(4, plotW/len(values)))
+		for r := columnRows - 1; r >= 0; r-- {
+			axis := ""
+			switch r {
+			case columnRows - 1:
+				axis = top
+			case 0:
+				axis = bottom
+			}
+			fmt.Fprintf(b, "%*s ┤", gutter, axis)
+			for _, v := range values {
+				level := int(math.Round((v-lo)/(hi-lo)*columnRows*8)) - r*8
+				cell := " "
+				switch {
+				case level >= 8:
+					cell = "█"
+				case level > 0:
+					cell = string(vBlocks[level-1])
+				}
+				b.WriteString(strings.Repeat(cell, colW))
+			}
+			b.WriteString("\n")
+		}
+
+		first, last := pts[0].cat, pts[len(pts)-1].cat
+		span := len(values) * colW
+		pad := span - utf8.RuneCountInString(first) - utf8.RuneCountInString(last)
+		if len(pts) == 1 || pad < 1 {
+			last, pad = "", 0
+		}
+		fmt.Fprintf(b, "%*s  %s%s%s\n", gutter, "", first, strings.Repeat(" ", pad), last)
+	}
+}
+
+// resample reduces the points to at most n values by averaging buckets.
+func resample(pts []datum, n int) []float64 {
+	if len(pts) <= n {
+		out := make([]float64, len(pts))
+		for i, d := range pts {
+			out[i] = d.value
+		}
+		return out
+	}
+	out := make([]float64, n)
+	for i := range out {
+		from, to := i*len(pts)/n, (i+1)*len(pts)/n
+		var vals []float64
+		for _, d := range pts[from:to] {
+			vals = append(vals, d.value)
+		}
+		out[i] = sum(vals) / float64(len(vals))
+	}
+	return out
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
+
+func padRight(s string, n int) string {
+	if pad := n - utf8.RuneCountInString(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -29,11 +30,16 @@ const agentFields = `id projectId name sandboxName status providerName policyYam
 
 // CreateAgent registers a new agent in the server.
 func (c *Client) CreateAgent(input map[string]interface{}) (*Agent, error) {
+	return c.CreateAgentContext(c.context(), input)
+}
+
+// CreateAgentContext is like CreateAgent but uses ctx for cancellation.
+func (c *Client) CreateAgentContext(ctx context.Context, input map[string]interface{}) (*Agent, error) {
 	query := fmt.Sprintf(`mutation CreateAgent($data: CreateAgentInput!) {
 		createAgent(data: $data) { %s }
 	}`, agentFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": input,
 	})
 	if err != nil {
@@ -51,11 +57,16 @@ func (c *Client) CreateAgent(input map[string]interface{}) (*Agent, error) {
 
 // UpdateAgent updates an agent's status or metadata.
 func (c *Client) UpdateAgent(id int, updates map[string]interface{}) (*Agent, error) {
+	return c.UpdateAgentContext(c.context(), id, updates)
+}
+
+// UpdateAgentContext is like UpdateAgent but uses ctx for cancellation.
+func (c *Client) UpdateAgentContext(ctx context.Context, id int, updates map[string]interface{}) (*Agent, error) {
 	query := fmt.Sprintf(`mutation UpdateAgent($where: AgentWhereInput!, $data: UpdateAgentInput!) {
 		updateAgent(where: $where, data: $data) { %s }
 	}`, agentFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 		"data":  updates,
 	})
@@ -74,9 +85,14 @@ func (c *Client) UpdateAgent(id int, updates map[string]interface{}) (*Agent, er
 
 // ListAgents lists all agents for the current project.
 func (c *Client) ListAgents() ([]Agent, error) {
+	return c.ListAgentsContext(c.context())
+}
+
+// ListAgentsContext is like ListAgents but uses ctx for cancellation.
+func (c *Client) ListAgentsContext(ctx context.Context) ([]Agent, error) {
 	query := fmt.Sprintf(`query { agents { %s } }`, agentFields)
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing agents: %w", err)
 	}
@@ -93,7 +109,12 @@ func (c *Client) ListAgents() ([]Agent, error) {
 // GetAgentBySandboxName finds an agent by its sandbox name.
 // Returns nil if no matching agent is found.
 func (c *Client) GetAgentBySandboxName(sandboxName string) (*Agent, error) {
-	agents, err := c.ListAgents()
+	return c.GetAgentBySandboxNameContext(c.context(), sandboxName)
+}
+
+// GetAgentBySandboxNameContext is like GetAgentBySandboxName but uses ctx for cancellation.
+func (c *Client) GetAgentBySandboxNameContext(ctx context.Context, sandboxName string) (*Agent, error) {
+	agents, err := c.ListAgentsContext(ctx)
 	if err != nil {
 		return nil, err
 	}
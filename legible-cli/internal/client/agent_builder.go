@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -91,9 +92,14 @@ const agentDefVersionFields = `id agentDefinitionId version model systemPrompt c
 
 // ListAgentDefinitions returns all agent definitions for the current project.
 func (c *Client) ListAgentDefinitions() ([]AgentDefinition, error) {
+	return c.ListAgentDefinitionsContext(c.context())
+}
+
+// ListAgentDefinitionsContext is like ListAgentDefinitions but uses ctx for cancellation.
+func (c *Client) ListAgentDefinitionsContext(ctx context.Context) ([]AgentDefinition, error) {
 	query := fmt.Sprintf(`query { agentDefinitions { %s } }`, agentDefFields)
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing agent definitions: %w", err)
 	}
@@ -109,11 +115,16 @@ func (c *Client) ListAgentDefinitions() ([]AgentDefinition, error) {
 
 // GetAgentDefinition returns a single agent definition by ID.
 func (c *Client) GetAgentDefinition(id int) (*AgentDefinition, error) {
+	return c.GetAgentDefinitionContext(c.context(), id)
+}
+
+// GetAgentDefinitionContext is like GetAgentDefinition but uses ctx for cancellation.
+func (c *Client) GetAgentDefinitionContext(ctx context.Context, id int) (*AgentDefinition, error) {
 	query := fmt.Sprintf(`query($where: AgentDefinitionWhereInput!) {
 		agentDefinition(where: $where) { %s }
 	}`, agentDefFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 	})
 	if err != nil {
@@ -131,11 +142,16 @@ func (c *Client) GetAgentDefinition(id int) (*AgentDefinition, error) {
 
 // CreateAgentDefinition creates a new agent definition.
 func (c *Client) CreateAgentDefinition(input map[string]interface{}) (*AgentDefinition, error) {
+	return c.CreateAgentDefinitionContext(c.context(), input)
+}
+
+// CreateAgentDefinitionContext is like CreateAgentDefinition but uses ctx for cancellation.
+func (c *Client) CreateAgentDefinitionContext(ctx context.Context, input map[string]interface{}) (*AgentDefinition, error) {
 	query := fmt.Sprintf(`mutation($data: CreateAgentDefinitionInput!) {
 		createAgentDefinition(data: $data) { %s }
 	}`, agentDefFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": input,
 	})
 	if err != nil {
@@ -153,11 +169,16 @@ func (c *Client) CreateAgentDefinition(input map[string]interface{}) (*AgentDefi
 
 // UpdateAgentDefinition updates an existing agent definition.
 func (c *Client) UpdateAgentDefinition(id int, input map[string]interface{}) (*AgentDefinition, error) {
+	return c.UpdateAgentDefinitionContext(c.context(), id, input)
+}
+
+// UpdateAgentDefinitionContext is like UpdateAgentDefinition but uses ctx for cancellation.
+func (c *Client) UpdateAgentDefinitionContext(ctx context.Context, id int, input map[string]interface{}) (*AgentDefinition, error) {
 	query := fmt.Sprintf(`mutation($where: AgentDefinitionWhereInput!, $data: UpdateAgentDefinitionInput!) {
 		updateAgentDefinition(where: $where, data: $data) { %s }
 	}`, agentDefFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 		"data":  input,
 	})
@@ -176,11 +197,16 @@ func (c *Client) UpdateAgentDefinition(id int, input map[string]interface{}) (*A
 
 // DeleteAgentDefinition deletes an agent definition by ID.
 func (c *Client) DeleteAgentDefinition(id int) error {
+	return c.DeleteAgentDefinitionContext(c.context(), id)
+}
+
+// DeleteAgentDefinitionContext is like DeleteAgentDefinition but uses ctx for cancellation.
+func (c *Client) DeleteAgentDefinitionContext(ctx context.Context, id int) error {
 	query := `mutation($where: AgentDefinitionWhereInput!) {
 		deleteAgentDefinition(where: $where)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 	})
 	if err != nil {
@@ -191,6 +217,11 @@ func (c *Client) DeleteAgentDefinition(id int) error {
 
 // PublishAgentDefinition publishes a version of an agent definition.
 func (c *Client) PublishAgentDefinition(id int, changeNote string) (*AgentDefinition, error) {
+	return c.PublishAgentDefinitionContext(c.context(), id, changeNote)
+}
+
+// PublishAgentDefinitionContext is like PublishAgentDefinition but uses ctx for cancellation.
+func (c *Client) PublishAgentDefinitionContext(ctx context.Context, id int, changeNote string) (*AgentDefinition, error) {
 	query := fmt.Sprintf(`mutation($where: AgentDefinitionWhereInput!, $changeNote: String) {
 		publishAgentDefinition(where: $where, changeNote: $changeNote) { %s }
 	}`, agentDefFields)
@@ -202,7 +233,7 @@ func (c *Client) PublishAgentDefinition(id int, changeNote string) (*AgentDefini
 		vars["changeNote"] = changeNote
 	}
 
-	gqlResp, err := c.GraphQL(query, vars)
+	gqlResp, err := c.GraphQLContext(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("publishing agent definition: %w", err)
 	}
@@ -218,11 +249,16 @@ func (c *Client) PublishAgentDefinition(id int, changeNote string) (*AgentDefini
 
 // DeployAgentDefinition deploys an agent definition.
 func (c *Client) DeployAgentDefinition(id int) (*AgentDefinition, error) {
+	return c.DeployAgentDefinitionContext(c.context(), id)
+}
+
+// DeployAgentDefinitionContext is like DeployAgentDefinition but uses ctx for cancellation.
+func (c *Client) DeployAgentDefinitionContext(ctx context.Context, id int) (*AgentDefinition, error) {
 	query := fmt.Sprintf(`mutation($where: AgentDefinitionWhereInput!) {
 		deployAgentDefinition(where: $where) { %s }
 	}`, agentDefFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 	})
 	if err != nil {
@@ -240,11 +276,16 @@ func (c *Client) DeployAgentDefinition(id int) (*AgentDefinition, error) {
 
 // ArchiveAgentDefinition archives an agent definition.
 func (c *Client) ArchiveAgentDefinition(id int) (*AgentDefinition, error) {
+	return c.ArchiveAgentDefinitionContext(c.context(), id)
+}
+
+// ArchiveAgentDefinitionContext is like ArchiveAgentDefinition but uses ctx for cancellation.
+func (c *Client) ArchiveAgentDefinitionContext(ctx context.Context, id int) (*AgentDefinition, error) {
 	query := fmt.Sprintf(`mutation($where: AgentDefinitionWhereInput!) {
 		archiveAgentDefinition(where: $where) { %s }
 	}`, agentDefFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 	})
 	if err != nil {
@@ -262,11 +303,16 @@ func (c *Client) ArchiveAgentDefinition(id int) (*AgentDefinition, error) {
 
 // ListAgentDefinitionVersions returns the version history for an agent definition.
 func (c *Client) ListAgentDefinitionVersions(agentDefID int) ([]AgentDefinitionVersion, error) {
+	return c.ListAgentDefinitionVersionsContext(c.context(), agentDefID)
+}
+
+// ListAgentDefinitionVersionsContext is like ListAgentDefinitionVersions but uses ctx for cancellation.
+func (c *Client) ListAgentDefinitionVersionsContext(ctx context.Context, agentDefID int) ([]AgentDefinitionVersion, error) {
 	query := fmt.Sprintf(`query($agentDefinitionId: Int!) {
 		agentDefinitionVersions(agentDefinitionId: $agentDefinitionId) { %s }
 	}`, agentDefVersionFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"agentDefinitionId": agentDefID,
 	})
 	if err != nil {
@@ -286,9 +332,14 @@ func (c *Client) ListAgentDefinitionVersions(agentDefID int) ([]AgentDefinitionV
 
 // CreateAgentChatSession creates a new chat session for a deployed agent.
 func (c *Client) CreateAgentChatSession(agentID int) (*ChatSession, error) {
+	return c.CreateAgentChatSessionContext(c.context(), agentID)
+}
+
+// CreateAgentChatSessionContext is like CreateAgentChatSession but uses ctx for cancellation.
+func (c *Client) CreateAgentChatSessionContext(ctx context.Context, agentID int) (*ChatSession, error) {
 	var result ChatSession
 	path := fmt.Sprintf("/api/v1/agents/%d/sessions", agentID)
-	if err := c.PostJSON(path, nil, &result); err != nil {
+	if err := c.PostJSONContext(ctx, path, nil, &result); err != nil {
 		return nil, fmt.Errorf("creating chat session: %w", err)
 	}
 	return &result, nil
@@ -296,10 +347,15 @@ func (c *Client) CreateAgentChatSession(agentID int) (*ChatSession, error) {
 
 // SendAgentChatMessage sends a message and returns the response messages.
 func (c *Client) SendAgentChatMessage(agentID, sessionID int, message string) (*ChatSendResponse, error) {
+	return c.SendAgentChatMessageContext(c.context(), agentID, sessionID, message)
+}
+
+// SendAgentChatMessageContext is like SendAgentChatMessage but uses ctx for cancellation.
+func (c *Client) SendAgentChatMessageContext(ctx context.Context, agentID, sessionID int, message string) (*ChatSendResponse, error) {
 	var result ChatSendResponse
 	path := fmt.Sprintf("/api/v1/agents/%d/sessions/%d/messages", agentID, sessionID)
 	payload := map[string]string{"message": message}
-	if err := c.PostJSON(path, payload, &result); err != nil {
+	if err := c.PostJSONContext(ctx, path, payload, &result); err != nil {
 		return nil, fmt.Errorf("sending message: %w", err)
 	}
 	return &result, nil
@@ -307,9 +363,14 @@ func (c *Client) SendAgentChatMessage(agentID, sessionID int, message string) (*
 
 // GetAgentChatMessages lists messages in a session.
 func (c *Client) GetAgentChatMessages(agentID, sessionID int) (*ChatSendResponse, error) {
+	return c.GetAgentChatMessagesContext(c.context(), agentID, sessionID)
+}
+
+// GetAgentChatMessagesContext is like GetAgentChatMessages but uses ctx for cancellation.
+func (c *Client) GetAgentChatMessagesContext(ctx context.Context, agentID, sessionID int) (*ChatSendResponse, error) {
 	var result ChatSendResponse
 	path := fmt.Sprintf("/api/v1/agents/%d/sessions/%d/messages", agentID, sessionID)
-	if err := c.GetJSON(path, &result); err != nil {
+	if err := c.GetJSONContext(ctx, path, &result); err != nil {
 		return nil, fmt.Errorf("getting messages: %w", err)
 	}
 	return &result, nil
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,27 +15,33 @@ import (
 
 // Client is an HTTP client for the Legible REST and GraphQL APIs.
 type Client struct {
-	http      *http.Client
-	endpoint  string
-	apiKey    string
-	projectID string
+	http       *http.Client
+	endpoint   string
+	apiKey     string
+	projectID  string
+	ctx        context.Context
+	maxRetries int
 }
 
 // New creates a Client from the loaded config.
 func New(cfg *config.Config) (*Client, error) {
 	if cfg.Endpoint == "" {
-		return nil, fmt.Errorf("endpoint not configured — run: legible login")
+		return nil, fmt.Errorf("endpoint not configured for profile %q — run: legible login", cfg.Profile)
 	}
 	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("API key not configured — run: legible login")
+		if err := cfg.CredentialError(); err != nil {
+			return nil, err
+		}
+		return nil, &APIError{Code: "UNAUTHENTICATED", Message: fmt.Sprintf("API key not configured for profile %q — run: legible login", cfg.Profile)}
 	}
 	return &Client{
 		http: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		endpoint:  strings.TrimRight(cfg.Endpoint, "/"),
-		apiKey:    cfg.APIKey,
-		projectID: cfg.ProjectID,
+		endpoint:   strings.TrimRight(cfg.Endpoint, "/"),
+		apiKey:     cfg.APIKey,
+		projectID:  cfg.ProjectID,
+		maxRetries: DefaultMaxRetries,
 	}, nil
 }
 
@@ -43,9 +51,30 @@ func NewWithOverrides(endpoint, apiKey string) *Client {
 		http: &http.Client{
 			Timeout: 15 * time.Second,
 		},
-		endpoint: strings.TrimRight(endpoint, "/"),
-		apiKey:   apiKey,
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		apiKey:     apiKey,
+		maxRetries: DefaultMaxRetries,
+	}
+}
+
+// SetContext sets the context used by methods without a Context suffix.
+// The CLI passes a context that is cancelled on Ctrl+C.
+func (c *Client) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// SetMaxRetries changes how many times transient failures are retried.
+// Zero disables retries.
+func (c *Client) SetMaxRetries(n int) {
+	c.maxRetries = n
+}
+
+// context returns the default context for methods without a Context suffix.
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
 	}
+	return context.Background()
 }
 
 // GraphQLRequest represents a GraphQL request body.
@@ -57,29 +86,75 @@ type GraphQLRequest struct {
 // GraphQLResponse represents a GraphQL response.
 type GraphQLResponse struct {
 	Data   json.RawMessage `json:"data"`
-	Errors []struct {
-		Message string `json:"message"`
-	} `json:"errors,omitempty"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// GraphQLError is a single entry in a GraphQL response's errors list.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // doRequest builds and executes an authenticated HTTP request.
-func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response, error) {
+// Transient failures (connection errors, 429/502/503/504) are retried with
+// backoff when the request is safe to repeat; see canRetry.
+func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
 	url := c.endpoint + path
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+
+	// Buffer the body so it can be replayed on retry.
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	if c.projectID != "" {
-		req.Header.Set("X-Project-Id", c.projectID)
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		if c.projectID != "" {
+			req.Header.Set("X-Project-Id", c.projectID)
+		}
+		if id := requestIDFrom(ctx); id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+
+		resp, err := c.http.Do(req)
+		last := attempt >= c.maxRetries
+		switch {
+		case err != nil:
+			if last || !retryableError(ctx, err) || !canRetry(ctx, method, 0) {
+				return nil, err
+			}
+		case retryableStatus(resp.StatusCode):
+			if last || !canRetry(ctx, method, resp.StatusCode) {
+				return resp, nil
+			}
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		if err := sleepContext(ctx, backoff(attempt, resp)); err != nil {
+			return nil, err
+		}
 	}
-	return c.http.Do(req)
 }
 
 // GetJSON performs a GET request and decodes the JSON response into dest.
 func (c *Client) GetJSON(path string, dest interface{}) error {
-	resp, err := c.doRequest("GET", path, nil)
+	return c.GetJSONContext(c.context(), path, dest)
+}
+
+// GetJSONContext is like GetJSON but uses ctx for cancellation.
+func (c *Client) GetJSONContext(ctx context.Context, path string, dest interface{}) error {
+	resp, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return err
 	}
@@ -87,7 +162,7 @@ func (c *Client) GetJSON(path string, dest interface{}) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	return json.NewDecoder(resp.Body).Decode(dest)
@@ -95,12 +170,17 @@ func (c *Client) GetJSON(path string, dest interface{}) error {
 
 // PostJSON performs a POST request with a JSON body and decodes the response into dest.
 func (c *Client) PostJSON(path string, payload interface{}, dest interface{}) error {
+	return c.PostJSONContext(c.context(), path, payload, dest)
+}
+
+// PostJSONContext is like PostJSON but uses ctx for cancellation.
+func (c *Client) PostJSONContext(ctx context.Context, path string, payload interface{}, dest interface{}) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", path, strings.NewReader(string(data)))
+	resp, err := c.doRequest(ctx, "POST", path, strings.NewReader(string(data)))
 	if err != nil {
 		return err
 	}
@@ -108,7 +188,7 @@ func (c *Client) PostJSON(path string, payload interface{}, dest interface{}) er
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	if dest != nil {
@@ -119,12 +199,17 @@ func (c *Client) PostJSON(path string, payload interface{}, dest interface{}) er
 
 // PutJSON performs a PUT request with a JSON body and decodes the response into dest.
 func (c *Client) PutJSON(path string, payload interface{}, dest interface{}) error {
+	return c.PutJSONContext(c.context(), path, payload, dest)
+}
+
+// PutJSONContext is like PutJSON but uses ctx for cancellation.
+func (c *Client) PutJSONContext(ctx context.Context, path string, payload interface{}, dest interface{}) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest("PUT", path, strings.NewReader(string(data)))
+	resp, err := c.doRequest(ctx, "PUT", path, strings.NewReader(string(data)))
 	if err != nil {
 		return err
 	}
@@ -132,7 +217,7 @@ func (c *Client) PutJSON(path string, payload interface{}, dest interface{}) err
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	if dest != nil {
@@ -143,7 +228,12 @@ func (c *Client) PutJSON(path string, payload interface{}, dest interface{}) err
 
 // Delete performs a DELETE request. Returns nil on success (expects 204).
 func (c *Client) Delete(path string) error {
-	resp, err := c.doRequest("DELETE", path, nil)
+	return c.DeleteContext(c.context(), path)
+}
+
+// DeleteContext is like Delete but uses ctx for cancellation.
+func (c *Client) DeleteContext(ctx context.Context, path string) error {
+	resp, err := c.doRequest(ctx, "DELETE", path, nil)
 	if err != nil {
 		return err
 	}
@@ -151,13 +241,18 @@ func (c *Client) Delete(path string) error {
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 	return nil
 }
 
 // GraphQL executes a GraphQL query/mutation and returns the response.
 func (c *Client) GraphQL(query string, variables map[string]interface{}) (*GraphQLResponse, error) {
+	return c.GraphQLContext(c.context(), query, variables)
+}
+
+// GraphQLContext is like GraphQL but uses ctx for cancellation.
+func (c *Client) GraphQLContext(ctx context.Context, query string, variables map[string]interface{}) (*GraphQLResponse, error) {
 	gqlReq := GraphQLRequest{
 		Query:     query,
 		Variables: variables,
@@ -167,7 +262,12 @@ func (c *Client) GraphQL(query string, variables map[string]interface{}) (*Graph
 		return nil, fmt.Errorf("marshaling graphql request: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", "/api/graphql", strings.NewReader(string(data)))
+	// Queries have no side effects and can be retried; mutations cannot.
+	if !strings.HasPrefix(strings.TrimSpace(query), "mutation") {
+		ctx = withSafePOST(ctx)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/api/graphql", strings.NewReader(string(data)))
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +275,7 @@ func (c *Client) GraphQL(query string, variables map[string]interface{}) (*Graph
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GraphQL HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	gqlResp := &GraphQLResponse{}
@@ -184,11 +284,7 @@ func (c *Client) GraphQL(query string, variables map[string]interface{}) (*Graph
 	}
 
 	if len(gqlResp.Errors) > 0 {
-		msgs := make([]string, len(gqlResp.Errors))
-		for i, e := range gqlResp.Errors {
-			msgs[i] = e.Message
-		}
-		return gqlResp, fmt.Errorf("GraphQL errors: %s", strings.Join(msgs, "; "))
+		return gqlResp, newGraphQLError(resp, gqlResp.Errors)
 	}
 
 	return gqlResp, nil
@@ -197,26 +293,32 @@ func (c *Client) GraphQL(query string, variables map[string]interface{}) (*Graph
 // ValidateConnection checks that the endpoint is reachable and the API key is valid.
 // Returns organization/user info on success.
 func (c *Client) ValidateConnection() (*WhoAmIResult, error) {
+	return c.ValidateConnectionContext(c.context())
+}
+
+// ValidateConnectionContext is like ValidateConnection but uses ctx for cancellation.
+func (c *Client) ValidateConnectionContext(ctx context.Context) (*WhoAmIResult, error) {
 	// Use the models endpoint as a lightweight auth check.
 	// A 200 or 400 (no deployment) both indicate valid auth.
 	// Only 401 means the key is invalid.
-	resp, err := c.doRequest("GET", "/api/v1/models", nil)
+	resp, err := c.doRequest(ctx, "GET", "/api/v1/models", nil)
 	if err != nil {
 		return nil, fmt.Errorf("connection failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("authentication failed: invalid API key")
-	}
 	// 200 = success, 400 = auth OK but no deployment — both mean the key is valid
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		apiErr := newAPIError(resp, body)
+		if resp.StatusCode == http.StatusUnauthorized {
+			apiErr.Message = "authentication failed: invalid API key"
+		}
+		return nil, apiErr
 	}
 
 	// Now fetch user/org info via GraphQL
-	return c.fetchWhoAmI()
+	return c.fetchWhoAmI(ctx)
 }
 
 // WhoAmIResult contains the current authenticated user/org information.
@@ -230,12 +332,12 @@ type WhoAmIResult struct {
 	ProjectNames []string `json:"projectNames,omitempty"`
 }
 
-func (c *Client) fetchWhoAmI() (*WhoAmIResult, error) {
+func (c *Client) fetchWhoAmI(ctx context.Context) (*WhoAmIResult, error) {
 	result := &WhoAmIResult{}
 
 	// Try to get user info (works with session auth, not API key auth)
 	userQuery := `query { currentUser { email displayName } }`
-	if gqlResp, err := c.GraphQL(userQuery, nil); err == nil {
+	if gqlResp, err := c.GraphQLContext(ctx, userQuery, nil); err == nil {
 		var data struct {
 			CurrentUser struct {
 				Email       string `json:"email"`
@@ -250,7 +352,7 @@ func (c *Client) fetchWhoAmI() (*WhoAmIResult, error) {
 
 	// Try to get project list (works with API key auth via organizationId)
 	projQuery := `query { listProjects { id displayName } }`
-	if gqlResp, err := c.GraphQL(projQuery, nil); err == nil {
+	if gqlResp, err := c.GraphQLContext(ctx, projQuery, nil); err == nil {
 		var data struct {
 			ListProjects []struct {
 				ID          int    `json:"id"`
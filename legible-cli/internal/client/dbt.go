@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -21,13 +22,18 @@ type SaveDataSourceResult struct {
 
 // SaveDataSource configures the data source connection for the current project.
 func (c *Client) SaveDataSource(input *SaveDataSourceInput) (*SaveDataSourceResult, error) {
+	return c.SaveDataSourceContext(c.context(), input)
+}
+
+// SaveDataSourceContext is like SaveDataSource but uses ctx for cancellation.
+func (c *Client) SaveDataSourceContext(ctx context.Context, input *SaveDataSourceInput) (*SaveDataSourceResult, error) {
 	query := `mutation SaveDataSource($data: DataSourceInput!) {
 		saveDataSource(data: $data) {
 			type properties projectId
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": input,
 	})
 	if err != nil {
@@ -45,11 +51,16 @@ func (c *Client) SaveDataSource(input *SaveDataSourceInput) (*SaveDataSourceResu
 
 // SaveTables tells the server to import the given table names as models.
 func (c *Client) SaveTables(tableNames []string) error {
+	return c.SaveTablesContext(c.context(), tableNames)
+}
+
+// SaveTablesContext is like SaveTables but uses ctx for cancellation.
+func (c *Client) SaveTablesContext(ctx context.Context, tableNames []string) error {
 	query := `mutation SaveTables($data: SaveTablesInput!) {
 		saveTables(data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{
 			"tables": tableNames,
 		},
@@ -76,11 +87,16 @@ type CreateModelInput struct {
 
 // CreateModel creates a model from a database table with the specified columns.
 func (c *Client) CreateModel(input *CreateModelInput) error {
+	return c.CreateModelContext(c.context(), input)
+}
+
+// CreateModelContext is like CreateModel but uses ctx for cancellation.
+func (c *Client) CreateModelContext(ctx context.Context, input *CreateModelInput) error {
 	query := `mutation CreateModel($data: CreateModelInput!) {
 		createModel(data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": input,
 	})
 	if err != nil {
@@ -89,6 +105,54 @@ func (c *Client) CreateModel(input *CreateModelInput) error {
 	return nil
 }
 
+// UpdateModelInput is the input for changing a model's columns and primary key.
+type UpdateModelInput struct {
+	Fields     []string `json:"fields"`
+	PrimaryKey string   `json:"primaryKey,omitempty"`
+}
+
+// UpdateModel replaces a model's columns, keeping its calculated fields,
+// relationships and metadata.
+func (c *Client) UpdateModel(modelID int, input *UpdateModelInput) error {
+	return c.UpdateModelContext(c.context(), modelID, input)
+}
+
+// UpdateModelContext is like UpdateModel but uses ctx for cancellation.
+func (c *Client) UpdateModelContext(ctx context.Context, modelID int, input *UpdateModelInput) error {
+	query := `mutation UpdateModel($where: ModelWhereInput!, $data: UpdateModelInput!) {
+		updateModel(where: $where, data: $data)
+	}`
+
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
+		"where": map[string]interface{}{"id": modelID},
+		"data":  input,
+	})
+	if err != nil {
+		return fmt.Errorf("updating model (id=%d): %w", modelID, err)
+	}
+	return nil
+}
+
+// DeleteModel deletes a model with its calculated fields and relationships.
+func (c *Client) DeleteModel(modelID int) error {
+	return c.DeleteModelContext(c.context(), modelID)
+}
+
+// DeleteModelContext is like DeleteModel but uses ctx for cancellation.
+func (c *Client) DeleteModelContext(ctx context.Context, modelID int) error {
+	query := `mutation DeleteModel($where: ModelWhereInput!) {
+		deleteModel(where: $where)
+	}`
+
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
+		"where": map[string]interface{}{"id": modelID},
+	})
+	if err != nil {
+		return fmt.Errorf("deleting model (id=%d): %w", modelID, err)
+	}
+	return nil
+}
+
 // RelationInput mirrors the GraphQL RelationInput type.
 type RelationInput struct {
 	FromModelID  int    `json:"fromModelId"`
@@ -101,11 +165,16 @@ type RelationInput struct {
 // SaveRelations creates or replaces relationships between models.
 // This mutation automatically triggers an async deploy.
 func (c *Client) SaveRelations(relations []RelationInput) error {
+	return c.SaveRelationsContext(c.context(), relations)
+}
+
+// SaveRelationsContext is like SaveRelations but uses ctx for cancellation.
+func (c *Client) SaveRelationsContext(ctx context.Context, relations []RelationInput) error {
 	query := `mutation SaveRelations($data: SaveRelationInput!) {
 		saveRelations(data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{
 			"relations": relations,
 		},
@@ -132,11 +201,16 @@ type UpdateModelMetadataInput struct {
 
 // UpdateModelMetadata updates a model's display name, description, and column metadata.
 func (c *Client) UpdateModelMetadata(modelID int, input *UpdateModelMetadataInput) error {
+	return c.UpdateModelMetadataContext(c.context(), modelID, input)
+}
+
+// UpdateModelMetadataContext is like UpdateModelMetadata but uses ctx for cancellation.
+func (c *Client) UpdateModelMetadataContext(ctx context.Context, modelID int, input *UpdateModelMetadataInput) error {
 	query := `mutation UpdateModelMetadata($where: ModelWhereInput!, $data: UpdateModelMetadataInput!) {
 		updateModelMetadata(where: $where, data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": modelID},
 		"data":  input,
 	})
@@ -150,7 +224,12 @@ func (c *Client) UpdateModelMetadata(modelID int, input *UpdateModelMetadataInpu
 // RelationInput structs (model/column IDs) by looking up the current models.
 // Returns the resolved relations and any names that couldn't be resolved.
 func (c *Client) ResolveRelations(rels []MDLRelation) ([]RelationInput, []string, error) {
-	models, err := c.ListModels()
+	return c.ResolveRelationsContext(c.context(), rels)
+}
+
+// ResolveRelationsContext is like ResolveRelations but uses ctx for cancellation.
+func (c *Client) ResolveRelationsContext(ctx context.Context, rels []MDLRelation) ([]RelationInput, []string, error) {
+	models, err := c.ListModelsContext(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Error kinds reported by APIError.Kind and KindOf.
+const (
+	KindAuth        = "auth"         // missing, invalid or unauthorized API key
+	KindNotDeployed = "not_deployed" // the project has no deployed models
+	KindSQL         = "sql"          // the SQL failed to parse, plan or execute
+	KindNonSQL      = "non_sql"      // the question could not be answered with SQL
+	KindTimeout     = "timeout"      // the server or client gave up waiting
+	KindOther       = "other"
+)
+
+// APIError is a failure reported by the Legible API, either as a non-2xx
+// HTTP response or as an error payload ({"code": ..., "error": ...}) or
+// GraphQL error. Use errors.As to inspect it.
+type APIError struct {
+	StatusCode int    `json:"statusCode,omitempty"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message"`
+	RequestID  string `json:"requestId,omitempty"`
+
+	// GraphQL-only fields, from the first error in the response.
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	graphQL bool
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.graphQL:
+		return "GraphQL errors: " + e.Message
+	case e.Code != "":
+		return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	default:
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	}
+}
+
+// Kind classifies the error into one of the Kind* categories.
+func (e *APIError) Kind() string {
+	switch e.Code {
+	case "NO_DEPLOYMENT_FOUND":
+		return KindNotDeployed
+	case "INVALID_SQL_ERROR", "SQL_EXECUTION_ERROR", "DRY_RUN_ERROR", "DRY_PLAN_ERROR",
+		"WREN_ENGINE_ERROR", "IBIS_SERVER_ERROR":
+		return KindSQL
+	case "NON_SQL_QUERY", "NO_RELEVANT_DATA", "NO_RELEVANT_SQL",
+		"IDENTIED_AS_GENERAL", "IDENTIED_AS_MISLEADING_QUERY":
+		return KindNonSQL
+	case "POLLING_TIMEOUT", "DEPLOY_TIMEOUT_ERROR":
+		return KindTimeout
+	case "UNAUTHENTICATED", "UNAUTHORIZED", "FORBIDDEN":
+		return KindAuth
+	}
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return KindAuth
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return KindTimeout
+	}
+	return KindOther
+}
+
+// KindOf classifies any error returned by the client. Besides APIError, it
+// recognizes client-side timeouts (context deadlines and HTTP timeouts).
+func KindOf(err error) string {
+	if err == nil {
+		return ""
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Kind()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return KindTimeout
+	}
+	return KindOther
+}
+
+// newAPIError builds an APIError from a failed HTTP response and its body.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  requestIDOf(resp),
+	}
+
+	var payload struct {
+		Code    string `json:"code"`
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil && (payload.Error != "" || payload.Message != "") {
+		e.Code = payload.Code
+		e.Message = payload.Error
+		if e.Message == "" {
+			e.Message = payload.Message
+		}
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
+
+// newPayloadError builds an APIError from the code/error fields that the
+// REST endpoints return alongside a (possibly 200) response.
+func newPayloadError(resp *http.Response, code, message string) *APIError {
+	if code == "" {
+		code = "ERROR"
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       code,
+		Message:    message,
+		RequestID:  requestIDOf(resp),
+	}
+}
+
+// newGraphQLError builds an APIError from the errors of a GraphQL response.
+func newGraphQLError(resp *http.Response, errs []GraphQLError) *APIError {
+	msgs := make([]string, len(errs))
+	for i, ge := range errs {
+		msgs[i] = ge.Message
+	}
+	first := errs[0]
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.Join(msgs, "; "),
+		RequestID:  requestIDOf(resp),
+		Path:       first.Path,
+		Extensions: first.Extensions,
+		graphQL:    true,
+	}
+	if code, ok := first.Extensions["code"].(string); ok {
+		e.Code = code
+	}
+	return e
+}
+
+// requestIDOf returns the request ID echoed by the server, or the one the
+// client sent.
+func requestIDOf(resp *http.Response) string {
+	if id := resp.Header.Get("X-Request-ID"); id != "" {
+		return id
+	}
+	if resp.Request != nil {
+		return resp.Request.Header.Get("X-Request-ID")
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/config"
+)
+
+func TestAPIErrorKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *APIError
+		want string
+	}{
+		{"unauthorized", &APIError{StatusCode: 401, Message: "Invalid API key"}, KindAuth},
+		{"forbidden", &APIError{StatusCode: 403}, KindAuth},
+		{"graphql unauthenticated", &APIError{StatusCode: 200, Code: "UNAUTHENTICATED"}, KindAuth},
+		{"no deployment", &APIError{StatusCode: 400, Code: "NO_DEPLOYMENT_FOUND"}, KindNotDeployed},
+		{"invalid sql", &APIError{StatusCode: 400, Code: "INVALID_SQL_ERROR"}, KindSQL},
+		{"engine error", &APIError{StatusCode: 500, Code: "WREN_ENGINE_ERROR"}, KindSQL},
+		{"non sql", &APIError{StatusCode: 400, Code: "NON_SQL_QUERY"}, KindNonSQL},
+		{"polling timeout", &APIError{StatusCode: 500, Code: "POLLING_TIMEOUT"}, KindTimeout},
+		{"gateway timeout", &APIError{StatusCode: 504}, KindTimeout},
+		{"server error", &APIError{StatusCode: 500}, KindOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Kind(); got != tt.want {
+				t.Errorf("Kind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	wrapped := fmt.Errorf("listing models: %w", &APIError{StatusCode: 401})
+	if got := KindOf(wrapped); got != KindAuth {
+		t.Errorf("KindOf(wrapped auth) = %q, want %q", got, KindAuth)
+	}
+	if got := KindOf(fmt.Errorf("asking: %w", context.DeadlineExceeded)); got != KindTimeout {
+		t.Errorf("KindOf(deadline) = %q, want %q", got, KindTimeout)
+	}
+	if got := KindOf(errors.New("boom")); got != KindOther {
+		t.Errorf("KindOf(plain) = %q, want %q", got, KindOther)
+	}
+}
+
+func TestGetJSONReturnsAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "srv-123")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"Invalid API key"}`))
+	})
+
+	err := c.GetJSON("/api/v1/models", &struct{}{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != 401 || apiErr.Message != "Invalid API key" || apiErr.RequestID != "srv-123" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+	if got := err.Error(); got != "HTTP 401: Invalid API key" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestGraphQLReturnsAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":null,"errors":[{"message":"Model not found","path":["model"],"extensions":{"code":"RESOURCE_NOT_FOUND"}}]}`))
+	})
+
+	_, err := c.GetModel(42)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.Code != "RESOURCE_NOT_FOUND" || apiErr.Message != "Model not found" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+	if len(apiErr.Path) != 1 || apiErr.Path[0] != "model" {
+		t.Errorf("Path = %v, want [model]", apiErr.Path)
+	}
+}
+
+func TestRunSQLPayloadError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"id":"q1","code":"INVALID_SQL_ERROR","error":"column \"x\" does not exist"}`))
+	})
+
+	result, err := c.RunSQL(&RunSQLRequest{SQL: "SELECT x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.Kind() != KindSQL {
+		t.Errorf("Kind() = %q, want %q", apiErr.Kind(), KindSQL)
+	}
+	if result == nil || result.ID != "q1" {
+		t.Errorf("result = %+v, want parsed payload", result)
+	}
+}
+
+func TestAPIErrorNonJSONBody(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<html>oops</html>"))
+	})
+
+	_, err := c.Ask(&AskRequest{Question: "q"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != 500 || apiErr.Message != "<html>oops</html>" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestValidateConnectionReturnsAPIError(t *testing.T) {
+	tests := []struct {
+		status   int
+		wantKind string
+	}{
+		{http.StatusUnauthorized, KindAuth},
+		{http.StatusForbidden, KindAuth},
+		{http.StatusBadGateway, KindOther},
+	}
+	for _, tt := range tests {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		})
+		c.maxRetries = 0
+		_, err := c.ValidateConnection()
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+			t.Fatalf("ValidateConnection() with %d = %v, want an APIError", tt.status, err)
+		}
+		if got := KindOf(err); got != tt.wantKind {
+			t.Errorf("KindOf(%d) = %q, want %q", tt.status, got, tt.wantKind)
+		}
+	}
+}
+
+func TestNewWithoutAPIKey(t *testing.T) {
+	_, err := New(&config.Config{Profile: "default", Endpoint: "http://localhost"})
+	if KindOf(err) != KindAuth {
+		t.Errorf("New() without an API key = %v (kind %q), want kind %q", err, KindOf(err), KindAuth)
+	}
+}
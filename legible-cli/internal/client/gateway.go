@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -30,11 +31,16 @@ const gatewayFields = `id organizationId status endpoint port pid cpus memory sa
 
 // GetGateway fetches a gateway by ID.
 func (c *Client) GetGateway(id int) (*Gateway, error) {
+	return c.GetGatewayContext(c.context(), id)
+}
+
+// GetGatewayContext is like GetGateway but uses ctx for cancellation.
+func (c *Client) GetGatewayContext(ctx context.Context, id int) (*Gateway, error) {
 	query := fmt.Sprintf(`query GetGateway($where: GatewayWhereInput!) {
 		gateway(where: $where) { %s }
 	}`, gatewayFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 	})
 	if err != nil {
@@ -52,11 +58,16 @@ func (c *Client) GetGateway(id int) (*Gateway, error) {
 
 // GetGatewayForOrganization fetches the gateway for an organization.
 func (c *Client) GetGatewayForOrganization(orgID int) (*Gateway, error) {
+	return c.GetGatewayForOrganizationContext(c.context(), orgID)
+}
+
+// GetGatewayForOrganizationContext is like GetGatewayForOrganization but uses ctx for cancellation.
+func (c *Client) GetGatewayForOrganizationContext(ctx context.Context, orgID int) (*Gateway, error) {
 	query := fmt.Sprintf(`query GetGatewayForOrg($organizationId: Int!) {
 		gatewayForOrganization(organizationId: $organizationId) { %s }
 	}`, gatewayFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"organizationId": orgID,
 	})
 	if err != nil {
@@ -74,11 +85,16 @@ func (c *Client) GetGatewayForOrganization(orgID int) (*Gateway, error) {
 
 // ListRunningGateways lists all running gateways.
 func (c *Client) ListRunningGateways() ([]Gateway, error) {
+	return c.ListRunningGatewaysContext(c.context())
+}
+
+// ListRunningGatewaysContext is like ListRunningGateways but uses ctx for cancellation.
+func (c *Client) ListRunningGatewaysContext(ctx context.Context) ([]Gateway, error) {
 	query := fmt.Sprintf(`query {
 		runningGateways { %s }
 	}`, gatewayFields)
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing gateways: %w", err)
 	}
@@ -94,6 +110,11 @@ func (c *Client) ListRunningGateways() ([]Gateway, error) {
 
 // CreateGateway creates a new gateway for an organization.
 func (c *Client) CreateGateway(orgID int, cpus, memory string, maxSandboxes int) (*Gateway, error) {
+	return c.CreateGatewayContext(c.context(), orgID, cpus, memory, maxSandboxes)
+}
+
+// CreateGatewayContext is like CreateGateway but uses ctx for cancellation.
+func (c *Client) CreateGatewayContext(ctx context.Context, orgID int, cpus, memory string, maxSandboxes int) (*Gateway, error) {
 	query := fmt.Sprintf(`mutation CreateGateway($data: CreateGatewayInput!) {
 		createGateway(data: $data) { %s }
 	}`, gatewayFields)
@@ -111,7 +132,7 @@ func (c *Client) CreateGateway(orgID int, cpus, memory string, maxSandboxes int)
 		input["maxSandboxes"] = maxSandboxes
 	}
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": input,
 	})
 	if err != nil {
@@ -129,11 +150,16 @@ func (c *Client) CreateGateway(orgID int, cpus, memory string, maxSandboxes int)
 
 // UpdateGateway updates a gateway's properties.
 func (c *Client) UpdateGateway(id int, updates map[string]interface{}) (*Gateway, error) {
+	return c.UpdateGatewayContext(c.context(), id, updates)
+}
+
+// UpdateGatewayContext is like UpdateGateway but uses ctx for cancellation.
+func (c *Client) UpdateGatewayContext(ctx context.Context, id int, updates map[string]interface{}) (*Gateway, error) {
 	query := fmt.Sprintf(`mutation UpdateGateway($where: GatewayWhereInput!, $data: UpdateGatewayInput!) {
 		updateGateway(where: $where, data: $data) { %s }
 	}`, gatewayFields)
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 		"data":  updates,
 	})
@@ -152,11 +178,16 @@ func (c *Client) UpdateGateway(id int, updates map[string]interface{}) (*Gateway
 
 // DeleteGateway deletes a gateway by ID.
 func (c *Client) DeleteGateway(id int) error {
+	return c.DeleteGatewayContext(c.context(), id)
+}
+
+// DeleteGatewayContext is like DeleteGateway but uses ctx for cancellation.
+func (c *Client) DeleteGatewayContext(ctx context.Context, id int) error {
 	query := `mutation DeleteGateway($where: GatewayWhereInput!) {
 		deleteGateway(where: $where)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": id},
 	})
 	if err != nil {
@@ -167,7 +198,12 @@ func (c *Client) DeleteGateway(id int) error {
 
 // GetCurrentOrgID resolves the organization ID from the current session.
 func (c *Client) GetCurrentOrgID() (int, error) {
-	org, err := c.GetCurrentOrg()
+	return c.GetCurrentOrgIDContext(c.context())
+}
+
+// GetCurrentOrgIDContext is like GetCurrentOrgID but uses ctx for cancellation.
+func (c *Client) GetCurrentOrgIDContext(ctx context.Context) (int, error) {
+	org, err := c.GetCurrentOrgContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -182,9 +218,14 @@ type OrgInfo struct {
 
 // GetCurrentOrg resolves the current organization (ID + slug).
 func (c *Client) GetCurrentOrg() (*OrgInfo, error) {
+	return c.GetCurrentOrgContext(c.context())
+}
+
+// GetCurrentOrgContext is like GetCurrentOrg but uses ctx for cancellation.
+func (c *Client) GetCurrentOrgContext(ctx context.Context) (*OrgInfo, error) {
 	query := `query { listOrganizations { id slug displayName } }`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("fetching organization: %w", err)
 	}
@@ -1,6 +1,9 @@
 package client
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // --- Instructions ---
 
@@ -28,8 +31,13 @@ type UpdateInstructionRequest struct {
 
 // ListInstructions returns all instructions for the current project.
 func (c *Client) ListInstructions() ([]Instruction, error) {
+	return c.ListInstructionsContext(c.context())
+}
+
+// ListInstructionsContext is like ListInstructions but uses ctx for cancellation.
+func (c *Client) ListInstructionsContext(ctx context.Context) ([]Instruction, error) {
 	var result []Instruction
-	if err := c.GetJSON("/api/v1/knowledge/instructions", &result); err != nil {
+	if err := c.GetJSONContext(ctx, "/api/v1/knowledge/instructions", &result); err != nil {
 		return nil, fmt.Errorf("listing instructions: %w", err)
 	}
 	return result, nil
@@ -37,8 +45,13 @@ func (c *Client) ListInstructions() ([]Instruction, error) {
 
 // CreateInstruction creates a new instruction.
 func (c *Client) CreateInstruction(req *CreateInstructionRequest) (*Instruction, error) {
+	return c.CreateInstructionContext(c.context(), req)
+}
+
+// CreateInstructionContext is like CreateInstruction but uses ctx for cancellation.
+func (c *Client) CreateInstructionContext(ctx context.Context, req *CreateInstructionRequest) (*Instruction, error) {
 	var result Instruction
-	if err := c.PostJSON("/api/v1/knowledge/instructions", req, &result); err != nil {
+	if err := c.PostJSONContext(ctx, "/api/v1/knowledge/instructions", req, &result); err != nil {
 		return nil, fmt.Errorf("creating instruction: %w", err)
 	}
 	return &result, nil
@@ -46,9 +59,14 @@ func (c *Client) CreateInstruction(req *CreateInstructionRequest) (*Instruction,
 
 // UpdateInstruction updates an existing instruction.
 func (c *Client) UpdateInstruction(id int, req *UpdateInstructionRequest) (*Instruction, error) {
+	return c.UpdateInstructionContext(c.context(), id, req)
+}
+
+// UpdateInstructionContext is like UpdateInstruction but uses ctx for cancellation.
+func (c *Client) UpdateInstructionContext(ctx context.Context, id int, req *UpdateInstructionRequest) (*Instruction, error) {
 	var result Instruction
 	path := fmt.Sprintf("/api/v1/knowledge/instructions/%d", id)
-	if err := c.PutJSON(path, req, &result); err != nil {
+	if err := c.PutJSONContext(ctx, path, req, &result); err != nil {
 		return nil, fmt.Errorf("updating instruction: %w", err)
 	}
 	return &result, nil
@@ -56,8 +74,13 @@ func (c *Client) UpdateInstruction(id int, req *UpdateInstructionRequest) (*Inst
 
 // DeleteInstruction deletes an instruction by ID.
 func (c *Client) DeleteInstruction(id int) error {
+	return c.DeleteInstructionContext(c.context(), id)
+}
+
+// DeleteInstructionContext is like DeleteInstruction but uses ctx for cancellation.
+func (c *Client) DeleteInstructionContext(ctx context.Context, id int) error {
 	path := fmt.Sprintf("/api/v1/knowledge/instructions/%d", id)
-	if err := c.Delete(path); err != nil {
+	if err := c.DeleteContext(ctx, path); err != nil {
 		return fmt.Errorf("deleting instruction: %w", err)
 	}
 	return nil
@@ -89,8 +112,13 @@ type UpdateSqlPairRequest struct {
 
 // ListSqlPairs returns all SQL pairs for the current project.
 func (c *Client) ListSqlPairs() ([]SqlPair, error) {
+	return c.ListSqlPairsContext(c.context())
+}
+
+// ListSqlPairsContext is like ListSqlPairs but uses ctx for cancellation.
+func (c *Client) ListSqlPairsContext(ctx context.Context) ([]SqlPair, error) {
 	var result []SqlPair
-	if err := c.GetJSON("/api/v1/knowledge/sql_pairs", &result); err != nil {
+	if err := c.GetJSONContext(ctx, "/api/v1/knowledge/sql_pairs", &result); err != nil {
 		return nil, fmt.Errorf("listing SQL pairs: %w", err)
 	}
 	return result, nil
@@ -98,8 +126,13 @@ func (c *Client) ListSqlPairs() ([]SqlPair, error) {
 
 // CreateSqlPair creates a new SQL pair.
 func (c *Client) CreateSqlPair(req *CreateSqlPairRequest) (*SqlPair, error) {
+	return c.CreateSqlPairContext(c.context(), req)
+}
+
+// CreateSqlPairContext is like CreateSqlPair but uses ctx for cancellation.
+func (c *Client) CreateSqlPairContext(ctx context.Context, req *CreateSqlPairRequest) (*SqlPair, error) {
 	var result SqlPair
-	if err := c.PostJSON("/api/v1/knowledge/sql_pairs", req, &result); err != nil {
+	if err := c.PostJSONContext(ctx, "/api/v1/knowledge/sql_pairs", req, &result); err != nil {
 		return nil, fmt.Errorf("creating SQL pair: %w", err)
 	}
 	return &result, nil
@@ -107,9 +140,14 @@ func (c *Client) CreateSqlPair(req *CreateSqlPairRequest) (*SqlPair, error) {
 
 // UpdateSqlPair updates an existing SQL pair.
 func (c *Client) UpdateSqlPair(id int, req *UpdateSqlPairRequest) (*SqlPair, error) {
+	return c.UpdateSqlPairContext(c.context(), id, req)
+}
+
+// UpdateSqlPairContext is like UpdateSqlPair but uses ctx for cancellation.
+func (c *Client) UpdateSqlPairContext(ctx context.Context, id int, req *UpdateSqlPairRequest) (*SqlPair, error) {
 	var result SqlPair
 	path := fmt.Sprintf("/api/v1/knowledge/sql_pairs/%d", id)
-	if err := c.PutJSON(path, req, &result); err != nil {
+	if err := c.PutJSONContext(ctx, path, req, &result); err != nil {
 		return nil, fmt.Errorf("updating SQL pair: %w", err)
 	}
 	return &result, nil
@@ -117,8 +155,13 @@ func (c *Client) UpdateSqlPair(id int, req *UpdateSqlPairRequest) (*SqlPair, err
 
 // DeleteSqlPair deletes a SQL pair by ID.
 func (c *Client) DeleteSqlPair(id int) error {
+	return c.DeleteSqlPairContext(c.context(), id)
+}
+
+// DeleteSqlPairContext is like DeleteSqlPair but uses ctx for cancellation.
+func (c *Client) DeleteSqlPairContext(ctx context.Context, id int) error {
 	path := fmt.Sprintf("/api/v1/knowledge/sql_pairs/%d", id)
-	if err := c.Delete(path); err != nil {
+	if err := c.DeleteContext(ctx, path); err != nil {
 		return fmt.Errorf("deleting SQL pair: %w", err)
 	}
 	return nil
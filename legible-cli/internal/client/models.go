@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -18,13 +19,18 @@ type Project struct {
 
 // ListProjects returns all projects in the organization.
 func (c *Client) ListProjects() ([]Project, error) {
+	return c.ListProjectsContext(c.context())
+}
+
+// ListProjectsContext is like ListProjects but uses ctx for cancellation.
+func (c *Client) ListProjectsContext(ctx context.Context) ([]Project, error) {
 	query := `query {
 		listProjects {
 			id displayName type language timezone createdAt updatedAt
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing projects: %w", err)
 	}
@@ -40,13 +46,18 @@ func (c *Client) ListProjects() ([]Project, error) {
 
 // GetProject returns a single project by ID.
 func (c *Client) GetProject(projectID int) (*Project, error) {
+	return c.GetProjectContext(c.context(), projectID)
+}
+
+// GetProjectContext is like GetProject but uses ctx for cancellation.
+func (c *Client) GetProjectContext(ctx context.Context, projectID int) (*Project, error) {
 	query := `query GetProject($projectId: Int!) {
 		project(projectId: $projectId) {
 			id displayName type language timezone createdAt updatedAt
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"projectId": projectID,
 	})
 	if err != nil {
@@ -87,6 +98,14 @@ type Field struct {
 	IsCalculated     bool   `json:"isCalculated"`
 	NotNull          bool   `json:"notNull"`
 	Expression       string `json:"expression,omitempty"`
+	// Properties holds the field's metadata, such as its description.
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Description returns the field's description, or "" if it has none.
+func (f Field) Description() string {
+	description, _ := f.Properties["description"].(string)
+	return description
 }
 
 // DetailedModel has full detail including relations.
@@ -134,16 +153,21 @@ type View struct {
 
 // ListModels returns all models in the current project.
 func (c *Client) ListModels() ([]Model, error) {
+	return c.ListModelsContext(c.context())
+}
+
+// ListModelsContext is like ListModels but uses ctx for cancellation.
+func (c *Client) ListModelsContext(ctx context.Context) ([]Model, error) {
 	query := `query {
 		listModels {
 			id displayName referenceName sourceTableName refSql
 			primaryKey cached refreshTime description
-			fields { id displayName referenceName sourceColumnName type isCalculated notNull expression }
-			calculatedFields { id displayName referenceName sourceColumnName type isCalculated notNull expression }
+			fields { id displayName referenceName sourceColumnName type isCalculated notNull expression properties }
+			calculatedFields { id displayName referenceName sourceColumnName type isCalculated notNull expression properties }
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing models: %w", err)
 	}
@@ -159,6 +183,11 @@ func (c *Client) ListModels() ([]Model, error) {
 
 // GetModel returns detailed information for a single model.
 func (c *Client) GetModel(modelID int) (*DetailedModel, error) {
+	return c.GetModelContext(c.context(), modelID)
+}
+
+// GetModelContext is like GetModel but uses ctx for cancellation.
+func (c *Client) GetModelContext(ctx context.Context, modelID int) (*DetailedModel, error) {
 	query := `query GetModel($where: ModelWhereInput!) {
 		model(where: $where) {
 			displayName referenceName sourceTableName
@@ -169,7 +198,7 @@ func (c *Client) GetModel(modelID int) (*DetailedModel, error) {
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": modelID},
 	})
 	if err != nil {
@@ -187,11 +216,16 @@ func (c *Client) GetModel(modelID int) (*DetailedModel, error) {
 
 // ListViews returns all views in the current project.
 func (c *Client) ListViews() ([]View, error) {
+	return c.ListViewsContext(c.context())
+}
+
+// ListViewsContext is like ListViews but uses ctx for cancellation.
+func (c *Client) ListViewsContext(ctx context.Context) ([]View, error) {
 	query := `query {
 		listViews { id name statement displayName }
 	}`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing views: %w", err)
 	}
@@ -213,11 +247,16 @@ type DeployResult struct {
 
 // Deploy triggers a deployment of the current project's MDL.
 func (c *Client) Deploy(force bool) (*DeployResult, error) {
+	return c.DeployContext(c.context(), force)
+}
+
+// DeployContext is like Deploy but uses ctx for cancellation.
+func (c *Client) DeployContext(ctx context.Context, force bool) (*DeployResult, error) {
 	query := `mutation Deploy($force: Boolean) {
 		deploy(force: $force)
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"force": force,
 	})
 	if err != nil {
@@ -246,8 +285,13 @@ type DeployedMDL struct {
 
 // GetDeployedModels returns the currently deployed MDL via REST API.
 func (c *Client) GetDeployedModels() (*DeployedMDL, error) {
+	return c.GetDeployedModelsContext(c.context())
+}
+
+// GetDeployedModelsContext is like GetDeployedModels but uses ctx for cancellation.
+func (c *Client) GetDeployedModelsContext(ctx context.Context) (*DeployedMDL, error) {
 	var result DeployedMDL
-	if err := c.GetJSON("/api/v1/models", &result); err != nil {
+	if err := c.GetJSONContext(ctx, "/api/v1/models", &result); err != nil {
 		return nil, fmt.Errorf("getting deployed models: %w", err)
 	}
 	return &result, nil
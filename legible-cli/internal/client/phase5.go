@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -29,9 +30,14 @@ type DetailedThread struct {
 
 // ListThreads returns all threads for the current project.
 func (c *Client) ListThreads() ([]Thread, error) {
+	return c.ListThreadsContext(c.context())
+}
+
+// ListThreadsContext is like ListThreads but uses ctx for cancellation.
+func (c *Client) ListThreadsContext(ctx context.Context) ([]Thread, error) {
 	query := `query { threads { id summary } }`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing threads: %w", err)
 	}
@@ -47,6 +53,11 @@ func (c *Client) ListThreads() ([]Thread, error) {
 
 // GetThread returns a thread with all its responses.
 func (c *Client) GetThread(threadID int) (*DetailedThread, error) {
+	return c.GetThreadContext(c.context(), threadID)
+}
+
+// GetThreadContext is like GetThread but uses ctx for cancellation.
+func (c *Client) GetThreadContext(ctx context.Context, threadID int) (*DetailedThread, error) {
 	query := `query GetThread($threadId: Int!) {
 		thread(threadId: $threadId) {
 			id
@@ -54,7 +65,7 @@ func (c *Client) GetThread(threadID int) (*DetailedThread, error) {
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"threadId": threadID,
 	})
 	if err != nil {
@@ -70,13 +81,46 @@ func (c *Client) GetThread(threadID int) (*DetailedThread, error) {
 	return &data.Thread, nil
 }
 
+// CreateThread starts a thread whose first response is question and sql,
+// without running the AI pipeline.
+func (c *Client) CreateThread(question, sql string) (*Thread, error) {
+	return c.CreateThreadContext(c.context(), question, sql)
+}
+
+// CreateThreadContext is like CreateThread but uses ctx for cancellation.
+func (c *Client) CreateThreadContext(ctx context.Context, question, sql string) (*Thread, error) {
+	query := `mutation CreateThread($data: CreateThreadInput!) {
+		createThread(data: $data) { id summary }
+	}`
+
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
+		"data": map[string]interface{}{"question": question, "sql": sql},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating thread: %w", err)
+	}
+
+	var data struct {
+		CreateThread Thread `json:"createThread"`
+	}
+	if err := json.Unmarshal(gqlResp.Data, &data); err != nil {
+		return nil, fmt.Errorf("parsing thread: %w", err)
+	}
+	return &data.CreateThread, nil
+}
+
 // UpdateThread updates a thread's summary.
 func (c *Client) UpdateThread(threadID int, summary string) (*Thread, error) {
+	return c.UpdateThreadContext(c.context(), threadID, summary)
+}
+
+// UpdateThreadContext is like UpdateThread but uses ctx for cancellation.
+func (c *Client) UpdateThreadContext(ctx context.Context, threadID int, summary string) (*Thread, error) {
 	query := `mutation UpdateThread($where: ThreadUniqueWhereInput!, $data: UpdateThreadInput!) {
 		updateThread(where: $where, data: $data) { id summary }
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": threadID},
 		"data":  map[string]interface{}{"summary": summary},
 	})
@@ -95,11 +139,16 @@ func (c *Client) UpdateThread(threadID int, summary string) (*Thread, error) {
 
 // DeleteThread deletes a thread by ID.
 func (c *Client) DeleteThread(threadID int) error {
+	return c.DeleteThreadContext(c.context(), threadID)
+}
+
+// DeleteThreadContext is like DeleteThread but uses ctx for cancellation.
+func (c *Client) DeleteThreadContext(ctx context.Context, threadID int) error {
 	query := `mutation DeleteThread($where: ThreadUniqueWhereInput!) {
 		deleteThread(where: $where)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": threadID},
 	})
 	if err != nil {
@@ -131,6 +180,11 @@ type Relation struct {
 
 // ListRelations returns all relationships via the diagram query.
 func (c *Client) ListRelations() ([]Relation, error) {
+	return c.ListRelationsContext(c.context())
+}
+
+// ListRelationsContext is like ListRelations but uses ctx for cancellation.
+func (c *Client) ListRelationsContext(ctx context.Context) ([]Relation, error) {
 	query := `query {
 		diagram {
 			models {
@@ -145,7 +199,7 @@ func (c *Client) ListRelations() ([]Relation, error) {
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing relations: %w", err)
 	}
@@ -177,11 +231,16 @@ func (c *Client) ListRelations() ([]Relation, error) {
 
 // CreateRelation creates a new relationship between models.
 func (c *Client) CreateRelation(fromModelID, fromColumnID, toModelID, toColumnID int, relType string) error {
+	return c.CreateRelationContext(c.context(), fromModelID, fromColumnID, toModelID, toColumnID, relType)
+}
+
+// CreateRelationContext is like CreateRelation but uses ctx for cancellation.
+func (c *Client) CreateRelationContext(ctx context.Context, fromModelID, fromColumnID, toModelID, toColumnID int, relType string) error {
 	query := `mutation CreateRelation($data: RelationInput!) {
 		createRelation(data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{
 			"fromModelId":  fromModelID,
 			"fromColumnId": fromColumnID,
@@ -198,11 +257,16 @@ func (c *Client) CreateRelation(fromModelID, fromColumnID, toModelID, toColumnID
 
 // UpdateRelation updates a relationship's type.
 func (c *Client) UpdateRelation(relationID int, relType string) error {
+	return c.UpdateRelationContext(c.context(), relationID, relType)
+}
+
+// UpdateRelationContext is like UpdateRelation but uses ctx for cancellation.
+func (c *Client) UpdateRelationContext(ctx context.Context, relationID int, relType string) error {
 	query := `mutation UpdateRelation($where: WhereIdInput!, $data: UpdateRelationInput!) {
 		updateRelation(where: $where, data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": relationID},
 		"data":  map[string]interface{}{"type": relType},
 	})
@@ -214,11 +278,16 @@ func (c *Client) UpdateRelation(relationID int, relType string) error {
 
 // DeleteRelation deletes a relationship.
 func (c *Client) DeleteRelation(relationID int) error {
+	return c.DeleteRelationContext(c.context(), relationID)
+}
+
+// DeleteRelationContext is like DeleteRelation but uses ctx for cancellation.
+func (c *Client) DeleteRelationContext(ctx context.Context, relationID int) error {
 	query := `mutation DeleteRelation($where: WhereIdInput!) {
 		deleteRelation(where: $where)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": relationID},
 	})
 	if err != nil {
@@ -231,13 +300,18 @@ func (c *Client) DeleteRelation(relationID int) error {
 
 // CreateProject creates a new project.
 func (c *Client) CreateProject(displayName string) (*Project, error) {
+	return c.CreateProjectContext(c.context(), displayName)
+}
+
+// CreateProjectContext is like CreateProject but uses ctx for cancellation.
+func (c *Client) CreateProjectContext(ctx context.Context, displayName string) (*Project, error) {
 	query := `mutation CreateProject($data: CreateProjectInput!) {
 		createProject(data: $data) {
 			id displayName type language timezone createdAt updatedAt
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{"displayName": displayName},
 	})
 	if err != nil {
@@ -255,6 +329,11 @@ func (c *Client) CreateProject(displayName string) (*Project, error) {
 
 // UpdateProject updates a project's settings.
 func (c *Client) UpdateProject(projectID int, displayName, language, timezone *string) (*Project, error) {
+	return c.UpdateProjectContext(c.context(), projectID, displayName, language, timezone)
+}
+
+// UpdateProjectContext is like UpdateProject but uses ctx for cancellation.
+func (c *Client) UpdateProjectContext(ctx context.Context, projectID int, displayName, language, timezone *string) (*Project, error) {
 	query := `mutation UpdateProject($projectId: Int!, $data: UpdateProjectInput!) {
 		updateProject(projectId: $projectId, data: $data) {
 			id displayName type language timezone createdAt updatedAt
@@ -272,7 +351,7 @@ func (c *Client) UpdateProject(projectID int, displayName, language, timezone *s
 		updateData["timezone"] = *timezone
 	}
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"projectId": projectID,
 		"data":      updateData,
 	})
@@ -291,11 +370,16 @@ func (c *Client) UpdateProject(projectID int, displayName, language, timezone *s
 
 // DeleteProject deletes a project by ID.
 func (c *Client) DeleteProject(projectID int) error {
+	return c.DeleteProjectContext(c.context(), projectID)
+}
+
+// DeleteProjectContext is like DeleteProject but uses ctx for cancellation.
+func (c *Client) DeleteProjectContext(ctx context.Context, projectID int) error {
 	query := `mutation DeleteProject($projectId: Int!) {
 		deleteProject(projectId: $projectId)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"projectId": projectID,
 	})
 	if err != nil {
@@ -308,11 +392,16 @@ func (c *Client) DeleteProject(projectID int) error {
 
 // GetView returns a single view by ID.
 func (c *Client) GetView(viewID int) (*View, error) {
+	return c.GetViewContext(c.context(), viewID)
+}
+
+// GetViewContext is like GetView but uses ctx for cancellation.
+func (c *Client) GetViewContext(ctx context.Context, viewID int) (*View, error) {
 	query := `query GetView($where: ViewWhereUniqueInput!) {
 		view(where: $where) { id name statement displayName }
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": viewID},
 	})
 	if err != nil {
@@ -330,11 +419,16 @@ func (c *Client) GetView(viewID int) (*View, error) {
 
 // CreateView creates a new view from a thread response.
 func (c *Client) CreateView(name string, responseID int) (*View, error) {
+	return c.CreateViewContext(c.context(), name, responseID)
+}
+
+// CreateViewContext is like CreateView but uses ctx for cancellation.
+func (c *Client) CreateViewContext(ctx context.Context, name string, responseID int) (*View, error) {
 	query := `mutation CreateView($data: CreateViewInput!) {
 		createView(data: $data) { id name statement displayName }
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{
 			"name":               name,
 			"responseId":         responseID,
@@ -356,11 +450,16 @@ func (c *Client) CreateView(name string, responseID int) (*View, error) {
 
 // DeleteView deletes a view by ID.
 func (c *Client) DeleteView(viewID int) error {
+	return c.DeleteViewContext(c.context(), viewID)
+}
+
+// DeleteViewContext is like DeleteView but uses ctx for cancellation.
+func (c *Client) DeleteViewContext(ctx context.Context, viewID int) error {
 	query := `mutation DeleteView($where: ViewWhereUniqueInput!) {
 		deleteView(where: $where)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": viewID},
 	})
 	if err != nil {
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -24,6 +25,11 @@ type CalculatedField struct {
 
 // ListCalculatedFields returns calculated fields for a model.
 func (c *Client) ListCalculatedFields(modelID int) ([]CalculatedField, error) {
+	return c.ListCalculatedFieldsContext(c.context(), modelID)
+}
+
+// ListCalculatedFieldsContext is like ListCalculatedFields but uses ctx for cancellation.
+func (c *Client) ListCalculatedFieldsContext(ctx context.Context, modelID int) ([]CalculatedField, error) {
 	// Use listModels which returns FieldInfo (with id) for calculatedFields
 	query := `query {
 		listModels {
@@ -34,7 +40,7 @@ func (c *Client) ListCalculatedFields(modelID int) ([]CalculatedField, error) {
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing calculated fields: %w", err)
 	}
@@ -60,11 +66,16 @@ func (c *Client) ListCalculatedFields(modelID int) ([]CalculatedField, error) {
 
 // CreateCalculatedField creates a new calculated field on a model.
 func (c *Client) CreateCalculatedField(modelID int, name, expression string, lineage []int) error {
+	return c.CreateCalculatedFieldContext(c.context(), modelID, name, expression, lineage)
+}
+
+// CreateCalculatedFieldContext is like CreateCalculatedField but uses ctx for cancellation.
+func (c *Client) CreateCalculatedFieldContext(ctx context.Context, modelID int, name, expression string, lineage []int) error {
 	query := `mutation CreateCalculatedField($data: CreateCalculatedFieldInput!) {
 		createCalculatedField(data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{
 			"modelId":    modelID,
 			"name":       name,
@@ -80,11 +91,16 @@ func (c *Client) CreateCalculatedField(modelID int, name, expression string, lin
 
 // UpdateCalculatedField updates a calculated field.
 func (c *Client) UpdateCalculatedField(fieldID int, name, expression string, lineage []int) error {
+	return c.UpdateCalculatedFieldContext(c.context(), fieldID, name, expression, lineage)
+}
+
+// UpdateCalculatedFieldContext is like UpdateCalculatedField but uses ctx for cancellation.
+func (c *Client) UpdateCalculatedFieldContext(ctx context.Context, fieldID int, name, expression string, lineage []int) error {
 	query := `mutation UpdateCalculatedField($where: UpdateCalculatedFieldWhere!, $data: UpdateCalculatedFieldInput!) {
 		updateCalculatedField(where: $where, data: $data)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": fieldID},
 		"data": map[string]interface{}{
 			"name":       name,
@@ -100,11 +116,16 @@ func (c *Client) UpdateCalculatedField(fieldID int, name, expression string, lin
 
 // DeleteCalculatedField deletes a calculated field.
 func (c *Client) DeleteCalculatedField(fieldID int) error {
+	return c.DeleteCalculatedFieldContext(c.context(), fieldID)
+}
+
+// DeleteCalculatedFieldContext is like DeleteCalculatedField but uses ctx for cancellation.
+func (c *Client) DeleteCalculatedFieldContext(ctx context.Context, fieldID int) error {
 	query := `mutation DeleteCalculatedField($where: UpdateCalculatedFieldWhere!) {
 		deleteCalculatedField(where: $where)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"where": map[string]interface{}{"id": fieldID},
 	})
 	if err != nil {
@@ -115,6 +136,11 @@ func (c *Client) DeleteCalculatedField(fieldID int) error {
 
 // ValidateCalculatedField checks if a calculated field name is valid.
 func (c *Client) ValidateCalculatedField(name string, modelID int, columnID *int) (*CalcFieldValidation, error) {
+	return c.ValidateCalculatedFieldContext(c.context(), name, modelID, columnID)
+}
+
+// ValidateCalculatedFieldContext is like ValidateCalculatedField but uses ctx for cancellation.
+func (c *Client) ValidateCalculatedFieldContext(ctx context.Context, name string, modelID int, columnID *int) (*CalcFieldValidation, error) {
 	query := `mutation ValidateCalculatedField($data: ValidateCalculatedFieldInput!) {
 		validateCalculatedField(data: $data) { valid message }
 	}`
@@ -127,7 +153,7 @@ func (c *Client) ValidateCalculatedField(name string, modelID int, columnID *int
 		data["columnId"] = *columnID
 	}
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": data,
 	})
 	if err != nil {
@@ -182,6 +208,11 @@ type ApiHistoryFilter struct {
 
 // GetApiHistory queries API history with optional filters and pagination.
 func (c *Client) GetApiHistory(filter *ApiHistoryFilter, offset, limit int) (*ApiHistoryPage, error) {
+	return c.GetApiHistoryContext(c.context(), filter, offset, limit)
+}
+
+// GetApiHistoryContext is like GetApiHistory but uses ctx for cancellation.
+func (c *Client) GetApiHistoryContext(ctx context.Context, filter *ApiHistoryFilter, offset, limit int) (*ApiHistoryPage, error) {
 	query := `query GetApiHistory($filter: ApiHistoryFilterInput, $pagination: ApiHistoryPaginationInput!) {
 		apiHistory(filter: $filter, pagination: $pagination) {
 			items {
@@ -220,7 +251,7 @@ func (c *Client) GetApiHistory(filter *ApiHistoryFilter, offset, limit int) (*Ap
 		vars["filter"] = f
 	}
 
-	gqlResp, err := c.GraphQL(query, vars)
+	gqlResp, err := c.GraphQLContext(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("querying API history: %w", err)
 	}
@@ -256,13 +287,18 @@ type CreateApiKeyResult struct {
 
 // ListApiKeys returns all API keys for the organization.
 func (c *Client) ListApiKeys() ([]ApiKey, error) {
+	return c.ListApiKeysContext(c.context())
+}
+
+// ListApiKeysContext is like ListApiKeys but uses ctx for cancellation.
+func (c *Client) ListApiKeysContext(ctx context.Context) ([]ApiKey, error) {
 	query := `query {
 		listApiKeys {
 			id name secretKeyMasked lastUsedAt expiresAt createdByEmail createdAt revokedAt
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, nil)
+	gqlResp, err := c.GraphQLContext(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("listing API keys: %w", err)
 	}
@@ -278,6 +314,11 @@ func (c *Client) ListApiKeys() ([]ApiKey, error) {
 
 // CreateApiKey creates a new API key.
 func (c *Client) CreateApiKey(name string) (*CreateApiKeyResult, error) {
+	return c.CreateApiKeyContext(c.context(), name)
+}
+
+// CreateApiKeyContext is like CreateApiKey but uses ctx for cancellation.
+func (c *Client) CreateApiKeyContext(ctx context.Context, name string) (*CreateApiKeyResult, error) {
 	query := `mutation CreateApiKey($data: CreateApiKeyInput!) {
 		createApiKey(data: $data) {
 			key { id name secretKeyMasked createdAt }
@@ -285,7 +326,7 @@ func (c *Client) CreateApiKey(name string) (*CreateApiKeyResult, error) {
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{"name": name},
 	})
 	if err != nil {
@@ -303,11 +344,16 @@ func (c *Client) CreateApiKey(name string) (*CreateApiKeyResult, error) {
 
 // RevokeApiKey revokes (disables) an API key without deleting it.
 func (c *Client) RevokeApiKey(keyID int) error {
+	return c.RevokeApiKeyContext(c.context(), keyID)
+}
+
+// RevokeApiKeyContext is like RevokeApiKey but uses ctx for cancellation.
+func (c *Client) RevokeApiKeyContext(ctx context.Context, keyID int) error {
 	query := `mutation RevokeApiKey($keyId: Int!) {
 		revokeApiKey(keyId: $keyId)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"keyId": keyID,
 	})
 	if err != nil {
@@ -318,11 +364,16 @@ func (c *Client) RevokeApiKey(keyID int) error {
 
 // DeleteApiKey permanently deletes an API key.
 func (c *Client) DeleteApiKey(keyID int) error {
+	return c.DeleteApiKeyContext(c.context(), keyID)
+}
+
+// DeleteApiKeyContext is like DeleteApiKey but uses ctx for cancellation.
+func (c *Client) DeleteApiKeyContext(ctx context.Context, keyID int) error {
 	query := `mutation DeleteApiKey($keyId: Int!) {
 		deleteApiKey(keyId: $keyId)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"keyId": keyID,
 	})
 	if err != nil {
@@ -354,6 +405,11 @@ type GenerateSummaryResult struct {
 
 // GenerateSummary generates a natural language summary from a question and SQL.
 func (c *Client) GenerateSummary(req *GenerateSummaryRequest) (*GenerateSummaryResult, error) {
+	return c.GenerateSummaryContext(c.context(), req)
+}
+
+// GenerateSummaryContext is like GenerateSummary but uses ctx for cancellation.
+func (c *Client) GenerateSummaryContext(ctx context.Context, req *GenerateSummaryRequest) (*GenerateSummaryResult, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
@@ -364,7 +420,7 @@ func (c *Client) GenerateSummary(req *GenerateSummaryRequest) (*GenerateSummaryR
 	c.http.Timeout = 4 * time.Minute
 	defer func() { c.http.Timeout = origTimeout }()
 
-	resp, err := c.doRequest("POST", "/api/v1/generate_summary", strings.NewReader(string(data)))
+	resp, err := c.doRequest(ctx, "POST", "/api/v1/generate_summary", strings.NewReader(string(data)))
 	if err != nil {
 		return nil, fmt.Errorf("generating summary: %w", err)
 	}
@@ -377,14 +433,17 @@ func (c *Client) GenerateSummary(req *GenerateSummaryRequest) (*GenerateSummaryR
 
 	var result GenerateSummaryResult
 	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode >= 300 {
+			return nil, newAPIError(resp, body)
+		}
 		return nil, fmt.Errorf("parsing response: %w (body: %s)", err, string(body))
 	}
 
 	if resp.StatusCode >= 400 {
 		if result.Error != "" {
-			return &result, fmt.Errorf("summary generation failed: %s", result.Error)
+			return &result, newPayloadError(resp, result.Code, result.Error)
 		}
-		return &result, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return &result, newAPIError(resp, body)
 	}
 
 	return &result, nil
@@ -412,6 +471,11 @@ type GenerateChartResult struct {
 
 // GenerateChart generates a Vega-Lite chart spec from a question and SQL.
 func (c *Client) GenerateChart(req *GenerateChartRequest) (*GenerateChartResult, error) {
+	return c.GenerateChartContext(c.context(), req)
+}
+
+// GenerateChartContext is like GenerateChart but uses ctx for cancellation.
+func (c *Client) GenerateChartContext(ctx context.Context, req *GenerateChartRequest) (*GenerateChartResult, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
@@ -422,7 +486,7 @@ func (c *Client) GenerateChart(req *GenerateChartRequest) (*GenerateChartResult,
 	c.http.Timeout = 4 * time.Minute
 	defer func() { c.http.Timeout = origTimeout }()
 
-	resp, err := c.doRequest("POST", "/api/v1/generate_vega_chart", strings.NewReader(string(data)))
+	resp, err := c.doRequest(ctx, "POST", "/api/v1/generate_vega_chart", strings.NewReader(string(data)))
 	if err != nil {
 		return nil, fmt.Errorf("generating chart: %w", err)
 	}
@@ -435,14 +499,17 @@ func (c *Client) GenerateChart(req *GenerateChartRequest) (*GenerateChartResult,
 
 	var result GenerateChartResult
 	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode >= 300 {
+			return nil, newAPIError(resp, body)
+		}
 		return nil, fmt.Errorf("parsing response: %w (body: %s)", err, string(body))
 	}
 
 	if resp.StatusCode >= 400 {
 		if result.Error != "" {
-			return &result, fmt.Errorf("chart generation failed: %s", result.Error)
+			return &result, newPayloadError(resp, result.Code, result.Error)
 		}
-		return &result, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return &result, newAPIError(resp, body)
 	}
 
 	return &result, nil
@@ -472,13 +539,18 @@ type CreateProjectApiKeyResult struct {
 
 // ListProjectApiKeys returns all API keys for the given project.
 func (c *Client) ListProjectApiKeys(projectID int) ([]ProjectApiKey, error) {
+	return c.ListProjectApiKeysContext(c.context(), projectID)
+}
+
+// ListProjectApiKeysContext is like ListProjectApiKeys but uses ctx for cancellation.
+func (c *Client) ListProjectApiKeysContext(ctx context.Context, projectID int) ([]ProjectApiKey, error) {
 	query := `query ListProjectApiKeys($projectId: Int!) {
 		listProjectApiKeys(projectId: $projectId) {
 			id projectId organizationId name secretKeyMasked lastUsedAt expiresAt createdByEmail createdAt revokedAt
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"projectId": projectID,
 	})
 	if err != nil {
@@ -496,6 +568,11 @@ func (c *Client) ListProjectApiKeys(projectID int) ([]ProjectApiKey, error) {
 
 // CreateProjectApiKey creates a new project-scoped API key.
 func (c *Client) CreateProjectApiKey(projectID int, name string) (*CreateProjectApiKeyResult, error) {
+	return c.CreateProjectApiKeyContext(c.context(), projectID, name)
+}
+
+// CreateProjectApiKeyContext is like CreateProjectApiKey but uses ctx for cancellation.
+func (c *Client) CreateProjectApiKeyContext(ctx context.Context, projectID int, name string) (*CreateProjectApiKeyResult, error) {
 	query := `mutation CreateProjectApiKey($data: CreateProjectApiKeyInput!) {
 		createProjectApiKey(data: $data) {
 			key { id projectId organizationId name secretKeyMasked createdAt }
@@ -503,7 +580,7 @@ func (c *Client) CreateProjectApiKey(projectID int, name string) (*CreateProject
 		}
 	}`
 
-	gqlResp, err := c.GraphQL(query, map[string]interface{}{
+	gqlResp, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"data": map[string]interface{}{
 			"projectId": projectID,
 			"name":      name,
@@ -524,11 +601,16 @@ func (c *Client) CreateProjectApiKey(projectID int, name string) (*CreateProject
 
 // RevokeProjectApiKey revokes (disables) a project API key without deleting it.
 func (c *Client) RevokeProjectApiKey(keyID, projectID int) error {
+	return c.RevokeProjectApiKeyContext(c.context(), keyID, projectID)
+}
+
+// RevokeProjectApiKeyContext is like RevokeProjectApiKey but uses ctx for cancellation.
+func (c *Client) RevokeProjectApiKeyContext(ctx context.Context, keyID, projectID int) error {
 	query := `mutation RevokeProjectApiKey($keyId: Int!, $projectId: Int!) {
 		revokeProjectApiKey(keyId: $keyId, projectId: $projectId)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"keyId":     keyID,
 		"projectId": projectID,
 	})
@@ -540,11 +622,16 @@ func (c *Client) RevokeProjectApiKey(keyID, projectID int) error {
 
 // DeleteProjectApiKey permanently deletes a project API key.
 func (c *Client) DeleteProjectApiKey(keyID, projectID int) error {
+	return c.DeleteProjectApiKeyContext(c.context(), keyID, projectID)
+}
+
+// DeleteProjectApiKeyContext is like DeleteProjectApiKey but uses ctx for cancellation.
+func (c *Client) DeleteProjectApiKeyContext(ctx context.Context, keyID, projectID int) error {
 	query := `mutation DeleteProjectApiKey($keyId: Int!, $projectId: Int!) {
 		deleteProjectApiKey(keyId: $keyId, projectId: $projectId)
 	}`
 
-	_, err := c.GraphQL(query, map[string]interface{}{
+	_, err := c.GraphQLContext(ctx, query, map[string]interface{}{
 		"keyId":     keyID,
 		"projectId": projectID,
 	})
@@ -1,9 +1,12 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"strings"
 	"time"
 )
@@ -39,12 +42,17 @@ type GenerateSQLResult struct {
 // GenerateSQL converts a natural language question into SQL.
 // This may take up to 3 minutes as it polls the AI service internally.
 func (c *Client) GenerateSQL(req *GenerateSQLRequest) (*GenerateSQLResult, error) {
+	return c.GenerateSQLContext(c.context(), req)
+}
+
+// GenerateSQLContext is like GenerateSQL but uses ctx for cancellation.
+func (c *Client) GenerateSQLContext(ctx context.Context, req *GenerateSQLRequest) (*GenerateSQLResult, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", "/api/v1/generate_sql", strings.NewReader(string(data)))
+	resp, err := c.doRequest(ctx, "POST", "/api/v1/generate_sql", strings.NewReader(string(data)))
 	if err != nil {
 		return nil, fmt.Errorf("generating SQL: %w", err)
 	}
@@ -57,19 +65,26 @@ func (c *Client) GenerateSQL(req *GenerateSQLRequest) (*GenerateSQLResult, error
 
 	var result GenerateSQLResult
 	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode >= 300 {
+			return nil, newAPIError(resp, body)
+		}
 		return nil, fmt.Errorf("parsing response: %w (body: %s)", err, string(body))
 	}
 
-	// 200 = success, 400 = non-SQL query or validation error
-	if resp.StatusCode == 200 {
-		return &result, nil
+	// A code means a non-SQL query or validation error (usually HTTP 400).
+	// The result is returned too so callers can show the explanation.
+	if result.Code != "" {
+		msg := result.Error
+		if msg == "" {
+			msg = "query could not be converted to SQL"
+		}
+		return &result, newPayloadError(resp, result.Code, msg)
 	}
-	if resp.StatusCode == 400 {
-		// Return the result with error info so the caller can handle it
+	if resp.StatusCode == 200 {
 		return &result, nil
 	}
 
-	return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	return nil, newAPIError(resp, body)
 }
 
 // --- Ask (full pipeline) ---
@@ -89,6 +104,10 @@ type AskResult struct {
 	Summary  string `json:"summary,omitempty"`
 	ThreadID string `json:"threadId,omitempty"`
 
+	// Result rows, when the server includes them
+	Columns []RunSQLColumn           `json:"columns,omitempty"`
+	Records []map[string]interface{} `json:"records,omitempty"`
+
 	// Non-SQL query response
 	Type        string `json:"type,omitempty"`
 	Explanation string `json:"explanation,omitempty"`
@@ -102,12 +121,17 @@ type AskResult struct {
 // Ask submits a natural language question and returns SQL + execution results + summary.
 // This may take up to 3 minutes as it runs the full AI pipeline.
 func (c *Client) Ask(req *AskRequest) (*AskResult, error) {
+	return c.AskContext(c.context(), req)
+}
+
+// AskContext is like Ask but uses ctx for cancellation.
+func (c *Client) AskContext(ctx context.Context, req *AskRequest) (*AskResult, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", "/api/v1/ask", strings.NewReader(string(data)))
+	resp, err := c.doRequest(ctx, "POST", "/api/v1/ask", strings.NewReader(string(data)))
 	if err != nil {
 		return nil, fmt.Errorf("asking: %w", err)
 	}
@@ -119,20 +143,23 @@ func (c *Client) Ask(req *AskRequest) (*AskResult, error) {
 	}
 
 	var result AskResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := unmarshalUseNumber(body, &result); err != nil {
+		if resp.StatusCode >= 300 {
+			return nil, newAPIError(resp, body)
+		}
 		return nil, fmt.Errorf("parsing response: %w (body: %s)", err, string(body))
 	}
 
-	if resp.StatusCode == 200 {
-		return &result, nil
-	}
-
-	// Return parsed error info if available (e.g., 400 with code/error fields)
+	// Error payloads (e.g. 400 with code/error fields) are returned as an
+	// APIError alongside the parsed result.
 	if result.Code != "" || result.Error != "" {
+		return &result, newPayloadError(resp, result.Code, result.Error)
+	}
+	if resp.StatusCode == 200 {
 		return &result, nil
 	}
 
-	return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	return nil, newAPIError(resp, body)
 }
 
 // --- Run SQL ---
@@ -167,12 +194,17 @@ type RunSQLResult struct {
 
 // RunSQL executes a SQL query against the Legible Engine and returns results.
 func (c *Client) RunSQL(req *RunSQLRequest) (*RunSQLResult, error) {
+	return c.RunSQLContext(c.context(), req)
+}
+
+// RunSQLContext is like RunSQL but uses ctx for cancellation.
+func (c *Client) RunSQLContext(ctx context.Context, req *RunSQLRequest) (*RunSQLResult, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	resp, err := c.doRequest("POST", "/api/v1/run_sql", strings.NewReader(string(data)))
+	resp, err := c.doRequest(ctx, "POST", "/api/v1/run_sql", strings.NewReader(string(data)))
 	if err != nil {
 		return nil, fmt.Errorf("running SQL: %w", err)
 	}
@@ -184,17 +216,169 @@ func (c *Client) RunSQL(req *RunSQLRequest) (*RunSQLResult, error) {
 	}
 
 	var result RunSQLResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := unmarshalUseNumber(body, &result); err != nil {
+		if resp.StatusCode >= 300 {
+			return nil, newAPIError(resp, body)
+		}
 		return nil, fmt.Errorf("parsing response: %w (body: %s)", err, string(body))
 	}
 
+	// Error payloads (e.g. 400 with code/error fields) are returned as an
+	// APIError alongside the parsed result.
+	if result.Code != "" || result.Error != "" {
+		return &result, newPayloadError(resp, result.Code, result.Error)
+	}
 	if resp.StatusCode == 200 {
 		return &result, nil
 	}
 
-	// Return parsed error info if available (e.g., 400 with code/error fields)
-	if result.Code != "" || result.Error != "" {
-		return &result, nil
+	return nil, newAPIError(resp, body)
+}
+
+// DefaultPageSize is the page size RunSQLPages uses when none is given. It
+// matches the server's default row limit for run_sql.
+const DefaultPageSize = 1000
+
+// RunSQLPages runs req.SQL one page at a time and yields each page. Pages
+// are fetched by adding LIMIT and OFFSET to the query, so it must end with
+// an ORDER BY, on a unique key for the pages to be stable, and must not
+// have a LIMIT or OFFSET of its own. A subquery's ORDER BY is not enough,
+// as engines may drop it.
+//
+// The server may return fewer rows than a page holds, so each page starts
+// after the rows received so far, and iteration stops at the first empty
+// page, after req.Limit rows in total when it is set, or on the first
+// error, which is yielded with a nil page.
+//
+// Every page carries the columns and the thread ID of the first page.
+// Page requests are read-only and are retried like GETs.
+func (c *Client) RunSQLPages(ctx context.Context, req *RunSQLRequest, pageSize int) iter.Seq2[*RunSQLResult, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return func(yield func(*RunSQLResult, error) bool) {
+		if err := checkPageable(req.SQL); err != nil {
+			yield(nil, err)
+			return
+		}
+		ctx := withSafePOST(ctx)
+		threadID := req.ThreadID
+		var columns []RunSQLColumn
+		for offset := 0; ; {
+			size := pageSize
+			if req.Limit > 0 {
+				if offset >= req.Limit {
+					return
+				}
+				size = min(size, req.Limit-offset)
+			}
+
+			page, err := c.RunSQLContext(ctx, &RunSQLRequest{
+				SQL:      pageSQL(req.SQL, size, offset),
+				ThreadID: threadID,
+				Limit:    size,
+			})
+			if err != nil {
+				yield(nil, fmt.Errorf("fetching rows %d-%d: %w", offset+1, offset+size, err))
+				return
+			}
+			if columns == nil {
+				columns = page.Columns
+				threadID = page.ThreadID
+			} else {
+				page.Columns = columns
+				page.ThreadID = threadID
+			}
+			if len(page.Records) == 0 && offset > 0 {
+				return
+			}
+			if !yield(page, nil) || len(page.Records) == 0 {
+				return
+			}
+			offset += len(page.Records)
+		}
+	}
+}
+
+// checkPageable reports why a query cannot be paged with LIMIT and OFFSET.
+func checkPageable(sql string) error {
+	words := topLevelWords(sql)
+	orderBy := -1
+	for i := 0; i+1 < len(words); i++ {
+		if words[i] == "ORDER" && words[i+1] == "BY" {
+			orderBy = i
+		}
 	}
+	if orderBy < 0 {
+		return fmt.Errorf("paging needs a query that ends with an ORDER BY on a unique key, so that pages do not overlap")
+	}
+	for _, word := range words[orderBy:] {
+		switch word {
+		case "LIMIT", "OFFSET", "FETCH":
+			return fmt.Errorf("paging a query with its own %s is not supported — set a limit on the request instead", word)
+		}
+	}
+	return nil
+}
 
-	return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))}
\ No newline at end of file
+// topLevelWords returns the words of a query outside of parentheses,
+// literals, quoted identifiers and comments, in upper case.
+func topLevelWords(sql string) []string {
+	var words []string
+	depth := 0
+	for i := 0; i < len(sql); {
+		switch ch := sql[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := strings.IndexByte(sql[i+1:], ch)
+			if end < 0 {
+				return words
+			}
+			i += end + 2
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return words
+			}
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i:], "*/")
+			if end < 0 {
+				return words
+			}
+			i += end + 2
+		case ch == '(':
+			depth++
+			i++
+		case ch == ')':
+			depth--
+			i++
+		case ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z':
+			start := i
+			for i < len(sql) && (sql[i] == '_' || 'a' <= sql[i] && sql[i] <= 'z' || 'A' <= sql[i] && sql[i] <= 'Z' || '0' <= sql[i] && sql[i] <= '9') {
+				i++
+			}
+			if depth == 0 {
+				words = append(words, strings.ToUpper(sql[start:i]))
+			}
+		default:
+			i++
+		}
+	}
+	return words
+}
+
+// pageSQL adds a LIMIT and OFFSET to a query so that it returns a single
+// page of rows.
+func pageSQL(sql string, limit, offset int) string {
+	sql = strings.TrimRight(strings.TrimSpace(sql), "; \t\n")
+	return fmt.Sprintf("%s\nLIMIT %d OFFSET %d", sql, limit, offset)
+}
+
+// unmarshalUseNumber decodes a response that carries result records, keeping
+// numbers as json.Number so large integers and decimals don't lose
+// precision to float64.
+func unmarshalUseNumber(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var pageRe = regexp.MustCompile(`LIMIT (\d+) OFFSET (\d+)$`)
+
+// pagedServer serves run_sql for a table of total rows, honoring the
+// LIMIT/OFFSET that RunSQLPages adds to the query. A positive maxRows caps
+// the rows of each response, like a server-side row limit.
+func pagedServer(t *testing.T, total, maxRows, failAt int) (*Client, *[]string) {
+	t.Helper()
+	var queries []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req RunSQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+			return
+		}
+		queries = append(queries, req.SQL)
+
+		m := pageRe.FindStringSubmatch(req.SQL)
+		if m == nil {
+			t.Errorf("query not paginated: %q", req.SQL)
+			return
+		}
+		limit, _ := strconv.Atoi(m[1])
+		offset, _ := strconv.Atoi(m[2])
+		if req.Limit != limit {
+			t.Errorf("request limit = %d, want %d", req.Limit, limit)
+		}
+		if failAt >= 0 && offset >= failAt {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":"INVALID_SQL_ERROR","error":"boom"}`)
+			return
+		}
+
+		records := []map[string]interface{}{}
+		if maxRows > 0 {
+			limit = min(limit, maxRows)
+		}
+		for i := offset; i < min(offset+limit, total); i++ {
+			records = append(records, map[string]interface{}{"id": i})
+		}
+		threadID := req.ThreadID
+		if threadID == "" {
+			threadID = fmt.Sprintf("thread-%d", len(queries))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"records":   records,
+			"columns":   []RunSQLColumn{{Name: "id", Type: "int64"}},
+			"threadId":  threadID,
+			"totalRows": len(records),
+		})
+	})
+	return c, &queries
+}
+
+func TestRunSQLPages(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int
+		maxRows     int
+		pageSize    int
+		limit       int
+		wantRows    int
+		wantPages   int
+		wantQueries int
+	}{
+		{"exact multiple", 6, 0, 3, 0, 6, 2, 3},
+		{"short last page", 7, 0, 3, 0, 7, 3, 4},
+		{"empty result", 0, 0, 3, 0, 0, 1, 1},
+		{"limit caps total rows", 10, 0, 3, 5, 5, 2, 2},
+		{"limit equal to page size", 10, 0, 3, 3, 3, 1, 1},
+		{"server caps page size", 7, 2, 5, 0, 7, 4, 5},
+		{"server cap with limit", 10, 2, 5, 5, 5, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, queries := pagedServer(t, tt.total, tt.maxRows, -1)
+			req := &RunSQLRequest{SQL: "SELECT id FROM t ORDER BY id;", Limit: tt.limit}
+
+			rows, pages := 0, 0
+			for page, err := range c.RunSQLPages(context.Background(), req, tt.pageSize) {
+				if err != nil {
+					t.Fatalf("page %d: %v", pages, err)
+				}
+				if page.ThreadID != "thread-1" {
+					t.Errorf("page %d thread = %q, want thread-1", pages, page.ThreadID)
+				}
+				if len(page.Columns) != 1 {
+					t.Errorf("page %d has %d columns", pages, len(page.Columns))
+				}
+				for _, rec := range page.Records {
+					if got := rec["id"].(json.Number).String(); got != strconv.Itoa(rows) {
+						t.Errorf("row %d id = %s", rows, got)
+					}
+					rows++
+				}
+				pages++
+			}
+			if rows != tt.wantRows || pages != tt.wantPages {
+				t.Errorf("got %d rows in %d pages, want %d in %d", rows, pages, tt.wantRows, tt.wantPages)
+			}
+			if len(*queries) != tt.wantQueries {
+				t.Errorf("server saw %d queries, want %d", len(*queries), tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestRunSQLPagesError(t *testing.T) {
+	c, _ := pagedServer(t, 10, 0, 3)
+	var errs []error
+	rows := 0
+	for page, err := range c.RunSQLPages(context.Background(), &RunSQLRequest{SQL: "SELECT id FROM t ORDER BY id"}, 3) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rows += len(page.Records)
+	}
+	if rows != 3 || len(errs) != 1 {
+		t.Fatalf("got %d rows and %d errors, want 3 and 1", rows, len(errs))
+	}
+	if KindOf(errs[0]) != KindSQL {
+		t.Errorf("error kind = %q, want %q (%v)", KindOf(errs[0]), KindSQL, errs[0])
+	}
+}
+
+func TestRunSQLPagesUnordered(t *testing.T) {
+	c, queries := pagedServer(t, 10, 0, -1)
+	tests := []struct {
+		sql, want string
+	}{
+		{"SELECT id FROM t", "ORDER BY"},
+		{"SELECT * FROM (SELECT id FROM t ORDER BY id) AS s", "ORDER BY"},
+		{"SELECT id FROM t -- ORDER BY id", "ORDER BY"},
+		{"SELECT id FROM t ORDER BY id LIMIT 5", "own LIMIT"},
+		{"SELECT id FROM t ORDER BY id OFFSET 5 ROWS FETCH NEXT 5 ROWS ONLY", "own OFFSET"},
+	}
+	for _, tt := range tests {
+		var errs []error
+		for _, err := range c.RunSQLPages(context.Background(), &RunSQLRequest{SQL: tt.sql}, 3) {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 || errs[0] == nil || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("RunSQLPages(%q) = %v, want an error about %s", tt.sql, errs, tt.want)
+		}
+	}
+	if len(*queries) != 0 {
+		t.Errorf("server saw %d queries, want none", len(*queries))
+	}
+
+	for _, sql := range []string{
+		"SELECT id, '(' AS p FROM t ORDER BY id",
+		"SELECT * FROM (SELECT id FROM t LIMIT 5) AS s ORDER BY \"id\" DESC",
+		"WITH s AS (SELECT id FROM t) SELECT id FROM s UNION ALL SELECT 1 ORDER BY 1",
+	} {
+		if err := checkPageable(sql); err != nil {
+			t.Errorf("checkPageable(%q) = %v", sql, err)
+		}
+	}
+}
+
+func TestPageSQL(t *testing.T) {
+	got := pageSQL("  SELECT * FROM t ORDER BY id -- trailing comment\n;  ", 10, 20)
+	want := "SELECT * FROM t ORDER BY id -- trailing comment\nLIMIT 10 OFFSET 20"
+	if got != want {
+		t.Errorf("pageSQL = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retry policy for transient gateway failures. Variables so tests can
+// shorten the delays.
+var (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// DefaultMaxRetries is the number of times a failed request is retried.
+const DefaultMaxRetries = 3
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	retryPOSTKey
+)
+
+// WithRequestID attaches a client-chosen request ID to ctx. It is sent as
+// X-Request-ID, and lets the server de-duplicate retried POSTs, so POSTs
+// carrying one are retried like idempotent requests.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// requestIDFrom returns the request ID attached with WithRequestID, if any.
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// withSafePOST marks a POST as free of side effects (e.g. a GraphQL query),
+// so it can be retried.
+func withSafePOST(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryPOSTKey, true)
+}
+
+// canRetry reports whether a request may be sent again after a failure.
+// GET, PUT and DELETE are idempotent; POST only when it carries a request
+// ID or is known to be read-only. Any request rejected with 429 was not
+// processed and is always safe to repeat.
+func canRetry(ctx context.Context, method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		safe, _ := ctx.Value(retryPOSTKey).(bool)
+		return safe || requestIDFrom(ctx) != ""
+	}
+	return false
+}
+
+// retryableStatus reports whether an HTTP status indicates a transient failure.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryableError reports whether a transport error is worth retrying.
+// Cancellation by the caller never is.
+func retryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// backoff returns how long to wait before retry number attempt (0-based):
+// the server's Retry-After if given, otherwise exponential backoff with
+// full jitter.
+func backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(d, retryMaxDelay)
+		}
+	}
+	d := retryBaseDelay << attempt
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return rand.N(d) + 1
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or HTTP-date form.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
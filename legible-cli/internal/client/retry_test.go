@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	orig := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = orig })
+
+	return NewWithOverrides(srv.URL, "osk-test")
+}
+
+func TestDoRequestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		requestID string
+		status    int
+		wantCalls int32
+		wantCode  int
+	}{
+		{"GET retried until success", http.MethodGet, "", http.StatusServiceUnavailable, 3, http.StatusOK},
+		{"PUT retried on 502", http.MethodPut, "", http.StatusBadGateway, 3, http.StatusOK},
+		{"POST not retried on 503", http.MethodPost, "", http.StatusServiceUnavailable, 1, http.StatusServiceUnavailable},
+		{"POST with request ID retried", http.MethodPost, "req-1", http.StatusServiceUnavailable, 3, http.StatusOK},
+		{"POST retried on 429", http.MethodPost, "", http.StatusTooManyRequests, 3, http.StatusOK},
+		{"GET not retried on 500", http.MethodGet, "", http.StatusInternalServerError, 1, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("X-Request-ID"); got != tt.requestID {
+					t.Errorf("X-Request-ID = %q, want %q", got, tt.requestID)
+				}
+				if calls.Add(1) < 3 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ctx := context.Background()
+			if tt.requestID != "" {
+				ctx = WithRequestID(ctx, tt.requestID)
+			}
+			resp, err := c.doRequest(ctx, tt.method, "/x", nil)
+			if err != nil {
+				t.Fatalf("doRequest() error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDoRequestGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	c.SetMaxRetries(2)
+
+	resp, err := c.doRequest(context.Background(), http.MethodGet, "/x", nil)
+	if err != nil {
+		t.Fatalf("doRequest() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
+
+func TestDoRequestCancelledDuringBackoff(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.doRequest(ctx, http.MethodGet, "/x", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("cancellation did not interrupt Retry-After wait")
+	}
+}
+
+func TestDoRequestReplaysBody(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 64)
+		n, _ := r.Body.Read(buf)
+		if string(buf[:n]) != `{"a":1}` {
+			t.Errorf("attempt %d body = %q", calls.Load()+1, buf[:n])
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if err := c.PutJSON("/x", map[string]int{"a": 1}, nil); err != nil {
+		t.Fatalf("PutJSON() error: %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true}, // in the past
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,15 +12,78 @@ import (
 const (
 	configDir  = ".legible"
 	configFile = "config.yaml"
+
+	// DefaultProfile is the profile used when none is selected.
+	DefaultProfile = "default"
 )
 
-// Config holds the CLI configuration persisted to ~/.wren/config.yaml.
-type Config struct {
+// Environment variables that override the persisted configuration.
+const (
+	EnvProfile   = "LEGIBLE_PROFILE"
+	EnvEndpoint  = "LEGIBLE_ENDPOINT"
+	EnvAPIKey    = "LEGIBLE_API_KEY"
+	EnvProjectID = "LEGIBLE_PROJECT_ID"
+)
+
+// profileOverride is set from the global --profile flag and takes
+// precedence over LEGIBLE_PROFILE and the file's current_profile.
+var profileOverride string
+
+// SetProfileOverride selects the profile used by subsequent calls to Load.
+func SetProfileOverride(name string) {
+	profileOverride = name
+}
+
+// Profile holds the connection settings for a single named profile.
+type Profile struct {
+	Endpoint  string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	APIKey    string `yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	ProjectID string `yaml:"project_id,omitempty" json:"project_id,omitempty"`
+}
+
+// File is the on-disk layout of ~/.legible/config.yaml.
+//
+// Older versions stored a single endpoint/api_key/project_id at the top level;
+// those fields are still read and migrated into the "default" profile.
+type File struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+
+	// CredentialStore selects where API keys are kept: auto (default),
+	// keyring, file or plaintext. CredentialHelper, if set, overrides it.
+	CredentialStore  string `yaml:"credential_store,omitempty"`
+	CredentialHelper string `yaml:"credential_helper,omitempty"`
+
 	Endpoint  string `yaml:"endpoint,omitempty"`
 	APIKey    string `yaml:"api_key,omitempty"`
 	ProjectID string `yaml:"project_id,omitempty"`
 }
 
+// Config is the resolved configuration for the active profile, with
+// environment overrides applied. Save writes changes back to that profile.
+type Config struct {
+	Endpoint  string
+	APIKey    string
+	ProjectID string
+
+	// Profile is the name of the active profile.
+	Profile string
+
+	file *File
+	env  Profile
+
+	// storedKey and storeName record the API key and store as loaded, so
+	// Save only touches the credential store when something changed.
+	storedKey string
+	storeName string
+	keyErr    error
+
+	// previousStore is the credential store in use before Set changed it,
+	// whose keys Save moves to the new one.
+	previousStore CredentialStore
+	storeSwitched bool
+}
+
 // configPath returns the full path to the config file.
 func configPath() (string, error) {
 	home, err := os.UserHomeDir()
@@ -29,31 +93,64 @@ func configPath() (string, error) {
 	return filepath.Join(home, configDir, configFile), nil
 }
 
-// Load reads the config from ~/.wren/config.yaml.
-// Returns a zero-value Config (no error) if the file does not exist.
-func Load() (*Config, error) {
+// Dir returns the path to ~/.legible.
+func Dir() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// LoadFile reads ~/.legible/config.yaml without resolving a profile.
+// Returns an empty File (no error) if the file does not exist.
+func LoadFile() (*File, error) {
 	path, err := configPath()
 	if err != nil {
 		return nil, err
 	}
 
+	f := &File{}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return &Config{}, nil
+			f.migrate()
+			return f, nil
 		}
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
 
-	cfg := &Config{}
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	if err := yaml.Unmarshal(data, f); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
-	return cfg, nil
+	f.migrate()
+	return f, nil
 }
 
-// Save writes the config to ~/.wren/config.yaml, creating the directory if needed.
-func (c *Config) Save() error {
+// migrate moves legacy top-level fields into the default profile.
+func (f *File) migrate() {
+	if f.Profiles == nil {
+		f.Profiles = make(map[string]*Profile)
+	}
+	if f.Endpoint != "" || f.APIKey != "" || f.ProjectID != "" {
+		if _, ok := f.Profiles[DefaultProfile]; !ok {
+			f.Profiles[DefaultProfile] = &Profile{
+				Endpoint:  f.Endpoint,
+				APIKey:    f.APIKey,
+				ProjectID: f.ProjectID,
+			}
+		}
+		f.Endpoint, f.APIKey, f.ProjectID = "", "", ""
+	}
+	for name, p := range f.Profiles {
+		if p == nil {
+			f.Profiles[name] = &Profile{}
+		}
+	}
+}
+
+// Save writes the file to ~/.legible/config.yaml, creating the directory if needed.
+func (f *File) Save() error {
 	path, err := configPath()
 	if err != nil {
 		return err
@@ -64,7 +161,7 @@ func (c *Config) Save() error {
 		return fmt.Errorf("creating config directory: %w", err)
 	}
 
-	data, err := yaml.Marshal(c)
+	data, err := yaml.Marshal(f)
 	if err != nil {
 		return fmt.Errorf("serializing config: %w", err)
 	}
@@ -75,6 +172,125 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// ActiveProfile returns the profile name selected by --profile,
+// LEGIBLE_PROFILE, the file's current_profile, or "default", in that order.
+func (f *File) ActiveProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if env := os.Getenv(EnvProfile); env != "" {
+		return env
+	}
+	if f.CurrentProfile != "" {
+		return f.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// ProfileNames returns the names of all stored profiles, sorted.
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load reads ~/.legible/config.yaml and resolves the active profile.
+// Returns a zero-value Config (no error) if the file does not exist.
+func Load() (*Config, error) {
+	f, err := LoadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	name := f.ActiveProfile()
+	cfg := &Config{Profile: name, file: f}
+	if p, ok := f.Profiles[name]; ok {
+		cfg.Endpoint = p.Endpoint
+		cfg.ProjectID = p.ProjectID
+	}
+
+	cfg.env = Profile{
+		Endpoint:  os.Getenv(EnvEndpoint),
+		APIKey:    os.Getenv(EnvAPIKey),
+		ProjectID: os.Getenv(EnvProjectID),
+	}
+	if cfg.env.Endpoint != "" {
+		cfg.Endpoint = cfg.env.Endpoint
+	}
+	if cfg.env.APIKey != "" {
+		cfg.APIKey = cfg.env.APIKey
+	} else {
+		// A failing store (locked keyring, missing passphrase) should not
+		// block commands that don't need the key; see CredentialError.
+		cfg.storedKey, cfg.keyErr = f.GetAPIKey(name)
+		cfg.APIKey = cfg.storedKey
+	}
+	cfg.storeName = f.CredentialStoreName()
+	if cfg.env.ProjectID != "" {
+		cfg.ProjectID = cfg.env.ProjectID
+	}
+	return cfg, nil
+}
+
+// CredentialError reports why the API key could not be read from the
+// credential store, if it could not.
+func (c *Config) CredentialError() error {
+	return c.keyErr
+}
+
+// Save writes the active profile back to ~/.legible/config.yaml and the API
+// key to the credential store. Values that came from environment overrides
+// and were not changed afterwards are not persisted.
+func (c *Config) Save() error {
+	if c.file == nil {
+		f, err := LoadFile()
+		if err != nil {
+			return err
+		}
+		c.file = f
+	}
+	if c.Profile == "" {
+		c.Profile = c.file.ActiveProfile()
+	}
+
+	p, ok := c.file.Profiles[c.Profile]
+	if !ok {
+		p = &Profile{}
+		c.file.Profiles[c.Profile] = p
+	}
+	if c.env.Endpoint == "" || c.Endpoint != c.env.Endpoint {
+		p.Endpoint = c.Endpoint
+	}
+	if c.env.ProjectID == "" || c.ProjectID != c.env.ProjectID {
+		p.ProjectID = c.ProjectID
+	}
+	if c.storeSwitched {
+		if err := c.file.moveAPIKeys(c.previousStore); err != nil {
+			return err
+		}
+		c.previousStore, c.storeSwitched = nil, false
+	}
+	if c.env.APIKey == "" || c.APIKey != c.env.APIKey {
+		changed := c.APIKey != c.storedKey || c.file.CredentialStoreName() != c.storeName
+		if changed || p.APIKey != "" {
+			if err := c.file.SetAPIKey(c.Profile, c.APIKey); err != nil {
+				// Moving an unchanged plaintext key out of config.yaml is
+				// opportunistic; only fail if the key itself was updated.
+				if changed {
+					return err
+				}
+			} else {
+				c.storedKey = c.APIKey
+				c.storeName = c.file.CredentialStoreName()
+			}
+		}
+	}
+	return c.file.Save()
+}
+
 // Set updates a single config field by name.
 func (c *Config) Set(key, value string) error {
 	switch key {
@@ -84,12 +300,36 @@ func (c *Config) Set(key, value string) error {
 		c.APIKey = value
 	case "project-id", "project_id":
 		c.ProjectID = value
+	case "credential-store", "credential_store":
+		switch value {
+		case StoreAuto, StoreKeyring, StoreFile, StorePlaintext:
+		default:
+			return fmt.Errorf("invalid credential store %q (valid: auto, keyring, file, plaintext)", value)
+		}
+		c.switchStore()
+		c.ensureFile().CredentialStore = value
+	case "credential-helper", "credential_helper":
+		c.switchStore()
+		c.ensureFile().CredentialHelper = value
 	default:
-		return fmt.Errorf("unknown config key: %q (valid keys: endpoint, api-key, project-id)", key)
+		return fmt.Errorf("unknown config key: %q (valid keys: endpoint, api-key, project-id, credential-store, credential-helper)", key)
 	}
 	return nil
 }
 
+// switchStore records the credential store in use before its settings
+// change, so that Save can move the stored API keys out of it.
+func (c *Config) switchStore() {
+	if c.storeSwitched {
+		return
+	}
+	store, err := c.ensureFile().credentialStore()
+	if err != nil {
+		return
+	}
+	c.previousStore, c.storeSwitched = store, true
+}
+
 // Get returns the value of a config field by name.
 func (c *Config) Get(key string) (string, error) {
 	switch key {
@@ -99,21 +339,47 @@ func (c *Config) Get(key string) (string, error) {
 		return c.APIKey, nil
 	case "project-id", "project_id":
 		return c.ProjectID, nil
+	case "profile":
+		return c.Profile, nil
+	case "credential-store", "credential_store":
+		return c.ensureFile().CredentialStoreName(), nil
+	case "credential-helper", "credential_helper":
+		return c.ensureFile().CredentialHelper, nil
 	default:
-		return "", fmt.Errorf("unknown config key: %q (valid keys: endpoint, api-key, project-id)", key)
+		return "", fmt.Errorf("unknown config key: %q (valid keys: endpoint, api-key, project-id, profile, credential-store, credential-helper)", key)
+	}
+}
+
+// ensureFile returns the underlying File, reading it from disk for
+// Configs that were not produced by Load.
+func (c *Config) ensureFile() *File {
+	if c.file == nil {
+		f, err := LoadFile()
+		if err != nil {
+			f = &File{}
+			f.migrate()
+		}
+		c.file = f
 	}
+	return c.file
 }
 
 // Display returns a map of all config fields for display purposes.
 // API key is masked for security.
 func (c *Config) Display() map[string]string {
-	apiKey := c.APIKey
-	if len(apiKey) > 12 {
-		apiKey = apiKey[:12] + "..." + apiKey[len(apiKey)-4:]
-	}
 	return map[string]string{
-		"endpoint":   c.Endpoint,
-		"api_key":    apiKey,
-		"project_id": c.ProjectID,
+		"profile":          c.Profile,
+		"endpoint":         c.Endpoint,
+		"api_key":          MaskAPIKey(c.APIKey),
+		"project_id":       c.ProjectID,
+		"credential_store": c.ensureFile().CredentialStoreName(),
+	}
+}
+
+// MaskAPIKey shortens an API key for display, keeping its prefix and last characters.
+func MaskAPIKey(apiKey string) string {
+	if len(apiKey) > 12 {
+		return apiKey[:12] + "..." + apiKey[len(apiKey)-4:]
 	}
+	return apiKey
 }
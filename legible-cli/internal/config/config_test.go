@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvProfile, "")
+	t.Setenv(EnvEndpoint, "")
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvProjectID, "")
+	t.Setenv(EnvPassphrase, "test-passphrase")
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "") // no keyring: auto uses the encrypted file
+	SetProfileOverride("")
+	t.Cleanup(func() { SetProfileOverride("") })
+
+	if content != "" {
+		dir := filepath.Join(home, configDir)
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, configFile), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return home
+}
+
+func TestLoad_LegacyFileMigratesToDefault(t *testing.T) {
+	writeConfig(t, `endpoint: https://legacy.example.com
+api_key: osk-legacy
+project_id: "7"
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Profile != DefaultProfile {
+		t.Errorf("Profile = %q, want %q", cfg.Profile, DefaultProfile)
+	}
+	if cfg.Endpoint != "https://legacy.example.com" || cfg.APIKey != "osk-legacy" || cfg.ProjectID != "7" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_ProfileSelection(t *testing.T) {
+	content := `current_profile: staging
+profiles:
+  staging:
+    endpoint: https://staging.example.com
+    api_key: osk-staging
+  prod:
+    endpoint: https://prod.example.com
+    api_key: osk-prod
+    project_id: "3"
+`
+	tests := []struct {
+		name         string
+		override     string
+		env          string
+		wantProfile  string
+		wantEndpoint string
+	}{
+		{"current profile", "", "", "staging", "https://staging.example.com"},
+		{"env selects profile", "", "prod", "prod", "https://prod.example.com"},
+		{"flag beats env", "staging", "prod", "staging", "https://staging.example.com"},
+		{"unknown profile is empty", "missing", "", "missing", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, content)
+			t.Setenv(EnvProfile, tt.env)
+			SetProfileOverride(tt.override)
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error: %v", err)
+			}
+			if cfg.Profile != tt.wantProfile {
+				t.Errorf("Profile = %q, want %q", cfg.Profile, tt.wantProfile)
+			}
+			if cfg.Endpoint != tt.wantEndpoint {
+				t.Errorf("Endpoint = %q, want %q", cfg.Endpoint, tt.wantEndpoint)
+			}
+		})
+	}
+}
+
+func TestLoad_EnvOverridesNotPersisted(t *testing.T) {
+	writeConfig(t, `profiles:
+  default:
+    endpoint: https://stored.example.com
+    api_key: osk-stored
+`)
+	t.Setenv(EnvAPIKey, "osk-from-env")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.APIKey != "osk-from-env" {
+		t.Errorf("APIKey = %q, want env override", cfg.APIKey)
+	}
+
+	cfg.ProjectID = "9"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	f, err := LoadFile()
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	p := f.Profiles[DefaultProfile]
+	if p.APIKey != "osk-stored" {
+		t.Errorf("stored APIKey = %q, want %q", p.APIKey, "osk-stored")
+	}
+	if p.ProjectID != "9" {
+		t.Errorf("stored ProjectID = %q, want %q", p.ProjectID, "9")
+	}
+}
+
+func TestSave_CreatesNamedProfile(t *testing.T) {
+	writeConfig(t, "")
+	SetProfileOverride("customer-a")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	cfg.Endpoint = "https://a.example.com"
+	cfg.APIKey = "osk-a"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	f, err := LoadFile()
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if got := f.ProfileNames(); len(got) != 1 || got[0] != "customer-a" {
+		t.Errorf("ProfileNames() = %v, want [customer-a]", got)
+	}
+	if f.CurrentProfile != "" {
+		t.Errorf("CurrentProfile = %q, want unchanged", f.CurrentProfile)
+	}
+}
+
+func TestMaskAPIKey(t *testing.T) {
+	if got := MaskAPIKey("osk-short"); got != "osk-short" {
+		t.Errorf("MaskAPIKey(short) = %q", got)
+	}
+	if got := MaskAPIKey("osk-abcdefghijklmnop"); got != "osk-abcdefgh...mnop" {
+		t.Errorf("MaskAPIKey(long) = %q", got)
+	}
+}
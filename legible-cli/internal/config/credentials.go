@@ -0,0 +1,534 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Credential store names accepted by the credential_store config key.
+const (
+	StoreAuto      = "auto"
+	StoreKeyring   = "keyring"
+	StoreFile      = "file"
+	StorePlaintext = "plaintext"
+	StoreHelper    = "helper"
+)
+
+// EnvPassphrase supplies the passphrase for the encrypted file store
+// non-interactively.
+const EnvPassphrase = "LEGIBLE_PASSPHRASE"
+
+const (
+	credentialsFile = "credentials.enc"
+	keyringService  = "legible-cli"
+	pbkdf2Iters     = 600000
+)
+
+// CredentialStore keeps API keys outside of config.yaml, keyed by profile name.
+type CredentialStore interface {
+	// Name identifies the store for display, e.g. "keyring".
+	Name() string
+	// Get returns the secret for a profile, or "" if none is stored.
+	Get(profile string) (string, error)
+	// Set stores the secret for a profile, replacing any previous value.
+	Set(profile, secret string) error
+	// Delete removes the secret for a profile. Missing secrets are not an error.
+	Delete(profile string) error
+}
+
+// PassphraseFunc returns the passphrase protecting the encrypted credentials
+// file. confirm is true when the file is about to be created, so interactive
+// implementations should ask twice. The CLI replaces this with a terminal
+// prompt; the default only reads LEGIBLE_PASSPHRASE.
+var PassphraseFunc = func(confirm bool) (string, error) {
+	if p := os.Getenv(EnvPassphrase); p != "" {
+		return p, nil
+	}
+	return "", fmt.Errorf("no passphrase for encrypted credentials — set %s or use a keyring", EnvPassphrase)
+}
+
+// credentialStore returns the store selected by the file's credential_helper
+// and credential_store settings. A nil store means keys stay in config.yaml.
+func (f *File) credentialStore() (CredentialStore, error) {
+	if f.CredentialHelper != "" {
+		return &helperStore{command: f.CredentialHelper}, nil
+	}
+	switch f.CredentialStore {
+	case "", StoreAuto:
+		if keyringAvailable() {
+			return keyringStore{}, nil
+		}
+		return openFileStore()
+	case StoreKeyring:
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("no OS keyring available on this system — use credential_store: file")
+		}
+		return keyringStore{}, nil
+	case StoreFile:
+		return openFileStore()
+	case StorePlaintext:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown credential_store %q (valid: auto, keyring, file, plaintext)", f.CredentialStore)
+	}
+}
+
+// CredentialStoreName returns the name of the store API keys are written to.
+func (f *File) CredentialStoreName() string {
+	store, err := f.credentialStore()
+	if err != nil || store == nil {
+		return StorePlaintext
+	}
+	return store.Name()
+}
+
+// GetAPIKey returns the API key for a profile, reading it from the credential
+// store unless an older plaintext key is still present in config.yaml.
+func (f *File) GetAPIKey(profile string) (string, error) {
+	if p, ok := f.Profiles[profile]; ok && p.APIKey != "" {
+		return p.APIKey, nil
+	}
+	store, err := f.credentialStore()
+	if err != nil || store == nil {
+		return "", err
+	}
+	key, err := store.Get(profile)
+	if err != nil {
+		return "", fmt.Errorf("reading API key for profile %q from %s: %w", profile, store.Name(), err)
+	}
+	return key, nil
+}
+
+// SetAPIKey stores the API key for a profile in the credential store and
+// removes any plaintext copy from config.yaml. The file itself is not saved.
+func (f *File) SetAPIKey(profile, apiKey string) error {
+	p, ok := f.Profiles[profile]
+	if !ok {
+		p = &Profile{}
+		f.Profiles[profile] = p
+	}
+
+	store, err := f.credentialStore()
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		p.APIKey = apiKey
+		return nil
+	}
+
+	if apiKey == "" {
+		err = store.Delete(profile)
+	} else {
+		err = store.Set(profile, apiKey)
+	}
+	if err != nil {
+		return fmt.Errorf("writing API key for profile %q to %s: %w", profile, store.Name(), err)
+	}
+	p.APIKey = ""
+	return nil
+}
+
+// DeleteAPIKey removes a profile's API key from the credential store.
+func (f *File) DeleteAPIKey(profile string) error {
+	store, err := f.credentialStore()
+	if err != nil || store == nil {
+		return err
+	}
+	return store.Delete(profile)
+}
+
+// moveAPIKeys moves the API keys of all profiles from the store they were
+// kept in before credential_store or credential_helper changed into the
+// current one, so that no copy is left behind. A nil store is config.yaml.
+func (f *File) moveAPIKeys(from CredentialStore) error {
+	to, err := f.credentialStore()
+	if err != nil {
+		return err
+	}
+	if sameStore(from, to) {
+		return nil
+	}
+	for _, name := range f.ProfileNames() {
+		key := f.Profiles[name].APIKey
+		if from != nil {
+			if key, err = from.Get(name); err != nil {
+				return fmt.Errorf("reading API key for profile %q from %s: %w", name, from.Name(), err)
+			}
+		}
+		if key == "" {
+			continue
+		}
+		if err := f.SetAPIKey(name, key); err != nil {
+			return err
+		}
+		if from != nil {
+			if err := from.Delete(name); err != nil {
+				return fmt.Errorf("removing API key for profile %q from %s: %w", name, from.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// sameStore reports whether two credential stores keep keys in the same place.
+func sameStore(a, b CredentialStore) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ha, okA := a.(*helperStore)
+	hb, okB := b.(*helperStore)
+	if okA || okB {
+		return okA && okB && ha.command == hb.command
+	}
+	return a.Name() == b.Name()
+}
+
+// --- OS keyring ---
+
+// keyringStore shells out to the platform keyring tool: secret-tool
+// (freedesktop Secret Service) on Linux and security(1) on macOS.
+type keyringStore struct{}
+
+func keyringAvailable() bool {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return false
+		}
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func (keyringStore) Name() string { return StoreKeyring }
+
+func (keyringStore) Get(profile string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", profile, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "profile", profile)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// A missing item is exit status 44 for security(1), and a silent
+		// non-zero exit for secret-tool.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if runtime.GOOS == "darwin" && exitErr.ExitCode() == 44 {
+				return "", nil
+			}
+			if runtime.GOOS != "darwin" && stderr.Len() == 0 {
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+func (keyringStore) Set(profile, secret string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// Arguments are visible to other users in the process list, so the
+		// command goes to security(1) on stdin, with the secret hex-encoded.
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -X %s\n",
+			securityQuote(keyringService), securityQuote(profile), securityQuote("Legible CLI ("+profile+")"),
+			hex.EncodeToString([]byte(secret))))
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label=Legible CLI ("+profile+")",
+			"service", keyringService, "profile", profile)
+		cmd.Stdin = strings.NewReader(secret)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// securityQuote quotes an argument for a command read by security -i.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (keyringStore) Delete(profile string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", profile)
+	} else {
+		cmd = exec.Command("secret-tool", "clear", "service", keyringService, "profile", profile)
+	}
+	// Deleting a missing item fails on macOS; that is not an error for us.
+	_ = cmd.Run()
+	return nil
+}
+
+// --- Encrypted file ---
+
+// encryptedFile is the on-disk layout of ~/.legible/credentials.enc.
+// Data is a JSON map of profile name to API key, sealed with AES-256-GCM
+// using a key derived from the passphrase with PBKDF2-SHA256.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Data       string `json:"data"`
+}
+
+// fileStore keeps all API keys in a single passphrase-encrypted file.
+// The passphrase is requested at most once per process.
+type fileStore struct {
+	file       string
+	passphrase string
+	secrets    map[string]string
+	salt       []byte
+}
+
+// currentFileStore is shared so Load and Save don't each ask for the passphrase.
+var currentFileStore *fileStore
+
+func openFileStore() (*fileStore, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, credentialsFile)
+	if currentFileStore == nil || currentFileStore.file != path {
+		currentFileStore = &fileStore{file: path}
+	}
+	return currentFileStore, nil
+}
+
+func (s *fileStore) Name() string { return StoreFile }
+
+func (s *fileStore) load() error {
+	if s.secrets != nil {
+		return nil
+	}
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.secrets = map[string]string{}
+			return nil
+		}
+		return fmt.Errorf("reading %s: %w", credentialsFile, err)
+	}
+
+	var ef encryptedFile
+	if err := json.Unmarshal(data, &ef); err != nil {
+		return fmt.Errorf("parsing %s: %w", credentialsFile, err)
+	}
+	if ef.Version != 1 {
+		return fmt.Errorf("unsupported %s version %d", credentialsFile, ef.Version)
+	}
+	salt, err1 := base64.StdEncoding.DecodeString(ef.Salt)
+	nonce, err2 := base64.StdEncoding.DecodeString(ef.Nonce)
+	sealed, err3 := base64.StdEncoding.DecodeString(ef.Data)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return fmt.Errorf("parsing %s: %w", credentialsFile, err)
+	}
+
+	if s.passphrase == "" {
+		if s.passphrase, err = PassphraseFunc(false); err != nil {
+			return err
+		}
+	}
+	gcm, err := newGCM(s.passphrase, salt, ef.Iterations)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		s.passphrase = ""
+		return fmt.Errorf("decrypting %s: wrong passphrase or corrupted file", credentialsFile)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("parsing %s: %w", credentialsFile, err)
+	}
+	s.secrets = secrets
+	s.salt = salt
+	return nil
+}
+
+func (s *fileStore) save() error {
+	var err error
+	if s.passphrase == "" {
+		if s.passphrase, err = PassphraseFunc(true); err != nil {
+			return err
+		}
+	}
+	if s.salt == nil {
+		s.salt = make([]byte, 16)
+		if _, err := rand.Read(s.salt); err != nil {
+			return err
+		}
+	}
+	gcm, err := newGCM(s.passphrase, s.salt, pbkdf2Iters)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    1,
+		Iterations: pbkdf2Iters,
+		Salt:       base64.StdEncoding.EncodeToString(s.salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Data:       base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, nil)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := os.WriteFile(s.file, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", credentialsFile, err)
+	}
+	return nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fileStore) Get(profile string) (string, error) {
+	// Don't ask for a passphrase when there is nothing to decrypt.
+	if _, err := os.Stat(s.file); os.IsNotExist(err) {
+		return "", nil
+	}
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	return s.secrets[profile], nil
+}
+
+func (s *fileStore) Set(profile, secret string) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.secrets[profile] = secret
+	return s.save()
+}
+
+func (s *fileStore) Delete(profile string) error {
+	if _, err := os.Stat(s.file); os.IsNotExist(err) {
+		return nil
+	}
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.secrets[profile]; !ok {
+		return nil
+	}
+	delete(s.secrets, profile)
+	return s.save()
+}
+
+// --- Credential helper ---
+
+// helperStore delegates to an external command, in the style of git's
+// credential helpers. The configured command is run through the shell with
+// "get", "store" or "erase" appended, and receives key=value lines on stdin:
+//
+//	profile=<name>
+//	api_key=<secret>   (store only)
+//
+// For "get", the helper prints "api_key=<secret>" (or just the secret) on stdout.
+type helperStore struct {
+	command string
+}
+
+func (s *helperStore) Name() string { return StoreHelper }
+
+func (s *helperStore) run(action, input string) (string, error) {
+	line := s.command + " " + action
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", line)
+	} else {
+		cmd = exec.Command("sh", "-c", line)
+	}
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential helper %q %s: %w", s.command, action, err)
+	}
+	return string(out), nil
+}
+
+func (s *helperStore) Get(profile string) (string, error) {
+	out, err := s.run("get", "profile="+profile+"\n")
+	if err != nil {
+		return "", err
+	}
+	var bare string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && (k == "api_key" || k == "password") {
+			return v, nil
+		}
+		if bare == "" && !strings.Contains(line, "=") {
+			bare = line
+		}
+	}
+	return bare, nil
+}
+
+func (s *helperStore) Set(profile, secret string) error {
+	_, err := s.run("store", "profile="+profile+"\napi_key="+secret+"\n")
+	return err
+}
+
+func (s *helperStore) Delete(profile string) error {
+	_, err := s.run("erase", "profile="+profile+"\n")
+	return err
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func readConfigFile(t *testing.T, home string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(home, configDir, configFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSave_EncryptedFileStore(t *testing.T) {
+	home := writeConfig(t, "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	cfg.Endpoint = "https://example.com"
+	cfg.APIKey = "osk-secret-value"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	if strings.Contains(readConfigFile(t, home), "osk-secret-value") {
+		t.Error("API key written to config.yaml in clear text")
+	}
+	enc, err := os.ReadFile(filepath.Join(home, configDir, credentialsFile))
+	if err != nil {
+		t.Fatalf("reading credentials file: %v", err)
+	}
+	if strings.Contains(string(enc), "osk-secret-value") {
+		t.Error("API key written to credentials file in clear text")
+	}
+
+	// A fresh process must decrypt with the passphrase.
+	currentFileStore = nil
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.APIKey != "osk-secret-value" {
+		t.Errorf("APIKey = %q, want %q", cfg.APIKey, "osk-secret-value")
+	}
+	if got := cfg.Display()["credential_store"]; got != StoreFile {
+		t.Errorf("credential_store = %q, want %q", got, StoreFile)
+	}
+}
+
+func TestLoad_WrongPassphrase(t *testing.T) {
+	writeConfig(t, "credential_store: file\n")
+
+	cfg, _ := Load()
+	cfg.APIKey = "osk-secret-value"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	currentFileStore = nil
+	t.Setenv(EnvPassphrase, "not-the-passphrase")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.APIKey != "" {
+		t.Errorf("APIKey = %q, want empty", cfg.APIKey)
+	}
+	if err := cfg.CredentialError(); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("CredentialError() = %v, want wrong passphrase error", err)
+	}
+}
+
+func TestSave_MigratesPlaintextKey(t *testing.T) {
+	home := writeConfig(t, `endpoint: https://legacy.example.com
+api_key: osk-legacy-plaintext
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	cfg.ProjectID = "4"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	if strings.Contains(readConfigFile(t, home), "osk-legacy-plaintext") {
+		t.Error("plaintext API key left in config.yaml after Save")
+	}
+	currentFileStore = nil
+	cfg, _ = Load()
+	if cfg.APIKey != "osk-legacy-plaintext" {
+		t.Errorf("APIKey = %q, want migrated key", cfg.APIKey)
+	}
+}
+
+func TestSave_PlaintextStore(t *testing.T) {
+	home := writeConfig(t, "credential_store: plaintext\n")
+
+	cfg, _ := Load()
+	cfg.APIKey = "osk-sandbox"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	if !strings.Contains(readConfigFile(t, home), "osk-sandbox") {
+		t.Error("plaintext store did not keep the key in config.yaml")
+	}
+	if _, err := os.Stat(filepath.Join(home, configDir, credentialsFile)); !os.IsNotExist(err) {
+		t.Error("plaintext store created a credentials file")
+	}
+}
+
+func TestCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script uses sh")
+	}
+	home := writeConfig(t, "")
+
+	// A helper that keeps the key in a file next to itself.
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	store := filepath.Join(dir, "secret")
+	helper := `#!/bin/sh
+case "$1" in
+  get) [ -f "` + store + `" ] && printf 'api_key=%s\n' "$(cat "` + store + `")" ;;
+  store) sed -n 's/^api_key=//p' > "` + store + `" ;;
+  erase) rm -f "` + store + `" ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(helper), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _ := Load()
+	if err := cfg.Set("credential-helper", script); err != nil {
+		t.Fatal(err)
+	}
+	cfg.APIKey = "osk-from-helper"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	if strings.Contains(readConfigFile(t, home), "osk-from-helper") {
+		t.Error("API key written to config.yaml in clear text")
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.APIKey != "osk-from-helper" {
+		t.Errorf("APIKey = %q, want %q", cfg.APIKey, "osk-from-helper")
+	}
+
+	f, _ := LoadFile()
+	if err := f.DeleteAPIKey(DefaultProfile); err != nil {
+		t.Fatalf("DeleteAPIKey() error: %v", err)
+	}
+	if _, err := os.Stat(store); !os.IsNotExist(err) {
+		t.Error("helper erase was not called")
+	}
+}
+
+func TestSet_InvalidCredentialStore(t *testing.T) {
+	writeConfig(t, "")
+	cfg, _ := Load()
+	if err := cfg.Set("credential-store", "vault"); err == nil {
+		t.Error("Set(credential-store, vault) should fail")
+	}
+}
+
+func TestSave_SwitchStoreMovesKeys(t *testing.T) {
+	home := writeConfig(t, "current_profile: default\nprofiles:\n  staging:\n    endpoint: https://staging.example.com\n")
+
+	cfg, _ := Load()
+	cfg.APIKey = "osk-default"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	f, _ := LoadFile()
+	if err := f.SetAPIKey("staging", "osk-staging"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _ = Load()
+	if err := cfg.Set("credential-store", StorePlaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	data := readConfigFile(t, home)
+	if !strings.Contains(data, "osk-default") || !strings.Contains(data, "osk-staging") {
+		t.Errorf("keys were not moved to config.yaml:\n%s", data)
+	}
+	currentFileStore = nil
+	store, _ := openFileStore()
+	for _, profile := range []string{"default", "staging"} {
+		if key, err := store.Get(profile); err != nil || key != "" {
+			t.Errorf("encrypted file still has %s = %q, %v", profile, key, err)
+		}
+	}
+}
@@ -5,9 +5,20 @@ import "regexp"
 // ModelFilter applies include/exclude regex patterns to filter model names.
 // A model passes if it matches ANY include pattern (or there are none)
 // and does NOT match ANY exclude pattern.
+//
+// It can also apply dbt node selectors (see Manifest.Select): a model
+// passes if its dbt node is selected by ANY select expression (or there are
+// none) and by NO exclude-select expression. Selectors are evaluated
+// against manifest.json by Resolve and applied by MatchNode.
 type ModelFilter struct {
-	Includes []string
-	Excludes []string
+	Includes       []string
+	Excludes       []string
+	Selects        []string
+	ExcludeSelects []string
+
+	// selected and excluded hold the unique_ids resolved from the selectors
+	selected map[string]bool
+	excluded map[string]bool
 }
 
 // NewFilter creates a ModelFilter from include/exclude pattern slices.
@@ -28,9 +39,56 @@ func NewFilterSingle(include, exclude string) *ModelFilter {
 	return &ModelFilter{Includes: includes, Excludes: excludes}
 }
 
-// IsEmpty returns true if no patterns are configured.
+// IsEmpty returns true if no patterns or selectors are configured.
 func (f *ModelFilter) IsEmpty() bool {
-	return len(f.Includes) == 0 && len(f.Excludes) == 0
+	return len(f.Includes) == 0 && len(f.Excludes) == 0 && !f.HasSelectors()
+}
+
+// HasSelectors returns true if any dbt selectors are configured.
+func (f *ModelFilter) HasSelectors() bool {
+	return len(f.Selects) > 0 || len(f.ExcludeSelects) > 0
+}
+
+// Resolve evaluates the dbt selectors against a manifest, for MatchNode.
+func (f *ModelFilter) Resolve(m *Manifest) error {
+	f.selected = nil
+	if len(f.Selects) > 0 {
+		f.selected = make(map[string]bool)
+		for _, expr := range f.Selects {
+			ids, err := m.Select(expr)
+			if err != nil {
+				return err
+			}
+			for id := range ids {
+				f.selected[id] = true
+			}
+		}
+	}
+	f.excluded = make(map[string]bool)
+	for _, expr := range f.ExcludeSelects {
+		ids, err := m.Select(expr)
+		if err != nil {
+			return err
+		}
+		for id := range ids {
+			f.excluded[id] = true
+		}
+	}
+	return nil
+}
+
+// MatchNode returns true if a model passes the include/exclude patterns by
+// its name, and the selectors by the unique_id of the dbt node it was
+// converted from. Selectors must be resolved first; until then, no model
+// passes a select expression.
+func (f *ModelFilter) MatchNode(name, uniqueID string) bool {
+	if !f.Match(name) {
+		return false
+	}
+	if len(f.Selects) > 0 && !f.selected[uniqueID] {
+		return false
+	}
+	return !f.excluded[uniqueID]
 }
 
 // Match returns true if the given name passes the include/exclude patterns.
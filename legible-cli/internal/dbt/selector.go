@@ -0,0 +1,363 @@
+package dbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Manifest holds the parts of a dbt manifest.json that node selectors
+// are evaluated against.
+type Manifest struct {
+	Nodes     map[string]ManifestNode `json:"nodes"`
+	Sources   map[string]ManifestNode `json:"sources"`
+	ParentMap map[string][]string     `json:"parent_map"`
+	ChildMap  map[string][]string     `json:"child_map"`
+}
+
+// ManifestNode is a node or source in manifest.json.
+type ManifestNode struct {
+	UniqueID         string                 `json:"unique_id"`
+	ResourceType     string                 `json:"resource_type"`
+	PackageName      string                 `json:"package_name"`
+	Name             string                 `json:"name"`
+	SourceName       string                 `json:"source_name"`
+	OriginalFilePath string                 `json:"original_file_path"`
+	FQN              []string               `json:"fqn"`
+	Tags             []string               `json:"tags"`
+	Config           map[string]interface{} `json:"config"`
+	DependsOn        struct {
+		Nodes []string `json:"nodes"`
+	} `json:"depends_on"`
+}
+
+// LoadManifest reads a dbt manifest.json.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found — run 'dbt build' or 'dbt compile' to use dbt selectors", path)
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// selectorTermRegex splits a selector term such as "@tag:finance" or
+// "2+path:models/marts+1" into its graph operators, method and value.
+var selectorTermRegex = regexp.MustCompile(`^(@)?((\d*)\+)?(?:([\w.]+):)?(.*?)(\+(\d*))?$`)
+
+// Select returns the unique_ids of the nodes selected by a dbt selector
+// expression. As in dbt, space-separated selectors are unioned and
+// comma-separated selectors are intersected, e.g. "tag:finance,config.materialized:table +orders".
+//
+// Supported methods are fqn (the default), tag, path, file, config.<key>,
+// resource_type, package and source, with the +, n+, +n and @ graph operators.
+func (m *Manifest) Select(expr string) (map[string]bool, error) {
+	union := make(map[string]bool)
+	for _, group := range strings.Fields(expr) {
+		var intersection map[string]bool
+		for _, term := range strings.Split(group, ",") {
+			if term == "" {
+				continue
+			}
+			selected, err := m.selectTerm(term)
+			if err != nil {
+				return nil, err
+			}
+			if intersection == nil {
+				intersection = selected
+				continue
+			}
+			for id := range intersection {
+				if !selected[id] {
+					delete(intersection, id)
+				}
+			}
+		}
+		for id := range intersection {
+			union[id] = true
+		}
+	}
+	return union, nil
+}
+
+// selectTerm evaluates a single selector term, including its graph operators.
+func (m *Manifest) selectTerm(term string) (map[string]bool, error) {
+	parts := selectorTermRegex.FindStringSubmatch(term)
+	if parts == nil || parts[5] == "" {
+		return nil, fmt.Errorf("invalid dbt selector %q", term)
+	}
+	childrensParents := parts[1] != ""
+	withParents, parentsDepth := parts[2] != "", parts[3]
+	method, value := parts[4], parts[5]
+	withChildren, childrenDepth := parts[6] != "", parts[7]
+
+	match, err := nodeMatcher(method, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dbt selector %q: %w", term, err)
+	}
+
+	selected := make(map[string]bool)
+	for _, nodes := range []map[string]ManifestNode{m.Nodes, m.Sources} {
+		for id, node := range nodes {
+			if match(node) {
+				selected[id] = true
+			}
+		}
+	}
+
+	result := make(map[string]bool, len(selected))
+	for id := range selected {
+		result[id] = true
+	}
+	if withParents {
+		for id := range walkGraph(selected, m.parents(), depthLimit(parentsDepth)) {
+			result[id] = true
+		}
+	}
+	if withChildren {
+		for id := range walkGraph(selected, m.children(), depthLimit(childrenDepth)) {
+			result[id] = true
+		}
+	}
+	if childrensParents {
+		// @ selects the nodes, their descendants and the ancestors of those descendants.
+		descendants := walkGraph(selected, m.children(), -1)
+		for id := range descendants {
+			result[id] = true
+		}
+		for id := range walkGraph(descendants, m.parents(), -1) {
+			result[id] = true
+		}
+	}
+	return result, nil
+}
+
+// nodeMatcher returns a function that reports whether a node matches a
+// selector method and value. Values may use shell-style wildcards.
+func nodeMatcher(method, value string) (func(ManifestNode) bool, error) {
+	if method == "" {
+		// Like dbt, a bare value is a path if it looks like one, and an fqn otherwise.
+		method = "fqn"
+		if strings.ContainsAny(value, `/\`) || strings.HasSuffix(value, ".sql") || strings.HasSuffix(value, ".py") || strings.HasSuffix(value, ".csv") {
+			method = "path"
+		}
+	}
+
+	if configPath, ok := strings.CutPrefix(method, "config."); ok {
+		keys := strings.Split(configPath, ".")
+		return func(node ManifestNode) bool {
+			return configMatches(node.Config, keys, value)
+		}, nil
+	}
+
+	switch method {
+	case "fqn":
+		return func(node ManifestNode) bool {
+			return fqnMatches(node.FQN, value) || (len(node.FQN) > 1 && fqnMatches(node.FQN[1:], value))
+		}, nil
+	case "tag":
+		return func(node ManifestNode) bool {
+			for _, tag := range node.Tags {
+				if globMatch(value, tag) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "path":
+		return func(node ManifestNode) bool {
+			return pathMatches(node.OriginalFilePath, value)
+		}, nil
+	case "file":
+		return func(node ManifestNode) bool {
+			base := path.Base(filepath.ToSlash(node.OriginalFilePath))
+			return globMatch(value, base) || globMatch(value, strings.TrimSuffix(base, path.Ext(base)))
+		}, nil
+	case "resource_type":
+		return func(node ManifestNode) bool {
+			return node.ResourceType == value
+		}, nil
+	case "package":
+		return func(node ManifestNode) bool {
+			return globMatch(value, node.PackageName)
+		}, nil
+	case "source":
+		// source:<source>, source:<source>.<table> or source:<package>.<source>.<table>
+		parts := strings.Split(value, ".")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("source selector must be <source>, <source>.<table> or <package>.<source>.<table>")
+		}
+		return func(node ManifestNode) bool {
+			if node.ResourceType != "source" {
+				return false
+			}
+			target := []string{node.PackageName, node.SourceName, node.Name}
+			switch len(parts) {
+			case 1:
+				return globMatch(parts[0], node.SourceName)
+			case 2:
+				return globMatch(parts[0], node.SourceName) && globMatch(parts[1], node.Name)
+			default:
+				for i := range parts {
+					if !globMatch(parts[i], target[i]) {
+						return false
+					}
+				}
+				return true
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported selector method %q", method)
+	}
+}
+
+// fqnMatches reports whether a node's fully qualified name matches a
+// selector: either its name, or a dotted prefix such as "marts.finance".
+func fqnMatches(fqn []string, selector string) bool {
+	if len(fqn) == 0 {
+		return false
+	}
+	if globMatch(selector, fqn[len(fqn)-1]) {
+		return true
+	}
+	parts := strings.Split(selector, ".")
+	if len(parts) > len(fqn) {
+		return false
+	}
+	for i, part := range parts {
+		if !globMatch(part, fqn[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// pathMatches reports whether a node's file matches a path selector, which
+// is either the file itself or a directory containing it.
+func pathMatches(filePath, selector string) bool {
+	if filePath == "" {
+		return false
+	}
+	filePath = path.Clean(filepath.ToSlash(filePath))
+	selector = path.Clean(filepath.ToSlash(selector))
+	if globMatch(selector, filePath) {
+		return true
+	}
+	dirs := strings.Split(filePath, "/")
+	for i := 1; i < len(dirs); i++ {
+		if globMatch(selector, strings.Join(dirs[:i], "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// configMatches reports whether the config value at the given key path,
+// such as materialized or meta.owner, matches value. For list values, such
+// as tags, any element may match.
+func configMatches(config map[string]interface{}, keys []string, value string) bool {
+	var current interface{} = config
+	for _, key := range keys {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if current, ok = m[key]; !ok {
+			return false
+		}
+	}
+	switch v := current.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		for _, item := range v {
+			if globMatch(value, fmt.Sprint(item)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return globMatch(value, fmt.Sprint(v))
+	}
+}
+
+// globMatch matches a shell-style wildcard pattern, falling back to plain
+// equality for patterns that are not valid globs.
+func globMatch(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+	if err != nil {
+		return pattern == s
+	}
+	return ok
+}
+
+// depthLimit parses the depth of a graph operator; no depth means unlimited.
+func depthLimit(depth string) int {
+	n, err := strconv.Atoi(depth)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// walkGraph returns the nodes reachable from start along edges, up to depth
+// steps away (unlimited when negative), not including start itself unless reachable.
+func walkGraph(start map[string]bool, edges map[string][]string, depth int) map[string]bool {
+	reached := make(map[string]bool)
+	frontier := make([]string, 0, len(start))
+	for id := range start {
+		frontier = append(frontier, id)
+	}
+	for step := 0; len(frontier) > 0 && (depth < 0 || step < depth); step++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbor := range edges[id] {
+				if !reached[neighbor] {
+					reached[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+	return reached
+}
+
+// parents returns each node's direct parents, from parent_map or, for
+// manifests without one, from the nodes' depends_on.
+func (m *Manifest) parents() map[string][]string {
+	if len(m.ParentMap) > 0 {
+		return m.ParentMap
+	}
+	parents := make(map[string][]string, len(m.Nodes))
+	for id, node := range m.Nodes {
+		parents[id] = node.DependsOn.Nodes
+	}
+	return parents
+}
+
+// children returns each node's direct children, from child_map or by
+// inverting parents.
+func (m *Manifest) children() map[string][]string {
+	if len(m.ChildMap) > 0 {
+		return m.ChildMap
+	}
+	children := make(map[string][]string)
+	for id, parents := range m.parents() {
+		for _, parent := range parents {
+			children[parent] = append(children[parent], id)
+		}
+	}
+	return children
+}
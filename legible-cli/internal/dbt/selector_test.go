@@ -0,0 +1,204 @@
+package dbt
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// testManifest is a small project: raw.orders -> stg_orders -> orders -> finance_summary,
+// and raw.customers -> customers -> finance_summary.
+const testManifestJSON = `{
+  "nodes": {
+    "model.shop.stg_orders": {
+      "unique_id": "model.shop.stg_orders", "resource_type": "model", "package_name": "shop",
+      "name": "stg_orders", "original_file_path": "models/staging/stg_orders.sql",
+      "fqn": ["shop", "staging", "stg_orders"], "tags": ["staging"],
+      "config": {"materialized": "view"},
+      "depends_on": {"nodes": ["source.shop.raw.orders"]}
+    },
+    "model.shop.orders": {
+      "unique_id": "model.shop.orders", "resource_type": "model", "package_name": "shop",
+      "name": "orders", "original_file_path": "models/marts/orders.sql",
+      "fqn": ["shop", "marts", "orders"], "tags": ["finance"],
+      "config": {"materialized": "table", "meta": {"owner": "finance-team"}},
+      "depends_on": {"nodes": ["model.shop.stg_orders"]}
+    },
+    "model.shop.customers": {
+      "unique_id": "model.shop.customers", "resource_type": "model", "package_name": "shop",
+      "name": "customers", "original_file_path": "models/marts/customers.sql",
+      "fqn": ["shop", "marts", "customers"], "tags": [],
+      "config": {"materialized": "table", "meta": {"owner": "crm-team"}},
+      "depends_on": {"nodes": ["source.shop.raw.customers"]}
+    },
+    "model.shop.finance_summary": {
+      "unique_id": "model.shop.finance_summary", "resource_type": "model", "package_name": "shop",
+      "name": "finance_summary", "original_file_path": "models/marts/finance/finance_summary.sql",
+      "fqn": ["shop", "marts", "finance", "finance_summary"], "tags": ["finance"],
+      "config": {"materialized": "view"},
+      "depends_on": {"nodes": ["model.shop.orders", "model.shop.customers"]}
+    },
+    "seed.shop.country": {
+      "unique_id": "seed.shop.country", "resource_type": "seed", "package_name": "shop",
+      "name": "country", "original_file_path": "seeds/country.csv",
+      "fqn": ["shop", "country"], "tags": [], "config": {"materialized": "seed"},
+      "depends_on": {"nodes": []}
+    }
+  },
+  "sources": {
+    "source.shop.raw.orders": {
+      "unique_id": "source.shop.raw.orders", "resource_type": "source", "package_name": "shop",
+      "name": "orders", "source_name": "raw", "original_file_path": "models/staging/sources.yml",
+      "fqn": ["shop", "staging", "raw", "orders"], "tags": [], "config": {}
+    },
+    "source.shop.raw.customers": {
+      "unique_id": "source.shop.raw.customers", "resource_type": "source", "package_name": "shop",
+      "name": "customers", "source_name": "raw", "original_file_path": "models/staging/sources.yml",
+      "fqn": ["shop", "staging", "raw", "customers"], "tags": [], "config": {}
+    }
+  }
+}`
+
+func loadTestManifest(t *testing.T) *Manifest {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte(testManifestJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error: %v", err)
+	}
+	return m
+}
+
+func sortedIDs(ids map[string]bool) []string {
+	var out []string
+	for id := range ids {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestManifest_Select(t *testing.T) {
+	m := loadTestManifest(t)
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"orders", []string{"model.shop.orders", "source.shop.raw.orders"}},
+		{"fqn:marts", []string{"model.shop.customers", "model.shop.finance_summary", "model.shop.orders"}},
+		{"shop.marts.finance", []string{"model.shop.finance_summary"}},
+		{"stg_*", []string{"model.shop.stg_orders"}},
+		{"tag:finance", []string{"model.shop.finance_summary", "model.shop.orders"}},
+		{"path:models/marts/finance", []string{"model.shop.finance_summary"}},
+		{"models/staging", []string{"model.shop.stg_orders", "source.shop.raw.customers", "source.shop.raw.orders"}},
+		{"models/marts/orders.sql", []string{"model.shop.orders"}},
+		{"file:customers", []string{"model.shop.customers"}},
+		{"config.materialized:table", []string{"model.shop.customers", "model.shop.orders"}},
+		{"config.meta.owner:finance-*", []string{"model.shop.orders"}},
+		{"resource_type:seed", []string{"seed.shop.country"}},
+		{"source:raw.customers", []string{"source.shop.raw.customers"}},
+		{"source:raw", []string{"source.shop.raw.customers", "source.shop.raw.orders"}},
+
+		// Graph operators
+		{"+orders", []string{"model.shop.orders", "model.shop.stg_orders", "source.shop.raw.orders"}},
+		{"1+fqn:shop.marts.orders", []string{"model.shop.orders", "model.shop.stg_orders"}},
+		{"source:raw.customers+", []string{"model.shop.customers", "model.shop.finance_summary", "source.shop.raw.customers"}},
+		{"stg_orders+1", []string{"model.shop.orders", "model.shop.stg_orders"}},
+		{"@stg_orders", []string{
+			"model.shop.customers", "model.shop.finance_summary", "model.shop.orders", "model.shop.stg_orders",
+			"source.shop.raw.customers", "source.shop.raw.orders",
+		}},
+
+		// Unions and intersections
+		{"tag:finance fqn:shop.marts.customers", []string{"model.shop.customers", "model.shop.finance_summary", "model.shop.orders"}},
+		{"tag:finance,config.materialized:table", []string{"model.shop.orders"}},
+		{"path:models/marts,config.materialized:view resource_type:seed", []string{"model.shop.finance_summary", "seed.shop.country"}},
+		{"tag:nonexistent", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			ids, err := m.Select(tt.expr)
+			if err != nil {
+				t.Fatalf("Select(%q) error: %v", tt.expr, err)
+			}
+			if got := sortedIDs(ids); !strSliceEqual(got, tt.want) {
+				t.Errorf("Select(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifest_SelectErrors(t *testing.T) {
+	m := loadTestManifest(t)
+	for _, expr := range []string{"owner:finance", "tag:", "source:a.b.c.d", "+"} {
+		if _, err := m.Select(expr); err == nil {
+			t.Errorf("Select(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestManifest_SelectWithoutGraphMaps(t *testing.T) {
+	// Graph operators fall back to depends_on when parent_map/child_map are missing
+	m := loadTestManifest(t)
+	if len(m.ParentMap) != 0 || len(m.ChildMap) != 0 {
+		t.Fatal("test manifest should not have graph maps")
+	}
+	ids, err := m.Select("source:raw.orders+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"model.shop.finance_summary", "model.shop.orders", "model.shop.stg_orders", "source.shop.raw.orders"}
+	if got := sortedIDs(ids); !strSliceEqual(got, want) {
+		t.Errorf("Select(source:raw.orders+) = %v, want %v", got, want)
+	}
+}
+
+func TestLoadManifest_NotFound(t *testing.T) {
+	if _, err := LoadManifest(filepath.Join(t.TempDir(), "manifest.json")); err == nil {
+		t.Fatal("expected error for missing manifest.json")
+	}
+}
+
+func TestModelFilter_MatchNode(t *testing.T) {
+	m := loadTestManifest(t)
+
+	tests := []struct {
+		name           string
+		includes       []string
+		selects        []string
+		excludeSelects []string
+		model          string
+		uniqueID       string
+		want           bool
+	}{
+		{"selected", nil, []string{"tag:finance"}, nil, "orders", "model.shop.orders", true},
+		{"not selected", nil, []string{"tag:finance"}, nil, "customers", "model.shop.customers", false},
+		{"any select expression", nil, []string{"tag:finance", "customers"}, nil, "customers", "model.shop.customers", true},
+		{"exclude selected", nil, nil, []string{"path:models/marts/finance"}, "finance_summary", "model.shop.finance_summary", false},
+		{"exclude not selected", nil, nil, []string{"path:models/marts/finance"}, "orders", "model.shop.orders", true},
+		{"exclude wins", nil, []string{"tag:finance"}, []string{"finance_summary"}, "finance_summary", "model.shop.finance_summary", false},
+		{"regex and selector", []string{"^cust"}, []string{"tag:finance"}, nil, "orders", "model.shop.orders", false},
+		{"unknown node with select", nil, []string{"tag:finance"}, nil, "manual", "", false},
+		{"unknown node with exclude only", nil, nil, []string{"tag:finance"}, "manual", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFilter(tt.includes, nil)
+			f.Selects, f.ExcludeSelects = tt.selects, tt.excludeSelects
+			if f.IsEmpty() {
+				t.Fatal("IsEmpty() = true with selectors")
+			}
+			if err := f.Resolve(m); err != nil {
+				t.Fatalf("Resolve() error: %v", err)
+			}
+			if got := f.MatchNode(tt.model, tt.uniqueID); got != tt.want {
+				t.Errorf("MatchNode(%q, %q) = %v, want %v", tt.model, tt.uniqueID, got, tt.want)
+			}
+		})
+	}
+}
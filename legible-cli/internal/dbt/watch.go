@@ -0,0 +1,76 @@
+package dbt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ArtifactNames are the dbt artifacts in target/ that a conversion reads.
+var ArtifactNames = []string{"manifest.json", "catalog.json", "semantic_manifest.json"}
+
+// WatchArtifacts watches dir (a dbt project's target/ directory) and calls
+// onChange with the names of the artifacts that were written, once none has
+// been written for the debounce period. dbt writes several artifacts per
+// command, and may write each in steps, so a command causes a single call.
+// It returns nil when ctx is done.
+func WatchArtifacts(ctx context.Context, dir string, debounce time.Duration, onChange func(changed []string)) error {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s not found — run 'dbt build' and 'dbt docs generate' first", dir)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory rather than the files: dbt replaces them, which
+	// would end a watch on the file itself.
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+
+	artifacts := make(map[string]bool, len(ArtifactNames))
+	for _, name := range ArtifactNames {
+		artifacts[name] = true
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	changed := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			name := filepath.Base(event.Name)
+			if !artifacts[name] || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			changed[name] = true
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watching %s: %w", dir, err)
+		case <-timer.C:
+			names := make([]string, 0, len(changed))
+			for name := range changed {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			changed = make(map[string]bool)
+			onChange(names)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package dbt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := make(chan []string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchArtifacts(ctx, dir, 100*time.Millisecond, func(changed []string) {
+			calls <- changed
+		})
+	}()
+	// Give the watcher time to start
+	time.Sleep(100 * time.Millisecond)
+
+	// One dbt command writes several artifacts, some more than once
+	for _, name := range []string{"manifest.json", "run_results.json", "catalog.json", "manifest.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case got := <-calls:
+		if want := []string{"catalog.json", "manifest.json"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("changed = %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("onChange was not called")
+	}
+	select {
+	case got := <-calls:
+		t.Errorf("onChange called again with %v, want a single call", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("WatchArtifacts() = %v", err)
+	}
+}
+
+func TestWatchArtifactsMissingDir(t *testing.T) {
+	err := WatchArtifacts(context.Background(), filepath.Join(t.TempDir(), "target"), time.Second, func([]string) {})
+	if err == nil {
+		t.Error("expected an error for a missing target directory")
+	}
+}
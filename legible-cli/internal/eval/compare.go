@@ -0,0 +1,316 @@
+package eval
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+// maxDiffRows is how many missing and unexpected rows a diff lists.
+const maxDiffRows = 10
+
+// cell is a normalized result value. Numbers, including numeric strings,
+// compare by value so that 12.5, "12.50" and a DECIMAL 12.500 are equal.
+type cell struct {
+	null  bool
+	isNum bool
+	num   float64
+	str   string
+}
+
+func newCell(v interface{}) cell {
+	switch val := v.(type) {
+	case nil:
+		return cell{null: true}
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return cell{isNum: true, num: f}
+		}
+		return cell{str: val.String()}
+	case float64:
+		return cell{isNum: true, num: val}
+	case int:
+		return cell{isNum: true, num: float64(val)}
+	case int64:
+		return cell{isNum: true, num: float64(val)}
+	case bool:
+		return cell{str: strconv.FormatBool(val)}
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return cell{isNum: true, num: f}
+		}
+		return cell{str: val}
+	}
+	data, _ := json.Marshal(v)
+	return cell{str: string(data)}
+}
+
+func (c cell) String() string {
+	switch {
+	case c.null:
+		return "NULL"
+	case c.isNum:
+		return strconv.FormatFloat(c.num, 'g', -1, 64)
+	}
+	return strconv.Quote(c.str)
+}
+
+// compareCells orders cells: NULL, then numbers, then text.
+func compareCells(a, b cell) int {
+	rank := func(c cell) int {
+		switch {
+		case c.null:
+			return 0
+		case c.isNum:
+			return 1
+		}
+		return 2
+	}
+	if r := cmp.Compare(rank(a), rank(b)); r != 0 {
+		return r
+	}
+	if a.isNum {
+		return cmp.Compare(a.num, b.num)
+	}
+	return strings.Compare(a.str, b.str)
+}
+
+// equalCells reports whether two cells are equal, allowing numbers to
+// differ by tol relative to their magnitude (and by tol absolutely near
+// zero).
+func equalCells(a, b cell, tol float64) bool {
+	switch {
+	case a.null || b.null:
+		return a.null && b.null
+	case a.isNum && b.isNum:
+		return math.Abs(a.num-b.num) <= tol*math.Max(1, math.Max(math.Abs(a.num), math.Abs(b.num)))
+	case a.isNum || b.isNum:
+		return false
+	}
+	return a.str == b.str
+}
+
+type row []cell
+
+func (r row) String() string {
+	parts := make([]string, len(r))
+	for i, c := range r {
+		parts[i] = c.String()
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func compareRows(a, b row) int {
+	for i := range min(len(a), len(b)) {
+		if c := compareCells(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func equalRows(a, b row, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalCells(a[i], b[i], tol) {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff is the difference between an expected and an actual result set.
+type Diff struct {
+	// Columns is set when the results have different column counts, in
+	// which case rows are not compared.
+	Columns string
+	Missing []string // expected rows absent from the actual result
+	Extra   []string // actual rows absent from the expected result
+	// MissingCount and ExtraCount are the full counts; Missing and Extra
+	// list at most maxDiffRows rows each.
+	MissingCount, ExtraCount int
+}
+
+// Match reports whether the result sets are equal.
+func (d *Diff) Match() bool {
+	return d.Columns == "" && d.MissingCount == 0 && d.ExtraCount == 0
+}
+
+// String renders the diff with "-" for missing and "+" for extra rows.
+func (d *Diff) String() string {
+	if d.Match() {
+		return ""
+	}
+	if d.Columns != "" {
+		return d.Columns
+	}
+	var b strings.Builder
+	write := func(sign string, rows []string, total int) {
+		for _, r := range rows {
+			fmt.Fprintf(&b, "%s %s\n", sign, r)
+		}
+		if more := total - len(rows); more > 0 {
+			fmt.Fprintf(&b, "%s ... and %d more\n", sign, more)
+		}
+	}
+	write("-", d.Missing, d.MissingCount)
+	write("+", d.Extra, d.ExtraCount)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Compare compares two result sets as multisets of rows, ignoring row
+// order. Columns are matched by name when both results have the same
+// column names, and by position otherwise, since generated SQL often
+// aliases columns differently. Numbers may differ by the relative
+// tolerance tol.
+func Compare(expected, actual *client.RunSQLResult, tol float64) *Diff {
+	if len(expected.Columns) != len(actual.Columns) {
+		return &Diff{Columns: fmt.Sprintf("expected %d column(s) (%s), got %d (%s)",
+			len(expected.Columns), columnNames(expected.Columns),
+			len(actual.Columns), columnNames(actual.Columns))}
+	}
+
+	actualCols := actual.Columns
+	if sameNames(expected.Columns, actual.Columns) {
+		actualCols = expected.Columns
+	}
+	want := rows(expected.Records, expected.Columns)
+	got := actualRows(actual, actualCols)
+
+	slices.SortFunc(want, compareRows)
+	slices.SortFunc(got, compareRows)
+
+	d := &Diff{}
+	missing := func(r row) {
+		d.MissingCount++
+		if len(d.Missing) < maxDiffRows {
+			d.Missing = append(d.Missing, r.String())
+		}
+	}
+	extra := func(r row) {
+		d.ExtraCount++
+		if len(d.Extra) < maxDiffRows {
+			d.Extra = append(d.Extra, r.String())
+		}
+	}
+	i, j := 0, 0
+	for i < len(want) && j < len(got) {
+		switch {
+		case equalRows(want[i], got[j], tol):
+			i, j = i+1, j+1
+		case compareRows(want[i], got[j]) < 0:
+			missing(want[i])
+			i++
+		default:
+			extra(got[j])
+			j++
+		}
+	}
+	for ; i < len(want); i++ {
+		missing(want[i])
+	}
+	for ; j < len(got); j++ {
+		extra(got[j])
+	}
+	return d
+}
+
+// rows extracts records as rows in the order of cols.
+func rows(records []map[string]interface{}, cols []client.RunSQLColumn) []row {
+	out := make([]row, len(records))
+	for i, rec := range records {
+		r := make(row, len(cols))
+		for k, c := range cols {
+			r[k] = newCell(rec[c.Name])
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// actualRows extracts the actual result's records with its columns in the
+// order of order, which names the same columns (possibly in other case).
+func actualRows(res *client.RunSQLResult, order []client.RunSQLColumn) []row {
+	cols := make([]client.RunSQLColumn, len(order))
+	for i, c := range order {
+		cols[i] = c
+		for _, ac := range res.Columns {
+			if strings.EqualFold(ac.Name, c.Name) {
+				cols[i] = ac
+				break
+			}
+		}
+	}
+	return rows(res.Records, cols)
+}
+
+func sameNames(a, b []client.RunSQLColumn) bool {
+	names := map[string]int{}
+	for _, c := range a {
+		names[strings.ToLower(c.Name)]++
+	}
+	for _, c := range b {
+		names[strings.ToLower(c.Name)]--
+	}
+	for _, n := range names {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func columnNames(cols []client.RunSQLColumn) string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// NormalizeSQL canonicalizes a query for exact-match comparison: keywords
+// and identifiers are lowercased, whitespace is collapsed, and a trailing
+// semicolon is dropped. Quoted strings and identifiers are kept as written.
+func NormalizeSQL(sql string) string {
+	var b strings.Builder
+	var quote, last rune
+	space := false
+	for _, r := range strings.TrimRight(strings.TrimSpace(sql), "; \t\n") {
+		switch {
+		case quote != 0:
+			b.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+			last = r
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		}
+		if space {
+			if last != 0 && !isPunct(r) && !isPunct(last) {
+				b.WriteByte(' ')
+			}
+			space = false
+		}
+		last = unicode.ToLower(r)
+		b.WriteRune(last)
+	}
+	return b.String()
+}
+
+func isPunct(r rune) bool {
+	return strings.ContainsRune("(),=<>+-*/", r)
+}
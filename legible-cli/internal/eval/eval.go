@@ -0,0 +1,194 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+// Backend generates and runs SQL; *client.Client implements it.
+type Backend interface {
+	GenerateSQLContext(ctx context.Context, req *client.GenerateSQLRequest) (*client.GenerateSQLResult, error)
+	RunSQLContext(ctx context.Context, req *client.RunSQLRequest) (*client.RunSQLResult, error)
+}
+
+// Options control an evaluation run.
+type Options struct {
+	// Concurrency is the number of questions evaluated at once.
+	Concurrency int
+	// Tolerance is the relative difference allowed between numbers.
+	Tolerance float64
+	// Limit caps the rows fetched per query. Results that reach it are
+	// flagged as truncated, since two unordered queries may then return
+	// different subsets.
+	Limit int
+	// Language is passed to SQL generation.
+	Language string
+}
+
+// Default option values.
+const (
+	DefaultConcurrency = 4
+	DefaultTolerance   = 1e-6
+	DefaultLimit       = 10000
+)
+
+// Status is the outcome of one case.
+type Status string
+
+const (
+	StatusPass  Status = "pass"  // the result sets match
+	StatusFail  Status = "fail"  // the result sets differ
+	StatusError Status = "error" // SQL generation or execution failed
+)
+
+// CaseResult is the outcome of one question.
+type CaseResult struct {
+	ID             string  `json:"id"`
+	Question       string  `json:"question"`
+	ExpectedSQL    string  `json:"expectedSql"`
+	GeneratedSQL   string  `json:"generatedSql,omitempty"`
+	Status         Status  `json:"status"`
+	ExactMatch     bool    `json:"exactMatch"`
+	ExecutionMatch bool    `json:"executionMatch"`
+	ExpectedRows   int     `json:"expectedRows"`
+	GeneratedRows  int     `json:"generatedRows"`
+	Truncated      bool    `json:"truncated,omitempty"`
+	Diff           string  `json:"diff,omitempty"`
+	Error          string  `json:"error,omitempty"`
+	Seconds        float64 `json:"seconds"`
+}
+
+// Summary aggregates the results of a run.
+type Summary struct {
+	Total              int     `json:"total"`
+	ExactMatch         int     `json:"exactMatch"`
+	ExecutionMatch     int     `json:"executionMatch"`
+	Errors             int     `json:"errors"`
+	ExactMatchRate     float64 `json:"exactMatchRate"`
+	ExecutionMatchRate float64 `json:"executionMatchRate"`
+	ErrorRate          float64 `json:"errorRate"`
+}
+
+// Report is the result of evaluating a suite.
+type Report struct {
+	Suite     string       `json:"suite"`
+	StartedAt time.Time    `json:"startedAt"`
+	Seconds   float64      `json:"seconds"`
+	Summary   Summary      `json:"summary"`
+	Cases     []CaseResult `json:"cases"`
+}
+
+// Run evaluates every case of the suite, up to opts.Concurrency at a time,
+// and calls progress (if not nil) as each case finishes. Cases are
+// reported in suite order. If ctx is cancelled, Run returns ctx.Err()
+// along with the report of the cases that finished.
+func Run(ctx context.Context, b Backend, suite *Suite, opts Options, progress func(CaseResult)) (*Report, error) {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	report := &Report{Suite: suite.Name, StartedAt: time.Now().UTC()}
+	results := make([]*CaseResult, len(suite.Cases))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, opts.Concurrency)
+	for i, c := range suite.Cases {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r := runCase(ctx, b, c, opts)
+			if ctx.Err() != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			results[i] = &r
+			if progress != nil {
+				progress(r)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r != nil {
+			report.Cases = append(report.Cases, *r)
+		}
+	}
+	report.Seconds = time.Since(report.StartedAt).Seconds()
+	report.Summary = summarize(report.Cases)
+	return report, ctx.Err()
+}
+
+func runCase(ctx context.Context, b Backend, c Case, opts Options) CaseResult {
+	start := time.Now()
+	r := CaseResult{ID: c.ID, Question: c.Question, ExpectedSQL: c.SQL, Status: StatusError}
+
+	gen, err := b.GenerateSQLContext(ctx, &client.GenerateSQLRequest{Question: c.Question, Language: opts.Language})
+	if err != nil {
+		r.Error = fmt.Sprintf("generating SQL: %v", err)
+		r.Seconds = time.Since(start).Seconds()
+		return r
+	}
+	r.GeneratedSQL = gen.SQL
+	r.ExactMatch = NormalizeSQL(gen.SQL) == NormalizeSQL(c.SQL)
+
+	expected, err := b.RunSQLContext(ctx, &client.RunSQLRequest{SQL: c.SQL, Limit: opts.Limit})
+	if err != nil {
+		r.Error = fmt.Sprintf("running expected SQL: %v", err)
+		r.Seconds = time.Since(start).Seconds()
+		return r
+	}
+	actual, err := b.RunSQLContext(ctx, &client.RunSQLRequest{SQL: gen.SQL, Limit: opts.Limit})
+	if err != nil {
+		r.Error = fmt.Sprintf("running generated SQL: %v", err)
+		r.Seconds = time.Since(start).Seconds()
+		return r
+	}
+
+	r.ExpectedRows, r.GeneratedRows = len(expected.Records), len(actual.Records)
+	r.Truncated = opts.Limit > 0 && (r.ExpectedRows >= opts.Limit || r.GeneratedRows >= opts.Limit)
+	diff := Compare(expected, actual, opts.Tolerance)
+	r.ExecutionMatch = diff.Match()
+	r.Diff = diff.String()
+	r.Status = StatusFail
+	if r.ExecutionMatch {
+		r.Status = StatusPass
+	}
+	r.Seconds = time.Since(start).Seconds()
+	return r
+}
+
+func summarize(cases []CaseResult) Summary {
+	s := Summary{Total: len(cases)}
+	for _, c := range cases {
+		if c.ExactMatch {
+			s.ExactMatch++
+		}
+		if c.ExecutionMatch {
+			s.ExecutionMatch++
+		}
+		if c.Status == StatusError {
+			s.Errors++
+		}
+	}
+	if s.Total > 0 {
+		n := float64(s.Total)
+		s.ExactMatchRate = float64(s.ExactMatch) / n
+		s.ExecutionMatchRate = float64(s.ExecutionMatch) / n
+		s.ErrorRate = float64(s.Errors) / n
+	}
+	return s
+}
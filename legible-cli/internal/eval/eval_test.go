@@ -0,0 +1,223 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+func result(cols []string, rows ...[]interface{}) *client.RunSQLResult {
+	r := &client.RunSQLResult{}
+	for _, c := range cols {
+		r.Columns = append(r.Columns, client.RunSQLColumn{Name: c})
+	}
+	for _, row := range rows {
+		rec := map[string]interface{}{}
+		for i, v := range row {
+			rec[cols[i]] = v
+		}
+		r.Records = append(r.Records, rec)
+	}
+	return r
+}
+
+func TestCompare(t *testing.T) {
+	expected := result([]string{"region", "total"},
+		[]interface{}{"EU", json.Number("12.5")},
+		[]interface{}{"US", json.Number("30")},
+		[]interface{}{nil, json.Number("1")},
+	)
+
+	tests := []struct {
+		name    string
+		actual  *client.RunSQLResult
+		match   bool
+		missing int
+		extra   int
+	}{
+		{"reordered rows", result([]string{"region", "total"},
+			[]interface{}{"US", 30.0}, []interface{}{nil, 1.0}, []interface{}{"EU", "12.50"}), true, 0, 0},
+		{"reordered columns", result([]string{"TOTAL", "region"},
+			[]interface{}{30.0, "US"}, []interface{}{1.0, nil}, []interface{}{12.5, "EU"}), true, 0, 0},
+		{"aliased columns", result([]string{"r", "sum_total"},
+			[]interface{}{"US", 30.0}, []interface{}{nil, 1.0}, []interface{}{"EU", 12.5000000001}), true, 0, 0},
+		{"wrong value", result([]string{"region", "total"},
+			[]interface{}{"US", 31.0}, []interface{}{nil, 1.0}, []interface{}{"EU", 12.5}), false, 1, 1},
+		{"missing row", result([]string{"region", "total"},
+			[]interface{}{"US", 30.0}, []interface{}{"EU", 12.5}), false, 1, 0},
+		{"duplicate row", result([]string{"region", "total"},
+			[]interface{}{"US", 30.0}, []interface{}{"US", 30.0}, []interface{}{nil, 1.0}, []interface{}{"EU", 12.5}), false, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compare(expected, tt.actual, DefaultTolerance)
+			if d.Match() != tt.match || d.MissingCount != tt.missing || d.ExtraCount != tt.extra {
+				t.Errorf("match=%v missing=%d extra=%d, want %v %d %d\n%s",
+					d.Match(), d.MissingCount, d.ExtraCount, tt.match, tt.missing, tt.extra, d)
+			}
+		})
+	}
+
+	d := Compare(expected, result([]string{"region"}, []interface{}{"EU"}), DefaultTolerance)
+	if d.Match() || !strings.Contains(d.String(), "expected 2 column(s)") {
+		t.Errorf("column mismatch diff = %q", d)
+	}
+	d = Compare(expected, result([]string{"region", "total"}, []interface{}{"EU", 12.5}), DefaultTolerance)
+	if got := d.String(); !strings.Contains(got, `- ("US", 30)`) {
+		t.Errorf("diff = %q", got)
+	}
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	a := "SELECT region,  SUM(total)\n  FROM orders\nWHERE status = 'Shipped' ;"
+	b := "select region , sum( total ) from ORDERS where status='Shipped'"
+	if NormalizeSQL(a) != NormalizeSQL(b) {
+		t.Errorf("%q != %q", NormalizeSQL(a), NormalizeSQL(b))
+	}
+	if NormalizeSQL("SELECT 'A'") == NormalizeSQL("SELECT 'a'") {
+		t.Error("string literals should keep their case")
+	}
+}
+
+func TestLoadSuite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sales.yaml")
+	os.WriteFile(path, []byte(`questions:
+  - question: Total revenue?
+    sql: SELECT SUM(total) FROM orders
+  - id: by-region
+    question: Revenue by region?
+    sql: SELECT region, SUM(total) FROM orders GROUP BY 1
+`), 0644)
+	s, err := LoadSuite(path)
+	if err != nil {
+		t.Fatalf("LoadSuite: %v", err)
+	}
+	if s.Name != "sales" || len(s.Cases) != 2 || s.Cases[0].ID != "q1" || s.Cases[1].ID != "by-region" {
+		t.Errorf("suite = %+v", s)
+	}
+
+	os.WriteFile(path, []byte("questions:\n  - question: no sql\n"), 0644)
+	if _, err := LoadSuite(path); err == nil {
+		t.Error("expected an error for a question without sql")
+	}
+}
+
+// fakeBackend answers with canned SQL and results.
+type fakeBackend struct {
+	generated map[string]string               // question -> SQL
+	results   map[string]*client.RunSQLResult // SQL -> result
+	running   atomic.Int32
+	peak      atomic.Int32
+}
+
+func (f *fakeBackend) GenerateSQLContext(ctx context.Context, req *client.GenerateSQLRequest) (*client.GenerateSQLResult, error) {
+	n := f.running.Add(1)
+	defer f.running.Add(-1)
+	for {
+		p := f.peak.Load()
+		if n <= p || f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	sql, ok := f.generated[req.Question]
+	if !ok {
+		return nil, errors.New("NO_RELEVANT_SQL")
+	}
+	return &client.GenerateSQLResult{SQL: sql}, nil
+}
+
+func (f *fakeBackend) RunSQLContext(ctx context.Context, req *client.RunSQLRequest) (*client.RunSQLResult, error) {
+	r, ok := f.results[req.SQL]
+	if !ok {
+		return nil, errors.New("syntax error")
+	}
+	return r, nil
+}
+
+func TestRun(t *testing.T) {
+	one := result([]string{"n"}, []interface{}{1.0})
+	b := &fakeBackend{
+		generated: map[string]string{
+			"exact":   "SELECT 1",
+			"equiv":   "SELECT 1.0 AS x",
+			"wrong":   "SELECT 2",
+			"invalid": "SELEC",
+		},
+		results: map[string]*client.RunSQLResult{
+			"SELECT 1":        one,
+			"select 1;":       one,
+			"SELECT 1.0 AS x": result([]string{"x"}, []interface{}{json.Number("1.0")}),
+			"SELECT 2":        result([]string{"n"}, []interface{}{2.0}),
+		},
+	}
+	suite := &Suite{Name: "demo", Cases: []Case{
+		{ID: "exact", Question: "exact", SQL: "select 1;"},
+		{ID: "equiv", Question: "equiv", SQL: "SELECT 1"},
+		{ID: "wrong", Question: "wrong", SQL: "SELECT 1"},
+		{ID: "invalid", Question: "invalid", SQL: "SELECT 1"},
+		{ID: "unanswerable", Question: "unanswerable", SQL: "SELECT 1"},
+	}}
+
+	var seen atomic.Int32
+	report, err := Run(context.Background(), b, suite, Options{Concurrency: 2, Tolerance: DefaultTolerance},
+		func(CaseResult) { seen.Add(1) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if seen.Load() != 5 || len(report.Cases) != 5 {
+		t.Fatalf("progress calls = %d, cases = %d", seen.Load(), len(report.Cases))
+	}
+	if b.peak.Load() > 2 {
+		t.Errorf("ran %d questions at once, want at most 2", b.peak.Load())
+	}
+
+	want := map[string]Status{"exact": StatusPass, "equiv": StatusPass, "wrong": StatusFail, "invalid": StatusError, "unanswerable": StatusError}
+	for i, c := range report.Cases {
+		if c.ID != suite.Cases[i].ID {
+			t.Errorf("case %d = %s, want suite order", i, c.ID)
+		}
+		if c.Status != want[c.ID] {
+			t.Errorf("%s: status %s, want %s (%s)", c.ID, c.Status, want[c.ID], c.Error)
+		}
+	}
+	if !report.Cases[0].ExactMatch || report.Cases[1].ExactMatch {
+		t.Error("exact match should compare normalized SQL")
+	}
+	s := report.Summary
+	if s.Total != 5 || s.ExactMatch != 1 || s.ExecutionMatch != 2 || s.Errors != 2 || s.ExecutionMatchRate != 0.4 {
+		t.Errorf("summary = %+v", s)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, report); err != nil {
+		t.Fatalf("WriteJUnit: %v", err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parsing JUnit XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 5 || doc.Failures != 1 || doc.Errors != 2 {
+		t.Errorf("junit totals = %d/%d/%d", doc.Tests, doc.Failures, doc.Errors)
+	}
+	if f := doc.Suites[0].Cases[2].Failure; f == nil || !strings.Contains(f.Body, "- (1)") {
+		t.Errorf("wrong case failure = %+v", f)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	suite := &Suite{Name: "demo", Cases: []Case{{ID: "a", Question: "a", SQL: "SELECT 1"}}}
+	if _, err := Run(ctx, &fakeBackend{}, suite, Options{Concurrency: 1}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
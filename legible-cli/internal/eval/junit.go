@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JUnit XML elements, in the subset CI systems read.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut *junitText    `xml:"system-out"`
+}
+
+// junitText is element content written as CDATA, keeping line breaks
+// readable in CI logs.
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",cdata"`
+}
+
+// WriteJUnit writes the report as JUnit XML: one test case per question,
+// failing when the result sets differ and erroring when SQL could not be
+// generated or run.
+func WriteJUnit(w io.Writer, r *Report) error {
+	suite := junitSuite{
+		Name:      r.Suite,
+		Tests:     len(r.Cases),
+		Time:      seconds(r.Seconds),
+		Timestamp: r.StartedAt.Format("2006-01-02T15:04:05"),
+	}
+	for _, c := range r.Cases {
+		tc := junitCase{
+			Name:      c.ID,
+			Classname: r.Suite,
+			Time:      seconds(c.Seconds),
+			SystemOut: &junitText{caseDetails(c)},
+		}
+		switch c.Status {
+		case StatusFail:
+			suite.Failures++
+			tc.Failure = &junitProblem{Message: "result sets differ", Body: c.Diff}
+		case StatusError:
+			suite.Errors++
+			tc.Error = &junitProblem{Message: c.Error, Body: c.Error}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	doc := junitSuites{
+		Name:     "legible eval",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("writing JUnit XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// caseDetails is the question and both queries, for the CI log.
+func caseDetails(c CaseResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n\nExpected SQL:\n%s\n", c.Question, c.ExpectedSQL)
+	if c.GeneratedSQL != "" {
+		fmt.Fprintf(&b, "\nGenerated SQL:\n%s\n", c.GeneratedSQL)
+	}
+	if c.Truncated {
+		b.WriteString("\nResults were truncated at the row limit.\n")
+	}
+	return b.String()
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
@@ -0,0 +1,96 @@
+// Package eval measures text-to-SQL accuracy: it asks a suite of questions,
+// runs the generated SQL next to a known-good expected SQL, and compares the
+// result sets.
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a named set of evaluation cases.
+type Suite struct {
+	Name  string `yaml:"name"`
+	Cases []Case `yaml:"questions"`
+}
+
+// Case is one question and the SQL that answers it correctly.
+type Case struct {
+	ID       string `yaml:"id,omitempty"`
+	Question string `yaml:"question"`
+	SQL      string `yaml:"sql"`
+}
+
+// LoadSuite reads a suite file:
+//
+//	name: ecommerce
+//	questions:
+//	  - id: total-revenue
+//	    question: What is the total revenue?
+//	    sql: SELECT SUM(price) FROM orders
+//
+// Case IDs are optional and default to q1, q2, ...
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading suite: %w", err)
+	}
+	var s Suite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if s.Name == "" {
+		base := filepath.Base(path)
+		s.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// SuiteFromSqlPairs builds a suite from a project's SQL pairs.
+func SuiteFromSqlPairs(name string, pairs []client.SqlPair) (*Suite, error) {
+	s := &Suite{Name: name}
+	for _, p := range pairs {
+		s.Cases = append(s.Cases, Case{
+			ID:       fmt.Sprintf("sql-pair-%d", p.ID),
+			Question: p.Question,
+			SQL:      p.SQL,
+		})
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validate checks every case is complete and fills in missing IDs.
+func (s *Suite) validate() error {
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("suite has no questions")
+	}
+	seen := map[string]bool{}
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("q%d", i+1)
+		}
+		if strings.TrimSpace(c.Question) == "" {
+			return fmt.Errorf("question %s has no question text", c.ID)
+		}
+		if strings.TrimSpace(c.SQL) == "" {
+			return fmt.Errorf("question %s has no expected sql", c.ID)
+		}
+		if seen[c.ID] {
+			return fmt.Errorf("duplicate question id %q", c.ID)
+		}
+		seen[c.ID] = true
+	}
+	return nil
+}
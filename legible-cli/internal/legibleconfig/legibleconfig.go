@@ -1,20 +1,53 @@
 package legibleconfig
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
 	"gopkg.in/yaml.v3"
 )
 
 const FileName = ".legibleconfig"
 
+// SnapshotFileName is the dbt MDL of the last sync, stored next to
+// .legibleconfig so that updates can tell dbt changes from UI changes.
+const SnapshotFileName = ".legible-snapshot.json"
+
 // Config represents the .legibleconfig file stored in a dbt project directory.
+//
+// The top level links one Legible project. Targets link further projects,
+// e.g. one per domain with its own subset of models; each target is a
+// Config of its own, without targets.
 type Config struct {
-	WrenProject WrenProject `yaml:"wren_project"`
+	WrenProject WrenProject `yaml:"wren_project,omitempty"`
 	Filter      Filter      `yaml:"filter,omitempty"`
+	// Profile and DbtTarget are the dbt profile and target to convert with,
+	// and IncludeStagingModels, IncludeSources, IncludeSeeds and
+	// IncludeSnapshots include those resources, unless the corresponding
+	// flags are given.
+	Profile              string `yaml:"profile,omitempty"`
+	DbtTarget            string `yaml:"dbt_target,omitempty"`
+	IncludeStagingModels bool   `yaml:"include_staging_models,omitempty"`
+	IncludeSources       bool   `yaml:"include_sources,omitempty"`
+	IncludeSeeds         bool   `yaml:"include_seeds,omitempty"`
+	IncludeSnapshots     bool   `yaml:"include_snapshots,omitempty"`
+	// MetadataMapping is the path, relative to the dbt project, of a YAML
+	// file mapping dbt meta, config, tags and docs into MDL properties.
+	MetadataMapping string `yaml:"metadata_mapping,omitempty"`
+	// PreferDBT resolves conflicts with changes made in the UI in favour of
+	// dbt, as --prefer-dbt does.
+	PreferDBT bool `yaml:"prefer_dbt,omitempty"`
+	// InferredRelations names the inferred relationships that were accepted
+	// with --infer-relations; they are inferred and kept again on update.
+	InferredRelations []string `yaml:"inferred_relations,omitempty"`
+	// Targets are the further linked projects by name.
+	Targets map[string]*Config `yaml:"targets,omitempty"`
 }
 
 // WrenProject identifies the linked Legible/Wren AI project.
@@ -23,10 +56,13 @@ type WrenProject struct {
 	LastSynced string `yaml:"last_synced,omitempty"`
 }
 
-// Filter defines include/exclude regex patterns for model selection.
+// Filter defines include/exclude regex patterns and dbt node selectors
+// (e.g. "tag:finance", "+orders") for model selection.
 type Filter struct {
-	Include []string `yaml:"include,omitempty"`
-	Exclude []string `yaml:"exclude,omitempty"`
+	Include       []string `yaml:"include,omitempty"`
+	Exclude       []string `yaml:"exclude,omitempty"`
+	Select        []string `yaml:"select,omitempty"`
+	ExcludeSelect []string `yaml:"exclude_select,omitempty"`
 }
 
 // Load reads .legibleconfig from the given directory.
@@ -44,6 +80,14 @@ func Load(dir string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
+	for name, target := range cfg.Targets {
+		switch {
+		case target == nil || target.WrenProject.ID == "":
+			return nil, fmt.Errorf("parsing %s: target %q has no wren_project.id", path, name)
+		case len(target.Targets) > 0:
+			return nil, fmt.Errorf("parsing %s: target %q cannot have targets", path, name)
+		}
+	}
 	return &cfg, nil
 }
 
@@ -61,6 +105,48 @@ func Save(dir string, cfg *Config) error {
 	return nil
 }
 
+// SnapshotFile returns the name of a target's MDL snapshot file; the
+// top-level project ("") uses SnapshotFileName.
+func SnapshotFile(target string) string {
+	if target == "" {
+		return SnapshotFileName
+	}
+	return strings.TrimSuffix(SnapshotFileName, ".json") + "." + target + ".json"
+}
+
+// LoadSnapshot reads a target's MDL snapshot from the given directory. It
+// returns nil if there is none, e.g. for projects created by older versions.
+func LoadSnapshot(dir, target string) (*dbt.LegibleMDLManifest, error) {
+	path := filepath.Join(dir, SnapshotFile(target))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var mdl dbt.LegibleMDLManifest
+	if err := json.Unmarshal(data, &mdl); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &mdl, nil
+}
+
+// SaveSnapshot writes a target's MDL snapshot to the given directory.
+func SaveSnapshot(dir, target string, mdl *dbt.LegibleMDLManifest) error {
+	path := filepath.Join(dir, SnapshotFile(target))
+	data, err := json.MarshalIndent(mdl, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling MDL snapshot: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
 // Exists returns true if .legibleconfig exists in the given directory.
 func Exists(dir string) bool {
 	path := filepath.Join(dir, FileName)
@@ -87,3 +173,41 @@ func NewConfig(projectID string, include, exclude []string) *Config {
 func (c *Config) TouchSynced() {
 	c.WrenProject.LastSynced = time.Now().UTC().Format(time.RFC3339)
 }
+
+// TargetNames returns the names of the targets, sorted.
+func (c *Config) TargetNames() []string {
+	names := make([]string, 0, len(c.Targets))
+	for name := range c.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Target returns the named target, or the top-level project for "".
+func (c *Config) Target(name string) (*Config, error) {
+	if name == "" {
+		if c.WrenProject.ID == "" {
+			return nil, fmt.Errorf(".legibleconfig links no top-level project — use --target-project with one of its targets (%s) or --all", strings.Join(c.TargetNames(), ", "))
+		}
+		return c, nil
+	}
+	target, ok := c.Targets[name]
+	if !ok {
+		if len(c.Targets) == 0 {
+			return nil, fmt.Errorf("target %q not found — .legibleconfig has no targets", name)
+		}
+		return nil, fmt.Errorf("target %q not found — .legibleconfig has targets %s", name, strings.Join(c.TargetNames(), ", "))
+	}
+	return target, nil
+}
+
+// AllTargets returns the names of every linked project: "" for the
+// top-level project if it links one, then the targets.
+func (c *Config) AllTargets() []string {
+	var names []string
+	if c.WrenProject.ID != "" {
+		names = append(names, "")
+	}
+	return append(names, c.TargetNames()...)
+}
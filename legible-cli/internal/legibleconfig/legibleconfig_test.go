@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
 )
 
 func TestLoad_Valid(t *testing.T) {
@@ -67,9 +69,14 @@ func TestSaveAndLoad_Roundtrip(t *testing.T) {
 			LastSynced: "2025-06-15T12:00:00Z",
 		},
 		Filter: Filter{
-			Include: []string{"a_.*", "b_.*"},
-			Exclude: []string{"tmp_.*"},
+			Include:       []string{"a_.*", "b_.*"},
+			Exclude:       []string{"tmp_.*"},
+			Select:        []string{"tag:finance +orders"},
+			ExcludeSelect: []string{"path:models/legacy"},
 		},
+		InferredRelations: []string{"payments_to_customers_by_customer_id"},
+		IncludeSources:    true,
+		IncludeSnapshots:  true,
 	}
 
 	if err := Save(dir, cfg); err != nil {
@@ -92,6 +99,18 @@ func TestSaveAndLoad_Roundtrip(t *testing.T) {
 	if len(loaded.Filter.Exclude) != 1 {
 		t.Errorf("Exclude len = %d, want 1", len(loaded.Filter.Exclude))
 	}
+	if len(loaded.Filter.Select) != 1 || loaded.Filter.Select[0] != "tag:finance +orders" {
+		t.Errorf("Select = %v, want [tag:finance +orders]", loaded.Filter.Select)
+	}
+	if len(loaded.Filter.ExcludeSelect) != 1 || loaded.Filter.ExcludeSelect[0] != "path:models/legacy" {
+		t.Errorf("ExcludeSelect = %v, want [path:models/legacy]", loaded.Filter.ExcludeSelect)
+	}
+	if len(loaded.InferredRelations) != 1 || loaded.InferredRelations[0] != "payments_to_customers_by_customer_id" {
+		t.Errorf("InferredRelations = %v, want [payments_to_customers_by_customer_id]", loaded.InferredRelations)
+	}
+	if !loaded.IncludeSources || loaded.IncludeSeeds || !loaded.IncludeSnapshots {
+		t.Errorf("IncludeSources, IncludeSeeds, IncludeSnapshots = %v, %v, %v, want true, false, true", loaded.IncludeSources, loaded.IncludeSeeds, loaded.IncludeSnapshots)
+	}
 }
 
 func TestSave_FilePermissions(t *testing.T) {
@@ -175,3 +194,114 @@ func TestTouchSynced(t *testing.T) {
 		t.Errorf("TouchSynced timestamp %v not in [%v, %v]", ts, before, after)
 	}
 }
+
+func TestSnapshot_Roundtrip(t *testing.T) {
+	dir := t.TempDir()
+	snapshot, err := LoadSnapshot(dir, "")
+	if err != nil || snapshot != nil {
+		t.Fatalf("LoadSnapshot() = %v, %v, want nil, nil without a snapshot", snapshot, err)
+	}
+
+	mdl := &dbt.LegibleMDLManifest{
+		Models: []dbt.LegibleModel{{Name: "orders", Columns: []dbt.LegibleColumn{{Name: "id", Type: "integer"}}}},
+	}
+	if err := SaveSnapshot(dir, "", mdl); err != nil {
+		t.Fatalf("SaveSnapshot() error: %v", err)
+	}
+	snapshot, err = LoadSnapshot(dir, "")
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	if len(snapshot.Models) != 1 || snapshot.Models[0].Columns[0].Type != "integer" {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+}
+
+func TestSnapshot_Targets(t *testing.T) {
+	dir := t.TempDir()
+	mdl := &dbt.LegibleMDLManifest{Models: []dbt.LegibleModel{{Name: "orders"}}}
+	if err := SaveSnapshot(dir, "finance", mdl); err != nil {
+		t.Fatalf("SaveSnapshot() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".legible-snapshot.finance.json")); err != nil {
+		t.Errorf("finance snapshot not written: %v", err)
+	}
+	if snapshot, err := LoadSnapshot(dir, ""); err != nil || snapshot != nil {
+		t.Errorf("LoadSnapshot(\"\") = %v, %v, want no top-level snapshot", snapshot, err)
+	}
+	if snapshot, err := LoadSnapshot(dir, "finance"); err != nil || len(snapshot.Models) != 1 {
+		t.Errorf("LoadSnapshot(finance) = %v, %v", snapshot, err)
+	}
+}
+
+func TestLoad_Targets(t *testing.T) {
+	dir := t.TempDir()
+	content := `targets:
+  marketing:
+    wren_project:
+      id: "8"
+    filter:
+      select:
+        - "tag:marketing"
+  finance:
+    wren_project:
+      id: "7"
+    profile: analytics
+    dbt_target: prod
+    include_staging_models: true
+`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := cfg.AllTargets(); len(got) != 2 || got[0] != "finance" || got[1] != "marketing" {
+		t.Errorf("AllTargets() = %v, want [finance marketing]", got)
+	}
+	finance, err := cfg.Target("finance")
+	if err != nil {
+		t.Fatalf("Target(finance) error: %v", err)
+	}
+	if finance.WrenProject.ID != "7" || finance.Profile != "analytics" || finance.DbtTarget != "prod" || !finance.IncludeStagingModels {
+		t.Errorf("finance = %+v", finance)
+	}
+	if _, err := cfg.Target(""); err == nil {
+		t.Error("Target(\"\") should fail without a top-level project")
+	}
+	if _, err := cfg.Target("sales"); err == nil {
+		t.Error("Target(sales) should fail for an unknown target")
+	}
+
+	// Changes to a target are saved with the file
+	finance.TouchSynced()
+	if err := Save(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Targets["finance"].WrenProject.LastSynced == "" || loaded.WrenProject.ID != "" {
+		t.Errorf("loaded = %+v, finance = %+v", loaded, loaded.Targets["finance"])
+	}
+}
+
+func TestLoad_InvalidTargets(t *testing.T) {
+	for name, content := range map[string]string{
+		"no project id": "targets:\n  finance:\n    profile: analytics\n",
+		"nested":        "targets:\n  finance:\n    wren_project:\n      id: \"7\"\n    targets:\n      eu:\n        wren_project:\n          id: \"9\"\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
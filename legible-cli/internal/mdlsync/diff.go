@@ -0,0 +1,281 @@
+// Package mdlsync compares MDL manifests and merges the MDL converted from
+// a dbt project into a project that may have been edited in the UI since it
+// was last synced.
+package mdlsync
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+)
+
+// Kinds of objects a Change applies to.
+const (
+	KindModel           = "model"
+	KindColumn          = "column"
+	KindCalculatedField = "calculatedField"
+	KindRelationship    = "relationship"
+)
+
+// Actions of a Change.
+const (
+	ActionAdd    = "add"
+	ActionRemove = "remove"
+	ActionChange = "change"
+)
+
+// Change is a difference between two manifests.
+type Change struct {
+	Kind   string `json:"kind"`
+	Action string `json:"action"`
+	// Model is the model the object belongs to; it is empty for relationships.
+	Model string `json:"model,omitempty"`
+	// Name is the column or calculated field name, or the relationship's
+	// join condition, e.g. orders.customer_id = customers.id.
+	Name string `json:"name,omitempty"`
+	// Field is the attribute that changed: type, description, displayName,
+	// primaryKey, expression or joinType.
+	Field string `json:"field,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// Object returns the name of the changed object, e.g. orders.customer_id.
+func (c Change) Object() string {
+	switch {
+	case c.Model == "":
+		return c.Name
+	case c.Name == "":
+		return c.Model
+	}
+	return c.Model + "." + c.Name
+}
+
+// Detail describes an attribute change, e.g. "type: integer → bigint".
+func (c Change) Detail() string {
+	if c.Action != ActionChange {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s → %s", c.Field, quoteEmpty(c.From), quoteEmpty(c.To))
+}
+
+func quoteEmpty(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if len(s) > 40 {
+		return s[:37] + "..."
+	}
+	return s
+}
+
+// key identifies an object, or one of its attributes when field is set.
+type key struct {
+	kind, model, name, field string
+}
+
+func (k key) parent() key {
+	return key{kind: k.kind, model: k.model, name: k.name}
+}
+
+var kindOrder = map[string]int{KindModel: 0, KindColumn: 1, KindCalculatedField: 2, KindRelationship: 3}
+
+func (k key) less(o key) bool {
+	if k.kind != o.kind {
+		return kindOrder[k.kind] < kindOrder[o.kind]
+	}
+	if k.model != o.model {
+		return k.model < o.model
+	}
+	if k.name != o.name {
+		return k.name < o.name
+	}
+	return k.field < o.field
+}
+
+// flatManifest is a manifest as a map of the objects and attributes it
+// defines; an object maps to "" and an empty attribute is left out.
+type flatManifest struct {
+	values map[key]string
+	// models and relationships keep the objects they came from.
+	models        map[string]*dbt.LegibleModel
+	relationships map[string]*dbt.Relationship
+	// endpoints are the model.column pairs a relationship joins.
+	endpoints map[string][2][2]string
+	// modelOrder and relationshipOrder are the manifest's order.
+	modelOrder        []string
+	relationshipOrder []string
+}
+
+func flatten(m *dbt.LegibleMDLManifest) *flatManifest {
+	f := &flatManifest{
+		values:        make(map[key]string),
+		models:        make(map[string]*dbt.LegibleModel),
+		relationships: make(map[string]*dbt.Relationship),
+		endpoints:     make(map[string][2][2]string),
+	}
+	if m == nil {
+		return f
+	}
+	set := func(k key, value string) {
+		if value != "" {
+			f.values[k] = value
+		}
+	}
+	for i := range m.Models {
+		model := &m.Models[i]
+		f.models[model.Name] = model
+		f.modelOrder = append(f.modelOrder, model.Name)
+		f.values[key{kind: KindModel, model: model.Name}] = ""
+		set(key{KindModel, model.Name, "", "description"}, model.Properties["description"])
+		set(key{KindModel, model.Name, "", "displayName"}, model.Properties["displayName"])
+		set(key{KindModel, model.Name, "", "primaryKey"}, model.PrimaryKey)
+		for j := range model.Columns {
+			col := &model.Columns[j]
+			kind := KindColumn
+			if col.IsCalculated {
+				kind = KindCalculatedField
+			}
+			k := key{kind: kind, model: model.Name, name: col.Name}
+			f.values[k] = ""
+			set(key{kind, model.Name, col.Name, "type"}, col.Type)
+			set(key{kind, model.Name, col.Name, "description"}, col.Properties["description"])
+			set(key{kind, model.Name, col.Name, "displayName"}, col.DisplayName)
+			if col.Expression != nil {
+				set(key{kind, model.Name, col.Name, "expression"}, *col.Expression)
+			}
+		}
+	}
+	for i := range m.Relationships {
+		rel := &m.Relationships[i]
+		name, endpoints := relationshipKey(*rel)
+		f.relationships[name] = rel
+		f.relationshipOrder = append(f.relationshipOrder, name)
+		f.endpoints[name] = endpoints
+		f.values[key{kind: KindRelationship, name: name}] = ""
+		set(key{KindRelationship, "", name, "joinType"}, OrientJoinType(*rel, rel.JoinType))
+	}
+	return f
+}
+
+// conditionRegex parses a join condition on one column of each model,
+// quoted or not, e.g. "orders"."customer_id" = "customers"."id".
+var conditionRegex = regexp.MustCompile(`^\s*"?([^".\s]+)"?\s*\.\s*"?([^".\s]+)"?\s*=\s*"?([^".\s]+)"?\s*\.\s*"?([^".\s]+)"?\s*$`)
+
+// relationshipKey identifies a relationship by what it joins rather than by
+// its name, which differs between dbt and the UI. The two sides are sorted,
+// so the key is the same whichever way round the condition is written.
+func relationshipKey(rel dbt.Relationship) (string, [2][2]string) {
+	parts := conditionRegex.FindStringSubmatch(rel.Condition)
+	if parts == nil {
+		return rel.Name, [2][2]string{}
+	}
+	a, b := [2]string{parts[1], parts[2]}, [2]string{parts[3], parts[4]}
+	if !sidesOrdered(a, b) {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%s.%s = %s.%s", a[0], a[1], b[0], b[1]), [2][2]string{a, b}
+}
+
+func sidesOrdered(a, b [2]string) bool {
+	if a[0] != b[0] {
+		return a[0] < b[0]
+	}
+	return a[1] <= b[1]
+}
+
+// OrientJoinType converts between a relationship's join type and the join
+// type of its key, whose sides are sorted: if the relationship's condition
+// is written the other way round, ONE_TO_MANY and MANY_TO_ONE swap.
+func OrientJoinType(rel dbt.Relationship, joinType string) string {
+	parts := conditionRegex.FindStringSubmatch(rel.Condition)
+	if parts == nil || sidesOrdered([2]string{parts[1], parts[2]}, [2]string{parts[3], parts[4]}) {
+		return joinType
+	}
+	switch joinType {
+	case "ONE_TO_MANY":
+		return "MANY_TO_ONE"
+	case "MANY_TO_ONE":
+		return "ONE_TO_MANY"
+	}
+	return joinType
+}
+
+// RelationshipKey returns the name that Changes use for a relationship.
+func RelationshipKey(rel dbt.Relationship) string {
+	name, _ := relationshipKey(rel)
+	return name
+}
+
+// Compare returns the changes that turn from into to: added and removed
+// models, columns, calculated fields and relationships, and changed types,
+// descriptions, display names, primary keys, expressions and join types.
+// Objects inside an added or removed model are not listed separately.
+func Compare(from, to *dbt.LegibleMDLManifest) []Change {
+	return compareFlat(flatten(from), flatten(to))
+}
+
+func compareFlat(from, to *flatManifest) []Change {
+	keys := make(map[key]bool)
+	for k := range from.values {
+		keys[k] = true
+	}
+	for k := range to.values {
+		keys[k] = true
+	}
+	sorted := make([]key, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].less(sorted[j]) })
+
+	var changes []Change
+	for _, k := range sorted {
+		a, inFrom := from.values[k]
+		b, inTo := to.values[k]
+		if k.kind != KindModel && k.kind != KindRelationship {
+			// Skip objects of a model that was added or removed.
+			modelKey := key{kind: KindModel, model: k.model}
+			if _, ok := from.values[modelKey]; !ok {
+				continue
+			}
+			if _, ok := to.values[modelKey]; !ok {
+				continue
+			}
+		}
+		change := Change{Kind: k.kind, Model: k.model, Name: k.name}
+		if k.field == "" {
+			switch {
+			case inFrom && !inTo:
+				change.Action = ActionRemove
+			case !inFrom && inTo:
+				change.Action = ActionAdd
+			default:
+				continue
+			}
+			changes = append(changes, change)
+			continue
+		}
+		// Attributes of an added or removed object are part of that change.
+		_, parentFrom := from.values[k.parent()]
+		_, parentTo := to.values[k.parent()]
+		if !parentFrom || !parentTo || sameValue(k, a, b) {
+			continue
+		}
+		change.Action, change.Field, change.From, change.To = ActionChange, k.field, a, b
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// sameValue compares attribute values; types are compared case-insensitively,
+// since databases and dbt catalogs spell them differently.
+func sameValue(k key, a, b string) bool {
+	if k.field == "type" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,91 @@
+package mdlsync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+)
+
+func strPtr(s string) *string { return &s }
+
+// testManifest is the dbt MDL of a small shop that the tests change.
+func testManifest() *dbt.LegibleMDLManifest {
+	return &dbt.LegibleMDLManifest{
+		Models: []dbt.LegibleModel{
+			{
+				Name:       "orders",
+				PrimaryKey: "order_id",
+				Properties: map[string]string{"description": "One row per order."},
+				Columns: []dbt.LegibleColumn{
+					{Name: "order_id", Type: "integer"},
+					{Name: "customer_id", Type: "integer"},
+					{Name: "amount", Type: "numeric", Properties: map[string]string{"description": "Order total."}},
+				},
+			},
+			{
+				Name:       "customers",
+				PrimaryKey: "id",
+				Columns: []dbt.LegibleColumn{
+					{Name: "id", Type: "integer"},
+					{Name: "name", Type: "varchar"},
+				},
+			},
+		},
+		Relationships: []dbt.Relationship{
+			{Name: "orders_customers", Models: []string{"orders", "customers"}, JoinType: "MANY_TO_ONE", Condition: `"orders"."customer_id" = "customers"."id"`},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	from := testManifest()
+	to := testManifest()
+	to.Models[0].Columns[1].Type = "BIGINT" // only the case differs
+	to.Models[0].Columns[2].Type = "decimal"
+	to.Models[0].Columns[2].Properties["description"] = "Total in USD."
+	to.Models[0].Columns = append(to.Models[0].Columns[:1], to.Models[0].Columns[2:]...)
+	to.Models[0].Columns = append(to.Models[0].Columns,
+		dbt.LegibleColumn{Name: "ordered_at", Type: "date"},
+		dbt.LegibleColumn{Name: "amount_usd", IsCalculated: true, Expression: strPtr("amount * 1.1")},
+	)
+	to.Models = to.Models[:1]
+	to.Models = append(to.Models, dbt.LegibleModel{Name: "payments", Columns: []dbt.LegibleColumn{{Name: "id"}}})
+	// The same relationship written the other way round is unchanged.
+	to.Relationships[0] = dbt.Relationship{Name: "customer_orders", JoinType: "ONE_TO_MANY", Condition: `customers.id = orders.customer_id`}
+
+	want := []Change{
+		{Kind: KindModel, Action: ActionRemove, Model: "customers"},
+		{Kind: KindModel, Action: ActionAdd, Model: "payments"},
+		{Kind: KindColumn, Action: ActionChange, Model: "orders", Name: "amount", Field: "description", From: "Order total.", To: "Total in USD."},
+		{Kind: KindColumn, Action: ActionChange, Model: "orders", Name: "amount", Field: "type", From: "numeric", To: "decimal"},
+		{Kind: KindColumn, Action: ActionRemove, Model: "orders", Name: "customer_id"},
+		{Kind: KindColumn, Action: ActionAdd, Model: "orders", Name: "ordered_at"},
+		{Kind: KindCalculatedField, Action: ActionAdd, Model: "orders", Name: "amount_usd"},
+	}
+	if got := Compare(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// A join type change is reported in the key's orientation.
+	to = testManifest()
+	to.Relationships[0].JoinType = "ONE_TO_ONE"
+	want = []Change{{Kind: KindRelationship, Action: ActionChange, Name: "customers.id = orders.customer_id", Field: "joinType", From: "ONE_TO_MANY", To: "ONE_TO_ONE"}}
+	if got := Compare(testManifest(), to); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOrientJoinType(t *testing.T) {
+	rel := dbt.Relationship{Condition: `"orders"."customer_id" = "customers"."id"`}
+	if got := OrientJoinType(rel, "MANY_TO_ONE"); got != "ONE_TO_MANY" {
+		t.Errorf("OrientJoinType() = %q, want ONE_TO_MANY", got)
+	}
+	rel.Condition = `"customers"."id" = "orders"."customer_id"`
+	if got := OrientJoinType(rel, "ONE_TO_MANY"); got != "ONE_TO_MANY" {
+		t.Errorf("OrientJoinType() = %q, want ONE_TO_MANY", got)
+	}
+	if got := RelationshipKey(rel); got != "customers.id = orders.customer_id" {
+		t.Errorf("RelationshipKey() = %q", got)
+	}
+}
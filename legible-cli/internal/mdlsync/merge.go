@@ -0,0 +1,284 @@
+package mdlsync
+
+import (
+	"sort"
+
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+)
+
+// Conflict is an attribute that was changed both in the project and in the
+// dbt project since the last sync.
+type Conflict struct {
+	Kind   string `json:"kind"`
+	Model  string `json:"model,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Field  string `json:"field"`
+	Base   string `json:"base"`
+	Server string `json:"server"`
+	DBT    string `json:"dbt"`
+	// Kept is "server" or "dbt", whichever value the merge kept.
+	Kept string `json:"kept"`
+}
+
+// Object returns the name of the conflicting object, e.g. orders.customer_id.
+func (c Conflict) Object() string {
+	return Change{Model: c.Model, Name: c.Name}.Object()
+}
+
+// MergeResult is the outcome of a three-way merge.
+type MergeResult struct {
+	// Manifest is the merged MDL that the project should end up with.
+	Manifest *dbt.LegibleMDLManifest
+	// Changes turn the project into Manifest.
+	Changes []Change
+	// Kept are the changes between the dbt MDL and Manifest: the edits made
+	// in the project that the merge keeps.
+	Kept      []Change
+	Conflicts []Conflict
+}
+
+// Merge merges the MDL converted from dbt (incoming) into the project's
+// current MDL (server), using the dbt MDL of the last sync (base) to tell
+// which side changed each model, column, calculated field and relationship
+// and each of their attributes. A change on one side is kept; a change on
+// both sides is a conflict, resolved for the server unless preferDBT is set.
+// Column types always come from dbt, since they describe the tables.
+//
+// Without a base, dbt values replace the project's, and calculated fields,
+// relationships and attributes that dbt does not set are kept.
+func Merge(base, server, incoming *dbt.LegibleMDLManifest, preferDBT bool) *MergeResult {
+	s, in := flatten(server), flatten(incoming)
+	var b *flatManifest
+	if base != nil {
+		b = flatten(base)
+	} else {
+		b = assumedBase(s, in)
+	}
+
+	keys := make(map[key]bool)
+	for _, f := range []*flatManifest{b, s, in} {
+		for k := range f.values {
+			keys[k] = true
+		}
+	}
+
+	merged := make(map[key]string)
+	var conflicts []Conflict
+	for k := range keys {
+		bv, bok := b.values[k]
+		sv, sok := s.values[k]
+		iv, iok := in.values[k]
+		switch {
+		case k.kind == KindColumn && k.field == "type":
+			if iok {
+				merged[k] = iv
+			} else if sok {
+				merged[k] = sv
+			}
+		case sok == iok && sameValue(k, sv, iv):
+			if sok {
+				merged[k] = sv
+			}
+		case bok == sok && sameValue(k, bv, sv):
+			if iok {
+				merged[k] = iv
+			}
+		case bok == iok && sameValue(k, bv, iv):
+			if sok {
+				merged[k] = sv
+			}
+		default:
+			conflict := Conflict{Kind: k.kind, Model: k.model, Name: k.name, Field: k.field, Base: bv, Server: sv, DBT: iv, Kept: "server"}
+			if preferDBT {
+				conflict.Kept = "dbt"
+				if iok {
+					merged[k] = iv
+				}
+			} else if sok {
+				merged[k] = sv
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	prune(merged, s, in)
+
+	var kept []Conflict
+	for _, c := range conflicts {
+		if _, ok := merged[key{kind: c.Kind, model: c.Model, name: c.Name}]; ok {
+			kept = append(kept, c)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		return key{kept[i].Kind, kept[i].Model, kept[i].Name, kept[i].Field}.less(key{kept[j].Kind, kept[j].Model, kept[j].Name, kept[j].Field})
+	})
+
+	manifest := build(merged, s, in, incoming)
+	out := flatten(manifest)
+	return &MergeResult{
+		Manifest:  manifest,
+		Changes:   compareFlat(s, out),
+		Kept:      compareFlat(in, out),
+		Conflicts: kept,
+	}
+}
+
+// assumedBase stands in for the last synced MDL when there is none: the
+// project's models, columns and the attributes that dbt sets.
+func assumedBase(server, incoming *flatManifest) *flatManifest {
+	b := &flatManifest{values: make(map[key]string)}
+	for k, v := range server.values {
+		if k.kind == KindCalculatedField || k.kind == KindRelationship {
+			continue
+		}
+		if _, ok := incoming.values[k]; k.field == "" || ok {
+			b.values[k] = v
+		}
+	}
+	return b
+}
+
+// prune removes the objects of removed models, relationships that join a
+// removed model or column, and the attributes of removed objects.
+func prune(merged map[key]string, sources ...*flatManifest) {
+	for k := range merged {
+		if k.field == "" && (k.kind == KindColumn || k.kind == KindCalculatedField) {
+			if _, ok := merged[key{kind: KindModel, model: k.model}]; !ok {
+				delete(merged, k)
+			}
+		}
+	}
+	for k := range merged {
+		if k.kind != KindRelationship || k.field != "" {
+			continue
+		}
+		for _, f := range sources {
+			endpoints, ok := f.endpoints[k.name]
+			if !ok {
+				continue
+			}
+			if endpoints[0][0] != "" && (!hasColumn(merged, endpoints[0][0], endpoints[0][1]) || !hasColumn(merged, endpoints[1][0], endpoints[1][1])) {
+				delete(merged, k)
+			}
+			break
+		}
+	}
+	for k := range merged {
+		if k.field != "" {
+			if _, ok := merged[k.parent()]; !ok {
+				delete(merged, k)
+			}
+		}
+	}
+}
+
+func hasColumn(merged map[key]string, model, column string) bool {
+	if _, ok := merged[key{kind: KindModel, model: model}]; !ok {
+		return false
+	}
+	_, isColumn := merged[key{kind: KindColumn, model: model, name: column}]
+	_, isCalculated := merged[key{kind: KindCalculatedField, model: model, name: column}]
+	return isColumn || isCalculated
+}
+
+// build turns the merged values back into a manifest, taking everything
+// the values do not cover from the dbt or server object, in that order.
+func build(merged map[key]string, server, incoming *flatManifest, manifest *dbt.LegibleMDLManifest) *dbt.LegibleMDLManifest {
+	out := &dbt.LegibleMDLManifest{Relationships: []dbt.Relationship{}, Views: []dbt.View{}}
+	if manifest != nil {
+		out.JsonSchema, out.Catalog, out.Schema, out.DataSource = manifest.JsonSchema, manifest.Catalog, manifest.Schema, manifest.DataSource
+		out.EnumDefinitions, out.Metrics, out.Views = manifest.EnumDefinitions, manifest.Metrics, manifest.Views
+	}
+
+	seenModels := make(map[string]bool)
+	for _, source := range []*flatManifest{incoming, server} {
+		for _, name := range source.modelOrder {
+			if _, ok := merged[key{kind: KindModel, model: name}]; !ok || seenModels[name] {
+				continue
+			}
+			seenModels[name] = true
+			out.Models = append(out.Models, buildModel(name, merged, incoming, server))
+		}
+	}
+
+	seenRels := make(map[string]bool)
+	for _, source := range []*flatManifest{incoming, server} {
+		for _, name := range source.relationshipOrder {
+			if _, ok := merged[key{kind: KindRelationship, name: name}]; !ok || seenRels[name] {
+				continue
+			}
+			seenRels[name] = true
+			rel := *source.relationships[name]
+			rel.JoinType = OrientJoinType(rel, merged[key{KindRelationship, "", name, "joinType"}])
+			out.Relationships = append(out.Relationships, rel)
+		}
+	}
+	return out
+}
+
+func buildModel(name string, merged map[key]string, incoming, server *flatManifest) dbt.LegibleModel {
+	template := incoming.models[name]
+	if template == nil {
+		template = server.models[name]
+	}
+	model := *template
+	model.Properties = copyProperties(template.Properties, "description", "displayName")
+	setProperty(&model.Properties, "description", merged[key{KindModel, name, "", "description"}])
+	setProperty(&model.Properties, "displayName", merged[key{KindModel, name, "", "displayName"}])
+	model.PrimaryKey = merged[key{KindModel, name, "", "primaryKey"}]
+
+	model.Columns = nil
+	seen := make(map[key]bool)
+	for _, source := range []*dbt.LegibleModel{incoming.models[name], server.models[name]} {
+		if source == nil {
+			continue
+		}
+		for _, col := range source.Columns {
+			kind := KindColumn
+			if col.IsCalculated {
+				kind = KindCalculatedField
+			}
+			k := key{kind: kind, model: name, name: col.Name}
+			if _, ok := merged[k]; !ok || seen[k] {
+				continue
+			}
+			seen[k] = true
+			col.Properties = copyProperties(col.Properties, "description")
+			setProperty(&col.Properties, "description", merged[key{kind, name, col.Name, "description"}])
+			col.Type = merged[key{kind, name, col.Name, "type"}]
+			col.DisplayName = merged[key{kind, name, col.Name, "displayName"}]
+			if expression, ok := merged[key{kind, name, col.Name, "expression"}]; ok {
+				col.Expression = &expression
+			} else {
+				col.Expression = nil
+			}
+			model.Columns = append(model.Columns, col)
+		}
+	}
+	return model
+}
+
+// copyProperties copies properties without the given keys, or returns nil
+// if nothing is left.
+func copyProperties(properties map[string]string, without ...string) map[string]string {
+	out := make(map[string]string, len(properties))
+	for k, v := range properties {
+		out[k] = v
+	}
+	for _, k := range without {
+		delete(out, k)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func setProperty(properties *map[string]string, name, value string) {
+	if value == "" {
+		return
+	}
+	if *properties == nil {
+		*properties = make(map[string]string)
+	}
+	(*properties)[name] = value
+}
@@ -0,0 +1,121 @@
+package mdlsync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+)
+
+func TestMerge(t *testing.T) {
+	base := testManifest()
+
+	// Edited in the UI since the last sync.
+	server := testManifest()
+	server.Models[0].Columns[0].Type = "INTEGER"
+	server.Models[0].Properties["displayName"] = "Orders"
+	server.Models[0].Columns[2].Properties["description"] = "Gross total."
+	server.Models[0].Columns = append(server.Models[0].Columns, dbt.LegibleColumn{Name: "amount_usd", Type: "numeric", IsCalculated: true, Expression: strPtr("amount * 1.1")})
+	server.Models[1].Properties = map[string]string{"description": "Our customers."}
+
+	// Changed in dbt since the last sync.
+	incoming := testManifest()
+	incoming.Models[0].Properties["description"] = "All orders."
+	incoming.Models[0].Columns[2].Properties["description"] = "Total in USD."
+	incoming.Models[0].Columns = append(incoming.Models[0].Columns, dbt.LegibleColumn{Name: "ordered_at", Type: "date"})
+	incoming.Models[1].Columns = incoming.Models[1].Columns[:1]
+	incoming.Models = append(incoming.Models, dbt.LegibleModel{Name: "payments", Columns: []dbt.LegibleColumn{{Name: "order_id", Type: "integer"}}})
+	incoming.Relationships = append(incoming.Relationships, dbt.Relationship{
+		Name: "payments_orders", Models: []string{"payments", "orders"}, JoinType: "MANY_TO_ONE", Condition: `"payments"."order_id" = "orders"."order_id"`,
+	})
+
+	result := Merge(base, server, incoming, false)
+
+	wantChanges := []Change{
+		{Kind: KindModel, Action: ActionChange, Model: "orders", Field: "description", From: "One row per order.", To: "All orders."},
+		{Kind: KindModel, Action: ActionAdd, Model: "payments"},
+		{Kind: KindColumn, Action: ActionRemove, Model: "customers", Name: "name"},
+		{Kind: KindColumn, Action: ActionAdd, Model: "orders", Name: "ordered_at"},
+		{Kind: KindRelationship, Action: ActionAdd, Name: "orders.order_id = payments.order_id"},
+	}
+	if !reflect.DeepEqual(result.Changes, wantChanges) {
+		t.Errorf("Changes =\n%+v\nwant\n%+v", result.Changes, wantChanges)
+	}
+	wantKept := []Change{
+		{Kind: KindModel, Action: ActionChange, Model: "customers", Field: "description", To: "Our customers."},
+		{Kind: KindModel, Action: ActionChange, Model: "orders", Field: "displayName", To: "Orders"},
+		{Kind: KindColumn, Action: ActionChange, Model: "orders", Name: "amount", Field: "description", From: "Total in USD.", To: "Gross total."},
+		{Kind: KindCalculatedField, Action: ActionAdd, Model: "orders", Name: "amount_usd"},
+	}
+	if !reflect.DeepEqual(result.Kept, wantKept) {
+		t.Errorf("Kept =\n%+v\nwant\n%+v", result.Kept, wantKept)
+	}
+	wantConflicts := []Conflict{{
+		Kind: KindColumn, Model: "orders", Name: "amount", Field: "description",
+		Base: "Order total.", Server: "Gross total.", DBT: "Total in USD.", Kept: "server",
+	}}
+	if !reflect.DeepEqual(result.Conflicts, wantConflicts) {
+		t.Errorf("Conflicts = %+v, want %+v", result.Conflicts, wantConflicts)
+	}
+
+	orders := result.Manifest.Models[0]
+	if orders.Name != "orders" || len(orders.Columns) != 5 || orders.Columns[4].Name != "amount_usd" || *orders.Columns[4].Expression != "amount * 1.1" {
+		t.Errorf("merged orders model = %+v", orders)
+	}
+	if len(result.Manifest.Relationships) != 2 || result.Manifest.Relationships[1].JoinType != "MANY_TO_ONE" {
+		t.Errorf("merged relationships = %+v", result.Manifest.Relationships)
+	}
+
+	// Conflicts can be resolved for dbt instead.
+	result = Merge(base, server, incoming, true)
+	if got := result.Manifest.Models[0].Columns[2].Properties["description"]; got != "Total in USD." {
+		t.Errorf("description = %q, want the dbt description", got)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Kept != "dbt" {
+		t.Errorf("Conflicts = %+v", result.Conflicts)
+	}
+}
+
+func TestMergeRemovesRelationshipsOfRemovedColumns(t *testing.T) {
+	base := testManifest()
+	server := testManifest()
+	// The relationship was edited in the UI, but dbt removed its column.
+	server.Relationships[0].JoinType = "ONE_TO_ONE"
+	incoming := testManifest()
+	incoming.Models[0].Columns = append(incoming.Models[0].Columns[:1], incoming.Models[0].Columns[2])
+
+	result := Merge(base, server, incoming, false)
+	if len(result.Manifest.Relationships) != 0 {
+		t.Errorf("relationships = %+v, want none", result.Manifest.Relationships)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Conflicts = %+v, want none", result.Conflicts)
+	}
+}
+
+func TestMergeWithoutBase(t *testing.T) {
+	server := testManifest()
+	server.Models[0].Properties["description"] = "Edited in the UI."
+	server.Models[1].Properties = map[string]string{"description": "Only in the UI."}
+	server.Models[0].Columns = append(server.Models[0].Columns, dbt.LegibleColumn{Name: "amount_usd", IsCalculated: true, Expression: strPtr("amount * 1.1")})
+	server.Models = append(server.Models, dbt.LegibleModel{Name: "legacy", Columns: []dbt.LegibleColumn{{Name: "id"}}})
+	server.Relationships = append(server.Relationships, dbt.Relationship{
+		Name: "customers_name", JoinType: "ONE_TO_ONE", Condition: `"customers"."name" = "orders"."order_id"`,
+	})
+	incoming := testManifest()
+
+	result := Merge(nil, server, incoming, false)
+	wantChanges := []Change{
+		{Kind: KindModel, Action: ActionRemove, Model: "legacy"},
+		{Kind: KindModel, Action: ActionChange, Model: "orders", Field: "description", From: "Edited in the UI.", To: "One row per order."},
+	}
+	if !reflect.DeepEqual(result.Changes, wantChanges) {
+		t.Errorf("Changes =\n%+v\nwant\n%+v", result.Changes, wantChanges)
+	}
+	if len(result.Kept) != 3 {
+		t.Errorf("Kept = %+v, want the customers description, calculated field and relationship", result.Kept)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Conflicts = %+v, want none", result.Conflicts)
+	}
+}
@@ -0,0 +1,63 @@
+package mdlsync
+
+import (
+	"fmt"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
+)
+
+// FromServer describes a project's models and relationships as an MDL
+// manifest, so it can be compared with and merged into the dbt MDL. Display
+// names that only repeat the reference name are left out, as dbt does.
+func FromServer(models []client.Model, relations []client.Relation) *dbt.LegibleMDLManifest {
+	m := &dbt.LegibleMDLManifest{Relationships: []dbt.Relationship{}, Views: []dbt.View{}}
+	for _, sm := range models {
+		model := dbt.LegibleModel{
+			Name:           sm.ReferenceName,
+			TableReference: dbt.TableReference{Table: sm.SourceTableName},
+			PrimaryKey:     sm.PrimaryKey,
+			Cached:         sm.Cached,
+			RefreshTime:    sm.RefreshTime,
+			Columns:        []dbt.LegibleColumn{},
+		}
+		setProperty(&model.Properties, "description", sm.Description)
+		if sm.DisplayName != sm.ReferenceName {
+			setProperty(&model.Properties, "displayName", sm.DisplayName)
+		}
+		for _, fields := range [][]client.Field{sm.Fields, sm.CalculatedFields} {
+			for _, f := range fields {
+				col := dbt.LegibleColumn{
+					Name:         f.ReferenceName,
+					Type:         f.Type,
+					IsCalculated: f.IsCalculated,
+					NotNull:      f.NotNull,
+				}
+				if f.DisplayName != f.ReferenceName {
+					col.DisplayName = f.DisplayName
+				}
+				if f.Expression != "" {
+					expression := f.Expression
+					col.Expression = &expression
+				}
+				setProperty(&col.Properties, "description", f.Description())
+				model.Columns = append(model.Columns, col)
+			}
+		}
+		m.Models = append(m.Models, model)
+	}
+	for _, r := range relations {
+		m.Relationships = append(m.Relationships, ServerRelationship(r))
+	}
+	return m
+}
+
+// ServerRelationship describes a project relationship as an MDL relationship.
+func ServerRelationship(r client.Relation) dbt.Relationship {
+	return dbt.Relationship{
+		Name:      r.Name,
+		Models:    []string{r.FromModelName, r.ToModelName},
+		JoinType:  r.Type,
+		Condition: fmt.Sprintf("\"%s\".\"%s\" = \"%s\".\"%s\"", r.FromModelName, r.FromColumnName, r.ToModelName, r.ToColumnName),
+	}
+}
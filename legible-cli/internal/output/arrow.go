@@ -0,0 +1,230 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+// recordBatchWriter is the part of the Arrow IPC and Parquet writers we use.
+type recordBatchWriter interface {
+	Write(rec arrow.RecordBatch) error
+	Close() error
+}
+
+// arrowWriter converts each batch of records into an Arrow record batch and
+// hands it to an IPC or Parquet file writer, so memory use is bounded by the
+// batch size.
+type arrowWriter struct {
+	cols   []client.RunSQLColumn
+	kinds  []kind
+	schema *arrow.Schema
+	rb     *array.RecordBuilder
+	out    recordBatchWriter
+}
+
+// writerOnly hides Close from the destination: the Parquet writer closes its
+// sink, but Writers must leave the caller's io.Writer open.
+type writerOnly struct{ io.Writer }
+
+func newArrowWriter(w io.Writer, cols []client.RunSQLColumn) (*arrowWriter, error) {
+	a := newArrowBuilder(cols)
+	fw, err := ipc.NewFileWriter(writerOnly{w}, ipc.WithSchema(a.schema))
+	if err != nil {
+		return nil, fmt.Errorf("creating arrow writer: %w", err)
+	}
+	a.out = fw
+	return a, nil
+}
+
+func newParquetWriter(w io.Writer, cols []client.RunSQLColumn) (*arrowWriter, error) {
+	a := newArrowBuilder(cols)
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	fw, err := pqarrow.NewFileWriter(a.schema, writerOnly{w}, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, fmt.Errorf("creating parquet writer: %w", err)
+	}
+	a.out = fw
+	return a, nil
+}
+
+func newArrowBuilder(cols []client.RunSQLColumn) *arrowWriter {
+	kinds := columnKinds(cols)
+	fields := make([]arrow.Field, len(cols))
+	for i, col := range cols {
+		fields[i] = arrow.Field{Name: col.Name, Type: arrowType(col.Type, kinds[i]), Nullable: !col.NotNull}
+	}
+	schema := arrow.NewSchema(fields, nil)
+	return &arrowWriter{
+		cols:   cols,
+		kinds:  kinds,
+		schema: schema,
+		rb:     array.NewRecordBuilder(memory.NewGoAllocator(), schema),
+	}
+}
+
+// arrowType maps a column kind to its Arrow type.
+func arrowType(typ string, k kind) arrow.DataType {
+	switch k {
+	case kindInt:
+		return arrow.PrimitiveTypes.Int64
+	case kindFloat:
+		return arrow.PrimitiveTypes.Float64
+	case kindDecimal:
+		p, s := decimalParams(typ)
+		return &arrow.Decimal128Type{Precision: p, Scale: s}
+	case kindBool:
+		return arrow.FixedWidthTypes.Boolean
+	case kindDate:
+		return arrow.FixedWidthTypes.Date32
+	case kindTimestamp:
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	}
+	return arrow.BinaryTypes.String
+}
+
+func (a *arrowWriter) Write(records []map[string]interface{}) error {
+	if len(records) == 0 {
+		return nil
+	}
+	for i, col := range a.cols {
+		b := a.rb.Field(i)
+		for _, record := range records {
+			if err := appendValue(b, a.kinds[i], record[col.Name]); err != nil {
+				return fmt.Errorf("column %q: %w", col.Name, err)
+			}
+		}
+	}
+	rec := a.rb.NewRecordBatch()
+	defer rec.Release()
+	return a.out.Write(rec)
+}
+
+func (a *arrowWriter) Close() error {
+	defer a.rb.Release()
+	return a.out.Close()
+}
+
+// appendValue converts a JSON value to the builder's type and appends it.
+func appendValue(b array.Builder, k kind, v interface{}) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+	switch k {
+	case kindInt:
+		n, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		b.(*array.Int64Builder).Append(n)
+	case kindFloat:
+		f, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		b.(*array.Float64Builder).Append(f)
+	case kindDecimal:
+		db := b.(*array.Decimal128Builder)
+		dt := db.Type().(*arrow.Decimal128Type)
+		s, _ := textValue(v, k)
+		n, err := decimal128.FromString(s, dt.Precision, dt.Scale)
+		if err != nil {
+			return fmt.Errorf("invalid decimal %q: %w", s, err)
+		}
+		db.Append(n)
+	case kindBool:
+		switch val := v.(type) {
+		case bool:
+			b.(*array.BooleanBuilder).Append(val)
+		case string:
+			parsed, err := strconv.ParseBool(val)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", val)
+			}
+			b.(*array.BooleanBuilder).Append(parsed)
+		default:
+			return fmt.Errorf("invalid boolean %v", v)
+		}
+	case kindDate:
+		t, ok := parseTime(v)
+		if !ok {
+			return fmt.Errorf("invalid date %v", v)
+		}
+		b.(*array.Date32Builder).Append(arrow.Date32FromTime(t))
+	case kindTimestamp:
+		t, ok := parseTime(v)
+		if !ok {
+			return fmt.Errorf("invalid timestamp %v", v)
+		}
+		ts, err := arrow.TimestampFromTime(t, arrow.Microsecond)
+		if err != nil {
+			return err
+		}
+		b.(*array.TimestampBuilder).Append(ts)
+	default:
+		s, _ := textValue(v, k)
+		b.(*array.StringBuilder).Append(s)
+	}
+	return nil
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := val.Float64()
+		if err != nil || f != float64(int64(f)) {
+			return 0, fmt.Errorf("invalid integer %q", val)
+		}
+		return int64(f), nil
+	case float64:
+		if val != float64(int64(val)) {
+			return 0, fmt.Errorf("invalid integer %v", val)
+		}
+		return int64(val), nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", val)
+		}
+		return n, nil
+	case bool:
+		if val {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("invalid integer %v", v)
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case json.Number:
+		return val.Float64()
+	case float64:
+		return val, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", val)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("invalid number %v", v)
+}
@@ -0,0 +1,88 @@
+// Package output writes query results (columns plus records, as returned by
+// run_sql) in the formats offered by "legible run-sql" and "legible ask".
+//
+// Writers accept records in batches so results can be streamed page by page;
+// only the table format needs to buffer rows to align its columns.
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+// Supported output formats.
+const (
+	Table    = "table"
+	CSV      = "csv"
+	TSV      = "tsv"
+	NDJSON   = "ndjson"
+	Markdown = "markdown"
+	Parquet  = "parquet"
+	Arrow    = "arrow"
+)
+
+// Formats lists the supported formats, for flag help and validation.
+var Formats = []string{Table, CSV, TSV, NDJSON, Markdown, Parquet, Arrow}
+
+// Writer renders records for a fixed set of columns.
+type Writer interface {
+	// Write appends a batch of records. Records are keyed by column name;
+	// missing keys and nil values are written as nulls.
+	Write(records []map[string]interface{}) error
+	// Close flushes buffered output and writes any footer. It does not
+	// close the underlying io.Writer.
+	Close() error
+}
+
+// New returns a Writer for format that writes to w.
+func New(format string, w io.Writer, cols []client.RunSQLColumn) (Writer, error) {
+	switch format {
+	case "", Table:
+		return newTableWriter(w, cols), nil
+	case CSV:
+		return newDelimitedWriter(w, cols, ','), nil
+	case TSV:
+		return newDelimitedWriter(w, cols, '\t'), nil
+	case NDJSON:
+		return newNDJSONWriter(w, cols), nil
+	case Markdown:
+		return newMarkdownWriter(w, cols), nil
+	case Parquet:
+		return newParquetWriter(w, cols)
+	case Arrow:
+		return newArrowWriter(w, cols)
+	default:
+		return nil, fmt.Errorf("unknown format %q (valid: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// IsBinary reports whether format produces binary output that should not
+// be written to a terminal.
+func IsBinary(format string) bool {
+	return format == Parquet || format == Arrow
+}
+
+// FormatFromPath infers a format from a file extension, or returns "".
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return CSV
+	case ".tsv", ".tab":
+		return TSV
+	case ".ndjson", ".jsonl":
+		return NDJSON
+	case ".md", ".markdown":
+		return Markdown
+	case ".parquet":
+		return Parquet
+	case ".arrow", ".feather", ".ipc":
+		return Arrow
+	case ".txt":
+		return Table
+	}
+	return ""
+}
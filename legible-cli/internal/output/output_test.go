@@ -0,0 +1,238 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+var testCols = []client.RunSQLColumn{
+	{Name: "id", Type: "int64"},
+	{Name: "amount", Type: "decimal128(10, 2)"},
+	{Name: "ratio", Type: "float64"},
+	{Name: "day", Type: "date32[day][pyarrow]"},
+	{Name: "note", Type: "object"},
+}
+
+var testRecords = []map[string]interface{}{
+	{"id": json.Number("9007199254740993"), "amount": json.Number("12.50"), "ratio": json.Number("0.1"), "day": "2024-03-01", "note": "a|b"},
+	{"id": json.Number("2"), "amount": nil, "ratio": nil, "day": json.Number("1709251200000"), "note": nil},
+}
+
+func render(t *testing.T, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := New(format, &buf, testCols)
+	if err != nil {
+		t.Fatalf("New(%q): %v", format, err)
+	}
+	if err := w.Write(testRecords); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.String()
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want kind
+	}{
+		{"int64", kindInt},
+		{"Int32", kindInt},
+		{"uint8", kindInt},
+		{"float64", kindFloat},
+		{"double", kindFloat},
+		{"decimal128(38, 9)", kindDecimal},
+		{"bool", kindBool},
+		{"boolean", kindBool},
+		{"datetime64[ns, UTC]", kindTimestamp},
+		{"timestamp[us][pyarrow]", kindTimestamp},
+		{"date32[day][pyarrow]", kindDate},
+		{"object", kindString},
+		{"", kindString},
+	}
+	for _, tt := range tests {
+		if got := kindOf(tt.typ); got != tt.want {
+			t.Errorf("kindOf(%q) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalParams(t *testing.T) {
+	if p, s := decimalParams("decimal128(10, 2)"); p != 10 || s != 2 {
+		t.Errorf("decimalParams = %d,%d, want 10,2", p, s)
+	}
+	if p, s := decimalParams("decimal"); p != 38 || s != 9 {
+		t.Errorf("decimalParams fallback = %d,%d, want 38,9", p, s)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	want := "id,amount,ratio,day,note\n" +
+		"9007199254740993,12.50,0.1,2024-03-01,a|b\n" +
+		"2,,,2024-03-01,\n"
+	if got := render(t, CSV); got != want {
+		t.Errorf("csv:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTSV(t *testing.T) {
+	got := render(t, TSV)
+	if !strings.HasPrefix(got, "id\tamount\tratio\tday\tnote\n") {
+		t.Errorf("tsv header: %q", got)
+	}
+	if !strings.Contains(got, "2\t\t\t2024-03-01\t\n") {
+		t.Errorf("tsv nulls should be empty fields: %q", got)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	want := `{"id":9007199254740993,"amount":12.50,"ratio":0.1,"day":"2024-03-01","note":"a|b"}` + "\n" +
+		`{"id":2,"amount":null,"ratio":null,"day":"2024-03-01","note":null}` + "\n"
+	if got := render(t, NDJSON); got != want {
+		t.Errorf("ndjson:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	got := render(t, Markdown)
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("markdown lines = %d, want 4:\n%s", len(lines), got)
+	}
+	if lines[1] != "| ---: | ---: | ---: | --- | --- |" {
+		t.Errorf("alignment row = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], `a\|b`) {
+		t.Errorf("pipe not escaped: %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "NULL") {
+		t.Errorf("null not rendered: %q", lines[3])
+	}
+}
+
+func TestTable(t *testing.T) {
+	got := render(t, Table)
+	if !strings.Contains(got, "9007199254740993") {
+		t.Errorf("large integer lost precision:\n%s", got)
+	}
+	if !strings.Contains(got, "NULL") {
+		t.Errorf("null not rendered:\n%s", got)
+	}
+}
+
+func TestArrowRoundTrip(t *testing.T) {
+	data := render(t, Arrow)
+	r, err := ipc.NewFileReader(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatalf("reading arrow file: %v", err)
+	}
+	defer r.Close()
+
+	checkSchema(t, r.Schema())
+	rec, err := r.RecordBatch(0)
+	if err != nil {
+		t.Fatalf("reading record batch: %v", err)
+	}
+	checkRecord(t, rec)
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	data := render(t, Parquet)
+	pf, err := file.NewParquetReader(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatalf("reading parquet file: %v", err)
+	}
+	defer pf.Close()
+
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+	if err != nil {
+		t.Fatalf("creating arrow reader: %v", err)
+	}
+	tbl, err := fr.ReadTable(t.Context())
+	if err != nil {
+		t.Fatalf("reading table: %v", err)
+	}
+	defer tbl.Release()
+
+	checkSchema(t, tbl.Schema())
+	if tbl.NumRows() != 2 {
+		t.Errorf("rows = %d, want 2", tbl.NumRows())
+	}
+}
+
+func checkSchema(t *testing.T, schema *arrow.Schema) {
+	t.Helper()
+	want := []arrow.DataType{
+		arrow.PrimitiveTypes.Int64,
+		&arrow.Decimal128Type{Precision: 10, Scale: 2},
+		arrow.PrimitiveTypes.Float64,
+		arrow.FixedWidthTypes.Date32,
+		arrow.BinaryTypes.String,
+	}
+	for i, typ := range want {
+		if got := schema.Field(i).Type; !arrow.TypeEqual(got, typ) {
+			t.Errorf("field %d type = %s, want %s", i, got, typ)
+		}
+	}
+}
+
+func checkRecord(t *testing.T, rec arrow.RecordBatch) {
+	t.Helper()
+	if rec.NumRows() != 2 {
+		t.Fatalf("rows = %d, want 2", rec.NumRows())
+	}
+	ids := rec.Column(0).(*array.Int64)
+	if ids.Value(0) != 9007199254740993 {
+		t.Errorf("id = %d, want 9007199254740993", ids.Value(0))
+	}
+	amounts := rec.Column(1).(*array.Decimal128)
+	if got := amounts.Value(0); got != decimal128.FromI64(1250) {
+		t.Errorf("amount = %s, want 12.50", amounts.ValueStr(0))
+	}
+	if !amounts.IsNull(1) {
+		t.Error("amount[1] should be null")
+	}
+	days := rec.Column(3).(*array.Date32)
+	if got := days.Value(1).ToTime().Format("2006-01-02"); got != "2024-03-01" {
+		t.Errorf("day[1] = %s, want 2024-03-01", got)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"out.csv":      CSV,
+		"out.TSV":      TSV,
+		"out.jsonl":    NDJSON,
+		"out.md":       Markdown,
+		"out.parquet":  Parquet,
+		"out.arrow":    Arrow,
+		"out.unknown":  "",
+		"no-extension": "",
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestNewUnknownFormat(t *testing.T) {
+	if _, err := New("xml", &bytes.Buffer{}, testCols); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,244 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Kubeworkz/legible/legible-cli/internal/client"
+)
+
+// --- Table ---
+
+// tableWriter renders an aligned, human-readable table. Rows are buffered
+// by the tabwriter until Close so that columns line up.
+type tableWriter struct {
+	tw    *tabwriter.Writer
+	cols  []client.RunSQLColumn
+	kinds []kind
+}
+
+func newTableWriter(w io.Writer, cols []client.RunSQLColumn) *tableWriter {
+	t := &tableWriter{
+		tw:    tabwriter.NewWriter(w, 0, 4, 2, ' ', 0),
+		cols:  cols,
+		kinds: columnKinds(cols),
+	}
+
+	headers := make([]string, len(cols))
+	seps := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.Name
+		seps[i] = strings.Repeat("-", max(len(col.Name), 4))
+	}
+	fmt.Fprintln(t.tw, strings.Join(headers, "\t"))
+	fmt.Fprintln(t.tw, strings.Join(seps, "\t"))
+	return t
+}
+
+func (t *tableWriter) Write(records []map[string]interface{}) error {
+	vals := make([]string, len(t.cols))
+	for _, record := range records {
+		for i, col := range t.cols {
+			s, ok := textValue(record[col.Name], t.kinds[i])
+			if !ok {
+				s = "NULL"
+			}
+			// Tabs and newlines would break the alignment.
+			vals[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(s)
+		}
+		if _, err := fmt.Fprintln(t.tw, strings.Join(vals, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tableWriter) Close() error {
+	return t.tw.Flush()
+}
+
+// --- CSV / TSV ---
+
+// delimitedWriter writes RFC 4180 CSV (or tab-separated values) with a
+// header row. Nulls are empty fields.
+type delimitedWriter struct {
+	cw    *csv.Writer
+	cols  []client.RunSQLColumn
+	kinds []kind
+	err   error
+}
+
+func newDelimitedWriter(w io.Writer, cols []client.RunSQLColumn, comma rune) *delimitedWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	d := &delimitedWriter{cw: cw, cols: cols, kinds: columnKinds(cols)}
+
+	headers := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.Name
+	}
+	d.err = cw.Write(headers)
+	return d
+}
+
+func (d *delimitedWriter) Write(records []map[string]interface{}) error {
+	if d.err != nil {
+		return d.err
+	}
+	row := make([]string, len(d.cols))
+	for _, record := range records {
+		for i, col := range d.cols {
+			row[i], _ = textValue(record[col.Name], d.kinds[i])
+		}
+		if err := d.cw.Write(row); err != nil {
+			return err
+		}
+	}
+	d.cw.Flush()
+	return d.cw.Error()
+}
+
+func (d *delimitedWriter) Close() error {
+	if d.err != nil {
+		return d.err
+	}
+	d.cw.Flush()
+	return d.cw.Error()
+}
+
+// --- NDJSON ---
+
+// ndjsonWriter writes one JSON object per record, keys in column order.
+// Numbers keep their exact digits; dates and timestamps become ISO 8601
+// strings; nulls stay null.
+type ndjsonWriter struct {
+	w     *bufio.Writer
+	cols  []client.RunSQLColumn
+	kinds []kind
+	keys  [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, cols []client.RunSQLColumn) *ndjsonWriter {
+	n := &ndjsonWriter{w: bufio.NewWriter(w), cols: cols, kinds: columnKinds(cols)}
+	for _, col := range cols {
+		key, _ := json.Marshal(col.Name)
+		n.keys = append(n.keys, key)
+	}
+	return n
+}
+
+func (n *ndjsonWriter) Write(records []map[string]interface{}) error {
+	var buf bytes.Buffer
+	for _, record := range records {
+		buf.Reset()
+		buf.WriteByte('{')
+		for i, col := range n.cols {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(n.keys[i])
+			buf.WriteByte(':')
+			if err := n.writeValue(&buf, record[col.Name], n.kinds[i]); err != nil {
+				return fmt.Errorf("column %q: %w", col.Name, err)
+			}
+		}
+		buf.WriteString("}\n")
+		if _, err := n.w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return n.w.Flush()
+}
+
+func (n *ndjsonWriter) writeValue(buf *bytes.Buffer, v interface{}, k kind) error {
+	if v == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	if k == kindDate || k == kindTimestamp {
+		s, _ := textValue(v, k)
+		b, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+// --- Markdown ---
+
+// markdownWriter writes a GitHub-flavored Markdown table. Numeric columns
+// are right-aligned.
+type markdownWriter struct {
+	w     *bufio.Writer
+	cols  []client.RunSQLColumn
+	kinds []kind
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", "<br>", "\r", "")
+
+func newMarkdownWriter(w io.Writer, cols []client.RunSQLColumn) *markdownWriter {
+	m := &markdownWriter{w: bufio.NewWriter(w), cols: cols, kinds: columnKinds(cols)}
+
+	headers := make([]string, len(cols))
+	aligns := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = markdownEscaper.Replace(col.Name)
+		switch m.kinds[i] {
+		case kindInt, kindFloat, kindDecimal:
+			aligns[i] = "---:"
+		default:
+			aligns[i] = "---"
+		}
+	}
+	fmt.Fprintf(m.w, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(m.w, "| %s |\n", strings.Join(aligns, " | "))
+	return m
+}
+
+func (m *markdownWriter) Write(records []map[string]interface{}) error {
+	vals := make([]string, len(m.cols))
+	for _, record := range records {
+		for i, col := range m.cols {
+			s, ok := textValue(record[col.Name], m.kinds[i])
+			if !ok {
+				s = "NULL"
+			}
+			vals[i] = markdownEscaper.Replace(s)
+		}
+		if _, err := fmt.Fprintf(m.w, "| %s |\n", strings.Join(vals, " | ")); err != nil {
+			return err
+		}
+	}
+	return m.w.Flush()
+}
+
+func (m *markdownWriter) Close() error {
+	return m.w.Flush()
+}
+
+// columnKinds returns the kind of each column.
+func columnKinds(cols []client.RunSQLColumn) []kind {
+	kinds := make([]kind, len(cols))
+	for i, col := range cols {
+		kinds[i] = kindOf(col.Type)
+	}
+	return kinds
+}
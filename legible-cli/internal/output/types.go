@@ -0,0 +1,128 @@
+package output
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// kind is the logical type of a result column, derived from the type name
+// the server reports (pandas/pyarrow dtypes such as "int64", "float64",
+// "datetime64[ns, UTC]", "date32[day][pyarrow]" or "decimal128(38, 9)").
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindFloat
+	kindDecimal
+	kindBool
+	kindDate
+	kindTimestamp
+)
+
+var decimalRe = regexp.MustCompile(`decimal(?:128|256)?\s*\(\s*(\d+)\s*,\s*(\d+)\s*\)`)
+
+// kindOf maps a server column type to a kind. Unknown types are strings.
+func kindOf(typ string) kind {
+	t := strings.ToLower(strings.TrimSpace(typ))
+	switch {
+	case strings.HasPrefix(t, "decimal"), strings.HasPrefix(t, "numeric"):
+		return kindDecimal
+	case strings.HasPrefix(t, "datetime"), strings.HasPrefix(t, "timestamp"):
+		return kindTimestamp
+	case strings.HasPrefix(t, "date"):
+		return kindDate
+	case strings.HasPrefix(t, "bool"):
+		return kindBool
+	case strings.HasPrefix(t, "int"), strings.HasPrefix(t, "uint"),
+		t == "bigint", t == "smallint", t == "tinyint", t == "long", t == "short":
+		return kindInt
+	case strings.HasPrefix(t, "float"), strings.HasPrefix(t, "double"), t == "real":
+		return kindFloat
+	}
+	return kindString
+}
+
+// decimalParams returns the precision and scale of a decimal type, with
+// 38,9 as the fallback when the type name doesn't carry them.
+func decimalParams(typ string) (precision, scale int32) {
+	m := decimalRe.FindStringSubmatch(strings.ToLower(typ))
+	if m == nil {
+		return 38, 9
+	}
+	p, _ := strconv.Atoi(m[1])
+	s, _ := strconv.Atoi(m[2])
+	return int32(p), int32(s)
+}
+
+// timeLayouts are the timestamp formats accepted from the server, most
+// specific first.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseTime parses a date or timestamp value. Numbers are taken as Unix
+// epoch milliseconds, which is how pandas serializes datetimes to JSON.
+func parseTime(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, true
+			}
+		}
+	case json.Number:
+		if ms, err := val.Int64(); err == nil {
+			return time.UnixMilli(ms).UTC(), true
+		}
+	case float64:
+		return time.UnixMilli(int64(val)).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// textValue renders a value as text for the given kind. ok is false for
+// nulls. Numbers keep their exact digits (no float rounding or exponent
+// notation), dates are ISO 8601, and nested values are JSON.
+func textValue(v interface{}, k kind) (s string, ok bool) {
+	if v == nil {
+		return "", false
+	}
+	switch k {
+	case kindDate:
+		if t, ok := parseTime(v); ok {
+			return t.Format("2006-01-02"), true
+		}
+	case kindTimestamp:
+		if t, ok := parseTime(v); ok {
+			return t.Format(time.RFC3339Nano), true
+		}
+	}
+
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case json.Number:
+		return val.String(), true
+	case float64:
+		if k == kindInt && val == float64(int64(val)) {
+			return strconv.FormatInt(int64(val), 10), true
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(val), true
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+}
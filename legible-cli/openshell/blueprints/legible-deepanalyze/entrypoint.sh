@@ -1,20 +1,15 @@
 #!/bin/bash
 # DeepAnalyze sandbox entrypoint
-# Configures Legible CLI credentials and starts WebUI v2 services
+# Reports the Legible CLI configuration and starts WebUI v2 services
 
 set -e
 
 echo "=== DeepAnalyze Sandbox ==="
 echo "Configuring environment..."
 
-# Configure Legible CLI if credentials are passed via environment
+# Legible CLI reads LEGIBLE_ENDPOINT, LEGIBLE_API_KEY and LEGIBLE_PROJECT_ID
+# from the environment; the API key is not written to disk
 if [ -n "$LEGIBLE_ENDPOINT" ] && [ -n "$LEGIBLE_API_KEY" ]; then
-    mkdir -p ~/.legible
-    cat > ~/.legible/config.yaml <<EOF
-endpoint: ${LEGIBLE_ENDPOINT}
-api_key: ${LEGIBLE_API_KEY}
-project_id: "${LEGIBLE_PROJECT_ID:-}"
-EOF
     echo "Legible CLI configured → ${LEGIBLE_ENDPOINT}"
 fi
 
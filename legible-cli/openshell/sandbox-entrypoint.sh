@@ -6,19 +6,17 @@
 
 set -e
 
-# Configure legible CLI if provider injected credentials
+# The legible CLI reads LEGIBLE_ENDPOINT, LEGIBLE_API_KEY and
+# LEGIBLE_PROJECT_ID from the environment, so the injected credentials are
+# used as they are and the API key is never written to disk.
 if [ -n "$LEGIBLE_ENDPOINT" ] && [ -n "$LEGIBLE_API_KEY" ]; then
-    mkdir -p ~/.legible
-    cat > ~/.legible/config.yaml <<EOF
-endpoint: ${LEGIBLE_ENDPOINT}
-api_key: ${LEGIBLE_API_KEY}
-project_id: ${LEGIBLE_PROJECT_ID:-}
-EOF
     echo "[legible] CLI configured for ${LEGIBLE_ENDPOINT}"
 fi
 
 # Configure MCP connection if endpoint is set
 if [ -n "$LEGIBLE_MCP_ENDPOINT" ]; then
+    mkdir -p ~/.legible
+
     # Build optional db2i MCP server block
     DB2I_MCP_BLOCK=""
     if [ -n "$LEGIBLE_DB2I_MCP_ENDPOINT" ]; then
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Kubeworkz/legible/legible-launcher/commands/dbt"
 	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
 )
 
 // DbtAutoConvert automatically searches for dbt profiles and catalog.json,
@@ -17,6 +18,13 @@ func DbtAutoConvert() {
 		ProfileName          string
 		Target               string
 		IncludeStagingModels bool
+		IncludeSources       bool
+		IncludeSeeds         bool
+		IncludeSnapshots     bool
+		MetadataMapping      string
+		InferRelations       bool
+		Vars                 string
+		KeepSecretRefs       bool
 	}
 
 	// Define command line flags
@@ -25,6 +33,13 @@ func DbtAutoConvert() {
 	flag.StringVar(&opts.ProfileName, "profile", "", "Specific profile name to use (optional, uses first found if not provided)")
 	flag.StringVar(&opts.Target, "target", "", "Specific target to use (optional, uses profile default if not provided)")
 	flag.BoolVar(&opts.IncludeStagingModels, "include-staging-models", false, "If set, staging models will be included during conversion")
+	flag.BoolVar(&opts.IncludeSources, "include-sources", false, "If set, dbt sources will be converted to models")
+	flag.BoolVar(&opts.IncludeSeeds, "include-seeds", false, "If set, dbt seeds will be converted to models")
+	flag.BoolVar(&opts.IncludeSnapshots, "include-snapshots", false, "If set, dbt snapshots will be converted to models")
+	flag.StringVar(&opts.MetadataMapping, "metadata-mapping", "", "YAML file mapping dbt meta, config, tags and docs into MDL properties (optional)")
+	flag.BoolVar(&opts.InferRelations, "infer-relations", false, "If set, relationships without a relationships test are inferred and written to legible-inferred-relationships.json for review")
+	flag.StringVar(&opts.Vars, "vars", "", "YAML dictionary of values for var() in profiles.yml, like dbt's --vars (optional)")
+	flag.BoolVar(&opts.KeepSecretRefs, "keep-secret-refs", false, "If set, secrets read with env_var() are written to the data source as references instead of values")
 	flag.Parse()
 
 	// Validate required parameters
@@ -40,6 +55,24 @@ func DbtAutoConvert() {
 		os.Exit(1)
 	}
 
+	var vars map[string]interface{}
+	if opts.Vars != "" {
+		if err := yaml.Unmarshal([]byte(opts.Vars), &vars); err != nil {
+			pterm.Error.Printf("Error: --vars must be a YAML dictionary: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var metadata *dbt.MetadataMapping
+	if opts.MetadataMapping != "" {
+		var err error
+		metadata, err = dbt.LoadMetadataMapping(opts.MetadataMapping)
+		if err != nil {
+			pterm.Error.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// ConvertOptions struct for core conversion logic
 	convertOpts := dbt.ConvertOptions{
 		ProjectPath:          opts.ProjectPath,
@@ -48,6 +81,13 @@ func DbtAutoConvert() {
 		Target:               opts.Target,
 		RequireCatalog:       true, // DbtAutoConvert requires catalog.json to exist
 		IncludeStagingModels: opts.IncludeStagingModels,
+		IncludeSources:       opts.IncludeSources,
+		IncludeSeeds:         opts.IncludeSeeds,
+		IncludeSnapshots:     opts.IncludeSnapshots,
+		Metadata:             metadata,
+		InferRelations:       opts.InferRelations,
+		Vars:                 vars,
+		KeepSecretRefs:       opts.KeepSecretRefs,
 	}
 
 	// Call the core conversion logic
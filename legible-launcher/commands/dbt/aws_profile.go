@@ -0,0 +1,144 @@
+package dbt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+// References written to a data source in place of the access keys of an
+// AWS profile with KeepSecretRefs; `aws configure export-credentials`
+// prints a profile's keys as these variables.
+const (
+	awsAccessKeyIDRef     = "{{ env_var('AWS_ACCESS_KEY_ID') }}"
+	awsSecretAccessKeyRef = "{{ env_var('AWS_SECRET_ACCESS_KEY') }}"
+)
+
+// awsProfileName returns the AWS profile dbt uses when a profile names
+// none: AWS_PROFILE, or else the default profile.
+func awsProfileName(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv("AWS_PROFILE"); env != "" {
+		return env
+	}
+	return "default"
+}
+
+// awsProfileRegion reads the region of a named AWS profile from the shared
+// config file, ~/.aws/config or AWS_CONFIG_FILE, without loading its
+// credentials. It returns "" if the profile sets no region.
+func awsProfileRegion(profile string) string {
+	path := os.Getenv("AWS_CONFIG_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		path = filepath.Join(home, ".aws", "config")
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path is the user's AWS config file
+	if err != nil {
+		return ""
+	}
+
+	var section string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Join(strings.Fields(strings.Trim(line, "[]")), " ")
+			continue
+		}
+		if section != "profile "+profile && (profile != "default" || section != "default") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(key) == "region" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// awsExportCredentials prints the credentials of an AWS profile as JSON,
+// in the format of a credential_process. It is a variable for tests.
+var awsExportCredentials = func(profile string) ([]byte, error) {
+	// #nosec G204 -- the profile is passed as an argument, not through a shell
+	return exec.Command("aws", "configure", "export-credentials", "--profile", profile, "--format", "process").Output()
+}
+
+// awsProfileKeys returns the access keys of an AWS profile:
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY if they are set, or else the
+// keys the AWS CLI exports for the profile. Temporary keys, with a session
+// token, are an error, as the data source cannot refresh them.
+func awsProfileKeys(profile string) (string, string, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		if os.Getenv("AWS_SESSION_TOKEN") != "" {
+			return "", "", fmt.Errorf("AWS_ACCESS_KEY_ID is a temporary key (AWS_SESSION_TOKEN is set), which the data source cannot refresh; set a long-lived key of AWS profile '%s', or use --keep-secret-refs to write references to the keys", profile)
+		}
+		return id, secret, nil
+	}
+
+	out, err := awsExportCredentials(profile)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", "", fmt.Errorf("reading the keys of AWS profile '%s' with 'aws configure export-credentials': %w; set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or use --keep-secret-refs to write references to them", profile, err)
+	}
+	var creds struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken"`
+	}
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return "", "", fmt.Errorf("parsing the keys of AWS profile '%s': %w", profile, err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return "", "", fmt.Errorf("AWS profile '%s' has no access keys", profile)
+	}
+	if creds.SessionToken != "" {
+		return "", "", fmt.Errorf("AWS profile '%s' has temporary keys (SSO or an assumed role), which the data source cannot refresh; use a profile with a long-lived key, set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or use --keep-secret-refs to write references to them", profile)
+	}
+	return creds.AccessKeyID, creds.SecretAccessKey, nil
+}
+
+// setAWSProfileKeys sets the access keys of a Redshift IAM or Athena data
+// source that authenticates with an AWS profile: references to them with
+// keepRefs, or else the profile's keys.
+func setAWSProfileKeys(ds DataSource, keepRefs bool) error {
+	var profile string
+	var id, secret *string
+	switch typed := ds.(type) {
+	case *LegibleRedshiftDataSource:
+		profile, id, secret = typed.AwsProfile, &typed.AccessKeyID, &typed.AccessKeySecret
+	case *LegibleAthenaDataSource:
+		profile, id, secret = typed.AwsProfile, &typed.AwsAccessKeyID, &typed.AwsSecretAccessKey
+	default:
+		return nil
+	}
+	if profile == "" || *id != "" {
+		return nil
+	}
+
+	if keepRefs {
+		*id, *secret = awsAccessKeyIDRef, awsSecretAccessKeyRef
+		pterm.Warning.Printf("%s: the data source refers to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY instead of the keys of AWS profile '%s'; they must hold a long-lived key of that profile where the data source is created\n", ds.GetType(), profile)
+		return nil
+	}
+	var err error
+	if *id, *secret, err = awsProfileKeys(profile); err != nil {
+		return fmt.Errorf("%s: %w", ds.GetType(), err)
+	}
+	return nil
+}
@@ -15,6 +15,9 @@ import (
 // Note: All struct definitions (LegibleMDLManifest, LegibleModel, etc.) are defined
 // in legible_mdl.go to prevent "redeclared in this block" compilation errors.
 
+// mdlSchemaURL is the $schema of the MDL files the converter writes.
+const mdlSchemaURL = "https://raw.githubusercontent.com/Canner/Legible/main/wren-mdl/mdl.schema.json"
+
 // ConvertOptions holds the options for dbt project conversion
 type ConvertOptions struct {
 	ProjectPath          string
@@ -24,6 +27,21 @@ type ConvertOptions struct {
 	RequireCatalog       bool // if true, missing catalog.json is an error; if false, it's a warning
 	UsedByContainer      bool // if true, used by container, no need to print usage info
 	IncludeStagingModels bool // if true, staging models will be included in the conversion
+	IncludeSources       bool // if true, dbt sources will be converted to models
+	IncludeSeeds         bool // if true, dbt seeds will be converted to models
+	IncludeSnapshots     bool // if true, dbt snapshots will be converted to models
+	// Metadata maps dbt meta, config, tags and docs into MDL properties;
+	// nil uses DefaultMetadataMapping
+	Metadata *MetadataMapping
+	// InferRelations infers relationships that no relationships test
+	// declares and writes them to legible-inferred-relationships.json for review
+	InferRelations bool
+	// Vars are the values for var() in profiles.yml, as given to dbt with --vars
+	Vars map[string]interface{}
+	// KeepSecretRefs writes secrets that profiles.yml reads with env_var()
+	// to legible-datasource.json as the {{ env_var(...) }} expression
+	// instead of the secret itself
+	KeepSecretRefs bool
 }
 
 // ConvertResult holds the result of dbt project conversion
@@ -31,6 +49,14 @@ type ConvertResult struct {
 	LocalStoragePath    string
 	DataSourceGenerated bool
 	ModelsCount         int
+	// ModelNodeIDs maps each converted model's name to the unique_id of the
+	// dbt node it was converted from, e.g. "model.jaffle_shop.orders"
+	ModelNodeIDs map[string]string
+	// InferredRelationships are the relationships inferred with
+	// InferRelations, most confident first
+	InferredRelationships []InferredRelationship
+	// Validation is the result of validating the converted MDL
+	Validation *ValidationReport
 }
 
 // ConvertDbtProjectCore contains the core logic for converting dbt projects
@@ -102,7 +128,7 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 		pterm.Info.Printf("Found profiles.yml at: %s\n", profilesPath)
 
 		// Analyze profiles
-		profiles, err := AnalyzeDbtProfiles(profilesPath)
+		profiles, err := AnalyzeDbtProfiles(profilesPath, opts.Vars)
 		if err != nil {
 			return nil, fmt.Errorf("failed to analyze profiles: %w", err)
 		}
@@ -117,7 +143,11 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 		}
 
 		// Get active data sources
-		dataSources, err := GetActiveDataSources(profiles, opts.ProjectPath, opts.ProfileName, opts.Target)
+		connection, profileName, target, err := activeConnection(profiles, opts.ProfileName, opts.Target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data sources: %w", err)
+		}
+		dataSources, err := GetActiveDataSources(profiles, opts.ProjectPath, profileName, target)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get data sources: %w", err)
 		}
@@ -128,6 +158,9 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 		} else {
 			// Use the first data source
 			ds = dataSources[0]
+			if err := setAWSProfileKeys(ds, opts.KeepSecretRefs); err != nil {
+				return nil, fmt.Errorf("failed to get data sources: %w", err)
+			}
 
 			// Check if the first data source is duckdb (local file)
 			if localFileDS, ok := dataSources[0].(*LegibleLocalFileDataSource); ok {
@@ -212,6 +245,130 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 						"sslMode":  typedDS.SslMode,
 					},
 				}
+			case *LegibleSnowflakeDataSource:
+				legibleDataSource = map[string]interface{}{
+					"type": "snowflake",
+					"properties": map[string]interface{}{
+						"account":     typedDS.Account,
+						"user":        typedDS.User,
+						"password":    typedDS.Password,
+						"database":    typedDS.Database,
+						"schema":      typedDS.Schema,
+						"warehouse":   typedDS.Warehouse,
+						"private_key": typedDS.PrivateKey,
+						"kwargs":      typedDS.Kwargs(),
+					},
+				}
+			case *LegibleRedshiftDataSource:
+				properties := map[string]interface{}{
+					"redshift_type": "redshift",
+					"database":      typedDS.Database,
+					"user":          typedDS.User,
+				}
+				if typedDS.IAM {
+					properties["redshift_type"] = "redshift_iam"
+					properties["cluster_identifier"] = typedDS.ClusterIdentifier
+					properties["region"] = typedDS.Region
+					properties["access_key_id"] = typedDS.AccessKeyID
+					properties["access_key_secret"] = typedDS.AccessKeySecret
+				} else {
+					host := typedDS.Host
+					if opts.UsedByContainer {
+						host = handleLocalhostForContainer(typedDS.Host)
+					}
+					properties["host"] = host
+					properties["port"] = typedDS.Port
+					properties["password"] = typedDS.Password
+				}
+				legibleDataSource = map[string]interface{}{
+					"type":       "redshift",
+					"properties": properties,
+				}
+			case *LegibleTrinoDataSource:
+				host := typedDS.Host
+				if opts.UsedByContainer {
+					host = handleLocalhostForContainer(typedDS.Host)
+				}
+				legibleDataSource = map[string]interface{}{
+					"type": "trino",
+					"properties": map[string]interface{}{
+						"host":     host,
+						"port":     typedDS.Port,
+						"catalog":  typedDS.Catalog,
+						"schema":   typedDS.Schema,
+						"user":     typedDS.User,
+						"password": typedDS.Password,
+						"kwargs":   map[string]interface{}{"http_scheme": typedDS.HTTPScheme},
+					},
+				}
+			case *LegibleDatabricksDataSource:
+				properties := map[string]interface{}{
+					"databricks_type": typedDS.DatabricksType(),
+					"serverHostname":  typedDS.ServerHostname,
+					"httpPath":        typedDS.HTTPPath,
+				}
+				if opts.UsedByContainer {
+					properties["serverHostname"] = handleLocalhostForContainer(typedDS.ServerHostname)
+				}
+				if typedDS.AccessToken != "" {
+					properties["accessToken"] = typedDS.AccessToken
+				} else {
+					properties["clientId"] = typedDS.ClientID
+					properties["clientSecret"] = typedDS.ClientSecret
+					if typedDS.AzureTenantID != "" {
+						properties["azureTenantId"] = typedDS.AzureTenantID
+					}
+				}
+				legibleDataSource = map[string]interface{}{
+					"type":       "databricks",
+					"properties": properties,
+				}
+			case *LegibleClickHouseDataSource:
+				host := typedDS.Host
+				if opts.UsedByContainer {
+					host = handleLocalhostForContainer(typedDS.Host)
+				}
+				legibleDataSource = map[string]interface{}{
+					"type": "clickhouse",
+					"properties": map[string]interface{}{
+						"host":     host,
+						"port":     typedDS.Port,
+						"database": typedDS.Database,
+						"user":     typedDS.User,
+						"password": typedDS.Password,
+						"secure":   typedDS.Secure,
+					},
+				}
+			case *LegibleOracleDataSource:
+				host := typedDS.Host
+				if opts.UsedByContainer {
+					host = handleLocalhostForContainer(typedDS.Host)
+				}
+				properties := map[string]interface{}{
+					"host":     host,
+					"port":     typedDS.Port,
+					"database": typedDS.Database,
+					"user":     typedDS.User,
+					"password": typedDS.Password,
+				}
+				if dsn := typedDS.ConnectDSN(host); dsn != "" {
+					properties["dsn"] = dsn
+				}
+				legibleDataSource = map[string]interface{}{
+					"type":       "oracle",
+					"properties": properties,
+				}
+			case *LegibleAthenaDataSource:
+				legibleDataSource = map[string]interface{}{
+					"type": "athena",
+					"properties": map[string]interface{}{
+						"s3_staging_dir":        typedDS.S3StagingDir,
+						"region_name":           typedDS.Region,
+						"schema_name":           typedDS.Schema,
+						"aws_access_key_id":     typedDS.AwsAccessKeyID,
+						"aws_secret_access_key": typedDS.AwsSecretAccessKey,
+					},
+				}
 			default:
 				pterm.Warning.Printf("Warning: Unsupported data source type: %s\n", ds.GetType())
 				legibleDataSource = map[string]interface{}{
@@ -220,6 +377,12 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 				}
 			}
 
+			if opts.KeepSecretRefs {
+				if properties, ok := legibleDataSource["properties"].(map[string]interface{}); ok {
+					keepSecretRefs(ds.GetType(), properties, connection.SecretRefs)
+				}
+			}
+
 			// Write LegibleDataSource JSON
 			dataSourcePath := filepath.Join(opts.OutputDir, "legible-datasource.json")
 			dataSourceJSON, err := json.MarshalIndent(legibleDataSource, "", "  ")
@@ -244,10 +407,18 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 		ds = &DefaultDataSource{}
 	}
 
-	manifest, err := ConvertDbtCatalogToLegibleMDL(catalogPath, ds, manifestPathForConversion, semanticManifestPathForConversion, opts.IncludeStagingModels)
+	conversion, err := convertDbtCatalog(catalogPath, ds, manifestPathForConversion, semanticManifestPathForConversion, CatalogConvertOptions{
+		IncludeStagingModels: opts.IncludeStagingModels,
+		IncludeSources:       opts.IncludeSources,
+		IncludeSeeds:         opts.IncludeSeeds,
+		IncludeSnapshots:     opts.IncludeSnapshots,
+		Metadata:             opts.Metadata,
+		InferRelations:       opts.InferRelations,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert catalog: %w", err)
 	}
+	manifest := conversion.manifest
 
 	// Write Legible MDL JSON
 	mdlPath := filepath.Join(opts.OutputDir, "legible-mdl.json")
@@ -262,12 +433,36 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 
 	pterm.Success.Printf("✓ Legible MDL saved to: %s\n", mdlPath)
 
+	// Validate the MDL against the schema and its own references
+	validation := ValidateManifest(manifest)
+	for _, issue := range validation.Issues {
+		if issue.Severity == SeverityError {
+			pterm.Error.Printf("MDL %s: %s\n", issue.Path, issue.Message)
+		} else {
+			pterm.Warning.Printf("MDL %s: %s\n", issue.Path, issue.Message)
+		}
+	}
+
+	// Write the inferred relationships for review
+	if opts.InferRelations {
+		inferredPath := filepath.Join(opts.OutputDir, "legible-inferred-relationships.json")
+		inferredJSON, err := json.MarshalIndent(conversion.inferred, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal inferred relationships JSON: %w", err)
+		}
+		if err := os.WriteFile(inferredPath, inferredJSON, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write inferred relationships file: %w", err)
+		}
+		pterm.Success.Printf("✓ %d inferred relationships saved for review to: %s\n", len(conversion.inferred), inferredPath)
+	}
+
 	// Summary
 	pterm.Success.Println("\n🎉 Conversion completed successfully!")
 	pterm.Info.Printf("Models converted: %d\n", len(manifest.Models))
 	pterm.Info.Printf("Relationships generated: %d\n", len(manifest.Relationships))
 	pterm.Info.Printf("Metrics generated: %d\n", len(manifest.Metrics))
 	pterm.Info.Printf("Enums generated: %d\n", len(manifest.EnumDefinitions))
+	pterm.Info.Printf("Validation: %d errors, %d warnings\n", validation.Errors(), validation.Warnings())
 
 	if dataSourceGenerated {
 		pterm.Info.Println("Generated files:")
@@ -284,12 +479,49 @@ func ConvertDbtProjectCore(opts ConvertOptions) (*ConvertResult, error) {
 	}
 
 	return &ConvertResult{
-		LocalStoragePath:    localStoragePath,
-		DataSourceGenerated: dataSourceGenerated,
-		ModelsCount:         len(manifest.Models),
+		LocalStoragePath:      localStoragePath,
+		DataSourceGenerated:   dataSourceGenerated,
+		ModelsCount:           len(manifest.Models),
+		ModelNodeIDs:          conversion.index.nodeIDs(manifest.Models),
+		InferredRelationships: conversion.inferred,
+		Validation:            validation,
 	}, nil
 }
 
+// secretRefFields maps the data source properties converted from a
+// profile field of another name to that field, by data source type. Other
+// properties are converted from the field of the same name; those with no
+// fields hold a value derived from the field, and keep it.
+var secretRefFields = map[string]map[string][]string{
+	"postgres":   {"password": {"password", "pass"}},
+	"snowflake":  {"private_key": nil},
+	"redshift":   {"access_key_secret": {"secret_access_key"}},
+	"databricks": {"accessToken": {"token"}, "clientSecret": {"client_secret", "azure_client_secret"}},
+	"oracle":     {"password": {"password", "pass"}},
+}
+
+// keepSecretRefs replaces the properties of a data source converted from
+// profile fields that were rendered into secrets with the Jinja expressions
+// they were rendered from. secretRefs maps the fields to the expressions.
+func keepSecretRefs(dsType string, properties map[string]interface{}, secretRefs map[string]string) {
+	for property, v := range properties {
+		if nested, ok := v.(map[string]interface{}); ok {
+			keepSecretRefs(dsType, nested, secretRefs)
+			continue
+		}
+		fields, ok := secretRefFields[dsType][property]
+		if !ok {
+			fields = []string{property}
+		}
+		for _, field := range fields {
+			if ref, ok := secretRefs[field]; ok {
+				properties[property] = ref
+				break
+			}
+		}
+	}
+}
+
 func handleLocalhostForContainer(host string) string {
 	// If the host is localhost, we need to handle it for container usage
 	if host == "localhost" || host == "127.0.0.1" {
@@ -302,9 +534,43 @@ func handleLocalhostForContainer(host string) string {
 	return host
 }
 
+// CatalogConvertOptions selects the dbt resources that ConvertDbtCatalogToLegibleMDL
+// converts to models. Models are always converted; the other resources are opt-in.
+type CatalogConvertOptions struct {
+	IncludeStagingModels bool // include models prefixed stg_ or staging_
+	IncludeSources       bool // include source tables
+	IncludeSeeds         bool // include seeds
+	IncludeSnapshots     bool // include snapshots, annotating their validity columns
+	// Metadata maps dbt meta, config, tags and docs into MDL properties;
+	// nil uses DefaultMetadataMapping
+	Metadata *MetadataMapping
+	// InferRelations infers relationships that no relationships test
+	// declares, for review; they are not added to the manifest
+	InferRelations bool
+}
+
 // ConvertDbtCatalogToLegibleMDL is the main function to convert a dbt catalog into a Legible MDL manifest.
 // It orchestrates the reading of dbt artifacts and processes each dbt node to convert it into a Wren model.
-func ConvertDbtCatalogToLegibleMDL(catalogPath string, dataSource DataSource, manifestPath string, semanticManifestPath string, includeStagingModels bool) (*LegibleMDLManifest, error) {
+func ConvertDbtCatalogToLegibleMDL(catalogPath string, dataSource DataSource, manifestPath string, semanticManifestPath string, opts CatalogConvertOptions) (*LegibleMDLManifest, error) {
+	conversion, err := convertDbtCatalog(catalogPath, dataSource, manifestPath, semanticManifestPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	return conversion.manifest, nil
+}
+
+// catalogConversion is the result of convertDbtCatalog.
+type catalogConversion struct {
+	manifest *LegibleMDLManifest
+	// index names the dbt nodes that were converted
+	index dbtNodeIndex
+	// inferred holds the relationships inferred when CatalogConvertOptions.InferRelations is set
+	inferred []InferredRelationship
+}
+
+// convertDbtCatalog converts a dbt catalog like ConvertDbtCatalogToLegibleMDL,
+// also returning the index of the dbt nodes that were converted.
+func convertDbtCatalog(catalogPath string, dataSource DataSource, manifestPath string, semanticManifestPath string, opts CatalogConvertOptions) (*catalogConversion, error) {
 	// --- 1. Read and Parse All Necessary DBT Artifact Files ---
 
 	// Read and unmarshal the primary catalog.json file.
@@ -343,7 +609,7 @@ func ConvertDbtCatalogToLegibleMDL(catalogPath string, dataSource DataSource, ma
 	// --- 2. Initialize Wren Manifest and Pre-process Metadata ---
 
 	manifest := &LegibleMDLManifest{
-		JsonSchema:      "https://raw.githubusercontent.com/Canner/Legible/main/wren-mdl/mdl.schema.json",
+		JsonSchema:      mdlSchemaURL,
 		Catalog:         "wren",
 		Schema:          "public",
 		EnumDefinitions: []EnumDefinition{},
@@ -354,6 +620,18 @@ func ConvertDbtCatalogToLegibleMDL(catalogPath string, dataSource DataSource, ma
 		DataSource:      dataSource.GetType(),
 	}
 
+	// Find the catalog nodes to convert. Sources are listed apart from the other nodes.
+	nodesValue, exists := catalogData["nodes"]
+	if !exists {
+		return nil, fmt.Errorf("no 'nodes' section found in catalog")
+	}
+	nodesMap, ok := nodesValue.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid 'nodes' format in catalog")
+	}
+	sourcesMap, _ := catalogData["sources"].(map[string]interface{})
+	index := newDbtNodeIndex(nodesMap, sourcesMap, manifestData, opts)
+
 	// Create lookup maps to store pre-processed information for quick access.
 	enumValueToNameMap := make(map[string]string)
 	columnToEnumNameMap := make(map[string]string)
@@ -362,7 +640,7 @@ func ConvertDbtCatalogToLegibleMDL(catalogPath string, dataSource DataSource, ma
 
 	// Pre-process the manifest to extract test data (enums, not-null constraints).
 	if manifestData != nil {
-		preprocessManifestForTests(manifestData, &manifest.EnumDefinitions, enumValueToNameMap, columnToEnumNameMap, columnToNotNullMap)
+		preprocessManifestForTests(manifestData, index, &manifest.EnumDefinitions, enumValueToNameMap, columnToEnumNameMap, columnToNotNullMap)
 	}
 
 	// Pre-process the semantic manifest to extract primary key information.
@@ -372,38 +650,34 @@ func ConvertDbtCatalogToLegibleMDL(catalogPath string, dataSource DataSource, ma
 
 	// --- 3. Convert dbt Nodes to Wren Models ---
 
-	nodesValue, exists := catalogData["nodes"]
-	if !exists {
-		return nil, fmt.Errorf("no 'nodes' section found in catalog")
+	metadata := opts.Metadata
+	if metadata == nil {
+		metadata = DefaultMetadataMapping()
 	}
-	nodesMap, ok := nodesValue.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid 'nodes' format in catalog")
+
+	nodeKeys := make([]string, 0, len(index.names))
+	for nodeKey := range index.names {
+		nodeKeys = append(nodeKeys, nodeKey)
 	}
+	sort.Strings(nodeKeys)
 
-	// Iterate through each node in the catalog and convert it to a Wren model.
-	for nodeKey, nodeValue := range nodesMap {
-		nodeMap, ok := nodeValue.(map[string]interface{})
+	// Convert each selected node in the catalog to a Wren model.
+	for _, nodeKey := range nodeKeys {
+		nodeMap, ok := nodesMap[nodeKey].(map[string]interface{})
 		if !ok {
-			continue
+			nodeMap, ok = sourcesMap[nodeKey].(map[string]interface{})
 		}
-		// We are only interested in nodes that represent dbt models.
-		if !strings.HasPrefix(nodeKey, "model.") {
-			continue
-		}
-
-		// Skip staging models if the user has opted to exclude them.
-		modelName := getModelNameFromNodeKey(nodeKey)
-		if !includeStagingModels && (strings.HasPrefix(modelName, "stg_") || strings.HasPrefix(modelName, "staging_")) {
+		if !ok {
 			continue
 		}
 
 		// Perform the conversion for the single node.
-		model, err := convertDbtNodeToLegibleModel(nodeKey, nodeMap, dataSource, manifestData, columnToEnumNameMap, columnToNotNullMap, modelToPrimaryKeyMap)
+		model, err := convertDbtNodeToLegibleModel(nodeKey, index.names[nodeKey], nodeMap, dataSource, manifestData, columnToEnumNameMap, columnToNotNullMap, modelToPrimaryKeyMap)
 		if err != nil {
 			pterm.Warning.Printf("Failed to convert model %s: %v\n", nodeKey, err)
 			continue
 		}
+		metadata.applyToModel(model, findManifestNode(manifestData, nodeKey))
 		manifest.Models = append(manifest.Models, *model)
 	}
 
@@ -411,20 +685,42 @@ func ConvertDbtCatalogToLegibleMDL(catalogPath string, dataSource DataSource, ma
 
 	// Generate relationships between models based on the dbt manifest.
 	if manifestData != nil {
-		manifest.Relationships = generateRelationships(manifestData)
+		manifest.Relationships = generateRelationships(manifestData, index)
 	}
 
-	// Generate metrics from the semantic manifest.
+	// Generate metrics, and relationships between the semantic models' entities, from the semantic manifest.
 	if semanticManifestData != nil {
-		manifest.Metrics = convertDbtMetricsToLegibleMetrics(semanticManifestData)
+		semantic := newSemanticGraph(semanticManifestData, manifest.Models)
+		manifest.Relationships = uniqueRelationships(append(manifest.Relationships, semantic.relationships()...))
+		manifest.Metrics = semantic.convertMetrics()
 	}
 
-	return manifest, nil
+	conversion := &catalogConversion{manifest: manifest, index: index}
+	if opts.InferRelations {
+		conversion.inferred = inferRelationships(manifest.Models, manifest.Relationships, manifestData, semanticManifestData, index)
+	}
+	return conversion, nil
 }
 
 // preprocessManifestForTests extracts information from dbt tests (like 'not_null' and 'accepted_values')
 // and populates maps that will be used later during model conversion.
-func preprocessManifestForTests(manifestData map[string]interface{}, enums *[]EnumDefinition, enumValueToNameMap, columnToEnumNameMap map[string]string, columnToNotNullMap map[string]bool) {
+func preprocessManifestForTests(manifestData map[string]interface{}, index dbtNodeIndex, enums *[]EnumDefinition, enumValueToNameMap, columnToEnumNameMap map[string]string, columnToNotNullMap map[string]bool) {
+	// Sources are listed apart from the other nodes, but their columns are tested the same way.
+	if sources, ok := manifestData["sources"].(map[string]interface{}); ok {
+		for sourceID, sourceValue := range sources {
+			if sourceMap, ok := sourceValue.(map[string]interface{}); ok {
+				modelName := getModelNameFromNodeKey(sourceID)
+				if columns, ok := sourceMap["columns"].(map[string]interface{}); ok {
+					for columnName, colData := range columns {
+						if colMap, ok := colData.(map[string]interface{}); ok {
+							processColumnForTests(sourceID, modelName, columnName, colMap, enums, enumValueToNameMap, columnToEnumNameMap, columnToNotNullMap)
+						}
+					}
+				}
+			}
+		}
+	}
+
 	nodes, ok := manifestData["nodes"].(map[string]interface{})
 	if !ok {
 		return
@@ -436,8 +732,8 @@ func preprocessManifestForTests(manifestData map[string]interface{}, enums *[]En
 			continue
 		}
 
-		// Process tests defined directly on model columns.
-		if strings.HasPrefix(nodeKey, "model.") {
+		// Process tests defined directly on model, seed and snapshot columns.
+		if isModelLikeNode(nodeKey) {
 			modelName := getModelNameFromNodeKey(nodeKey)
 			if columns, ok := nodeMap["columns"].(map[string]interface{}); ok {
 				for columnName, colData := range columns {
@@ -452,7 +748,7 @@ func preprocessManifestForTests(manifestData map[string]interface{}, enums *[]En
 		if strings.HasPrefix(nodeKey, "test.") {
 			testMeta, _ := nodeMap["test_metadata"].(map[string]interface{})
 			testName := getStringFromMap(testMeta, "name", "")
-			attachedNodeID := getStringFromMap(nodeMap, "attached_node", "")
+			attachedNodeID := testedNodeID(nodeMap, testMeta, index)
 			columnName := getStringFromMap(nodeMap, "column_name", "")
 
 			if attachedNodeID != "" && columnName != "" {
@@ -508,9 +804,58 @@ func preprocessSemanticManifestForPrimaryKeys(semanticData map[string]interface{
 	}
 }
 
+// isModelLikeNode reports whether a manifest node is a model, seed or snapshot,
+// which are all materialized as tables or views.
+func isModelLikeNode(nodeKey string) bool {
+	return strings.HasPrefix(nodeKey, "model.") || strings.HasPrefix(nodeKey, "seed.") || strings.HasPrefix(nodeKey, "snapshot.")
+}
+
+// testedNodeID returns the unique_id of the node a compiled test node tests.
+// Tests on sources have no attached node, so the source is read from the
+// test's model argument, e.g. "{{ get_where_subquery(source('raw', 'orders')) }}".
+func testedNodeID(testNode, testMeta map[string]interface{}, index dbtNodeIndex) string {
+	if attachedNodeID := getStringFromMap(testNode, "attached_node", ""); attachedNodeID != "" {
+		return attachedNodeID
+	}
+	kwargs, _ := testMeta["kwargs"].(map[string]interface{})
+	return index.sourceID(getStringFromMap(kwargs, "model", ""))
+}
+
 // generateRelationships iterates through the manifest and creates relationship definitions.
-func generateRelationships(manifestData map[string]interface{}) []Relationship {
+// Relationships can point at models with ref() or, when sources are converted, at sources with source().
+func generateRelationships(manifestData map[string]interface{}, index dbtNodeIndex) []Relationship {
 	var relationships []Relationship
+
+	// Case 1: Handle tests on model, seed, snapshot and source columns (including structs)
+	columnOwners := make(map[string]map[string]interface{})
+	if nodes, ok := manifestData["nodes"].(map[string]interface{}); ok {
+		for nodeKey, nodeValue := range nodes {
+			if nodeMap, ok := nodeValue.(map[string]interface{}); ok && isModelLikeNode(nodeKey) {
+				columnOwners[nodeKey] = nodeMap
+			}
+		}
+	}
+	if sources, ok := manifestData["sources"].(map[string]interface{}); ok {
+		for sourceID, sourceValue := range sources {
+			if sourceMap, ok := sourceValue.(map[string]interface{}); ok {
+				columnOwners[sourceID] = sourceMap
+			}
+		}
+	}
+	for nodeKey, nodeMap := range columnOwners {
+		fromModelName := index.modelName(nodeKey)
+		if fromModelName == "" {
+			continue
+		}
+		if columns, ok := nodeMap["columns"].(map[string]interface{}); ok {
+			for columnName, colData := range columns {
+				if colMap, ok := colData.(map[string]interface{}); ok {
+					relationships = append(relationships, parseTestsForRelationships(fromModelName, columnName, colMap, index)...)
+				}
+			}
+		}
+	}
+
 	if nodes, ok := manifestData["nodes"].(map[string]interface{}); ok {
 		for nodeKey, nodeValue := range nodes {
 			nodeMap, ok := nodeValue.(map[string]interface{})
@@ -518,21 +863,6 @@ func generateRelationships(manifestData map[string]interface{}) []Relationship {
 				continue
 			}
 
-			// Case 1: Handle tests on model columns (including structs)
-			if strings.HasPrefix(nodeKey, "model.") {
-				fromModelName := getModelNameFromNodeKey(nodeKey)
-				if fromModelName == "" {
-					continue
-				}
-				if columns, ok := nodeMap["columns"].(map[string]interface{}); ok {
-					for columnName, colData := range columns {
-						if colMap, ok := colData.(map[string]interface{}); ok {
-							relationships = append(relationships, parseTestsForRelationships(fromModelName, columnName, colMap)...)
-						}
-					}
-				}
-			}
-
 			// Case 2: Handle compiled test nodes for simple columns
 			if strings.HasPrefix(nodeKey, "test.") {
 				if testMeta, ok := nodeMap["test_metadata"].(map[string]interface{}); ok {
@@ -540,10 +870,9 @@ func generateRelationships(manifestData map[string]interface{}) []Relationship {
 						if kwargs, ok := testMeta["kwargs"].(map[string]interface{}); ok {
 							toRef := getStringFromMap(kwargs, "to", "")
 							toField := getStringFromMap(kwargs, "field", "")
-							toModelName := parseRef(toRef)
+							toModelName := index.resolve(toRef)
 							fromColumnName := getStringFromMap(nodeMap, "column_name", "")
-							attachedNodeID := getStringFromMap(nodeMap, "attached_node", "")
-							fromModelName := getModelNameFromNodeKey(attachedNodeID)
+							fromModelName := index.modelName(testedNodeID(nodeMap, testMeta, index))
 
 							if toModelName != "" && toField != "" && fromModelName != "" && fromColumnName != "" {
 								rel := Relationship{
@@ -560,6 +889,12 @@ func generateRelationships(manifestData map[string]interface{}) []Relationship {
 			}
 		}
 	}
+	return uniqueRelationships(relationships)
+}
+
+// uniqueRelationships drops repeated relationships, such as a relationship
+// that is both tested in dbt and declared by a semantic model's entities.
+func uniqueRelationships(relationships []Relationship) []Relationship {
 	seen := make(map[string]struct{}, len(relationships))
 	// if relationship is empty, return empty slice instead of nil
 	unique := []Relationship{}
@@ -575,11 +910,11 @@ func generateRelationships(manifestData map[string]interface{}) []Relationship {
 }
 
 // parseTestsForRelationships is a helper function to extract relationship tests from a column or its fields.
-func parseTestsForRelationships(fromModelName, columnName string, colMap map[string]interface{}) []Relationship {
+func parseTestsForRelationships(fromModelName, columnName string, colMap map[string]interface{}, index dbtNodeIndex) []Relationship {
 	var relationships []Relationship
 	// Case 1: Tests are directly on the column.
 	if tests, ok := colMap["tests"].([]interface{}); ok {
-		relationships = append(relationships, extractRelationshipsFromTests(fromModelName, columnName, tests)...)
+		relationships = append(relationships, extractRelationshipsFromTests(fromModelName, columnName, tests, index)...)
 	}
 	// Case 2: Tests are on fields within a struct column.
 	if fields, ok := colMap["fields"].([]interface{}); ok {
@@ -590,7 +925,7 @@ func parseTestsForRelationships(fromModelName, columnName string, colMap map[str
 					continue
 				}
 				if tests, ok := fieldMap["tests"].([]interface{}); ok {
-					relationships = append(relationships, extractRelationshipsFromTests(fromModelName, fieldName, tests)...)
+					relationships = append(relationships, extractRelationshipsFromTests(fromModelName, fieldName, tests, index)...)
 				}
 			}
 		}
@@ -599,7 +934,7 @@ func parseTestsForRelationships(fromModelName, columnName string, colMap map[str
 }
 
 // extractRelationshipsFromTests extracts relationship info from a 'tests' array.
-func extractRelationshipsFromTests(fromModelName, fromColumnName string, tests []interface{}) []Relationship {
+func extractRelationshipsFromTests(fromModelName, fromColumnName string, tests []interface{}, index dbtNodeIndex) []Relationship {
 	// if relationship is empty, return empty slice instead of nil
 	relationships := []Relationship{}
 	for _, test := range tests {
@@ -607,7 +942,7 @@ func extractRelationshipsFromTests(fromModelName, fromColumnName string, tests [
 			if relData, ok := relTest["relationships"].(map[string]interface{}); ok {
 				toRef := getStringFromMap(relData, "to", "")
 				toField := getStringFromMap(relData, "field", "")
-				toModelName := parseRef(toRef)
+				toModelName := index.resolve(toRef)
 
 				if toModelName != "" && toField != "" {
 					rel := Relationship{
@@ -703,210 +1038,19 @@ func processColumnForTests(nodeKey, modelName, columnName string, colMap map[str
 	}
 }
 
-// convertDbtMetricsToLegibleMetrics converts dbt metrics from the semantic manifest into the Legible MDL format.
-// It serves as the main entry point for metric conversion, orchestrating the creation of lookup tables
-// and processing each metric definition.
-func convertDbtMetricsToLegibleMetrics(semanticData map[string]interface{}) []Metric {
-	var wrenMetrics []Metric
-
-	// --- 1. Pre-process semantic models to build fast lookup maps ---
-	// These maps are essential for quickly finding the model a measure belongs to and its details.
-	measureToModelMap, measureDataLookup := buildMeasureLookups(semanticData)
-
-	// --- 2. Iterate through each metric and convert it ---
-	metrics, ok := semanticData["metrics"].([]interface{})
-	if !ok {
-		// If there's no 'metrics' array, there's nothing to do.
-		return wrenMetrics
-	}
-
-	for _, m := range metrics {
-		metricMap, ok := m.(map[string]interface{})
-		if !ok {
-			continue // Skip if the item is not a valid map.
-		}
-
-		// --- 3. Extract basic metric information ---
-		metricName := getStringFromMap(metricMap, "name", "")
-		if metricName == "" {
-			continue // A metric must have a name.
-		}
-
-		wrenMetric := Metric{
-			Name:        metricName,
-			DisplayName: getStringFromMap(metricMap, "label", metricName),
-			Description: getStringFromMap(metricMap, "description", ""),
-		}
-
-		typeParams, _ := metricMap["type_params"].(map[string]interface{})
-
-		// --- 4. Determine the base model and time dimensions for the metric ---
-		baseModel := findBaseModelForMetric(typeParams, measureToModelMap)
-		if baseModel == "" {
-			pterm.Warning.Printf("Could not find a parent model for metric '%s'\n", metricName)
-			continue // Skip metric if we can't associate it with a model.
-		}
-
-		wrenMetric.Models = []string{baseModel}
-		wrenMetric.Dimensions = findTimeDimensionsForModel(semanticData, baseModel)
-
-		// --- 5. Build the specific aggregation expression based on the metric type ---
-		metricType := getStringFromMap(metricMap, "type", "")
-		wrenMetric.Aggregation = buildAggregationExpression(metricType, typeParams, measureDataLookup)
-
-		// --- 6. Final validation before adding to the list ---
-		// A metric is only valid if it has a base model and a valid aggregation expression.
-		if wrenMetric.Aggregation != "" && len(wrenMetric.Models) > 0 {
-			wrenMetrics = append(wrenMetrics, wrenMetric)
-		}
+// findManifestNode returns a node's manifest.json entry, looking up sources
+// in the manifest's separate "sources" section.
+func findManifestNode(manifestData map[string]interface{}, nodeKey string) map[string]interface{} {
+	section := "nodes"
+	if strings.HasPrefix(nodeKey, "source.") {
+		section = "sources"
 	}
-
-	return wrenMetrics
-}
-
-// buildMeasureLookups preprocesses the semantic models to create two essential maps:
-// 1. measureToModelMap: Maps a measure's name to the name of the model it belongs to.
-// 2. measureDataLookup: Maps a measure's name to its full data map for easy access to properties like `agg` and `expr`.
-func buildMeasureLookups(semanticData map[string]interface{}) (map[string]string, map[string]map[string]interface{}) {
-	measureToModelMap := make(map[string]string)
-	measureDataLookup := make(map[string]map[string]interface{})
-
-	semanticModels, ok := semanticData["semantic_models"].([]interface{})
+	nodes, ok := manifestData[section].(map[string]interface{})
 	if !ok {
-		return measureToModelMap, measureDataLookup
+		return nil
 	}
-
-	for _, sm := range semanticModels {
-		smMap, ok := sm.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		modelName := getStringFromMap(smMap, "name", "")
-		if modelName == "" {
-			continue
-		}
-
-		if measures, ok := smMap["measures"].([]interface{}); ok {
-			for _, m := range measures {
-				if measureMap, ok := m.(map[string]interface{}); ok {
-					measureName := getStringFromMap(measureMap, "name", "")
-					if measureName != "" {
-						measureToModelMap[measureName] = modelName
-						measureDataLookup[measureName] = measureMap
-					}
-				}
-			}
-		}
-	}
-	return measureToModelMap, measureDataLookup
-}
-
-// findBaseModelForMetric identifies the underlying base model for a given metric
-// by looking at its "input_measures".
-func findBaseModelForMetric(typeParams map[string]interface{}, measureToModelMap map[string]string) string {
-	inputMeasuresValue, ok := typeParams["input_measures"]
-	if !ok {
-		// Fallback for simple metrics that use "measure" instead of "input_measures"
-		if measureValue, ok := typeParams["measure"]; ok {
-			if measureMap, ok := measureValue.(map[string]interface{}); ok {
-				measureName := getStringFromMap(measureMap, "name", "")
-				if model, exists := measureToModelMap[measureName]; exists {
-					return model
-				}
-			}
-		}
-		return ""
-	}
-
-	inputMeasuresList, ok := inputMeasuresValue.([]interface{})
-	if !ok || len(inputMeasuresList) == 0 {
-		return ""
-	}
-
-	// Assume all measures for a given metric come from the same base model.
-	// We only need to find the first valid one.
-	for _, inputMeasure := range inputMeasuresList {
-		if imMap, ok := inputMeasure.(map[string]interface{}); ok {
-			imName := getStringFromMap(imMap, "name", "")
-			if model, exists := measureToModelMap[imName]; exists {
-				return model // Return the first model we find.
-			}
-		}
-	}
-	return ""
-}
-
-// findTimeDimensionsForModel scans the semantic models to find all columns
-// marked with type "time" for a specific model name.
-func findTimeDimensionsForModel(semanticData map[string]interface{}, baseModelName string) []string {
-	var timeDimensions []string
-	semanticModels, ok := semanticData["semantic_models"].([]interface{})
-	if !ok {
-		return timeDimensions
-	}
-
-	for _, sm := range semanticModels {
-		smMap, ok := sm.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		if getStringFromMap(smMap, "name", "") == baseModelName {
-			if dims, ok := smMap["dimensions"].([]interface{}); ok {
-				for _, d := range dims {
-					if dimMap, ok := d.(map[string]interface{}); ok {
-						if getStringFromMap(dimMap, "type", "") == "time" {
-							timeDimensions = append(timeDimensions, getStringFromMap(dimMap, "name", ""))
-						}
-					}
-				}
-			}
-			break // Found the model, no need to continue looping.
-		}
-	}
-	return timeDimensions
-}
-
-// buildAggregationExpression constructs the SQL aggregation string for a Wren metric
-// based on its dbt type ('simple', 'ratio', or 'derived').
-func buildAggregationExpression(metricType string, typeParams map[string]interface{}, measureDataLookup map[string]map[string]interface{}) string {
-	switch metricType {
-	case "simple":
-		// A simple metric is a direct aggregation of one measure (e.g., SUM(revenue)).
-		if measure, ok := typeParams["measure"].(map[string]interface{}); ok {
-			measureName := getStringFromMap(measure, "name", "")
-			if measureData, ok := measureDataLookup[measureName]; ok {
-				agg := getStringFromMap(measureData, "agg", "sum")         // Default to SUM
-				expr := getStringFromMap(measureData, "expr", measureName) // Fallback to measure name
-				return fmt.Sprintf("%s(%s)", strings.ToUpper(agg), expr)
-			}
-		}
-	case "ratio":
-		// A ratio metric is a division of two measures (e.g., SUM(profit) / SUM(revenue)).
-		num, numOK := typeParams["numerator"].(map[string]interface{})
-		den, denOK := typeParams["denominator"].(map[string]interface{})
-		if !numOK || !denOK {
-			return ""
-		}
-
-		numName := getStringFromMap(num, "name", "")
-		denName := getStringFromMap(den, "name", "")
-		numData, numDataOK := measureDataLookup[numName]
-		denData, denDataOK := measureDataLookup[denName]
-
-		if numDataOK && denDataOK {
-			numAgg := strings.ToUpper(getStringFromMap(numData, "agg", "sum"))
-			denAgg := strings.ToUpper(getStringFromMap(denData, "agg", "sum"))
-			numExpr := getStringFromMap(numData, "expr", numName)
-			denExpr := getStringFromMap(denData, "expr", denName)
-			return fmt.Sprintf("(%s(%s)) / (%s(%s))", numAgg, numExpr, denAgg, denExpr)
-		}
-	case "derived":
-		// A derived metric uses a freeform SQL expression.
-		return getStringFromMap(typeParams, "expr", "")
-	}
-	return "" // Return empty string if no valid aggregation could be built.
+	manifestNode, _ := nodes[nodeKey].(map[string]interface{})
+	return manifestNode
 }
 
 // extractDescriptionsFromManifest parses the manifest.json data to find the
@@ -916,13 +1060,8 @@ func extractDescriptionsFromManifest(manifestData map[string]interface{}, nodeKe
 		return "", nil
 	}
 
-	nodes, ok := manifestData["nodes"].(map[string]interface{})
-	if !ok {
-		return "", nil
-	}
-
-	manifestNode, ok := nodes[nodeKey].(map[string]interface{})
-	if !ok {
+	manifestNode := findManifestNode(manifestData, nodeKey)
+	if manifestNode == nil {
 		return "", nil
 	}
 
@@ -1027,10 +1166,9 @@ func convertAndSortColumns(nodeData map[string]interface{}, nodeKey string, data
 	return wrenColumns, nil
 }
 
-// convertDbtNodeToLegibleModel converts a single dbt node to a Wren model.
+// convertDbtNodeToLegibleModel converts a single dbt node (a model, source, seed or snapshot) to a Wren model.
 // This function now orchestrates calls to helpers to perform the conversion.
-func convertDbtNodeToLegibleModel(nodeKey string, nodeData map[string]interface{}, dataSource DataSource, manifestData map[string]interface{}, columnToEnumNameMap map[string]string, columnToNotNullMap map[string]bool, modelToPrimaryKeyMap map[string]string) (*LegibleModel, error) {
-	modelName := getModelNameFromNodeKey(nodeKey)
+func convertDbtNodeToLegibleModel(nodeKey, modelName string, nodeData map[string]interface{}, dataSource DataSource, manifestData map[string]interface{}, columnToEnumNameMap map[string]string, columnToNotNullMap map[string]bool, modelToPrimaryKeyMap map[string]string) (*LegibleModel, error) {
 	if modelName == "" {
 		return nil, fmt.Errorf("invalid node key format: %s", nodeKey)
 	}
@@ -1076,9 +1214,62 @@ func convertDbtNodeToLegibleModel(nodeKey string, nodeData map[string]interface{
 		model.Properties = map[string]string{"description": modelDescription}
 	}
 
+	// Record the resource type of nodes that are not dbt models
+	if resourceType := strings.SplitN(nodeKey, ".", 2)[0]; resourceType != "model" {
+		if model.Properties == nil {
+			model.Properties = make(map[string]string)
+		}
+		model.Properties["dbtResourceType"] = resourceType
+	}
+
+	if strings.HasPrefix(nodeKey, "snapshot.") {
+		annotateSnapshotColumns(model.Columns, findManifestNode(manifestData, nodeKey))
+	}
+
 	return model, nil
 }
 
+// annotateSnapshotColumns marks the columns in which a dbt snapshot records
+// the period each row was valid for, so that current rows can be told apart
+// from history. The column names can be changed with the snapshot's
+// snapshot_meta_column_names config.
+func annotateSnapshotColumns(columns []LegibleColumn, manifestNode map[string]interface{}) {
+	config := getMapFromMap(manifestNode, "config", nil)
+	metaColumnNames := getMapFromMap(config, "snapshot_meta_column_names", nil)
+	validity := []struct {
+		column      string
+		property    string
+		description string
+	}{
+		{
+			column:      getStringFromMap(metaColumnNames, "dbt_valid_from", "dbt_valid_from"),
+			property:    "valid_from",
+			description: "The time from which this snapshot row is valid.",
+		},
+		{
+			column:      getStringFromMap(metaColumnNames, "dbt_valid_to", "dbt_valid_to"),
+			property:    "valid_to",
+			description: "The time until which this snapshot row is valid; null for the current row.",
+		},
+	}
+
+	for i := range columns {
+		for _, v := range validity {
+			// Some warehouses, such as Snowflake, report column names in upper case.
+			if !strings.EqualFold(columns[i].Name, v.column) {
+				continue
+			}
+			if columns[i].Properties == nil {
+				columns[i].Properties = make(map[string]string)
+			}
+			columns[i].Properties["snapshotValidity"] = v.property
+			if columns[i].Properties["description"] == "" {
+				columns[i].Properties["description"] = v.description
+			}
+		}
+	}
+}
+
 // getStringFromMap safely extracts a string value from a map
 func getStringFromMap(m map[string]interface{}, key, defaultValue string) string {
 	if m == nil {
@@ -0,0 +1,235 @@
+package dbt
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func writeJSONFile(t *testing.T, dir, name string, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal %s: %v", name, err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func catalogNode(name string, columns ...string) map[string]interface{} {
+	cols := make(map[string]interface{})
+	for i, c := range columns {
+		cols[c] = map[string]interface{}{"name": c, "type": "text", "index": i + 1}
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "schema": "analytics", "database": "warehouse"},
+		"columns":  cols,
+	}
+}
+
+func relationshipsTest(attachedNode, model, column, to, field string) map[string]interface{} {
+	return map[string]interface{}{
+		"attached_node": attachedNode,
+		"column_name":   column,
+		"test_metadata": map[string]interface{}{
+			"name": "relationships",
+			"kwargs": map[string]interface{}{
+				"model": model,
+				"to":    to,
+				"field": field,
+			},
+		},
+	}
+}
+
+func writeDbtArtifacts(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	catalog := map[string]interface{}{
+		"nodes": map[string]interface{}{
+			"model.shop.orders":      catalogNode("orders", "id", "customer_id", "raw_payment_id"),
+			"model.shop.stg_orders":  catalogNode("stg_orders", "id"),
+			"seed.shop.country":      catalogNode("country", "code", "name"),
+			"snapshot.shop.products": catalogNode("products", "id", "DBT_VALID_FROM", "dbt_valid_to"),
+		},
+		"sources": map[string]interface{}{
+			"source.shop.raw.customers": catalogNode("customers", "id", "country_code"),
+			"source.shop.raw.orders":    catalogNode("orders", "id"),
+			"source.shop.raw.payments":  catalogNode("payments", "id"),
+		},
+	}
+	manifest := map[string]interface{}{
+		"nodes": map[string]interface{}{
+			"snapshot.shop.products": map[string]interface{}{
+				"columns": map[string]interface{}{
+					"dbt_valid_to": map[string]interface{}{"description": "Closed when the product changes."},
+				},
+			},
+			"test.shop.rel_orders_customer":  relationshipsTest("model.shop.orders", "{{ get_where_subquery(ref('orders')) }}", "customer_id", "source('raw', 'customers')", "id"),
+			"test.shop.rel_orders_payment":   relationshipsTest("model.shop.orders", "{{ get_where_subquery(ref('orders')) }}", "raw_payment_id", `source("raw", "payments")`, "id"),
+			"test.shop.rel_customer_country": relationshipsTest("", "{{ get_where_subquery(source('raw', 'customers')) }}", "country_code", "ref('country')", "code"),
+		},
+		"sources": map[string]interface{}{
+			"source.shop.raw.customers": map[string]interface{}{"source_name": "raw", "name": "customers", "description": "Customers from the shop database."},
+			"source.shop.raw.orders":    map[string]interface{}{"source_name": "raw", "name": "orders"},
+			"source.shop.raw.payments":  map[string]interface{}{"source_name": "raw", "name": "payments"},
+		},
+	}
+	return writeJSONFile(t, dir, "catalog.json", catalog), writeJSONFile(t, dir, "manifest.json", manifest)
+}
+
+func modelsByName(manifest *LegibleMDLManifest) map[string]LegibleModel {
+	models := make(map[string]LegibleModel)
+	for _, m := range manifest.Models {
+		models[m.Name] = m
+	}
+	return models
+}
+
+func relationshipNames(manifest *LegibleMDLManifest) []string {
+	var names []string
+	for _, r := range manifest.Relationships {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestConvertDbtCatalogModelsOnlyByDefault(t *testing.T) {
+	catalogPath, manifestPath := writeDbtArtifacts(t)
+
+	manifest, err := ConvertDbtCatalogToLegibleMDL(catalogPath, &DefaultDataSource{}, manifestPath, "", CatalogConvertOptions{})
+	if err != nil {
+		t.Fatalf("ConvertDbtCatalogToLegibleMDL() error = %v", err)
+	}
+
+	if len(manifest.Models) != 1 || manifest.Models[0].Name != "orders" {
+		t.Fatalf("expected only the orders model, got %+v", manifest.Models)
+	}
+	// Relationships to sources are only generated when sources are converted.
+	if names := relationshipNames(manifest); len(names) != 0 {
+		t.Errorf("expected no relationships, got %v", names)
+	}
+}
+
+func TestConvertDbtCatalogSourcesSeedsAndSnapshots(t *testing.T) {
+	catalogPath, manifestPath := writeDbtArtifacts(t)
+
+	manifest, err := ConvertDbtCatalogToLegibleMDL(catalogPath, &DefaultDataSource{}, manifestPath, "", CatalogConvertOptions{
+		IncludeSources:   true,
+		IncludeSeeds:     true,
+		IncludeSnapshots: true,
+	})
+	if err != nil {
+		t.Fatalf("ConvertDbtCatalogToLegibleMDL() error = %v", err)
+	}
+
+	models := modelsByName(manifest)
+	var names []string
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// The orders source is prefixed with its source name, as the orders model takes its name.
+	want := []string{"country", "customers", "orders", "payments", "products", "raw_orders"}
+	if len(names) != len(want) {
+		t.Fatalf("models = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("models = %v, want %v", names, want)
+		}
+	}
+
+	customers := models["customers"]
+	if customers.TableReference.Table != "customers" || customers.TableReference.Schema != "analytics" {
+		t.Errorf("unexpected customers table reference: %+v", customers.TableReference)
+	}
+	if customers.Properties["dbtResourceType"] != "source" || customers.Properties["description"] != "Customers from the shop database." {
+		t.Errorf("unexpected customers properties: %v", customers.Properties)
+	}
+	if _, ok := models["orders"].Properties["dbtResourceType"]; ok {
+		t.Errorf("dbt models should not record a resource type: %v", models["orders"].Properties)
+	}
+	if models["country"].Properties["dbtResourceType"] != "seed" {
+		t.Errorf("unexpected country properties: %v", models["country"].Properties)
+	}
+
+	products := models["products"]
+	if products.Properties["dbtResourceType"] != "snapshot" {
+		t.Errorf("unexpected products properties: %v", products.Properties)
+	}
+	for _, col := range products.Columns {
+		switch col.Name {
+		case "DBT_VALID_FROM":
+			if col.Properties["snapshotValidity"] != "valid_from" || col.Properties["description"] == "" {
+				t.Errorf("unexpected DBT_VALID_FROM properties: %v", col.Properties)
+			}
+		case "dbt_valid_to":
+			if col.Properties["snapshotValidity"] != "valid_to" || col.Properties["description"] != "Closed when the product changes." {
+				t.Errorf("unexpected dbt_valid_to properties: %v", col.Properties)
+			}
+		default:
+			if col.Properties["snapshotValidity"] != "" {
+				t.Errorf("column %s should not be annotated: %v", col.Name, col.Properties)
+			}
+		}
+	}
+
+	wantRels := []string{
+		"customers_to_country_by_country_code",
+		"orders_to_customers_by_customer_id",
+		"orders_to_payments_by_raw_payment_id",
+	}
+	rels := relationshipNames(manifest)
+	if len(rels) != len(wantRels) {
+		t.Fatalf("relationships = %v, want %v", rels, wantRels)
+	}
+	for i := range wantRels {
+		if rels[i] != wantRels[i] {
+			t.Fatalf("relationships = %v, want %v", rels, wantRels)
+		}
+	}
+}
+
+func TestAnnotateSnapshotColumnsCustomNames(t *testing.T) {
+	columns := []LegibleColumn{{Name: "valid_from"}, {Name: "valid_to"}, {Name: "dbt_valid_to"}}
+	annotateSnapshotColumns(columns, map[string]interface{}{
+		"config": map[string]interface{}{
+			"snapshot_meta_column_names": map[string]interface{}{
+				"dbt_valid_from": "valid_from",
+				"dbt_valid_to":   "valid_to",
+			},
+		},
+	})
+
+	if columns[0].Properties["snapshotValidity"] != "valid_from" || columns[1].Properties["snapshotValidity"] != "valid_to" {
+		t.Errorf("custom validity columns not annotated: %+v", columns)
+	}
+	if columns[2].Properties != nil {
+		t.Errorf("default column name should not be annotated when renamed: %+v", columns[2])
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		in, source, table string
+	}{
+		{"source('raw', 'orders')", "raw", "orders"},
+		{`source( "raw" , "orders" )`, "raw", "orders"},
+		{"{{ get_where_subquery(source('raw', 'orders')) }}", "raw", "orders"},
+		{"ref('orders')", "", ""},
+		{"source('raw')", "", ""},
+	}
+	for _, tt := range tests {
+		source, table := parseSource(tt.in)
+		if source != tt.source || table != tt.table {
+			t.Errorf("parseSource(%q) = %q, %q, want %q, %q", tt.in, source, table, tt.source, tt.table)
+		}
+	}
+}
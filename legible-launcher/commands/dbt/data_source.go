@@ -87,6 +87,9 @@ func FromDbtProfiles(profiles *DbtProfiles) ([]DataSource, error) {
 
 // convertConnectionToDataSource converts connection to corresponding DataSource based on connection type
 func convertConnectionToDataSource(conn DbtConnection, dbtHomePath, profileName, outputName string) (DataSource, error) {
+	if conn.RenderError != nil {
+		return nil, fmt.Errorf("failed to render %s.%s: %w", profileName, outputName, conn.RenderError)
+	}
 	switch strings.ToLower(conn.Type) {
 	case postgresType, "postgresql":
 		return convertToPostgresDataSource(conn)
@@ -98,7 +101,21 @@ func convertConnectionToDataSource(conn DbtConnection, dbtHomePath, profileName,
 		return convertToMysqlDataSource(conn)
 	case "bigquery":
 		// Pass the dbtHomePath to the BigQuery converter
-		return convertToBigQueryDataSource(conn, dbtHomePath)
+		return skippedAsNil(convertToBigQueryDataSource(conn, dbtHomePath))
+	case "snowflake":
+		return convertToSnowflakeDataSource(conn, dbtHomePath)
+	case "redshift":
+		return skippedAsNil(convertToRedshiftDataSource(conn))
+	case "trino":
+		return skippedAsNil(convertToTrinoDataSource(conn))
+	case "athena":
+		return convertToAthenaDataSource(conn)
+	case "databricks":
+		return skippedAsNil(convertToDatabricksDataSource(conn))
+	case "clickhouse":
+		return convertToClickHouseDataSource(conn)
+	case "oracle":
+		return skippedAsNil(convertToOracleDataSource(conn))
 	default:
 		// For unsupported database types, we can choose to ignore or return error
 		// Here we choose to return nil and log a warning
@@ -107,6 +124,38 @@ func convertConnectionToDataSource(conn DbtConnection, dbtHomePath, profileName,
 	}
 }
 
+// skippedAsNil returns a nil DataSource when a converter skipped the
+// connection by returning a nil pointer, which would otherwise be a
+// non-nil interface value
+func skippedAsNil[T any, P interface {
+	*T
+	DataSource
+}](ds P, err error) (DataSource, error) {
+	if ds == nil {
+		return nil, err
+	}
+	return ds, err
+}
+
+// additionalString returns a string property that has no dedicated
+// DbtConnection field, or "" if it is missing
+func additionalString(conn DbtConnection, key string) string {
+	if v, ok := conn.Additional[key].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+// splitSourceType splits a parameterized type such as "NUMBER(38,0)" or
+// "array<string>" into its upper-cased base name and parameters
+func splitSourceType(sourceType string) (string, string) {
+	upper := strings.ToUpper(strings.TrimSpace(sourceType))
+	if i := strings.IndexAny(upper, "(<"); i >= 0 {
+		return strings.TrimSpace(upper[:i]), upper[i+1:]
+	}
+	return upper, ""
+}
+
 // convertToPostgresDataSource converts to PostgreSQL data source
 func convertToPostgresDataSource(conn DbtConnection) (*LegiblePostgresDataSource, error) {
 	// For PostgreSQL, prefer dbname over database field
@@ -307,6 +356,275 @@ func convertToBigQueryDataSource(conn DbtConnection, dbtHomePath string) (*Legib
 	return ds, nil
 }
 
+// convertToSnowflakeDataSource converts to Snowflake data source
+func convertToSnowflakeDataSource(conn DbtConnection, dbtHomePath string) (*LegibleSnowflakeDataSource, error) {
+	pterm.Info.Printf("Converting Snowflake data source: %s/%s.%s\n", conn.Account, conn.Database, conn.Schema)
+
+	ds := &LegibleSnowflakeDataSource{
+		Account:       conn.Account,
+		User:          conn.User,
+		Password:      conn.Password,
+		Database:      conn.Database,
+		Schema:        conn.Schema,
+		Warehouse:     conn.Warehouse,
+		Role:          conn.Role,
+		Authenticator: additionalString(conn, "authenticator"),
+		Token:         additionalString(conn, "token"),
+	}
+
+	// Key-pair authentication: private_key_path takes precedence over inline private_key
+	var privateKey []byte
+	if keyPath := additionalString(conn, "private_key_path"); keyPath != "" {
+		// If the key path is not absolute, join it
+		// with the dbt project's home directory path.
+		if !filepath.IsAbs(keyPath) && dbtHomePath != "" {
+			keyPath = filepath.Join(dbtHomePath, keyPath)
+		}
+		cleanPath := filepath.Clean(keyPath)
+		b, err := os.ReadFile(cleanPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key '%s': %w", cleanPath, err)
+		}
+		privateKey = b
+	} else if key := additionalString(conn, "private_key"); key != "" {
+		privateKey = []byte(key)
+	}
+	if privateKey != nil {
+		key, err := normalizePrivateKey(privateKey, additionalString(conn, "private_key_passphrase"))
+		if err != nil {
+			return nil, fmt.Errorf("snowflake: %w", err)
+		}
+		ds.PrivateKey = key
+	}
+
+	if strings.EqualFold(ds.Authenticator, "externalbrowser") {
+		pterm.Warning.Println("snowflake: the 'externalbrowser' authenticator opens a browser on the machine running the Legible engine")
+	}
+
+	return ds, nil
+}
+
+// convertToRedshiftDataSource converts to Redshift data source
+func convertToRedshiftDataSource(conn DbtConnection) (*LegibleRedshiftDataSource, error) {
+	dbName := conn.DbName
+	if dbName == "" {
+		dbName = conn.Database
+	}
+
+	method := strings.ToLower(strings.TrimSpace(conn.Method))
+	switch method {
+	case "database", "":
+		pterm.Info.Printf("Converting Redshift data source: %s:%d/%s\n", conn.Host, conn.Port, dbName)
+		port := strconv.Itoa(conn.Port)
+		if conn.Port == 0 {
+			port = "5439"
+		}
+		return &LegibleRedshiftDataSource{
+			Host:     conn.Host,
+			Port:     port,
+			Database: dbName,
+			User:     conn.User,
+			Password: conn.Password,
+		}, nil
+	case "iam":
+		clusterID := additionalString(conn, "cluster_id")
+		pterm.Info.Printf("Converting Redshift IAM data source: %s/%s\n", clusterID, dbName)
+		ds := &LegibleRedshiftDataSource{
+			IAM:               true,
+			ClusterIdentifier: clusterID,
+			Database:          dbName,
+			User:              conn.User,
+			Region:            additionalString(conn, "region"),
+			AccessKeyID:       additionalString(conn, "access_key_id"),
+			AccessKeySecret:   additionalString(conn, "secret_access_key"),
+		}
+		if ds.AccessKeyID == "" {
+			// dbt falls back to iam_profile (or the default profile) for
+			// credentials; its keys are set by setAWSProfileKeys
+			ds.AwsProfile = awsProfileName(additionalString(conn, "iam_profile"))
+			if ds.Region == "" {
+				ds.Region = awsProfileRegion(ds.AwsProfile)
+			}
+		}
+		return ds, nil
+	default:
+		pterm.Warning.Printf("redshift: unsupported auth method '%s'; supported: database, iam\n", method)
+		return nil, nil
+	}
+}
+
+// convertToTrinoDataSource converts to Trino data source
+func convertToTrinoDataSource(conn DbtConnection) (*LegibleTrinoDataSource, error) {
+	method := strings.ToLower(strings.TrimSpace(conn.Method))
+	if method != "" && method != "none" && method != "ldap" {
+		pterm.Warning.Printf("trino: unsupported auth method '%s'; supported: none, ldap\n", method)
+		return nil, nil
+	}
+
+	pterm.Info.Printf("Converting Trino data source: %s:%d/%s.%s\n", conn.Host, conn.Port, conn.Database, conn.Schema)
+	httpScheme := strings.ToLower(additionalString(conn, "http_scheme"))
+	if httpScheme == "" {
+		// dbt-trino defaults to https whenever credentials are sent
+		httpScheme = "http"
+		if method == "ldap" {
+			httpScheme = "https"
+		}
+	}
+	port := strconv.Itoa(conn.Port)
+	if conn.Port == 0 {
+		port = "8080"
+		if httpScheme == "https" {
+			port = "443"
+		}
+	}
+
+	ds := &LegibleTrinoDataSource{
+		Host:       conn.Host,
+		Port:       port,
+		Catalog:    conn.Database,
+		Schema:     conn.Schema,
+		User:       conn.User,
+		HTTPScheme: httpScheme,
+	}
+	if method == "ldap" {
+		ds.Password = conn.Password
+	}
+	return ds, nil
+}
+
+// convertToAthenaDataSource converts to Athena data source
+func convertToAthenaDataSource(conn DbtConnection) (*LegibleAthenaDataSource, error) {
+	pterm.Info.Printf("Converting Athena data source: %s/%s\n", conn.Database, conn.Schema)
+
+	ds := &LegibleAthenaDataSource{
+		S3StagingDir:       additionalString(conn, "s3_staging_dir"),
+		Region:             additionalString(conn, "region_name"),
+		Schema:             conn.Schema,
+		WorkGroup:          additionalString(conn, "work_group"),
+		AwsProfile:         additionalString(conn, "aws_profile_name"),
+		AwsAccessKeyID:     additionalString(conn, "aws_access_key_id"),
+		AwsSecretAccessKey: additionalString(conn, "aws_secret_access_key"),
+	}
+	if additionalString(conn, "aws_session_token") != "" {
+		pterm.Warning.Println("athena: aws_session_token is temporary and is not written to the data source; use a long-lived access key")
+	}
+	if ds.AwsProfile != "" && ds.AwsAccessKeyID == "" && ds.Region == "" {
+		ds.Region = awsProfileRegion(ds.AwsProfile)
+	}
+	if ds.WorkGroup != "" && ds.WorkGroup != "primary" {
+		pterm.Warning.Printf("athena: work group '%s' is not passed to Legible; queries will run in the primary work group\n", ds.WorkGroup)
+	}
+	if c := strings.ToLower(conn.Database); c != "" && c != "awsdatacatalog" {
+		pterm.Warning.Printf("athena: catalog '%s' is not passed to Legible; queries will use AwsDataCatalog\n", conn.Database)
+	}
+
+	return ds, nil
+}
+
+// convertToDatabricksDataSource converts to Databricks data source
+func convertToDatabricksDataSource(conn DbtConnection) (*LegibleDatabricksDataSource, error) {
+	// dbt-databricks takes a bare hostname, but a pasted workspace URL is a common mistake
+	host := strings.TrimSuffix(strings.TrimPrefix(conn.Host, "https://"), "/")
+	catalog := additionalString(conn, "catalog")
+	pterm.Info.Printf("Converting Databricks data source: %s (catalog %s, schema %s)\n", host, catalog, conn.Schema)
+
+	ds := &LegibleDatabricksDataSource{
+		ServerHostname: host,
+		HTTPPath:       additionalString(conn, "http_path"),
+		Catalog:        catalog,
+		Schema:         conn.Schema,
+	}
+	switch {
+	case additionalString(conn, "token") != "":
+		ds.AccessToken = additionalString(conn, "token")
+	case additionalString(conn, "client_secret") != "":
+		ds.ClientID = additionalString(conn, "client_id")
+		ds.ClientSecret = additionalString(conn, "client_secret")
+	case additionalString(conn, "azure_client_secret") != "":
+		ds.ClientID = additionalString(conn, "azure_client_id")
+		ds.ClientSecret = additionalString(conn, "azure_client_secret")
+		ds.AzureTenantID = additionalString(conn, "azure_tenant_id")
+	default:
+		pterm.Warning.Println("databricks: only token and OAuth machine-to-machine (client_id/client_secret) auth are supported; skipping data source")
+		return nil, nil
+	}
+	return ds, nil
+}
+
+// convertToClickHouseDataSource converts to ClickHouse data source
+func convertToClickHouseDataSource(conn DbtConnection) (*LegibleClickHouseDataSource, error) {
+	// dbt-clickhouse calls the ClickHouse database a schema
+	database := conn.Schema
+	if database == "" {
+		database = "default"
+	}
+	user := conn.User
+	if user == "" {
+		user = "default"
+	}
+	secure, _ := conn.Additional["secure"].(bool)
+	pterm.Info.Printf("Converting ClickHouse data source: %s:%d/%s\n", conn.Host, conn.Port, database)
+
+	port := strconv.Itoa(conn.Port)
+	if strings.EqualFold(additionalString(conn, "driver"), "native") {
+		// The Legible engine connects over HTTP, so the native protocol port does not apply
+		pterm.Warning.Println("clickhouse: the native driver is not supported; connecting over HTTP on the default port")
+		port = ""
+	}
+	if conn.Port == 0 || port == "" {
+		port = "8123"
+		if secure {
+			port = "8443"
+		}
+	}
+
+	return &LegibleClickHouseDataSource{
+		Host:     conn.Host,
+		Port:     port,
+		Database: database,
+		User:     user,
+		Password: conn.Password,
+		Secure:   secure,
+	}, nil
+}
+
+// convertToOracleDataSource converts to Oracle data source
+func convertToOracleDataSource(conn DbtConnection) (*LegibleOracleDataSource, error) {
+	if additionalString(conn, "tns_name") != "" {
+		pterm.Warning.Println("oracle: tns_name requires a tnsnames.ora file, which Legible cannot read; use host/port/service or connection_string instead")
+		return nil, nil
+	}
+
+	// dbt-oracle names the password field "pass"
+	password := conn.Password
+	if password == "" {
+		password = additionalString(conn, "pass")
+	}
+	port := strconv.Itoa(conn.Port)
+	if conn.Port == 0 {
+		port = "1521"
+	}
+
+	ds := &LegibleOracleDataSource{
+		Host:     conn.Host,
+		Port:     port,
+		Database: additionalString(conn, "service"),
+		SID:      additionalString(conn, "sid"),
+		DSN:      additionalString(conn, "connection_string"),
+		User:     conn.User,
+		Password: password,
+	}
+	if ds.Database == "" && ds.SID == "" {
+		ds.Database = conn.Database
+	}
+	if ds.DSN != "" {
+		pterm.Info.Printf("Converting Oracle data source: %s\n", ds.DSN)
+	} else {
+		pterm.Info.Printf("Converting Oracle data source: %s:%s/%s%s\n", ds.Host, ds.Port, ds.Database, ds.SID)
+	}
+	return ds, nil
+}
+
 type LegibleLocalFileDataSource struct {
 	Url    string `json:"url"`
 	Format string `json:"format"`
@@ -600,12 +918,632 @@ func (ds *LegibleBigQueryDataSource) MapType(sourceType string) string {
 	}
 }
 
+type LegibleSnowflakeDataSource struct {
+	Account    string `json:"account"`
+	User       string `json:"user"`
+	Password   string `json:"password"`
+	Database   string `json:"database"`
+	Schema     string `json:"schema"`
+	Warehouse  string `json:"warehouse"`
+	PrivateKey string `json:"private_key"`
+	// Role, Authenticator and Token are passed to the Snowflake connector as kwargs
+	Role          string `json:"role"`
+	Authenticator string `json:"authenticator"`
+	Token         string `json:"token"`
+}
+
+// GetType implements DataSource interface
+func (ds *LegibleSnowflakeDataSource) GetType() string {
+	return "snowflake"
+}
+
+// Validate implements DataSource interface
+func (ds *LegibleSnowflakeDataSource) Validate() error {
+	if strings.TrimSpace(ds.Account) == "" {
+		return fmt.Errorf("account cannot be empty")
+	}
+	if strings.TrimSpace(ds.User) == "" {
+		return fmt.Errorf("user cannot be empty")
+	}
+	if strings.TrimSpace(ds.Database) == "" {
+		return fmt.Errorf("database cannot be empty")
+	}
+	if strings.TrimSpace(ds.Schema) == "" {
+		return fmt.Errorf("schema cannot be empty")
+	}
+	if ds.Password == "" && ds.PrivateKey == "" && ds.Authenticator == "" {
+		return fmt.Errorf("one of password, private key or authenticator must be specified")
+	}
+	if strings.EqualFold(ds.Authenticator, "oauth") && ds.Token == "" {
+		return fmt.Errorf("token cannot be empty for the oauth authenticator")
+	}
+	return nil
+}
+
+// Kwargs returns the connection arguments that have no dedicated property
+// in the Legible engine's Snowflake connection info.
+func (ds *LegibleSnowflakeDataSource) Kwargs() map[string]interface{} {
+	kwargs := map[string]interface{}{}
+	if ds.Role != "" {
+		kwargs["role"] = ds.Role
+	}
+	if ds.Authenticator != "" {
+		kwargs["authenticator"] = ds.Authenticator
+	}
+	if ds.Token != "" {
+		kwargs["token"] = ds.Token
+	}
+	return kwargs
+}
+
+// MapType implements DataSource interface
+func (ds *LegibleSnowflakeDataSource) MapType(sourceType string) string {
+	baseType, params := splitSourceType(sourceType)
+	switch baseType {
+	case "NUMBER", "DECIMAL", "NUMERIC":
+		// NUMBER(p, 0) holds whole numbers only
+		if _, scale, ok := strings.Cut(strings.TrimSuffix(params, ")"), ","); ok && strings.TrimSpace(scale) == "0" {
+			return bigintType
+		}
+		return decimalType
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "BYTEINT":
+		return bigintType
+	case "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "DOUBLE PRECISION", "REAL":
+		return doubleType
+	case "VARCHAR", "STRING", "TEXT", "CHAR", "CHARACTER", "NCHAR", "NVARCHAR", "NVARCHAR2", "CHAR VARYING", "NCHAR VARYING":
+		return varcharType
+	case "BINARY", "VARBINARY":
+		return varcharType
+	case "BOOLEAN":
+		return booleanType
+	case "DATE":
+		return dateType
+	case "TIME":
+		return "time"
+	case "DATETIME", "TIMESTAMP", "TIMESTAMP_NTZ", "TIMESTAMP WITHOUT TIME ZONE":
+		return timestampType
+	case "TIMESTAMP_LTZ", "TIMESTAMP_TZ", "TIMESTAMP WITH LOCAL TIME ZONE", "TIMESTAMP WITH TIME ZONE":
+		return timestamptzType
+	case "VARIANT", "OBJECT", "ARRAY":
+		return jsonType
+	default:
+		return strings.ToLower(sourceType)
+	}
+}
+
+type LegibleRedshiftDataSource struct {
+	// IAM selects IAM authentication with ClusterIdentifier, Region and the
+	// access keys instead of Host, Port and Password
+	IAM               bool   `json:"-"`
+	Host              string `json:"host,omitempty"`
+	Port              string `json:"port,omitempty"`
+	Database          string `json:"database"`
+	User              string `json:"user"`
+	Password          string `json:"password,omitempty"`
+	ClusterIdentifier string `json:"cluster_identifier,omitempty"`
+	Region            string `json:"region,omitempty"`
+	AccessKeyID       string `json:"access_key_id,omitempty"`
+	AccessKeySecret   string `json:"access_key_secret,omitempty"`
+	// AwsProfile is the AWS profile to read the access keys from when the
+	// dbt profile gives none (see setAWSProfileKeys)
+	AwsProfile string `json:"-"`
+}
+
+// GetType implements DataSource interface
+func (ds *LegibleRedshiftDataSource) GetType() string {
+	return "redshift"
+}
+
+// Validate implements DataSource interface
+func (ds *LegibleRedshiftDataSource) Validate() error {
+	if strings.TrimSpace(ds.Database) == "" {
+		return fmt.Errorf("database cannot be empty")
+	}
+	if strings.TrimSpace(ds.User) == "" {
+		return fmt.Errorf("user cannot be empty")
+	}
+	if ds.IAM {
+		if strings.TrimSpace(ds.ClusterIdentifier) == "" {
+			return fmt.Errorf("cluster_identifier cannot be empty")
+		}
+		if strings.TrimSpace(ds.Region) == "" {
+			return fmt.Errorf("region cannot be empty")
+		}
+		if ds.AwsProfile == "" && (ds.AccessKeyID == "" || ds.AccessKeySecret == "") {
+			return fmt.Errorf("access_key_id and access_key_secret cannot be empty")
+		}
+		return nil
+	}
+	if strings.TrimSpace(ds.Host) == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	port, err := strconv.Atoi(ds.Port)
+	if err != nil {
+		return fmt.Errorf("port must be a valid number")
+	}
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if ds.Password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	return nil
+}
+
+// MapType implements DataSource interface
+func (ds *LegibleRedshiftDataSource) MapType(sourceType string) string {
+	baseType, _ := splitSourceType(sourceType)
+	switch baseType {
+	case "SMALLINT", "INT2":
+		return smallintType
+	case "INTEGER", "INT", "INT4":
+		return integerType
+	case "BIGINT", "INT8":
+		return bigintType
+	case "DECIMAL", "NUMERIC":
+		return decimalType
+	case "REAL", "FLOAT4":
+		return floatType
+	case "DOUBLE PRECISION", "FLOAT8", "FLOAT":
+		return doubleType
+	case "BOOLEAN", "BOOL":
+		return booleanType
+	case "CHAR", "CHARACTER", "NCHAR", "BPCHAR":
+		return charType
+	case "VARCHAR", "CHARACTER VARYING", "NVARCHAR", "TEXT":
+		return varcharType
+	case "VARBYTE", "VARBINARY", "BINARY VARYING":
+		return varcharType
+	case "DATE":
+		return dateType
+	case "TIME", "TIME WITHOUT TIME ZONE", "TIMETZ", "TIME WITH TIME ZONE":
+		return "time"
+	case "TIMESTAMP", "TIMESTAMP WITHOUT TIME ZONE":
+		return timestampType
+	case "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE":
+		return timestamptzType
+	case "SUPER":
+		return jsonType
+	default:
+		return strings.ToLower(sourceType)
+	}
+}
+
+type LegibleTrinoDataSource struct {
+	Host       string `json:"host"`
+	Port       string `json:"port"`
+	Catalog    string `json:"catalog"`
+	Schema     string `json:"schema"`
+	User       string `json:"user"`
+	Password   string `json:"password"`
+	HTTPScheme string `json:"http_scheme"`
+}
+
+// GetType implements DataSource interface
+func (ds *LegibleTrinoDataSource) GetType() string {
+	return "trino"
+}
+
+// Validate implements DataSource interface
+func (ds *LegibleTrinoDataSource) Validate() error {
+	if strings.TrimSpace(ds.Host) == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	port, err := strconv.Atoi(ds.Port)
+	if err != nil {
+		return fmt.Errorf("port must be a valid number")
+	}
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if strings.TrimSpace(ds.Catalog) == "" {
+		return fmt.Errorf("catalog cannot be empty")
+	}
+	if strings.TrimSpace(ds.Schema) == "" {
+		return fmt.Errorf("schema cannot be empty")
+	}
+	if ds.HTTPScheme != "http" && ds.HTTPScheme != "https" {
+		return fmt.Errorf("http_scheme must be http or https")
+	}
+	if ds.Password != "" && ds.HTTPScheme != "https" {
+		return fmt.Errorf("password authentication requires http_scheme https")
+	}
+	return nil
+}
+
+// MapType implements DataSource interface
+func (ds *LegibleTrinoDataSource) MapType(sourceType string) string {
+	baseType, params := splitSourceType(sourceType)
+	switch baseType {
+	case "BOOLEAN":
+		return booleanType
+	case "TINYINT", "SMALLINT":
+		return smallintType
+	case "INTEGER", "INT":
+		return integerType
+	case "BIGINT":
+		return bigintType
+	case "REAL":
+		return floatType
+	case "DOUBLE":
+		return doubleType
+	case "DECIMAL":
+		return decimalType
+	case "CHAR":
+		return charType
+	case "VARCHAR", "VARBINARY", "UUID", "IPADDRESS":
+		return varcharType
+	case "JSON", "ARRAY", "MAP", "ROW":
+		return jsonType
+	case "DATE":
+		return dateType
+	case "TIME":
+		return "time"
+	case "TIMESTAMP":
+		// timestamp(3) with time zone
+		if strings.HasSuffix(params, "WITH TIME ZONE") {
+			return timestamptzType
+		}
+		return timestampType
+	case "TIMESTAMP WITH TIME ZONE":
+		return timestamptzType
+	case "TIMESTAMP WITHOUT TIME ZONE":
+		return timestampType
+	case "INTERVAL YEAR TO MONTH", "INTERVAL DAY TO SECOND":
+		return intervalType
+	default:
+		return strings.ToLower(sourceType)
+	}
+}
+
+type LegibleAthenaDataSource struct {
+	S3StagingDir       string `json:"s3_staging_dir"`
+	Region             string `json:"region_name"`
+	Schema             string `json:"schema_name"`
+	AwsAccessKeyID     string `json:"aws_access_key_id"`
+	AwsSecretAccessKey string `json:"aws_secret_access_key"`
+	// WorkGroup and AwsProfile are read from the dbt profile; the keys of
+	// the AWS profile are set by setAWSProfileKeys
+	WorkGroup  string `json:"-"`
+	AwsProfile string `json:"-"`
+}
+
+// GetType implements DataSource interface
+func (ds *LegibleAthenaDataSource) GetType() string {
+	return "athena"
+}
+
+// Validate implements DataSource interface
+func (ds *LegibleAthenaDataSource) Validate() error {
+	if !strings.HasPrefix(ds.S3StagingDir, "s3://") {
+		return fmt.Errorf("s3_staging_dir must be an s3:// URI")
+	}
+	if strings.TrimSpace(ds.Region) == "" {
+		return fmt.Errorf("region_name cannot be empty")
+	}
+	if strings.TrimSpace(ds.Schema) == "" {
+		return fmt.Errorf("schema_name cannot be empty")
+	}
+	if (ds.AwsAccessKeyID == "") != (ds.AwsSecretAccessKey == "") {
+		return fmt.Errorf("aws_access_key_id and aws_secret_access_key must be set together")
+	}
+	return nil
+}
+
+// MapType implements DataSource interface
+func (ds *LegibleAthenaDataSource) MapType(sourceType string) string {
+	baseType, _ := splitSourceType(sourceType)
+	switch baseType {
+	case "BOOLEAN":
+		return booleanType
+	case "TINYINT", "SMALLINT":
+		return smallintType
+	case "INT", "INTEGER":
+		return integerType
+	case "BIGINT":
+		return bigintType
+	case "FLOAT", "REAL":
+		return floatType
+	case "DOUBLE":
+		return doubleType
+	case "DECIMAL":
+		return decimalType
+	case "CHAR":
+		return charType
+	case "VARCHAR", "STRING", "BINARY", "VARBINARY":
+		return varcharType
+	case "DATE":
+		return dateType
+	case "TIMESTAMP":
+		return timestampType
+	case "JSON", "ARRAY", "MAP", "STRUCT", "ROW":
+		return jsonType
+	default:
+		return strings.ToLower(sourceType)
+	}
+}
+
+type LegibleDatabricksDataSource struct {
+	ServerHostname string `json:"serverHostname"`
+	HTTPPath       string `json:"httpPath"`
+	AccessToken    string `json:"accessToken,omitempty"`
+	ClientID       string `json:"clientId,omitempty"`
+	ClientSecret   string `json:"clientSecret,omitempty"`
+	AzureTenantID  string `json:"azureTenantId,omitempty"`
+	// Catalog and Schema are the Unity Catalog location of the dbt models;
+	// models carry them in their table references
+	Catalog string `json:"-"`
+	Schema  string `json:"-"`
+}
+
+// GetType implements DataSource interface
+func (ds *LegibleDatabricksDataSource) GetType() string {
+	return "databricks"
+}
+
+// DatabricksType returns the engine's databricks_type for the auth method
+func (ds *LegibleDatabricksDataSource) DatabricksType() string {
+	if ds.AccessToken != "" {
+		return "token"
+	}
+	return "service_principal"
+}
+
+// Validate implements DataSource interface
+func (ds *LegibleDatabricksDataSource) Validate() error {
+	if strings.TrimSpace(ds.ServerHostname) == "" {
+		return fmt.Errorf("serverHostname cannot be empty")
+	}
+	if !strings.HasPrefix(ds.HTTPPath, "/") {
+		return fmt.Errorf("httpPath must start with /")
+	}
+	if ds.AccessToken == "" && (ds.ClientID == "" || ds.ClientSecret == "") {
+		return fmt.Errorf("either accessToken or clientId and clientSecret must be specified")
+	}
+	return nil
+}
+
+// MapType implements DataSource interface
+func (ds *LegibleDatabricksDataSource) MapType(sourceType string) string {
+	baseType, _ := splitSourceType(sourceType)
+	switch baseType {
+	case "BOOLEAN":
+		return booleanType
+	case "TINYINT", "BYTE", "SMALLINT", "SHORT":
+		return smallintType
+	case "INT", "INTEGER":
+		return integerType
+	case "BIGINT", "LONG":
+		return bigintType
+	case "FLOAT", "REAL":
+		return floatType
+	case "DOUBLE":
+		return doubleType
+	case "DECIMAL", "DEC", "NUMERIC":
+		return decimalType
+	case "STRING", "VARCHAR", "CHAR", "BINARY":
+		return varcharType
+	case "DATE":
+		return dateType
+	case "TIMESTAMP", "TIMESTAMP_LTZ":
+		// Databricks TIMESTAMP is an instant shown in the session time zone
+		return timestamptzType
+	case "TIMESTAMP_NTZ":
+		return timestampType
+	case "INTERVAL":
+		return intervalType
+	case "ARRAY", "MAP", "STRUCT", "VARIANT":
+		return jsonType
+	default:
+		return strings.ToLower(sourceType)
+	}
+}
+
+type LegibleClickHouseDataSource struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Database string `json:"database"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Secure   bool   `json:"secure"`
+}
+
+// GetType implements DataSource interface
+func (ds *LegibleClickHouseDataSource) GetType() string {
+	return "clickhouse"
+}
+
+// Validate implements DataSource interface
+func (ds *LegibleClickHouseDataSource) Validate() error {
+	if strings.TrimSpace(ds.Host) == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	port, err := strconv.Atoi(ds.Port)
+	if err != nil {
+		return fmt.Errorf("port must be a valid number")
+	}
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if strings.TrimSpace(ds.Database) == "" {
+		return fmt.Errorf("database cannot be empty")
+	}
+	if strings.TrimSpace(ds.User) == "" {
+		return fmt.Errorf("user cannot be empty")
+	}
+	return nil
+}
+
+// MapType implements DataSource interface
+func (ds *LegibleClickHouseDataSource) MapType(sourceType string) string {
+	baseType, params := splitSourceType(sourceType)
+	switch baseType {
+	case "NULLABLE", "LOWCARDINALITY":
+		// Nullable(String), LowCardinality(Nullable(String))
+		return ds.MapType(strings.TrimSuffix(strings.TrimSpace(params), ")"))
+	case "BOOL", "BOOLEAN":
+		return booleanType
+	case "INT8", "UINT8", "INT16":
+		return smallintType
+	case "UINT16", "INT32":
+		return integerType
+	case "UINT32", "INT64", "UINT64":
+		return bigintType
+	case "INT128", "UINT128", "INT256", "UINT256", "DECIMAL", "DECIMAL32", "DECIMAL64", "DECIMAL128", "DECIMAL256":
+		return decimalType
+	case "FLOAT32":
+		return floatType
+	case "FLOAT64":
+		return doubleType
+	case "STRING", "FIXEDSTRING", "UUID", "ENUM8", "ENUM16", "IPV4", "IPV6":
+		return varcharType
+	case "DATE", "DATE32":
+		return dateType
+	case "DATETIME", "DATETIME64":
+		// DateTime('UTC'), DateTime64(3, 'Europe/Berlin')
+		if strings.Contains(params, "'") {
+			return timestamptzType
+		}
+		return timestampType
+	case "ARRAY", "MAP", "TUPLE", "NESTED", "JSON", "OBJECT":
+		return jsonType
+	default:
+		return strings.ToLower(sourceType)
+	}
+}
+
+type LegibleOracleDataSource struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Database string `json:"database"` // service name
+	SID      string `json:"-"`
+	DSN      string `json:"dsn,omitempty"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// GetType implements DataSource interface
+func (ds *LegibleOracleDataSource) GetType() string {
+	return "oracle"
+}
+
+// ConnectDSN returns the DSN to connect with for host, which may differ
+// from ds.Host when rewritten for container usage. It is the configured
+// DSN, a connect descriptor when connecting by SID (which Easy Connect
+// strings cannot express), or "" to connect by host, port and service.
+func (ds *LegibleOracleDataSource) ConnectDSN(host string) string {
+	if ds.DSN != "" {
+		return ds.DSN
+	}
+	if ds.SID != "" {
+		return fmt.Sprintf("(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=%s)(PORT=%s))(CONNECT_DATA=(SID=%s)))", host, ds.Port, ds.SID)
+	}
+	return ""
+}
+
+// Validate implements DataSource interface
+func (ds *LegibleOracleDataSource) Validate() error {
+	if strings.TrimSpace(ds.User) == "" {
+		return fmt.Errorf("user cannot be empty")
+	}
+	if ds.DSN != "" {
+		return nil
+	}
+	if strings.TrimSpace(ds.Host) == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	port, err := strconv.Atoi(ds.Port)
+	if err != nil {
+		return fmt.Errorf("port must be a valid number")
+	}
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if strings.TrimSpace(ds.Database) == "" && strings.TrimSpace(ds.SID) == "" {
+		return fmt.Errorf("either service or sid must be specified")
+	}
+	return nil
+}
+
+// MapType implements DataSource interface
+func (ds *LegibleOracleDataSource) MapType(sourceType string) string {
+	baseType, params := splitSourceType(sourceType)
+	switch baseType {
+	case "NUMBER":
+		// NUMBER(p) and NUMBER(p, 0) hold whole numbers only
+		if params != "" {
+			_, scale, hasScale := strings.Cut(strings.TrimSuffix(params, ")"), ",")
+			if !hasScale || strings.TrimSpace(scale) == "0" {
+				return bigintType
+			}
+		}
+		return decimalType
+	case "INTEGER", "INT", "SMALLINT":
+		return bigintType
+	case "DECIMAL", "NUMERIC":
+		return decimalType
+	case "FLOAT", "BINARY_FLOAT":
+		return floatType
+	case "BINARY_DOUBLE":
+		return doubleType
+	case "VARCHAR2", "NVARCHAR2", "VARCHAR":
+		return varcharType
+	case "CHAR", "NCHAR":
+		return charType
+	case "CLOB", "NCLOB", "LONG":
+		return textType
+	case "RAW", "LONG RAW", "BLOB":
+		return varcharType
+	case "BOOLEAN":
+		return booleanType
+	case "DATE":
+		// Oracle DATE includes a time of day
+		return timestampType
+	case "TIMESTAMP":
+		// TIMESTAMP(6) WITH TIME ZONE, TIMESTAMP(6) WITH LOCAL TIME ZONE
+		if strings.Contains(params, "TIME ZONE") {
+			return timestamptzType
+		}
+		return timestampType
+	case "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITH LOCAL TIME ZONE":
+		return timestamptzType
+	case "JSON":
+		return jsonType
+	default:
+		// INTERVAL YEAR(2) TO MONTH, INTERVAL DAY(2) TO SECOND(6)
+		if strings.HasPrefix(baseType, "INTERVAL") {
+			return intervalType
+		}
+		return strings.ToLower(sourceType)
+	}
+}
+
 // GetActiveDataSources gets active data sources based on specified profile and target
 // If profileName is empty, it will use the first found profile
 // If targetName is empty, it will use the profile's default target
 func GetActiveDataSources(profiles *DbtProfiles, dbtHomePath, profileName, targetName string) ([]DataSource, error) {
+	connection, profileName, targetName, err := activeConnection(profiles, profileName, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSource, err := convertConnectionToDataSource(connection, dbtHomePath, profileName, targetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert connection %s.%s: %w", profileName, targetName, err)
+	}
+
+	if dataSource == nil {
+		return []DataSource{}, nil
+	}
+
+	return []DataSource{dataSource}, nil
+}
+
+// activeConnection returns the connection of the specified profile and
+// target, defaulted like GetActiveDataSources, with the resolved names
+func activeConnection(profiles *DbtProfiles, profileName, targetName string) (DbtConnection, string, string, error) {
 	if profiles == nil {
-		return nil, fmt.Errorf("profiles cannot be nil")
+		return DbtConnection{}, "", "", fmt.Errorf("profiles cannot be nil")
 	}
 
 	// If no profile is specified, use the first one
@@ -618,7 +1556,7 @@ func GetActiveDataSources(profiles *DbtProfiles, dbtHomePath, profileName, targe
 
 	profile, exists := profiles.Profiles[profileName]
 	if !exists {
-		return nil, fmt.Errorf("profile '%s' not found", profileName)
+		return DbtConnection{}, "", "", fmt.Errorf("profile '%s' not found", profileName)
 	}
 
 	// If no target is specified, use the default one
@@ -628,19 +1566,9 @@ func GetActiveDataSources(profiles *DbtProfiles, dbtHomePath, profileName, targe
 
 	connection, exists := profile.Outputs[targetName]
 	if !exists {
-		return nil, fmt.Errorf("target '%s' not found in profile '%s'", targetName, profileName)
-	}
-
-	dataSource, err := convertConnectionToDataSource(connection, dbtHomePath, profileName, targetName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert connection %s.%s: %w", profileName, targetName, err)
+		return DbtConnection{}, "", "", fmt.Errorf("target '%s' not found in profile '%s'", targetName, profileName)
 	}
-
-	if dataSource == nil {
-		return []DataSource{}, nil
-	}
-
-	return []DataSource{dataSource}, nil
+	return connection, profileName, targetName, nil
 }
 
 // GetDataSourceByType gets all data sources of specified type from profiles
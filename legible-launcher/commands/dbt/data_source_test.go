@@ -1,9 +1,21 @@
 package dbt
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -772,6 +784,632 @@ func TestGetDataSourceByType(t *testing.T) {
 	}
 }
 
+// encryptTestKey encrypts a PKCS#8 key the way `openssl pkcs8 -topk8 -v2 aes-256-cbc` does
+func encryptTestKey(t *testing.T, der []byte, passphrase string) []byte {
+	t.Helper()
+
+	salt, iv := make([]byte, 8), make([]byte, aes.BlockSize)
+	_, _ = rand.Read(salt)
+	_, _ = rand.Read(iv)
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, 2048, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := aes.NewCipher(key)
+	pad := aes.BlockSize - len(der)%aes.BlockSize
+	data := append(append([]byte{}, der...), []byte(strings.Repeat(string(rune(pad)), pad))...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+
+	raw := func(v interface{}) asn1.RawValue {
+		b, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return asn1.RawValue{FullBytes: b}
+	}
+	kdf := pbkdf2Params{Salt: salt, IterationCount: 2048, PRF: pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue}}
+	params := pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: raw(kdf)},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: raw(iv)},
+	}
+	encrypted, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: raw(params)},
+		EncryptedData: data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted})
+}
+
+func TestFromDbtProfiles_Snowflake(t *testing.T) {
+	tempDir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err := os.WriteFile(filepath.Join(tempDir, "rsa_key.p8"), encryptTestKey(t, der, "s3cret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	baseConn := DbtConnection{
+		Type:      "snowflake",
+		Account:   "xy12345.us-east-1",
+		User:      testUser,
+		Database:  "ANALYTICS",
+		Schema:    "PUBLIC",
+		Warehouse: "COMPUTE_WH",
+		Role:      "TRANSFORMER",
+	}
+
+	tests := []struct {
+		name           string
+		password       string
+		additional     map[string]interface{}
+		wantPrivateKey string
+		wantKwargs     map[string]interface{}
+		wantErr        bool
+	}{
+		{
+			name:       "password",
+			password:   testPassword,
+			wantKwargs: map[string]interface{}{"role": "TRANSFORMER"},
+		},
+		{
+			name: "key-pair with encrypted key file",
+			additional: map[string]interface{}{
+				"private_key_path":       "rsa_key.p8",
+				"private_key_passphrase": "s3cret",
+			},
+			wantPrivateKey: plainPEM,
+			wantKwargs:     map[string]interface{}{"role": "TRANSFORMER"},
+		},
+		{
+			name: "key-pair with inline base64 DER key",
+			additional: map[string]interface{}{
+				"private_key": base64.StdEncoding.EncodeToString(der),
+			},
+			wantPrivateKey: plainPEM,
+			wantKwargs:     map[string]interface{}{"role": "TRANSFORMER"},
+		},
+		{
+			name: "key-pair with wrong passphrase",
+			additional: map[string]interface{}{
+				"private_key_path":       filepath.Join(tempDir, "rsa_key.p8"),
+				"private_key_passphrase": "wrong",
+			},
+			wantErr: true,
+		},
+		{
+			name:       "key-pair with missing key file",
+			additional: map[string]interface{}{"private_key_path": "missing.p8"},
+			wantErr:    true,
+		},
+		{
+			name:       "authenticator",
+			additional: map[string]interface{}{"authenticator": "externalbrowser"},
+			wantKwargs: map[string]interface{}{"role": "TRANSFORMER", "authenticator": "externalbrowser"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := baseConn
+			conn.Password = tt.password
+			conn.Additional = tt.additional
+
+			ds, err := convertConnectionToDataSource(conn, tempDir, "test_profile", "dev")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("convertConnectionToDataSource failed: %v", err)
+			}
+
+			sf, ok := ds.(*LegibleSnowflakeDataSource)
+			if !ok {
+				t.Fatalf("Expected LegibleSnowflakeDataSource, got %T", ds)
+			}
+			if err := sf.Validate(); err != nil {
+				t.Errorf("Validation failed: %v", err)
+			}
+			if sf.Account != baseConn.Account || sf.Warehouse != baseConn.Warehouse || sf.Password != tt.password {
+				t.Errorf("Unexpected connection fields: %+v", sf)
+			}
+			if sf.PrivateKey != tt.wantPrivateKey {
+				t.Errorf("Expected private key %q, got %q", tt.wantPrivateKey, sf.PrivateKey)
+			}
+			kwargs := sf.Kwargs()
+			if len(kwargs) != len(tt.wantKwargs) {
+				t.Errorf("Expected kwargs %v, got %v", tt.wantKwargs, kwargs)
+			}
+			for k, v := range tt.wantKwargs {
+				if kwargs[k] != v {
+					t.Errorf("Expected kwargs[%s] = %v, got %v", k, v, kwargs[k])
+				}
+			}
+		})
+	}
+}
+
+func TestSnowflakeDataSourceValidation(t *testing.T) {
+	validDS := &LegibleSnowflakeDataSource{
+		Account:  "xy12345",
+		User:     testUser,
+		Password: testPassword,
+		Database: "ANALYTICS",
+		Schema:   "PUBLIC",
+	}
+
+	invalidCases := []struct {
+		name string
+		ds   Validator
+	}{
+		{"missing account", &LegibleSnowflakeDataSource{User: testUser, Password: testPassword, Database: "ANALYTICS", Schema: "PUBLIC"}},
+		{"missing user", &LegibleSnowflakeDataSource{Account: "xy12345", Password: testPassword, Database: "ANALYTICS", Schema: "PUBLIC"}},
+		{"missing database", &LegibleSnowflakeDataSource{Account: "xy12345", User: testUser, Password: testPassword, Schema: "PUBLIC"}},
+		{"missing schema", &LegibleSnowflakeDataSource{Account: "xy12345", User: testUser, Password: testPassword, Database: "ANALYTICS"}},
+		{"missing credentials", &LegibleSnowflakeDataSource{Account: "xy12345", User: testUser, Database: "ANALYTICS", Schema: "PUBLIC"}},
+		{"oauth without token", &LegibleSnowflakeDataSource{Account: "xy12345", User: testUser, Database: "ANALYTICS", Schema: "PUBLIC", Authenticator: "oauth"}},
+	}
+
+	testDataSourceValidation(t, "snowflake", validDS, invalidCases)
+}
+
+func TestFromDbtProfiles_Redshift(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configFile, []byte("[default]\nregion = us-east-1\n[profile analytics]\nregion = eu-west-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_PROFILE", "")
+
+	tests := []struct {
+		name    string
+		conn    DbtConnection
+		want    *LegibleRedshiftDataSource
+		wantNil bool
+	}{
+		{
+			name: "password",
+			conn: DbtConnection{Type: "redshift", Host: "cluster.abc.us-east-1.redshift.amazonaws.com", User: testUser, Password: testPassword, DbName: "dev"},
+			want: &LegibleRedshiftDataSource{Host: "cluster.abc.us-east-1.redshift.amazonaws.com", Port: "5439", Database: "dev", User: testUser, Password: testPassword},
+		},
+		{
+			name: "iam with access keys",
+			conn: DbtConnection{Type: "redshift", Method: "iam", User: testUser, Database: "dev", Additional: map[string]interface{}{
+				"cluster_id": "analytics", "region": "us-west-2", "access_key_id": "AKIA", "secret_access_key": "secret",
+			}},
+			want: &LegibleRedshiftDataSource{IAM: true, ClusterIdentifier: "analytics", Database: "dev", User: testUser, Region: "us-west-2", AccessKeyID: "AKIA", AccessKeySecret: "secret"},
+		},
+		{
+			name: "iam with profile",
+			conn: DbtConnection{Type: "redshift", Method: "iam", User: testUser, Database: "dev", Additional: map[string]interface{}{
+				"cluster_id": "analytics", "region": "us-west-2", "iam_profile": "analytics",
+			}},
+			want: &LegibleRedshiftDataSource{IAM: true, ClusterIdentifier: "analytics", Database: "dev", User: testUser, Region: "us-west-2", AwsProfile: "analytics"},
+		},
+		{
+			name: "iam with the default profile's region",
+			conn: DbtConnection{Type: "redshift", Method: "iam", User: testUser, Database: "dev", Additional: map[string]interface{}{
+				"cluster_id": "analytics",
+			}},
+			want: &LegibleRedshiftDataSource{IAM: true, ClusterIdentifier: "analytics", Database: "dev", User: testUser, Region: "us-east-1", AwsProfile: "default"},
+		},
+		{
+			name:    "unsupported method",
+			conn:    DbtConnection{Type: "redshift", Method: "idc", Host: "localhost"},
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := convertConnectionToDataSource(tt.conn, "", "test_profile", "dev")
+			if err != nil {
+				t.Fatalf("convertConnectionToDataSource failed: %v", err)
+			}
+			if tt.wantNil {
+				if ds != nil {
+					t.Fatalf("Expected no data source, got %T", ds)
+				}
+				return
+			}
+			got, ok := ds.(*LegibleRedshiftDataSource)
+			if !ok {
+				t.Fatalf("Expected LegibleRedshiftDataSource, got %T", ds)
+			}
+			if *got != *tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validation failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestFromDbtProfiles_Trino(t *testing.T) {
+	tests := []struct {
+		name    string
+		conn    DbtConnection
+		want    *LegibleTrinoDataSource
+		wantNil bool
+	}{
+		{
+			name: "no auth",
+			conn: DbtConnection{Type: "trino", Method: "none", Host: testHost, Port: 8080, User: "trino", Database: "hive", Schema: "analytics"},
+			want: &LegibleTrinoDataSource{Host: testHost, Port: "8080", Catalog: "hive", Schema: "analytics", User: "trino", HTTPScheme: "http"},
+		},
+		{
+			name: "ldap defaults to https",
+			conn: DbtConnection{Type: "trino", Method: "ldap", Host: "trino.example.com", User: testUser, Password: testPassword, Database: "iceberg", Schema: "sales"},
+			want: &LegibleTrinoDataSource{Host: "trino.example.com", Port: "443", Catalog: "iceberg", Schema: "sales", User: testUser, Password: testPassword, HTTPScheme: "https"},
+		},
+		{
+			name: "explicit http_scheme",
+			conn: DbtConnection{Type: "trino", Host: "trino.example.com", Port: 8443, User: "trino", Database: "hive", Schema: "default",
+				Additional: map[string]interface{}{"http_scheme": "HTTPS"}},
+			want: &LegibleTrinoDataSource{Host: "trino.example.com", Port: "8443", Catalog: "hive", Schema: "default", User: "trino", HTTPScheme: "https"},
+		},
+		{
+			name:    "unsupported method",
+			conn:    DbtConnection{Type: "trino", Method: "kerberos", Host: testHost},
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := convertConnectionToDataSource(tt.conn, "", "test_profile", "dev")
+			if err != nil {
+				t.Fatalf("convertConnectionToDataSource failed: %v", err)
+			}
+			if tt.wantNil {
+				if ds != nil {
+					t.Fatalf("Expected no data source, got %T", ds)
+				}
+				return
+			}
+			got, ok := ds.(*LegibleTrinoDataSource)
+			if !ok {
+				t.Fatalf("Expected LegibleTrinoDataSource, got %T", ds)
+			}
+			if *got != *tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validation failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestFromDbtProfiles_Athena(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configFile, []byte("[profile analytics]\nregion = eu-west-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	tests := []struct {
+		name string
+		conn DbtConnection
+		want *LegibleAthenaDataSource
+	}{
+		{
+			name: "access keys",
+			conn: DbtConnection{Type: "athena", Database: "awsdatacatalog", Schema: "analytics", Additional: map[string]interface{}{
+				"s3_staging_dir": "s3://bucket/athena/", "region_name": "us-east-1", "work_group": "primary",
+				"aws_access_key_id": "AKIA", "aws_secret_access_key": "secret",
+			}},
+			want: &LegibleAthenaDataSource{S3StagingDir: "s3://bucket/athena/", Region: "us-east-1", Schema: "analytics", WorkGroup: "primary", AwsAccessKeyID: "AKIA", AwsSecretAccessKey: "secret"},
+		},
+		{
+			name: "aws profile",
+			conn: DbtConnection{Type: "athena", Database: "awsdatacatalog", Schema: "analytics", Additional: map[string]interface{}{
+				"s3_staging_dir": "s3://bucket/athena/", "aws_profile_name": "analytics",
+			}},
+			want: &LegibleAthenaDataSource{S3StagingDir: "s3://bucket/athena/", Region: "eu-west-1", Schema: "analytics", AwsProfile: "analytics"},
+		},
+		{
+			name: "session token is not kept",
+			conn: DbtConnection{Type: "athena", Schema: "analytics", Additional: map[string]interface{}{
+				"s3_staging_dir": "s3://bucket/athena/", "region_name": "us-east-1",
+				"aws_access_key_id": "ASIA", "aws_secret_access_key": "secret", "aws_session_token": "token",
+			}},
+			want: &LegibleAthenaDataSource{S3StagingDir: "s3://bucket/athena/", Region: "us-east-1", Schema: "analytics", AwsAccessKeyID: "ASIA", AwsSecretAccessKey: "secret"},
+		},
+		{
+			name: "default credential chain",
+			conn: DbtConnection{Type: "athena", Schema: "analytics", Additional: map[string]interface{}{
+				"s3_staging_dir": "s3://bucket/athena/", "region_name": "us-east-2",
+			}},
+			want: &LegibleAthenaDataSource{S3StagingDir: "s3://bucket/athena/", Region: "us-east-2", Schema: "analytics"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := convertConnectionToDataSource(tt.conn, "", "test_profile", "dev")
+			if err != nil {
+				t.Fatalf("convertConnectionToDataSource failed: %v", err)
+			}
+			got, ok := ds.(*LegibleAthenaDataSource)
+			if !ok {
+				t.Fatalf("Expected LegibleAthenaDataSource, got %T", ds)
+			}
+			if *got != *tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validation failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestAwsDataSourceValidation(t *testing.T) {
+	testDataSourceValidation(t, "redshift",
+		&LegibleRedshiftDataSource{Host: "cluster", Port: "5439", Database: "dev", User: testUser, Password: testPassword},
+		[]struct {
+			name string
+			ds   Validator
+		}{
+			{"missing password", &LegibleRedshiftDataSource{Host: "cluster", Port: "5439", Database: "dev", User: testUser}},
+			{"invalid port", &LegibleRedshiftDataSource{Host: "cluster", Port: "abc", Database: "dev", User: testUser, Password: testPassword}},
+			{"iam missing cluster", &LegibleRedshiftDataSource{IAM: true, Database: "dev", User: testUser, Region: "us-west-2", AccessKeyID: "AKIA", AccessKeySecret: "secret"}},
+			{"iam missing keys", &LegibleRedshiftDataSource{IAM: true, ClusterIdentifier: "c", Database: "dev", User: testUser, Region: "us-west-2"}},
+		})
+
+	testDataSourceValidation(t, "trino",
+		&LegibleTrinoDataSource{Host: testHost, Port: "8080", Catalog: "hive", Schema: "default", HTTPScheme: "http"},
+		[]struct {
+			name string
+			ds   Validator
+		}{
+			{"missing catalog", &LegibleTrinoDataSource{Host: testHost, Port: "8080", Schema: "default", HTTPScheme: "http"}},
+			{"invalid http_scheme", &LegibleTrinoDataSource{Host: testHost, Port: "8080", Catalog: "hive", Schema: "default", HTTPScheme: "ftp"}},
+			{"password over http", &LegibleTrinoDataSource{Host: testHost, Port: "8080", Catalog: "hive", Schema: "default", Password: testPassword, HTTPScheme: "http"}},
+		})
+
+	testDataSourceValidation(t, "athena",
+		&LegibleAthenaDataSource{S3StagingDir: "s3://bucket/", Region: "us-east-1", Schema: "default"},
+		[]struct {
+			name string
+			ds   Validator
+		}{
+			{"invalid staging dir", &LegibleAthenaDataSource{S3StagingDir: "/tmp", Region: "us-east-1", Schema: "default"}},
+			{"missing region", &LegibleAthenaDataSource{S3StagingDir: "s3://bucket/", Schema: "default"}},
+			{"partial keys", &LegibleAthenaDataSource{S3StagingDir: "s3://bucket/", Region: "us-east-1", Schema: "default", AwsAccessKeyID: "AKIA"}},
+		})
+}
+
+func TestFromDbtProfiles_Databricks(t *testing.T) {
+	tests := []struct {
+		name     string
+		conn     DbtConnection
+		want     *LegibleDatabricksDataSource
+		wantType string
+	}{
+		{
+			name: "token",
+			conn: DbtConnection{Type: "databricks", Host: "https://dbc-1234.cloud.databricks.com/", Schema: "sales", Additional: map[string]interface{}{
+				"http_path": "/sql/1.0/warehouses/abc", "token": "dapi123", "catalog": "main",
+			}},
+			want:     &LegibleDatabricksDataSource{ServerHostname: "dbc-1234.cloud.databricks.com", HTTPPath: "/sql/1.0/warehouses/abc", AccessToken: "dapi123", Catalog: "main", Schema: "sales"},
+			wantType: "token",
+		},
+		{
+			name: "oauth machine-to-machine",
+			conn: DbtConnection{Type: "databricks", Host: "dbc-1234.cloud.databricks.com", Schema: "sales", Additional: map[string]interface{}{
+				"http_path": "/sql/1.0/warehouses/abc", "auth_type": "oauth", "client_id": "sp-id", "client_secret": "sp-secret",
+			}},
+			want:     &LegibleDatabricksDataSource{ServerHostname: "dbc-1234.cloud.databricks.com", HTTPPath: "/sql/1.0/warehouses/abc", ClientID: "sp-id", ClientSecret: "sp-secret", Schema: "sales"},
+			wantType: "service_principal",
+		},
+		{
+			name: "oauth user-to-machine is skipped",
+			conn: DbtConnection{Type: "databricks", Host: "dbc-1234.cloud.databricks.com", Additional: map[string]interface{}{
+				"http_path": "/sql/1.0/warehouses/abc", "auth_type": "oauth",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := convertConnectionToDataSource(tt.conn, "", "test_profile", "dev")
+			if err != nil {
+				t.Fatalf("convertConnectionToDataSource failed: %v", err)
+			}
+			if tt.want == nil {
+				if ds != nil {
+					t.Fatalf("Expected no data source, got %T", ds)
+				}
+				return
+			}
+			got, ok := ds.(*LegibleDatabricksDataSource)
+			if !ok {
+				t.Fatalf("Expected LegibleDatabricksDataSource, got %T", ds)
+			}
+			if *got != *tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if got.DatabricksType() != tt.wantType {
+				t.Errorf("Expected databricks_type '%s', got '%s'", tt.wantType, got.DatabricksType())
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validation failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestFromDbtProfiles_ClickHouse(t *testing.T) {
+	tests := []struct {
+		name string
+		conn DbtConnection
+		want *LegibleClickHouseDataSource
+	}{
+		{
+			name: "defaults",
+			conn: DbtConnection{Type: "clickhouse", Host: testHost},
+			want: &LegibleClickHouseDataSource{Host: testHost, Port: "8123", Database: "default", User: "default"},
+		},
+		{
+			name: "secure",
+			conn: DbtConnection{Type: "clickhouse", Host: "ch.example.com", User: testUser, Password: testPassword, Schema: "analytics",
+				Additional: map[string]interface{}{"secure": true}},
+			want: &LegibleClickHouseDataSource{Host: "ch.example.com", Port: "8443", Database: "analytics", User: testUser, Password: testPassword, Secure: true},
+		},
+		{
+			name: "explicit port",
+			conn: DbtConnection{Type: "clickhouse", Host: testHost, Port: 18123, Schema: "analytics"},
+			want: &LegibleClickHouseDataSource{Host: testHost, Port: "18123", Database: "analytics", User: "default"},
+		},
+		{
+			name: "native driver uses the HTTP port",
+			conn: DbtConnection{Type: "clickhouse", Host: testHost, Port: 9000, Schema: "analytics",
+				Additional: map[string]interface{}{"driver": "native"}},
+			want: &LegibleClickHouseDataSource{Host: testHost, Port: "8123", Database: "analytics", User: "default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := convertConnectionToDataSource(tt.conn, "", "test_profile", "dev")
+			if err != nil {
+				t.Fatalf("convertConnectionToDataSource failed: %v", err)
+			}
+			got, ok := ds.(*LegibleClickHouseDataSource)
+			if !ok {
+				t.Fatalf("Expected LegibleClickHouseDataSource, got %T", ds)
+			}
+			if *got != *tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validation failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestFromDbtProfiles_Oracle(t *testing.T) {
+	tests := []struct {
+		name    string
+		conn    DbtConnection
+		want    *LegibleOracleDataSource
+		wantDSN string
+	}{
+		{
+			name: "service",
+			conn: DbtConnection{Type: "oracle", Host: testHost, User: testUser, Database: "ORCLCDB", Additional: map[string]interface{}{
+				"pass": testPassword, "service": "ORCLPDB1",
+			}},
+			want: &LegibleOracleDataSource{Host: testHost, Port: "1521", Database: "ORCLPDB1", User: testUser, Password: testPassword},
+		},
+		{
+			name: "sid",
+			conn: DbtConnection{Type: "oracle", Host: "db.example.com", Port: 1522, User: testUser, Password: testPassword, Additional: map[string]interface{}{
+				"sid": "XE",
+			}},
+			want:    &LegibleOracleDataSource{Host: "db.example.com", Port: "1522", SID: "XE", User: testUser, Password: testPassword},
+			wantDSN: "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db.example.com)(PORT=1522))(CONNECT_DATA=(SID=XE)))",
+		},
+		{
+			name: "connection string",
+			conn: DbtConnection{Type: "oracle", User: testUser, Password: testPassword, Additional: map[string]interface{}{
+				"connection_string": "db.example.com:1521/ORCLPDB1",
+			}},
+			want:    &LegibleOracleDataSource{Port: "1521", DSN: "db.example.com:1521/ORCLPDB1", User: testUser, Password: testPassword},
+			wantDSN: "db.example.com:1521/ORCLPDB1",
+		},
+		{
+			name: "tns name is skipped",
+			conn: DbtConnection{Type: "oracle", User: testUser, Additional: map[string]interface{}{"tns_name": "ORCL"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := convertConnectionToDataSource(tt.conn, "", "test_profile", "dev")
+			if err != nil {
+				t.Fatalf("convertConnectionToDataSource failed: %v", err)
+			}
+			if tt.want == nil {
+				if ds != nil {
+					t.Fatalf("Expected no data source, got %T", ds)
+				}
+				return
+			}
+			got, ok := ds.(*LegibleOracleDataSource)
+			if !ok {
+				t.Fatalf("Expected LegibleOracleDataSource, got %T", ds)
+			}
+			if *got != *tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if dsn := got.ConnectDSN(got.Host); dsn != tt.wantDSN {
+				t.Errorf("Expected DSN '%s', got '%s'", tt.wantDSN, dsn)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validation failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestOracleConnectDSNUsesContainerHost(t *testing.T) {
+	ds := &LegibleOracleDataSource{Host: testHost, Port: "1521", SID: "XE"}
+	dsn := ds.ConnectDSN(handleLocalhostForContainer(ds.Host))
+	if !strings.Contains(dsn, "(HOST=host.docker.internal)") {
+		t.Errorf("Expected the DSN to use the container host, got '%s'", dsn)
+	}
+}
+
+func TestWarehouseDataSourceValidation(t *testing.T) {
+	testDataSourceValidation(t, "databricks",
+		&LegibleDatabricksDataSource{ServerHostname: "dbc.cloud.databricks.com", HTTPPath: "/sql/1.0/warehouses/abc", AccessToken: "dapi"},
+		[]struct {
+			name string
+			ds   Validator
+		}{
+			{"missing host", &LegibleDatabricksDataSource{HTTPPath: "/sql/1.0/warehouses/abc", AccessToken: "dapi"}},
+			{"relative http path", &LegibleDatabricksDataSource{ServerHostname: "dbc", HTTPPath: "sql/1.0", AccessToken: "dapi"}},
+			{"missing client secret", &LegibleDatabricksDataSource{ServerHostname: "dbc", HTTPPath: "/sql/1.0", ClientID: "sp"}},
+		})
+
+	testDataSourceValidation(t, "clickhouse",
+		&LegibleClickHouseDataSource{Host: testHost, Port: "8123", Database: "default", User: "default"},
+		[]struct {
+			name string
+			ds   Validator
+		}{
+			{"missing host", &LegibleClickHouseDataSource{Port: "8123", Database: "default", User: "default"}},
+			{"invalid port", &LegibleClickHouseDataSource{Host: testHost, Port: "0", Database: "default", User: "default"}},
+		})
+
+	testDataSourceValidation(t, "oracle",
+		&LegibleOracleDataSource{Host: testHost, Port: "1521", Database: "ORCLPDB1", User: testUser},
+		[]struct {
+			name string
+			ds   Validator
+		}{
+			{"missing user", &LegibleOracleDataSource{Host: testHost, Port: "1521", Database: "ORCLPDB1"}},
+			{"missing service and sid", &LegibleOracleDataSource{Host: testHost, Port: "1521", User: testUser}},
+		})
+}
+
 func TestMapType(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -809,6 +1447,240 @@ func TestMapType(t *testing.T) {
 			sourceType: "int",
 			want:       "integer",
 		},
+		{
+			name:       "Snowflake NUMBER(38,0) to bigint",
+			dataSource: &LegibleSnowflakeDataSource{},
+			sourceType: "NUMBER(38,0)",
+			want:       "bigint",
+		},
+		{
+			name:       "Snowflake NUMBER(10,2) to decimal",
+			dataSource: &LegibleSnowflakeDataSource{},
+			sourceType: "NUMBER(10,2)",
+			want:       "decimal",
+		},
+		{
+			name:       "Snowflake VARIANT to json",
+			dataSource: &LegibleSnowflakeDataSource{},
+			sourceType: "VARIANT",
+			want:       "json",
+		},
+		{
+			name:       "Snowflake TIMESTAMP_NTZ to timestamp",
+			dataSource: &LegibleSnowflakeDataSource{},
+			sourceType: "TIMESTAMP_NTZ",
+			want:       "timestamp",
+		},
+		{
+			name:       "Snowflake TIMESTAMP_LTZ to timestamptz",
+			dataSource: &LegibleSnowflakeDataSource{},
+			sourceType: "timestamp_ltz",
+			want:       "timestamptz",
+		},
+		{
+			name:       "Snowflake TIMESTAMP_TZ(9) to timestamptz",
+			dataSource: &LegibleSnowflakeDataSource{},
+			sourceType: "TIMESTAMP_TZ(9)",
+			want:       "timestamptz",
+		},
+		{
+			name:       "Snowflake VARCHAR(16777216) to varchar",
+			dataSource: &LegibleSnowflakeDataSource{},
+			sourceType: "VARCHAR(16777216)",
+			want:       "varchar",
+		},
+		{
+			name:       "Redshift INT8 to bigint",
+			dataSource: &LegibleRedshiftDataSource{},
+			sourceType: "INT8",
+			want:       "bigint",
+		},
+		{
+			name:       "Redshift character varying(256) to varchar",
+			dataSource: &LegibleRedshiftDataSource{},
+			sourceType: "character varying(256)",
+			want:       "varchar",
+		},
+		{
+			name:       "Redshift timestamp with time zone to timestamptz",
+			dataSource: &LegibleRedshiftDataSource{},
+			sourceType: "timestamp with time zone",
+			want:       "timestamptz",
+		},
+		{
+			name:       "Redshift SUPER to json",
+			dataSource: &LegibleRedshiftDataSource{},
+			sourceType: "SUPER",
+			want:       "json",
+		},
+		{
+			name:       "Redshift numeric(18,2) to decimal",
+			dataSource: &LegibleRedshiftDataSource{},
+			sourceType: "numeric(18,2)",
+			want:       "decimal",
+		},
+		{
+			name:       "Trino varchar(255) to varchar",
+			dataSource: &LegibleTrinoDataSource{},
+			sourceType: "varchar(255)",
+			want:       "varchar",
+		},
+		{
+			name:       "Trino timestamp(3) with time zone to timestamptz",
+			dataSource: &LegibleTrinoDataSource{},
+			sourceType: "timestamp(3) with time zone",
+			want:       "timestamptz",
+		},
+		{
+			name:       "Trino timestamp(6) to timestamp",
+			dataSource: &LegibleTrinoDataSource{},
+			sourceType: "timestamp(6)",
+			want:       "timestamp",
+		},
+		{
+			name:       "Trino array(varchar) to json",
+			dataSource: &LegibleTrinoDataSource{},
+			sourceType: "array(varchar)",
+			want:       "json",
+		},
+		{
+			name:       "Trino real to float",
+			dataSource: &LegibleTrinoDataSource{},
+			sourceType: "real",
+			want:       "float",
+		},
+		{
+			name:       "Athena string to varchar",
+			dataSource: &LegibleAthenaDataSource{},
+			sourceType: "string",
+			want:       "varchar",
+		},
+		{
+			name:       "Athena decimal(10,2) to decimal",
+			dataSource: &LegibleAthenaDataSource{},
+			sourceType: "decimal(10,2)",
+			want:       "decimal",
+		},
+		{
+			name:       "Athena struct<a:int> to json",
+			dataSource: &LegibleAthenaDataSource{},
+			sourceType: "struct<a:int>",
+			want:       "json",
+		},
+		{
+			name:       "Athena int to integer",
+			dataSource: &LegibleAthenaDataSource{},
+			sourceType: "int",
+			want:       "integer",
+		},
+		{
+			name:       "Databricks timestamp to timestamptz",
+			dataSource: &LegibleDatabricksDataSource{},
+			sourceType: "timestamp",
+			want:       "timestamptz",
+		},
+		{
+			name:       "Databricks timestamp_ntz to timestamp",
+			dataSource: &LegibleDatabricksDataSource{},
+			sourceType: "timestamp_ntz",
+			want:       "timestamp",
+		},
+		{
+			name:       "Databricks decimal(10,2) to decimal",
+			dataSource: &LegibleDatabricksDataSource{},
+			sourceType: "decimal(10,2)",
+			want:       "decimal",
+		},
+		{
+			name:       "Databricks struct<a:int> to json",
+			dataSource: &LegibleDatabricksDataSource{},
+			sourceType: "struct<a:int>",
+			want:       "json",
+		},
+		{
+			name:       "Databricks long to bigint",
+			dataSource: &LegibleDatabricksDataSource{},
+			sourceType: "long",
+			want:       "bigint",
+		},
+		{
+			name:       "ClickHouse LowCardinality(Nullable(String)) to varchar",
+			dataSource: &LegibleClickHouseDataSource{},
+			sourceType: "LowCardinality(Nullable(String))",
+			want:       "varchar",
+		},
+		{
+			name:       "ClickHouse Nullable(Int64) to bigint",
+			dataSource: &LegibleClickHouseDataSource{},
+			sourceType: "Nullable(Int64)",
+			want:       "bigint",
+		},
+		{
+			name:       "ClickHouse UInt8 to smallint",
+			dataSource: &LegibleClickHouseDataSource{},
+			sourceType: "UInt8",
+			want:       "smallint",
+		},
+		{
+			name:       "ClickHouse DateTime64(3, 'UTC') to timestamptz",
+			dataSource: &LegibleClickHouseDataSource{},
+			sourceType: "DateTime64(3, 'UTC')",
+			want:       "timestamptz",
+		},
+		{
+			name:       "ClickHouse DateTime to timestamp",
+			dataSource: &LegibleClickHouseDataSource{},
+			sourceType: "DateTime",
+			want:       "timestamp",
+		},
+		{
+			name:       "ClickHouse Array(String) to json",
+			dataSource: &LegibleClickHouseDataSource{},
+			sourceType: "Array(String)",
+			want:       "json",
+		},
+		{
+			name:       "Oracle NUMBER(10) to bigint",
+			dataSource: &LegibleOracleDataSource{},
+			sourceType: "NUMBER(10)",
+			want:       "bigint",
+		},
+		{
+			name:       "Oracle NUMBER(10,2) to decimal",
+			dataSource: &LegibleOracleDataSource{},
+			sourceType: "NUMBER(10,2)",
+			want:       "decimal",
+		},
+		{
+			name:       "Oracle NUMBER to decimal",
+			dataSource: &LegibleOracleDataSource{},
+			sourceType: "NUMBER",
+			want:       "decimal",
+		},
+		{
+			name:       "Oracle DATE to timestamp",
+			dataSource: &LegibleOracleDataSource{},
+			sourceType: "DATE",
+			want:       "timestamp",
+		},
+		{
+			name:       "Oracle TIMESTAMP(6) WITH LOCAL TIME ZONE to timestamptz",
+			dataSource: &LegibleOracleDataSource{},
+			sourceType: "TIMESTAMP(6) WITH LOCAL TIME ZONE",
+			want:       "timestamptz",
+		},
+		{
+			name:       "Oracle VARCHAR2(100) to varchar",
+			dataSource: &LegibleOracleDataSource{},
+			sourceType: "VARCHAR2(100)",
+			want:       "varchar",
+		},
+		{
+			name:       "Oracle INTERVAL DAY(2) TO SECOND(6) to interval",
+			dataSource: &LegibleOracleDataSource{},
+			sourceType: "INTERVAL DAY(2) TO SECOND(6)",
+			want:       "interval",
+		},
 		{
 			name:       "PostgresDataSource (no mapping)",
 			dataSource: &LegiblePostgresDataSource{},
@@ -853,3 +1725,60 @@ func testDataSourceValidation(t *testing.T, testName string, validDS Validator,
 		})
 	}
 }
+
+func TestSetAWSProfileKeys(t *testing.T) {
+	exported := `{"Version": 1, "AccessKeyId": "AKIAPROFILE", "SecretAccessKey": "profile-secret"}`
+	orig := awsExportCredentials
+	t.Cleanup(func() { awsExportCredentials = orig })
+	awsExportCredentials = func(profile string) ([]byte, error) {
+		if profile != "analytics" {
+			t.Errorf("exported profile = %q, want analytics", profile)
+		}
+		return []byte(exported), nil
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	// The profile's keys are exported
+	ds := &LegibleRedshiftDataSource{IAM: true, AwsProfile: "analytics"}
+	if err := setAWSProfileKeys(ds, false); err != nil {
+		t.Fatal(err)
+	}
+	if ds.AccessKeyID != "AKIAPROFILE" || ds.AccessKeySecret != "profile-secret" {
+		t.Errorf("keys = %q, %q, want the exported ones", ds.AccessKeyID, ds.AccessKeySecret)
+	}
+
+	// References are written only when asked for
+	athena := &LegibleAthenaDataSource{AwsProfile: "analytics"}
+	if err := setAWSProfileKeys(athena, true); err != nil {
+		t.Fatal(err)
+	}
+	if athena.AwsAccessKeyID != awsAccessKeyIDRef || athena.AwsSecretAccessKey != awsSecretAccessKeyRef {
+		t.Errorf("keys = %q, %q, want references", athena.AwsAccessKeyID, athena.AwsSecretAccessKey)
+	}
+
+	// Keys given in the dbt profile are kept
+	given := &LegibleAthenaDataSource{AwsProfile: "analytics", AwsAccessKeyID: "AKIA", AwsSecretAccessKey: "secret"}
+	if err := setAWSProfileKeys(given, false); err != nil || given.AwsAccessKeyID != "AKIA" {
+		t.Errorf("setAWSProfileKeys() = %v, keys %q", err, given.AwsAccessKeyID)
+	}
+
+	// Temporary keys are an error
+	exported = `{"Version": 1, "AccessKeyId": "ASIA", "SecretAccessKey": "secret", "SessionToken": "token"}`
+	if err := setAWSProfileKeys(&LegibleAthenaDataSource{AwsProfile: "analytics"}, false); err == nil || !strings.Contains(err.Error(), "temporary") {
+		t.Errorf("setAWSProfileKeys() with a session token = %v, want an error", err)
+	}
+	awsExportCredentials = func(string) ([]byte, error) { return nil, errors.New("aws: not found") }
+	if err := setAWSProfileKeys(&LegibleAthenaDataSource{AwsProfile: "analytics"}, false); err == nil || !strings.Contains(err.Error(), "AWS_ACCESS_KEY_ID") {
+		t.Errorf("setAWSProfileKeys() without the AWS CLI = %v, want an error", err)
+	}
+
+	// The environment comes first
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	ds = &LegibleRedshiftDataSource{IAM: true, AwsProfile: "analytics"}
+	if err := setAWSProfileKeys(ds, false); err != nil || ds.AccessKeyID != "AKIAENV" {
+		t.Errorf("setAWSProfileKeys() = %v, key %q, want AKIAENV", err, ds.AccessKeyID)
+	}
+}
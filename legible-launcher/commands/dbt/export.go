@@ -0,0 +1,489 @@
+package dbt
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DbtExport is an MDL manifest written back as dbt YAML, the reverse of
+// ConvertDbtCatalogToLegibleMDL.
+type DbtExport struct {
+	// Schema is a schema.yml with the models' descriptions and labels, and
+	// the columns' descriptions, labels and tests: unique and not_null for
+	// primary keys, not_null, accepted_values from enums, and relationships.
+	Schema []byte
+	// Semantic is a MetricFlow YAML with semantic models for the calculated
+	// fields and metrics, and the metrics. It is nil if there are neither.
+	Semantic []byte
+	// Models, Tests, SemanticModels and Metrics count what was exported.
+	Models         int
+	Tests          int
+	SemanticModels int
+	Metrics        int
+	// Skipped lists what has no dbt equivalent and was left out, and why.
+	Skipped []string
+}
+
+// dbtSchemaFile is a dbt properties file.
+type dbtSchemaFile struct {
+	Version int            `yaml:"version"`
+	Models  []dbtModelYAML `yaml:"models"`
+}
+
+type dbtModelYAML struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Meta        map[string]string `yaml:"meta,omitempty"`
+	Columns     []dbtColumnYAML   `yaml:"columns,omitempty"`
+}
+
+type dbtColumnYAML struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	DataType    string            `yaml:"data_type,omitempty"`
+	Meta        map[string]string `yaml:"meta,omitempty"`
+	Tests       []interface{}     `yaml:"tests,omitempty"`
+}
+
+// dbtSemanticFile is a MetricFlow file of semantic models and metrics.
+type dbtSemanticFile struct {
+	SemanticModels []semanticModelYAML `yaml:"semantic_models,omitempty"`
+	Metrics        []metricYAML        `yaml:"metrics,omitempty"`
+}
+
+type semanticModelYAML struct {
+	Name        string                  `yaml:"name"`
+	Description string                  `yaml:"description,omitempty"`
+	Model       string                  `yaml:"model"`
+	Defaults    map[string]string       `yaml:"defaults,omitempty"`
+	Entities    []semanticEntityYAML    `yaml:"entities,omitempty"`
+	Dimensions  []semanticDimensionYAML `yaml:"dimensions,omitempty"`
+	Measures    []semanticMeasureYAML   `yaml:"measures,omitempty"`
+}
+
+type semanticEntityYAML struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	Expr string `yaml:"expr,omitempty"`
+}
+
+type semanticDimensionYAML struct {
+	Name        string            `yaml:"name"`
+	Type        string            `yaml:"type"`
+	Label       string            `yaml:"label,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Expr        string            `yaml:"expr,omitempty"`
+	TypeParams  map[string]string `yaml:"type_params,omitempty"`
+}
+
+type semanticMeasureYAML struct {
+	Name string `yaml:"name"`
+	Agg  string `yaml:"agg"`
+	Expr string `yaml:"expr,omitempty"`
+}
+
+type metricYAML struct {
+	Name        string                 `yaml:"name"`
+	Label       string                 `yaml:"label"`
+	Description string                 `yaml:"description,omitempty"`
+	Type        string                 `yaml:"type"`
+	TypeParams  map[string]interface{} `yaml:"type_params"`
+}
+
+// exportConditionRegex parses a join condition on one column of each model,
+// quoted or not, e.g. "orders"."customer_id" = customers.id.
+var exportConditionRegex = regexp.MustCompile(`^\s*"?([^".\s]+)"?\s*\.\s*"?([^".\s]+)"?\s*=\s*"?([^".\s]+)"?\s*\.\s*"?([^".\s]+)"?\s*$`)
+
+// aggregationRegex parses an aggregation over a single expression, such as
+// SUM(amount) or COUNT(DISTINCT customer_id), into a MetricFlow measure.
+var aggregationRegex = regexp.MustCompile(`(?is)^\s*(SUM|COUNT|AVG|MIN|MAX)\s*\(\s*(DISTINCT\s+)?(.+)\)\s*$`)
+
+// modelReferenceRegex finds references to another model's columns in a
+// calculated field, e.g. customers.name, which MetricFlow dimensions cannot
+// express.
+var modelReferenceRegex = regexp.MustCompile(`\b[A-Za-z_]\w*\s*\.\s*[A-Za-z_]\w*`)
+
+// ExportDbtYAML writes an MDL manifest as a dbt schema.yml and MetricFlow
+// semantic models and metrics, so that descriptions, relationships,
+// calculated fields and metrics authored in Legible can be kept in dbt.
+//
+// Calculated fields become dimensions of a semantic model on their model.
+// Metrics are exported when their aggregation is a single SUM, COUNT,
+// COUNT(DISTINCT), AVG, MIN or MAX over one model; others are skipped.
+func ExportDbtYAML(mdl *LegibleMDLManifest) (*DbtExport, error) {
+	export := &DbtExport{}
+	e := newDbtExporter(mdl, export)
+
+	schema := dbtSchemaFile{Version: 2}
+	for i := range mdl.Models {
+		schema.Models = append(schema.Models, e.schemaModel(&mdl.Models[i]))
+	}
+	export.Models = len(schema.Models)
+	var err error
+	if export.Schema, err = marshalDbtYAML(schema); err != nil {
+		return nil, err
+	}
+
+	semantic := dbtSemanticFile{}
+	for _, metric := range mdl.Metrics {
+		if m, ok := e.metric(metric); ok {
+			semantic.Metrics = append(semantic.Metrics, m)
+		}
+	}
+	for _, name := range e.semanticOrder {
+		semantic.SemanticModels = append(semantic.SemanticModels, *e.semanticModels[name])
+	}
+	export.SemanticModels, export.Metrics = len(semantic.SemanticModels), len(semantic.Metrics)
+	if export.SemanticModels > 0 || export.Metrics > 0 {
+		if export.Semantic, err = marshalDbtYAML(semantic); err != nil {
+			return nil, err
+		}
+	}
+	return export, nil
+}
+
+func marshalDbtYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("encoding dbt YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding dbt YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// dbtExporter indexes a manifest for export.
+type dbtExporter struct {
+	mdl    *LegibleMDLManifest
+	export *DbtExport
+	models map[string]*LegibleModel
+	enums  map[string]*EnumDefinition
+	// relationshipTests are the relationships tests by model and column.
+	relationshipTests map[[2]string][]interface{}
+	// foreignEntities are the models each model refers to, by column.
+	foreignEntities map[string][]semanticEntityYAML
+	// semanticModels are created for models with calculated fields or metrics.
+	semanticModels map[string]*semanticModelYAML
+	semanticOrder  []string
+}
+
+func newDbtExporter(mdl *LegibleMDLManifest, export *DbtExport) *dbtExporter {
+	e := &dbtExporter{
+		mdl:               mdl,
+		export:            export,
+		models:            make(map[string]*LegibleModel, len(mdl.Models)),
+		enums:             make(map[string]*EnumDefinition, len(mdl.EnumDefinitions)),
+		relationshipTests: make(map[[2]string][]interface{}),
+		foreignEntities:   make(map[string][]semanticEntityYAML),
+		semanticModels:    make(map[string]*semanticModelYAML),
+	}
+	for i := range mdl.Models {
+		e.models[mdl.Models[i].Name] = &mdl.Models[i]
+	}
+	for i := range mdl.EnumDefinitions {
+		e.enums[mdl.EnumDefinitions[i].Name] = &mdl.EnumDefinitions[i]
+	}
+	for _, rel := range mdl.Relationships {
+		e.addRelationship(rel)
+	}
+	return e
+}
+
+// addRelationship turns a relationship into a relationships test on the
+// column of its "many" side, which refers to the column of its "one" side.
+func (e *dbtExporter) addRelationship(rel Relationship) {
+	parts := exportConditionRegex.FindStringSubmatch(rel.Condition)
+	if parts == nil {
+		e.skip("relationship %s: cannot parse condition %q", rel.Name, rel.Condition)
+		return
+	}
+	from, to := [2]string{parts[1], parts[2]}, [2]string{parts[3], parts[4]}
+	switch rel.JoinType {
+	case "MANY_TO_ONE", "ONE_TO_ONE":
+	case "ONE_TO_MANY":
+		from, to = to, from
+	default:
+		e.skip("relationship %s: %s relationships have no dbt test", rel.Name, rel.JoinType)
+		return
+	}
+	if e.models[from[0]] == nil || e.models[to[0]] == nil {
+		e.skip("relationship %s: model not exported", rel.Name)
+		return
+	}
+	e.relationshipTests[from] = append(e.relationshipTests[from], map[string]interface{}{
+		"relationships": map[string]string{
+			"to":    fmt.Sprintf("ref('%s')", to[0]),
+			"field": to[1],
+		},
+	})
+	e.foreignEntities[from[0]] = append(e.foreignEntities[from[0]], semanticEntityYAML{Name: to[0], Type: "foreign", Expr: from[1]})
+}
+
+func (e *dbtExporter) skip(format string, args ...interface{}) {
+	e.export.Skipped = append(e.export.Skipped, fmt.Sprintf(format, args...))
+}
+
+// schemaModel describes a model and its physical columns in schema.yml,
+// and adds its calculated fields to its semantic model.
+func (e *dbtExporter) schemaModel(model *LegibleModel) dbtModelYAML {
+	m := dbtModelYAML{
+		Name:        model.Name,
+		Description: model.Properties["description"],
+		Meta:        labelMeta(model.Properties["displayName"]),
+	}
+	for _, col := range model.Columns {
+		if col.IsCalculated {
+			e.calculatedField(model, col)
+			continue
+		}
+		c := dbtColumnYAML{
+			Name:        col.Name,
+			Description: col.Properties["description"],
+			DataType:    strings.ToLower(col.Type),
+			Meta:        labelMeta(col.DisplayName),
+		}
+		if col.Name == model.PrimaryKey {
+			c.Tests = append(c.Tests, "unique", "not_null")
+		} else if col.NotNull {
+			c.Tests = append(c.Tests, "not_null")
+		}
+		if enumName := col.Properties["enumDefinition"]; enumName != "" {
+			if enum := e.enums[enumName]; enum != nil {
+				var values []string
+				for _, v := range enum.Values {
+					values = append(values, cmp.Or(v.Value, v.Name))
+				}
+				c.Tests = append(c.Tests, map[string]interface{}{
+					"accepted_values": map[string]interface{}{"values": values},
+				})
+			}
+		}
+		c.Tests = append(c.Tests, e.relationshipTests[[2]string{model.Name, col.Name}]...)
+		e.export.Tests += len(c.Tests)
+		m.Columns = append(m.Columns, c)
+	}
+	return m
+}
+
+// labelMeta keeps a display name as meta.label, where the default metadata
+// mapping reads it from.
+func labelMeta(displayName string) map[string]string {
+	if displayName == "" {
+		return nil
+	}
+	return map[string]string{"label": displayName}
+}
+
+// calculatedField adds a calculated field to its model's semantic model as
+// a categorical dimension.
+func (e *dbtExporter) calculatedField(model *LegibleModel, col LegibleColumn) {
+	if col.Expression == nil || *col.Expression == "" {
+		e.skip("calculated field %s.%s: no expression", model.Name, col.Name)
+		return
+	}
+	if modelReferenceRegex.MatchString(stringLiteralRegex.ReplaceAllString(*col.Expression, "''")) {
+		e.skip("calculated field %s.%s: refers to another model, which a dimension cannot", model.Name, col.Name)
+		return
+	}
+	sm := e.semanticModel(model)
+	sm.Dimensions = append(sm.Dimensions, semanticDimensionYAML{
+		Name:        col.Name,
+		Type:        "categorical",
+		Label:       col.DisplayName,
+		Description: col.Properties["description"],
+		Expr:        *col.Expression,
+	})
+}
+
+// semanticModel returns the semantic model on a model, creating it if the
+// model has none yet.
+func (e *dbtExporter) semanticModel(model *LegibleModel) *semanticModelYAML {
+	if sm, ok := e.semanticModels[model.Name]; ok {
+		return sm
+	}
+	return e.setSemanticModel(model.Name, e.newSemanticModel(model))
+}
+
+func (e *dbtExporter) setSemanticModel(name string, sm semanticModelYAML) *semanticModelYAML {
+	if _, ok := e.semanticModels[name]; !ok {
+		e.semanticOrder = append(e.semanticOrder, name)
+	}
+	e.semanticModels[name] = &sm
+	return &sm
+}
+
+// newSemanticModel returns a semantic model on a model, with the model's
+// primary entity and the foreign entities of its relationships.
+func (e *dbtExporter) newSemanticModel(model *LegibleModel) semanticModelYAML {
+	sm := semanticModelYAML{
+		Name:        model.Name,
+		Description: model.Properties["description"],
+		Model:       fmt.Sprintf("ref('%s')", model.Name),
+	}
+	if model.PrimaryKey != "" {
+		sm.Entities = append(sm.Entities, semanticEntityYAML{Name: model.Name, Type: "primary", Expr: model.PrimaryKey})
+	}
+	sm.Entities = append(sm.Entities, e.foreignEntities[model.Name]...)
+	return sm
+}
+
+// addDimension adds a column to a semantic model as a dimension, unless it
+// is one already.
+func (sm *semanticModelYAML) addDimension(name string, time bool) {
+	for _, d := range sm.Dimensions {
+		if d.Name == name {
+			return
+		}
+	}
+	d := semanticDimensionYAML{Name: name, Type: "categorical"}
+	if time {
+		d.Type, d.TypeParams = "time", map[string]string{"time_granularity": "day"}
+	}
+	sm.Dimensions = append(sm.Dimensions, d)
+}
+
+// addMeasure adds a measure for an aggregation to a semantic model.
+func (sm *semanticModelYAML) addMeasure(name, aggregation string) bool {
+	parts := aggregationRegex.FindStringSubmatch(aggregation)
+	if parts == nil || !balancedParens(parts[3]) {
+		return false
+	}
+	agg := map[string]string{"SUM": "sum", "COUNT": "count", "AVG": "average", "MIN": "min", "MAX": "max"}[strings.ToUpper(parts[1])]
+	if parts[2] != "" {
+		if agg != "count" {
+			return false
+		}
+		agg = "count_distinct"
+	}
+	expr := strings.TrimSpace(parts[3])
+	if expr == "*" {
+		expr = "1"
+	}
+	sm.Measures = append(sm.Measures, semanticMeasureYAML{Name: name, Agg: agg, Expr: expr})
+	return true
+}
+
+// balancedParens reports whether expr's parentheses are balanced, so that
+// SUM(a) + SUM(b) is not read as the sum of "a) + SUM(b".
+func balancedParens(expr string) bool {
+	depth := 0
+	for _, r := range stringLiteralRegex.ReplaceAllString(expr, "''") {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// metric exports a metric over a single model as a MetricFlow metric on
+// measures added to the model's semantic model.
+func (e *dbtExporter) metric(metric Metric) (metricYAML, bool) {
+	if len(metric.Models) != 1 {
+		e.skip("metric %s: metrics over %d models cannot be exported", metric.Name, len(metric.Models))
+		return metricYAML{}, false
+	}
+	model := e.models[metric.Models[0]]
+	if model == nil {
+		e.skip("metric %s: model %s not exported", metric.Name, metric.Models[0])
+		return metricYAML{}, false
+	}
+	metricType := cmp.Or(metric.Type, "simple")
+	if metricType == "ratio" || metricType == "derived" {
+		e.skip("metric %s: %s metrics cannot be exported", metric.Name, metricType)
+		return metricYAML{}, false
+	}
+
+	// Build the measures on a copy, so that nothing is added if one fails
+	sm := e.newSemanticModel(model)
+	if existing, ok := e.semanticModels[model.Name]; ok {
+		sm = *existing
+		sm.Measures = append([]semanticMeasureYAML(nil), sm.Measures...)
+		sm.Dimensions = append([]semanticDimensionYAML(nil), sm.Dimensions...)
+	}
+	typeParams := make(map[string]interface{})
+	if metricType == "conversion" {
+		c := metric.Conversion
+		if c == nil {
+			e.skip("metric %s: conversion metric has no conversion", metric.Name)
+			return metricYAML{}, false
+		}
+		base, conversion := metric.Name+"_base", metric.Name+"_conversions"
+		if !sm.addMeasure(base, c.BaseAggregation) || !sm.addMeasure(conversion, c.ConversionAggregation) {
+			e.skip("metric %s: aggregations are not a single SUM, COUNT, AVG, MIN or MAX", metric.Name)
+			return metricYAML{}, false
+		}
+		params := map[string]interface{}{
+			"base_measure":       map[string]string{"name": base},
+			"conversion_measure": map[string]string{"name": conversion},
+			"entity":             c.Entity,
+		}
+		if c.Calculation != "" {
+			params["calculation"] = c.Calculation
+		}
+		if c.Window != nil {
+			params["window"] = metricWindowString(c.Window)
+		}
+		typeParams["conversion_type_params"] = params
+	} else {
+		if !sm.addMeasure(metric.Name, metric.Aggregation) {
+			e.skip("metric %s: aggregation %q is not a single SUM, COUNT, AVG, MIN or MAX", metric.Name, metric.Aggregation)
+			return metricYAML{}, false
+		}
+		typeParams["measure"] = metric.Name
+		if metricType == "cumulative" {
+			params := make(map[string]string)
+			if metric.Window != nil {
+				params["window"] = metricWindowString(metric.Window)
+			}
+			if metric.GrainToDate != "" {
+				params["grain_to_date"] = metric.GrainToDate
+			}
+			if len(params) > 0 {
+				typeParams["cumulative_type_params"] = params
+			}
+		}
+	}
+
+	for _, dim := range metric.Dimensions {
+		sm.addDimension(dim, dim == metric.TimeDimension)
+	}
+	if metric.TimeDimension != "" {
+		sm.addDimension(metric.TimeDimension, true)
+		if sm.Defaults == nil {
+			sm.Defaults = map[string]string{"agg_time_dimension": metric.TimeDimension}
+		}
+	}
+	e.setSemanticModel(model.Name, sm)
+
+	return metricYAML{
+		Name:        metric.Name,
+		Label:       cmp.Or(metric.DisplayName, metric.Name),
+		Description: metric.Description,
+		Type:        metricType,
+		TypeParams:  typeParams,
+	}, true
+}
+
+// metricWindowString writes a window as MetricFlow does, e.g. "7 days".
+func metricWindowString(w *MetricWindow) string {
+	granularity := strings.ToLower(w.Granularity)
+	if w.Count != 1 && !strings.HasSuffix(granularity, "s") {
+		granularity += "s"
+	}
+	return strconv.Itoa(w.Count) + " " + granularity
+}
@@ -0,0 +1,160 @@
+package dbt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportDbtYAML(t *testing.T) {
+	expr := "amount * 1.1"
+	joined := "customers.name"
+	mdl := &LegibleMDLManifest{
+		EnumDefinitions: []EnumDefinition{{Name: "orders_status_Enum", Values: []EnumValue{{Name: "placed"}, {Name: "shipped", Value: "SHIPPED"}}}},
+		Models: []LegibleModel{
+			{
+				Name:       "orders",
+				PrimaryKey: "order_id",
+				Properties: map[string]string{"description": "One row per order.", "displayName": "Orders"},
+				Columns: []LegibleColumn{
+					{Name: "order_id", Type: "INTEGER"},
+					{Name: "customer_id", Type: "integer", NotNull: true},
+					{Name: "status", Type: "varchar", Properties: map[string]string{"enumDefinition": "orders_status_Enum"}},
+					{Name: "amount", Type: "numeric", DisplayName: "Amount", Properties: map[string]string{"description": "Order total."}},
+					{Name: "ordered_at", Type: "date"},
+					{Name: "amount_usd", IsCalculated: true, Expression: &expr, Properties: map[string]string{"description": "Total in USD."}},
+					{Name: "customer_name", IsCalculated: true, Expression: &joined},
+				},
+			},
+			{
+				Name:       "customers",
+				PrimaryKey: "id",
+				Columns:    []LegibleColumn{{Name: "id", Type: "integer"}, {Name: "name", Type: "varchar"}},
+			},
+		},
+		Relationships: []Relationship{
+			{Name: "customers_orders", Models: []string{"customers", "orders"}, JoinType: "ONE_TO_MANY", Condition: `"customers"."id" = "orders"."customer_id"`},
+			{Name: "orders_orders", Models: []string{"orders", "orders"}, JoinType: "MANY_TO_MANY", Condition: `orders.order_id = orders.order_id`},
+		},
+		Metrics: []Metric{
+			{Name: "revenue", Models: []string{"orders"}, Dimensions: []string{"status"}, Aggregation: "SUM(amount)", DisplayName: "Revenue", Type: "simple", TimeDimension: "ordered_at"},
+			{Name: "customers_count", Models: []string{"orders"}, Aggregation: "COUNT(DISTINCT customer_id)", DisplayName: "Customers", TimeDimension: "ordered_at"},
+			{Name: "revenue_7d", Models: []string{"orders"}, Aggregation: "SUM(amount)", Type: "cumulative", Window: &MetricWindow{Count: 7, Granularity: "day"}},
+			{Name: "margin", Models: []string{"orders"}, Aggregation: "SUM(amount) / SUM(cost)", Type: "simple"},
+			{Name: "share", Models: []string{"orders"}, Aggregation: "(SUM(amount)) / (COUNT(*))", Type: "ratio"},
+		},
+	}
+
+	export, err := ExportDbtYAML(mdl)
+	if err != nil {
+		t.Fatalf("ExportDbtYAML() error: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := yaml.Unmarshal(export.Schema, &schema); err != nil {
+		t.Fatalf("parsing schema.yml: %v\n%s", err, export.Schema)
+	}
+	models := schema["models"].([]interface{})
+	orders := models[0].(map[string]interface{})
+	if orders["description"] != "One row per order." || !reflect.DeepEqual(orders["meta"], map[string]interface{}{"label": "Orders"}) {
+		t.Errorf("orders = %v", orders)
+	}
+	columns := orders["columns"].([]interface{})
+	if len(columns) != 5 {
+		t.Fatalf("orders columns = %v, want the 5 physical columns", columns)
+	}
+	wantTests := map[string][]interface{}{
+		"order_id": {"unique", "not_null"},
+		"customer_id": {"not_null", map[string]interface{}{
+			"relationships": map[string]interface{}{"to": "ref('customers')", "field": "id"},
+		}},
+		"status": {map[string]interface{}{
+			"accepted_values": map[string]interface{}{"values": []interface{}{"placed", "SHIPPED"}},
+		}},
+	}
+	for _, c := range columns {
+		col := c.(map[string]interface{})
+		name := col["name"].(string)
+		tests, _ := col["tests"].([]interface{})
+		if !reflect.DeepEqual(tests, wantTests[name]) {
+			t.Errorf("%s tests = %v, want %v", name, tests, wantTests[name])
+		}
+		if name == "order_id" && col["data_type"] != "integer" {
+			t.Errorf("order_id data_type = %v, want integer", col["data_type"])
+		}
+		if name == "amount" && (col["description"] != "Order total." || col["meta"] == nil) {
+			t.Errorf("amount = %v", col)
+		}
+	}
+	if export.Models != 2 || export.Tests != 7 {
+		t.Errorf("Models = %d, Tests = %d, want 2 and 7", export.Models, export.Tests)
+	}
+
+	var semantic dbtSemanticFile
+	if err := yaml.Unmarshal(export.Semantic, &semantic); err != nil {
+		t.Fatalf("parsing semantic models: %v\n%s", err, export.Semantic)
+	}
+	if len(semantic.SemanticModels) != 1 {
+		t.Fatalf("semantic models = %+v, want one on orders", semantic.SemanticModels)
+	}
+	sm := semantic.SemanticModels[0]
+	if sm.Model != "ref('orders')" || sm.Defaults["agg_time_dimension"] != "ordered_at" {
+		t.Errorf("semantic model = %+v", sm)
+	}
+	wantEntities := []semanticEntityYAML{{Name: "orders", Type: "primary", Expr: "order_id"}, {Name: "customers", Type: "foreign", Expr: "customer_id"}}
+	if !reflect.DeepEqual(sm.Entities, wantEntities) {
+		t.Errorf("entities = %+v, want %+v", sm.Entities, wantEntities)
+	}
+	wantDimensions := []semanticDimensionYAML{
+		{Name: "amount_usd", Type: "categorical", Description: "Total in USD.", Expr: "amount * 1.1"},
+		{Name: "status", Type: "categorical"},
+		{Name: "ordered_at", Type: "time", TypeParams: map[string]string{"time_granularity": "day"}},
+	}
+	if !reflect.DeepEqual(sm.Dimensions, wantDimensions) {
+		t.Errorf("dimensions = %+v, want %+v", sm.Dimensions, wantDimensions)
+	}
+	wantMeasures := []semanticMeasureYAML{
+		{Name: "revenue", Agg: "sum", Expr: "amount"},
+		{Name: "customers_count", Agg: "count_distinct", Expr: "customer_id"},
+		{Name: "revenue_7d", Agg: "sum", Expr: "amount"},
+	}
+	if !reflect.DeepEqual(sm.Measures, wantMeasures) {
+		t.Errorf("measures = %+v, want %+v", sm.Measures, wantMeasures)
+	}
+
+	if len(semantic.Metrics) != 3 {
+		t.Fatalf("metrics = %+v, want 3", semantic.Metrics)
+	}
+	if m := semantic.Metrics[1]; m.Type != "simple" || m.Label != "Customers" || m.TypeParams["measure"] != "customers_count" {
+		t.Errorf("customers_count = %+v", m)
+	}
+	cumulative := semantic.Metrics[2].TypeParams["cumulative_type_params"].(map[string]interface{})
+	if semantic.Metrics[2].Type != "cumulative" || cumulative["window"] != "7 days" {
+		t.Errorf("revenue_7d = %+v", semantic.Metrics[2])
+	}
+
+	wantSkipped := []string{"orders_orders", "customer_name", "margin", "share"}
+	if len(export.Skipped) != len(wantSkipped) {
+		t.Fatalf("Skipped = %q, want %d entries", export.Skipped, len(wantSkipped))
+	}
+	for i, name := range wantSkipped {
+		if !strings.Contains(export.Skipped[i], name) {
+			t.Errorf("Skipped[%d] = %q, want it to be about %s", i, export.Skipped[i], name)
+		}
+	}
+}
+
+func TestExportDbtYAMLWithoutSemanticModels(t *testing.T) {
+	export, err := ExportDbtYAML(&LegibleMDLManifest{Models: []LegibleModel{{Name: "orders", Columns: []LegibleColumn{{Name: "id"}}}}})
+	if err != nil {
+		t.Fatalf("ExportDbtYAML() error: %v", err)
+	}
+	if export.Semantic != nil {
+		t.Errorf("Semantic = %s, want nil", export.Semantic)
+	}
+	if !strings.HasPrefix(string(export.Schema), "version: 2\nmodels:\n  - name: orders\n") {
+		t.Errorf("Schema =\n%s", export.Schema)
+	}
+}
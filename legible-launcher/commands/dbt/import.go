@@ -0,0 +1,306 @@
+package dbt
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ImportFormats are the semantic layers ImportMDL converts from.
+var ImportFormats = []string{"lookml", "cube", "metricflow"}
+
+// MDLImport is a Legible MDL converted from another semantic layer's project.
+type MDLImport struct {
+	Manifest *LegibleMDLManifest
+	// Unmapped lists what has no MDL equivalent and was left out, and why.
+	Unmapped []string
+}
+
+// ImportMDL converts a LookML, Cube or MetricFlow project into a Legible MDL,
+// so that a project can be created from a semantic layer that is not built
+// on dbt. Tables become models and their dimensions columns, joins become
+// relationships, measures become metrics and derived tables become views.
+//
+// path is the project's directory. For MetricFlow it can also be a
+// semantic_manifest.json or a YAML file with semantic models and metrics.
+func ImportMDL(format, path string) (*MDLImport, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("reading %s project: %w", format, err)
+	}
+
+	imp := &MDLImport{Manifest: &LegibleMDLManifest{
+		JsonSchema:      mdlSchemaURL,
+		Catalog:         "wren",
+		Schema:          "public",
+		EnumDefinitions: []EnumDefinition{},
+		Models:          []LegibleModel{},
+		Relationships:   []Relationship{},
+		Metrics:         []Metric{},
+		Views:           []View{},
+	}}
+	var err error
+	switch format {
+	case "lookml":
+		err = importLookML(imp, path)
+	case "cube":
+		err = importCube(imp, path)
+	case "metricflow":
+		err = importMetricFlow(imp, path)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (want one of %s)", format, strings.Join(ImportFormats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imp.Manifest.Models) == 0 && len(imp.Manifest.Views) == 0 {
+		return nil, fmt.Errorf("no models found in %s", path)
+	}
+	imp.Manifest.Relationships = uniqueRelationships(imp.Manifest.Relationships)
+	return imp, nil
+}
+
+func (imp *MDLImport) unmapped(format string, args ...interface{}) {
+	imp.Unmapped = append(imp.Unmapped, fmt.Sprintf(format, args...))
+}
+
+// findImportFiles returns the files under root with one of the extensions,
+// in lexical order, skipping hidden and dependency directories. root may be
+// a file itself.
+func findImportFiles(root string, exts ...string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || d.Name() == "dbt_packages") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, ext := range exts {
+			if strings.HasSuffix(d.Name(), ext) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// parseTableReference parses a table name such as analytics.public."orders".
+func parseTableReference(name string) TableReference {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimSuffix(strings.TrimSpace(name), ";"), ".") {
+		parts = append(parts, strings.Trim(strings.TrimSpace(part), "\"`[]"))
+	}
+	switch len(parts) {
+	case 1:
+		return TableReference{Table: parts[0]}
+	case 2:
+		return TableReference{Schema: parts[0], Table: parts[1]}
+	default:
+		n := len(parts)
+		return TableReference{Catalog: strings.Join(parts[:n-2], "."), Schema: parts[n-2], Table: parts[n-1]}
+	}
+}
+
+// fieldReferenceRegex matches a reference in a LookML or Cube expression:
+// ${TABLE} or {CUBE}, a field of the same view or cube as ${field} or
+// {field}, or another one's as ${view.field} or {cube.field}. A trailing dot
+// is matched too, so that ${TABLE}.column can be read as a column.
+var fieldReferenceRegex = regexp.MustCompile(`\$?\{\s*(\w+)(?:\.(\w+))?\s*\}(\.)?`)
+
+// importedTable is a LookML view or cube converted into a model. Its fields
+// can refer to each other, so they are resolved into SQL over its table.
+type importedTable struct {
+	name string
+	// self is how the table refers to itself: TABLE in LookML, CUBE in Cube.
+	self string
+	// fieldSQL is the SQL of each dimension, as written.
+	fieldSQL map[string]string
+	model    *LegibleModel
+}
+
+func newImportedTable(name, self string) *importedTable {
+	return &importedTable{
+		name:     name,
+		self:     self,
+		fieldSQL: make(map[string]string),
+		model:    &LegibleModel{Name: name, TableReference: TableReference{Table: name}, Columns: []LegibleColumn{}},
+	}
+}
+
+// resolve replaces the field references in sql with the fields' SQL, so
+// that the result refers to the table's columns only.
+func (t *importedTable) resolve(sql string, visiting map[string]bool) (string, error) {
+	var resolveErr error
+	resolved := fieldReferenceRegex.ReplaceAllStringFunc(sql, func(ref string) string {
+		parts := fieldReferenceRegex.FindStringSubmatch(ref)
+		table, field, dot := parts[1], parts[2], parts[3]
+		if field == "" && (table == t.self || table == t.name) {
+			if dot == "" {
+				resolveErr = fmt.Errorf("cannot use {%s} other than to refer to a column", table)
+			}
+			return ""
+		}
+		if field == "" {
+			table, field = t.name, table
+		}
+		if table != t.self && table != t.name {
+			resolveErr = fmt.Errorf("refers to %s.%s, which is not in %s", table, field, t.name)
+			return ref
+		}
+		fieldSQL, ok := t.fieldSQL[field]
+		if !ok {
+			resolveErr = fmt.Errorf("refers to %s, which is not a dimension of %s", field, t.name)
+			return ref
+		}
+		if visiting[field] {
+			resolveErr = fmt.Errorf("%s refers to itself", field)
+			return ref
+		}
+		visiting[field] = true
+		expr, err := t.resolve(fieldSQL, visiting)
+		delete(visiting, field)
+		if err != nil {
+			resolveErr = err
+			return ref
+		}
+		if !isPlainIdentifier(expr) {
+			expr = "(" + expr + ")"
+		}
+		return expr + dot
+	})
+	return strings.TrimSpace(resolved), resolveErr
+}
+
+// addColumn adds a dimension as a column. A dimension over a column of
+// another name, or over an expression, keeps its name and gets the SQL as
+// the column's expression.
+func (t *importedTable) addColumn(name, sql, columnType, displayName, description string) error {
+	expr, err := t.resolve(sql, map[string]bool{name: true})
+	if err != nil {
+		return err
+	}
+	col := LegibleColumn{Name: name, Type: columnType, DisplayName: displayName}
+	if expr != name && expr != `"`+name+`"` {
+		col.Expression = &expr
+	}
+	if description != "" {
+		col.Properties = map[string]string{"description": description}
+	}
+	t.model.Columns = append(t.model.Columns, col)
+	return nil
+}
+
+// hasColumn reports whether the table has a column with the given name.
+func (t *importedTable) hasColumn(name string) bool {
+	for _, col := range t.model.Columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// columnOver returns the column over a column of the table, as referred to
+// by a join condition.
+func (t *importedTable) columnOver(column string) (string, bool) {
+	for _, col := range t.model.Columns {
+		if col.Expression == nil && col.Name == column || col.Expression != nil && *col.Expression == column {
+			return col.Name, true
+		}
+	}
+	return "", false
+}
+
+// metric converts a measure over the table into a metric named after the
+// table and the measure, as measure names are only unique within a table.
+func (t *importedTable) metric(name, agg, sql, displayName, description string) (Metric, error) {
+	aggregation, ok := importAggregations[agg]
+	if !ok {
+		return Metric{}, fmt.Errorf("%s measures have no MDL equivalent", agg)
+	}
+	switch {
+	case sql != "":
+		expr, err := t.resolve(sql, make(map[string]bool))
+		if err != nil {
+			return Metric{}, err
+		}
+		// A measure over a single dimension need not keep its parentheses.
+		if inner := strings.TrimSuffix(strings.TrimPrefix(expr, "("), ")"); len(inner) == len(expr)-2 && balancedParens(inner) {
+			expr = inner
+		}
+		aggregation = fmt.Sprintf(aggregation, expr)
+	case agg == "count":
+		aggregation = "COUNT(*)"
+	default:
+		return Metric{}, fmt.Errorf("%s measure has no sql", agg)
+	}
+
+	metric := Metric{
+		Name:        t.name + "_" + name,
+		Models:      []string{t.name},
+		Aggregation: aggregation,
+		DisplayName: displayName,
+		Description: description,
+		Type:        "simple",
+	}
+	if metric.DisplayName == "" {
+		metric.DisplayName = name
+	}
+	for _, col := range t.model.Columns {
+		metric.Dimensions = append(metric.Dimensions, col.Name)
+	}
+	return metric, nil
+}
+
+// importAggregations are the SQL aggregations of LookML and Cube measure
+// types that aggregate a single expression.
+var importAggregations = map[string]string{
+	"count":                 "COUNT(%s)",
+	"count_distinct":        "COUNT(DISTINCT %s)",
+	"count_distinct_approx": "COUNT(DISTINCT %s)",
+	"sum":                   "SUM(%s)",
+	"average":               "AVG(%s)",
+	"avg":                   "AVG(%s)",
+	"min":                   "MIN(%s)",
+	"max":                   "MAX(%s)",
+	"median":                "PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %s)",
+}
+
+// importJoinType returns the MDL join type of a LookML or Cube relationship.
+func importJoinType(relationship string) (string, error) {
+	switch relationship {
+	case "many_to_one", "belongs_to", "manyToOne", "belongsTo":
+		return "MANY_TO_ONE", nil
+	case "one_to_many", "has_many", "oneToMany", "hasMany":
+		return "ONE_TO_MANY", nil
+	case "one_to_one", "has_one", "oneToOne", "hasOne":
+		return "ONE_TO_ONE", nil
+	case "many_to_many":
+		return "MANY_TO_MANY", nil
+	default:
+		return "", fmt.Errorf("unknown relationship %q", relationship)
+	}
+}
+
+// importedRelationship relates two models' columns, named like the
+// relationships converted from MetricFlow entities.
+func importedRelationship(from, fromColumn, to, toColumn, joinType string) Relationship {
+	return Relationship{
+		Name:      fmt.Sprintf("%s_to_%s_by_%s", from, to, fromColumn),
+		Models:    []string{from, to},
+		JoinType:  joinType,
+		Condition: fmt.Sprintf("\"%s\".\"%s\" = \"%s\".\"%s\"", from, fromColumn, to, toColumn),
+	}
+}
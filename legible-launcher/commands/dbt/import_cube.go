@@ -0,0 +1,260 @@
+package dbt
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cubeFile is a Cube data model file in YAML.
+type cubeFile struct {
+	Cubes []cubeDefinition `yaml:"cubes"`
+	Views []struct {
+		Name string `yaml:"name"`
+	} `yaml:"views"`
+}
+
+// cubeDefinition is a cube, over a table (sql_table) or a query (sql).
+type cubeDefinition struct {
+	Name            string       `yaml:"name"`
+	SQLTable        string       `yaml:"sql_table"`
+	SQL             string       `yaml:"sql"`
+	Title           string       `yaml:"title"`
+	Description     string       `yaml:"description"`
+	Extends         string       `yaml:"extends"`
+	Joins           []cubeJoin   `yaml:"joins"`
+	Dimensions      []cubeMember `yaml:"dimensions"`
+	Measures        []cubeMember `yaml:"measures"`
+	Segments        []cubeMember `yaml:"segments"`
+	PreAggregations []cubeMember `yaml:"pre_aggregations"`
+}
+
+// cubeMember is a dimension, measure or segment of a cube.
+type cubeMember struct {
+	Name        string `yaml:"name"`
+	SQL         string `yaml:"sql"`
+	Type        string `yaml:"type"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	PrimaryKey  bool   `yaml:"primary_key"`
+	SubQuery    bool   `yaml:"sub_query"`
+	Filters     []struct {
+		SQL string `yaml:"sql"`
+	} `yaml:"filters"`
+	RollingWindow map[string]interface{} `yaml:"rolling_window"`
+}
+
+// cubeJoin joins a cube to another by a SQL condition.
+type cubeJoin struct {
+	Name         string `yaml:"name"`
+	SQL          string `yaml:"sql"`
+	Relationship string `yaml:"relationship"`
+}
+
+// cubeColumnTypes are the MDL column types of Cube dimension types.
+var cubeColumnTypes = map[string]string{
+	"string":  varcharType,
+	"number":  doubleType,
+	"boolean": booleanType,
+	"time":    timestampType,
+}
+
+// cubeSelectAllRegex matches a cube's sql that only selects a table, so
+// that the cube can be imported as a model over that table.
+var cubeSelectAllRegex = regexp.MustCompile(`(?is)^\s*SELECT\s+\*\s+FROM\s+([\w."` + "`" + `\[\]]+)\s*;?\s*$`)
+
+// cubeJoinSideRegex matches one side of a join condition: a column of a
+// cube, {CUBE}.id or {customers}.id, or a dimension, {CUBE.id} or {customers.id}.
+const cubeJoinSideRegex = `\$?\{\s*(\w+)(?:\.(\w+))?\s*\}(?:\.(\w+))?`
+
+var cubeJoinRegex = regexp.MustCompile(`^\s*` + cubeJoinSideRegex + `\s*=\s*` + cubeJoinSideRegex + `\s*$`)
+
+// importCube converts the cubes in a Cube project's YAML data model files.
+// A cube becomes a model over its sql_table, or over the table its sql
+// selects from, or otherwise a view; its dimensions become columns and its
+// measures metrics, and its joins become relationships.
+func importCube(imp *MDLImport, path string) error {
+	files, err := findImportFiles(path, ".yml", ".yaml", ".js")
+	if err != nil {
+		return err
+	}
+
+	var cubes []cubeDefinition
+	for _, file := range files {
+		if strings.HasSuffix(file, ".js") {
+			imp.unmapped("%s: JavaScript data model files are not imported, only YAML", file)
+			continue
+		}
+		data, err := os.ReadFile(file) // #nosec G304 -- file is in the project being imported
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+		var f cubeFile
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			imp.unmapped("%s: not imported: %v", file, err)
+			continue
+		}
+		cubes = append(cubes, f.Cubes...)
+		for _, view := range f.Views {
+			imp.unmapped("view %s: Cube views are not imported", view.Name)
+		}
+	}
+	if len(cubes) == 0 {
+		return fmt.Errorf("no cubes found in %s", path)
+	}
+
+	tables := make(map[string]*importedTable)
+	for _, cube := range cubes {
+		if tables[cube.Name] != nil || imp.hasView(cube.Name) {
+			imp.unmapped("cube %s: defined more than once, only the first is imported", cube.Name)
+			continue
+		}
+		if t := importCubeDefinition(imp, cube); t != nil {
+			tables[cube.Name] = t
+			imp.Manifest.Models = append(imp.Manifest.Models, *t.model)
+		}
+	}
+	for _, cube := range cubes {
+		from := tables[cube.Name]
+		if from == nil {
+			continue
+		}
+		for _, join := range cube.Joins {
+			rel, err := cubeRelationship(from, join, tables)
+			if err != nil {
+				imp.unmapped("cube %s, join %s: %v", cube.Name, join.Name, err)
+				continue
+			}
+			imp.Manifest.Relationships = append(imp.Manifest.Relationships, rel)
+		}
+	}
+	return nil
+}
+
+// importCubeDefinition converts a cube into a model, or into a view if it
+// is over a query, in which case it returns nil.
+func importCubeDefinition(imp *MDLImport, cube cubeDefinition) *importedTable {
+	properties := make(map[string]string)
+	if cube.Title != "" {
+		properties["displayName"] = cube.Title
+	}
+	if cube.Description != "" {
+		properties["description"] = cube.Description
+	}
+	if len(properties) == 0 {
+		properties = nil
+	}
+	if cube.Extends != "" {
+		imp.unmapped("cube %s: extends %s, whose members are not imported", cube.Name, cube.Extends)
+	}
+	for _, segment := range cube.Segments {
+		imp.unmapped("%s.%s: segments are not imported", cube.Name, segment.Name)
+	}
+	for _, preAggregation := range cube.PreAggregations {
+		imp.unmapped("%s.%s: pre-aggregations are not imported", cube.Name, preAggregation.Name)
+	}
+
+	t := newImportedTable(cube.Name, "CUBE")
+	switch parts := cubeSelectAllRegex.FindStringSubmatch(cube.SQL); {
+	case cube.SQLTable != "":
+		t.model.TableReference = parseTableReference(cube.SQLTable)
+	case parts != nil:
+		t.model.TableReference = parseTableReference(parts[1])
+	case cube.SQL != "":
+		imp.Manifest.Views = append(imp.Manifest.Views, View{Name: cube.Name, Statement: strings.TrimSpace(cube.SQL), Properties: properties})
+		var members []string
+		for _, member := range append(cube.Dimensions, cube.Measures...) {
+			members = append(members, member.Name)
+		}
+		if len(members) > 0 {
+			imp.unmapped("cube %s: imported as a view, without the members of a cube over a query: %s", cube.Name, strings.Join(members, ", "))
+		}
+		return nil
+	}
+	t.model.Properties = properties
+
+	for _, dim := range cube.Dimensions {
+		t.fieldSQL[dim.Name] = dim.SQL
+		if dim.SQL == "" {
+			t.fieldSQL[dim.Name] = dim.Name
+		}
+	}
+	for _, dim := range cube.Dimensions {
+		columnType, ok := cubeColumnTypes[dim.Type]
+		if !ok {
+			imp.unmapped("%s.%s: dimension of type %s has no MDL equivalent", cube.Name, dim.Name, dim.Type)
+			continue
+		}
+		if dim.SubQuery {
+			imp.unmapped("%s.%s: subquery dimensions are not imported", cube.Name, dim.Name)
+			continue
+		}
+		if err := t.addColumn(dim.Name, t.fieldSQL[dim.Name], columnType, dim.Title, dim.Description); err != nil {
+			imp.unmapped("%s.%s: %v", cube.Name, dim.Name, err)
+			continue
+		}
+		if dim.PrimaryKey {
+			t.model.PrimaryKey = dim.Name
+		}
+	}
+
+	for _, measure := range cube.Measures {
+		switch {
+		case len(measure.Filters) > 0:
+			imp.unmapped("%s.%s: measures with filters are not imported", cube.Name, measure.Name)
+			continue
+		case measure.RollingWindow != nil:
+			imp.unmapped("%s.%s: measures with a rolling window are not imported", cube.Name, measure.Name)
+			continue
+		}
+		metric, err := t.metric(measure.Name, measure.Type, measure.SQL, measure.Title, measure.Description)
+		if err != nil {
+			imp.unmapped("%s.%s: %v", cube.Name, measure.Name, err)
+			continue
+		}
+		imp.Manifest.Metrics = append(imp.Manifest.Metrics, metric)
+	}
+	return t
+}
+
+// cubeRelationship converts a join of the from cube into a relationship.
+func cubeRelationship(from *importedTable, join cubeJoin, tables map[string]*importedTable) (Relationship, error) {
+	to := tables[join.Name]
+	if to == nil {
+		return Relationship{}, fmt.Errorf("%s is not a cube over a table", join.Name)
+	}
+	joinType, err := importJoinType(join.Relationship)
+	if err != nil {
+		return Relationship{}, err
+	}
+	parts := cubeJoinRegex.FindStringSubmatch(join.SQL)
+	if parts == nil {
+		return Relationship{}, fmt.Errorf("only joins on the equality of two columns are imported, not %q", join.SQL)
+	}
+
+	columns := make(map[*importedTable]string)
+	for _, side := range [][]string{parts[1:4], parts[4:7]} {
+		t := to
+		if side[0] == "CUBE" || side[0] == from.name {
+			t = from
+		} else if side[0] != to.name {
+			return Relationship{}, fmt.Errorf("sql refers to %s", side[0])
+		}
+		column, ok := side[1], t.hasColumn(side[1])
+		if side[2] != "" {
+			column, ok = t.columnOver(side[2])
+		}
+		if !ok {
+			return Relationship{}, fmt.Errorf("%s has no dimension over %s", t.name, cmp.Or(side[1], side[2]))
+		}
+		columns[t] = column
+	}
+	if len(columns) != 2 {
+		return Relationship{}, fmt.Errorf("sql must compare %s with %s", from.name, to.name)
+	}
+	return importedRelationship(from.name, columns[from], to.name, columns[to], joinType), nil
+}
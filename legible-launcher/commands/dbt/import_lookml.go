@@ -0,0 +1,480 @@
+package dbt
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// lookmlNode is a LookML parameter: a value such as `type: sum`, a list
+// such as `filters: [status: "complete"]`, or a block of parameters, which
+// may be named, such as `dimension: id { ... }`.
+type lookmlNode struct {
+	key      string
+	name     string
+	value    string
+	list     []string
+	children []*lookmlNode
+}
+
+// child returns the node's first parameter with the given key, or nil.
+func (n *lookmlNode) child(key string) *lookmlNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// childValue returns the value of the node's parameter with the given key.
+func (n *lookmlNode) childValue(key string) string {
+	if c := n.child(key); c != nil {
+		return c.value
+	}
+	return ""
+}
+
+// childrenWith returns the node's parameters with the given key.
+func (n *lookmlNode) childrenWith(key string) []*lookmlNode {
+	var children []*lookmlNode
+	for _, c := range n.children {
+		if c.key == key {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// lookmlParser parses the parameters of a LookML file.
+type lookmlParser struct {
+	file string
+	src  string
+	pos  int
+}
+
+func parseLookML(file, src string) ([]*lookmlNode, error) {
+	p := &lookmlParser{file: file, src: src}
+	return p.parseBlock(false)
+}
+
+func (p *lookmlParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.src[:p.pos], "\n") + 1
+	return fmt.Errorf("%s:%d: %s", p.file, line, fmt.Sprintf(format, args...))
+}
+
+// parseBlock parses parameters up to the end of the file, or of the block
+// if nested.
+func (p *lookmlParser) parseBlock(nested bool) ([]*lookmlNode, error) {
+	var nodes []*lookmlNode
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			if nested {
+				return nil, p.errorf("missing '}'")
+			}
+			return nodes, nil
+		}
+		if p.src[p.pos] == '}' {
+			if !nested {
+				return nil, p.errorf("unexpected '}'")
+			}
+			p.pos++
+			return nodes, nil
+		}
+		node, err := p.parseParameter()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+func (p *lookmlParser) parseParameter() (*lookmlNode, error) {
+	key := p.token()
+	if key == "" {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+		return nil, p.errorf("missing ':' after %s", key)
+	}
+	p.pos++
+	node := &lookmlNode{key: key}
+
+	// SQL and HTML run up to ;; and may contain anything else.
+	if isLookMLSQLParameter(key) {
+		end := strings.Index(p.src[p.pos:], ";;")
+		if end < 0 {
+			return nil, p.errorf("missing ';;' after %s", key)
+		}
+		node.value = strings.TrimSpace(p.src[p.pos : p.pos+end])
+		p.pos += end + 2
+		return node, nil
+	}
+
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("missing value for %s", key)
+	}
+	var err error
+	switch p.src[p.pos] {
+	case '{':
+		p.pos++
+		node.children, err = p.parseBlock(true)
+	case '[':
+		p.pos++
+		node.list, err = p.parseList()
+	case '"':
+		node.value, err = p.parseString()
+	default:
+		if node.value = p.token(); node.value == "" {
+			return nil, p.errorf("unexpected %q", p.src[p.pos])
+		}
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '{' {
+			p.pos++
+			node.name, node.value = node.value, ""
+			node.children, err = p.parseBlock(true)
+		}
+	}
+	return node, err
+}
+
+// parseList parses the items of a list, e.g. [id, name] or
+// [status: "complete", amount: ">10"], as they are written.
+func (p *lookmlParser) parseList() ([]string, error) {
+	var items []string
+	var item strings.Builder
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case ']', ',':
+			p.pos++
+			if s := strings.TrimSpace(item.String()); s != "" {
+				items = append(items, strings.Trim(s, `"`))
+			}
+			item.Reset()
+			if c == ']' {
+				return items, nil
+			}
+		case '"':
+			s, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			item.WriteString(`"` + s + `"`)
+		default:
+			item.WriteByte(c)
+			p.pos++
+		}
+	}
+	return nil, p.errorf("missing ']'")
+}
+
+func (p *lookmlParser) parseString() (string, error) {
+	var s strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case '\\':
+			if p.pos+1 < len(p.src) {
+				p.pos++
+				s.WriteByte(p.src[p.pos])
+			}
+		case '"':
+			p.pos++
+			return s.String(), nil
+		default:
+			s.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// token reads a key or a value that is not a string, a list or a block.
+func (p *lookmlParser) token() string {
+	start := p.pos
+	for p.pos < len(p.src) && !unicode.IsSpace(rune(p.src[p.pos])) && !strings.ContainsRune(`{}[],:"#`, rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// skipSpace skips white space and # comments.
+func (p *lookmlParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case unicode.IsSpace(rune(c)):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// isLookMLSQLParameter reports whether a parameter's value is SQL or HTML
+// ending in ;;, e.g. sql, sql_on, sql_table_name or html.
+func isLookMLSQLParameter(key string) bool {
+	return key == "sql" || key == "html" || key == "expression" ||
+		strings.HasPrefix(key, "sql_") || strings.HasSuffix(key, "_sql")
+}
+
+// lookmlColumnTypes are the MDL column types of LookML dimension types.
+var lookmlColumnTypes = map[string]string{
+	"":          varcharType,
+	"string":    varcharType,
+	"zipcode":   varcharType,
+	"number":    doubleType,
+	"yesno":     booleanType,
+	"date":      dateType,
+	"date_time": timestampType,
+	"time":      timestampType,
+}
+
+// lookmlSQLTableNameRegex matches a derived table's reference to another
+// view's table, e.g. ${orders.SQL_TABLE_NAME}.
+var lookmlSQLTableNameRegex = regexp.MustCompile(`\$\{\s*(\w+)\.SQL_TABLE_NAME\s*\}`)
+
+// lookmlJoinRegex matches a join's sql_on, which must compare a field of
+// each view, e.g. ${orders.customer_id} = ${customers.id}.
+var lookmlJoinRegex = regexp.MustCompile(`^\$\{\s*(\w+)\.(\w+)\s*\}\s*=\s*\$\{\s*(\w+)\.(\w+)\s*\}$`)
+
+// importLookML converts the views and explores in a LookML project's .lkml
+// files. A view becomes a model over its sql_table_name, or a view if it is
+// a SQL derived table; its dimensions and dimension groups become columns
+// and its measures metrics. Explores' joins become relationships.
+func importLookML(imp *MDLImport, path string) error {
+	files, err := findImportFiles(path, ".lkml")
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no .lkml files found in %s", path)
+	}
+
+	var views, explores []*lookmlNode
+	for _, file := range files {
+		data, err := os.ReadFile(file) // #nosec G304 -- file is in the project being imported
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+		nodes, err := parseLookML(file, string(data))
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			switch node.key {
+			case "view":
+				views = append(views, node)
+			case "explore":
+				explores = append(explores, node)
+			}
+		}
+	}
+
+	tables := make(map[string]*importedTable)
+	var order []string
+	for _, view := range views {
+		switch {
+		case strings.HasPrefix(view.name, "+"):
+			imp.unmapped("view %s: refinements are not imported", view.name)
+			continue
+		case tables[view.name] != nil || imp.hasView(view.name):
+			imp.unmapped("view %s: defined more than once, only the first is imported", view.name)
+			continue
+		}
+		if extends := view.child("extends"); extends != nil {
+			imp.unmapped("view %s: extends %s, whose fields are not imported", view.name, strings.Join(extends.list, ", "))
+		}
+		if derived := view.child("derived_table"); derived != nil {
+			importLookMLDerivedTable(imp, view, derived)
+			continue
+		}
+		tables[view.name] = importLookMLView(imp, view)
+		order = append(order, view.name)
+	}
+	for _, name := range order {
+		imp.Manifest.Models = append(imp.Manifest.Models, *tables[name].model)
+	}
+
+	for _, explore := range explores {
+		importLookMLExplore(imp, explore, tables)
+	}
+	return nil
+}
+
+// hasView reports whether the import has a view with the given name.
+func (imp *MDLImport) hasView(name string) bool {
+	for _, view := range imp.Manifest.Views {
+		if view.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// importLookMLView converts a view over a table into a model.
+func importLookMLView(imp *MDLImport, view *lookmlNode) *importedTable {
+	t := newImportedTable(view.name, "TABLE")
+	if tableName := view.childValue("sql_table_name"); tableName != "" {
+		t.model.TableReference = parseTableReference(tableName)
+	}
+	t.model.Properties = lookmlProperties(view)
+
+	var fields []*lookmlNode
+	for _, field := range view.children {
+		if field.key == "dimension" || field.key == "dimension_group" {
+			fields = append(fields, field)
+			t.fieldSQL[field.name] = cmp.Or(field.childValue("sql"), "${TABLE}."+field.name)
+		}
+	}
+	for _, field := range fields {
+		fieldType := field.childValue("type")
+		columnType, ok := lookmlColumnTypes[fieldType]
+		if field.key == "dimension_group" {
+			ok = fieldType == "time"
+			columnType = timestampType
+			if field.childValue("datatype") == "date" {
+				columnType = dateType
+			}
+		}
+		if !ok {
+			imp.unmapped("%s.%s: %s of type %s has no MDL equivalent", view.name, field.name, strings.ReplaceAll(field.key, "_", " "), fieldType)
+			continue
+		}
+		if err := t.addColumn(field.name, t.fieldSQL[field.name], columnType, field.childValue("label"), field.childValue("description")); err != nil {
+			imp.unmapped("%s.%s: %v", view.name, field.name, err)
+			continue
+		}
+		if field.childValue("primary_key") == "yes" {
+			t.model.PrimaryKey = field.name
+		}
+	}
+
+	for _, measure := range view.childrenWith("measure") {
+		if filters := measure.child("filters"); filters != nil {
+			imp.unmapped("%s.%s: measures with filters are not imported", view.name, measure.name)
+			continue
+		}
+		measureType := cmp.Or(measure.childValue("type"), "count")
+		sql := measure.childValue("sql")
+		if sql == "" && measureType != "count" {
+			sql = "${TABLE}." + measure.name
+		}
+		metric, err := t.metric(measure.name, measureType, sql, measure.childValue("label"), measure.childValue("description"))
+		if err != nil {
+			imp.unmapped("%s.%s: %v", view.name, measure.name, err)
+			continue
+		}
+		imp.Manifest.Metrics = append(imp.Manifest.Metrics, metric)
+	}
+	return t
+}
+
+// importLookMLDerivedTable converts a SQL derived table into a view. Its
+// fields are not imported, as MDL views have no columns of their own.
+func importLookMLDerivedTable(imp *MDLImport, view, derived *lookmlNode) {
+	sql := derived.childValue("sql")
+	if sql == "" {
+		imp.unmapped("view %s: native derived tables are not imported", view.name)
+		return
+	}
+	imp.Manifest.Views = append(imp.Manifest.Views, View{
+		Name:       view.name,
+		Statement:  lookmlSQLTableNameRegex.ReplaceAllString(sql, "$1"),
+		Properties: lookmlProperties(view),
+	})
+	var fields []string
+	for _, field := range view.children {
+		if field.key == "dimension" || field.key == "dimension_group" || field.key == "measure" {
+			fields = append(fields, field.name)
+		}
+	}
+	if len(fields) > 0 {
+		imp.unmapped("view %s: imported as a view, without the fields of the derived table: %s", view.name, strings.Join(fields, ", "))
+	}
+}
+
+// importLookMLExplore converts an explore's joins into relationships
+// between the joined views.
+func importLookMLExplore(imp *MDLImport, explore *lookmlNode, tables map[string]*importedTable) {
+	aliases := map[string]string{explore.name: cmp.Or(explore.childValue("from"), explore.childValue("view_name"), explore.name)}
+	joins := explore.childrenWith("join")
+	for _, join := range joins {
+		aliases[join.name] = cmp.Or(join.childValue("from"), join.name)
+	}
+	if explore.child("sql_always_where") != nil || explore.child("always_filter") != nil {
+		imp.unmapped("explore %s: filters are not imported", explore.name)
+	}
+
+	for _, join := range joins {
+		rel, err := lookmlRelationship(join, aliases, tables)
+		if err != nil {
+			imp.unmapped("explore %s, join %s: %v", explore.name, join.name, err)
+			continue
+		}
+		imp.Manifest.Relationships = append(imp.Manifest.Relationships, rel)
+	}
+}
+
+// lookmlRelationship converts a join into a relationship from the view it is
+// joined to. The join's relationship describes it in that direction.
+func lookmlRelationship(join *lookmlNode, aliases map[string]string, tables map[string]*importedTable) (Relationship, error) {
+	sqlOn := join.childValue("sql_on")
+	if sqlOn == "" {
+		if join.child("foreign_key") != nil {
+			return Relationship{}, fmt.Errorf("joins on foreign_key are not imported, only on sql_on")
+		}
+		return Relationship{}, fmt.Errorf("has no sql_on")
+	}
+	parts := lookmlJoinRegex.FindStringSubmatch(sqlOn)
+	if parts == nil {
+		return Relationship{}, fmt.Errorf("only joins on the equality of two fields are imported, not %q", sqlOn)
+	}
+	from, fromField, to, toField := parts[1], parts[2], parts[3], parts[4]
+	if from == join.name {
+		from, fromField, to, toField = to, toField, from, fromField
+	}
+	if to != join.name {
+		return Relationship{}, fmt.Errorf("sql_on does not refer to %s", join.name)
+	}
+
+	joinType, err := importJoinType(cmp.Or(join.childValue("relationship"), "many_to_one"))
+	if err != nil {
+		return Relationship{}, err
+	}
+	fromTable, toTable := tables[aliases[from]], tables[aliases[to]]
+	for _, side := range []struct {
+		alias, field string
+		table        *importedTable
+	}{{from, fromField, fromTable}, {to, toField, toTable}} {
+		if side.table == nil {
+			return Relationship{}, fmt.Errorf("%s is not a view over a table", side.alias)
+		}
+		if !side.table.hasColumn(side.field) {
+			return Relationship{}, fmt.Errorf("%s is not a dimension of %s", side.field, side.table.name)
+		}
+	}
+	return importedRelationship(fromTable.name, fromField, toTable.name, toField, joinType), nil
+}
+
+// lookmlProperties returns the model or view properties of a LookML view.
+func lookmlProperties(view *lookmlNode) map[string]string {
+	properties := make(map[string]string)
+	if label := view.childValue("label"); label != "" {
+		properties["displayName"] = label
+	}
+	if description := view.childValue("description"); description != "" {
+		properties["description"] = description
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+	return properties
+}
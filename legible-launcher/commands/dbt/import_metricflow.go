@@ -0,0 +1,264 @@
+package dbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// windowRegex parses a MetricFlow window as written in YAML, e.g. "7 days".
+var windowRegex = regexp.MustCompile(`^\s*(\d+)\s+(\w+?)s?\s*$`)
+
+// importMetricFlow converts the semantic models and metrics of a MetricFlow
+// project that has no dbt catalog. Each semantic model becomes a model over
+// the table it is defined on, with its entities, dimensions and measure
+// columns as columns; entities relate the models, and the metrics are
+// converted like those of a dbt project.
+//
+// The semantic models are read from target/semantic_manifest.json if there
+// is one, or else from the YAML files they are written in.
+func importMetricFlow(imp *MDLImport, path string) error {
+	data, err := readMetricFlowProject(imp, path)
+	if err != nil {
+		return err
+	}
+
+	modelIndex := make(map[string]int)
+	for _, smMap := range mapsInList(data["semantic_models"]) {
+		name := getStringFromMap(smMap, "name", "")
+		nodeRelation := getMapFromMap(smMap, "node_relation", nil)
+		table := getStringFromMap(nodeRelation, "alias", "")
+		if table == "" {
+			imp.unmapped("semantic model %s: the model it is defined on is unknown", name)
+			continue
+		}
+		i, ok := modelIndex[table]
+		if !ok {
+			i = len(imp.Manifest.Models)
+			modelIndex[table] = i
+			imp.Manifest.Models = append(imp.Manifest.Models, LegibleModel{
+				Name: table,
+				TableReference: TableReference{
+					Catalog: getStringFromMap(nodeRelation, "database", ""),
+					Schema:  getStringFromMap(nodeRelation, "schema_name", ""),
+					Table:   table,
+				},
+				Columns: []LegibleColumn{},
+			})
+		}
+		addSemanticModelColumns(&imp.Manifest.Models[i], smMap)
+	}
+	for _, query := range mapsInList(data["saved_queries"]) {
+		imp.unmapped("saved query %s: saved queries are not imported", getStringFromMap(query, "name", ""))
+	}
+
+	semantic := newSemanticGraph(data, imp.Manifest.Models)
+	semantic.skipMetric = func(name string, err error) {
+		imp.unmapped("metric %s: %v", name, err)
+	}
+	imp.Manifest.Relationships = semantic.relationships()
+	imp.Manifest.Metrics = semantic.convertMetrics()
+	return nil
+}
+
+// addSemanticModelColumns adds a semantic model's entities, dimensions and
+// the columns its measures aggregate to a model. Entities and dimensions
+// over a column are named after the column, as metrics refer to them by
+// their expressions; those over a SQL expression keep their own names.
+// Semantic models do not declare column types, so the types are generic.
+func addSemanticModelColumns(model *LegibleModel, smMap map[string]interface{}) {
+	add := func(name, expr, columnType string) string {
+		if isPlainIdentifier(expr) {
+			name = expr
+		}
+		for _, col := range model.Columns {
+			if col.Name == name {
+				return name
+			}
+		}
+		col := LegibleColumn{Name: name, Type: columnType}
+		if expr != name {
+			col.Expression = &expr
+		}
+		model.Columns = append(model.Columns, col)
+		return name
+	}
+
+	if description := getStringFromMap(smMap, "description", ""); description != "" && model.Properties == nil {
+		model.Properties = map[string]string{"description": description}
+	}
+	for _, entity := range mapsInList(smMap["entities"]) {
+		name := getStringFromMap(entity, "name", "")
+		column := add(name, getStringFromMap(entity, "expr", name), varcharType)
+		if getStringFromMap(entity, "type", "") == "primary" && model.PrimaryKey == "" {
+			model.PrimaryKey = column
+		}
+	}
+	for _, dim := range mapsInList(smMap["dimensions"]) {
+		columnType := varcharType
+		if getStringFromMap(dim, "type", "") == "time" {
+			columnType = timestampType
+		}
+		name := getStringFromMap(dim, "name", "")
+		add(name, getStringFromMap(dim, "expr", name), columnType)
+	}
+	for _, measure := range mapsInList(smMap["measures"]) {
+		name := getStringFromMap(measure, "name", "")
+		if expr := getStringFromMap(measure, "expr", name); isPlainIdentifier(expr) {
+			add(expr, expr, doubleType)
+		}
+	}
+}
+
+// readMetricFlowProject reads the semantic models and metrics of a
+// MetricFlow project, in the shape of a semantic manifest.
+func readMetricFlowProject(imp *MDLImport, path string) (map[string]interface{}, error) {
+	manifestPath := path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		manifestPath = filepath.Join(path, "target", "semantic_manifest.json")
+	}
+	if strings.HasSuffix(manifestPath, ".json") && FileExists(manifestPath) {
+		data, err := os.ReadFile(manifestPath) // #nosec G304 -- manifestPath is in the project being imported
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", manifestPath, err)
+		}
+		var manifest map[string]interface{}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", manifestPath, err)
+		}
+		return manifest, nil
+	}
+
+	files, err := findImportFiles(path, ".yml", ".yaml")
+	if err != nil {
+		return nil, err
+	}
+	project := map[string]interface{}{}
+	for _, file := range files {
+		data, err := os.ReadFile(file) // #nosec G304 -- file is in the project being imported
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			imp.unmapped("%s: not imported: %v", file, err)
+			continue
+		}
+		for _, key := range []string{"semantic_models", "metrics", "saved_queries"} {
+			if list, ok := doc[key].([]interface{}); ok {
+				existing, _ := project[key].([]interface{})
+				project[key] = append(existing, list...)
+			}
+		}
+	}
+	if project["semantic_models"] == nil {
+		return nil, fmt.Errorf("no semantic models found in %s", path)
+	}
+	normalizeSemanticYAML(project)
+	return project, nil
+}
+
+// normalizeSemanticYAML rewrites semantic models and metrics as written in
+// YAML into the shape dbt gives them in semantic_manifest.json: the model a
+// semantic model is defined on as its node_relation, measure and metric
+// inputs and filters as objects, and windows as a count and granularity.
+func normalizeSemanticYAML(project map[string]interface{}) {
+	for _, smMap := range mapsInList(project["semantic_models"]) {
+		if _, ok := smMap["node_relation"]; !ok {
+			if model := parseRef(getStringFromMap(smMap, "model", "")); model != "" {
+				smMap["node_relation"] = map[string]interface{}{"alias": model}
+			}
+		}
+		// YAML reads an expression such as 1 as a number.
+		for _, key := range []string{"entities", "dimensions", "measures"} {
+			for _, element := range mapsInList(smMap[key]) {
+				if expr, ok := element["expr"]; ok && expr != nil {
+					element["expr"] = fmt.Sprint(expr)
+				}
+			}
+		}
+	}
+
+	for _, metricMap := range mapsInList(project["metrics"]) {
+		metricMap["filter"] = normalizeFilter(metricMap["filter"])
+		typeParams := getMapFromMap(metricMap, "type_params", nil)
+		if typeParams == nil {
+			continue
+		}
+		for _, key := range []string{"measure", "numerator", "denominator"} {
+			if input, ok := typeParams[key]; ok {
+				typeParams[key] = normalizeInput(input)
+			}
+		}
+		if inputs, ok := typeParams["metrics"].([]interface{}); ok {
+			for i, input := range inputs {
+				inputs[i] = normalizeInput(input)
+			}
+		}
+		if window, ok := typeParams["window"]; ok {
+			typeParams["window"] = normalizeWindow(window)
+		}
+		if cumulativeParams := getMapFromMap(typeParams, "cumulative_type_params", nil); cumulativeParams != nil {
+			cumulativeParams["window"] = normalizeWindow(cumulativeParams["window"])
+		}
+		if conversionParams := getMapFromMap(typeParams, "conversion_type_params", nil); conversionParams != nil {
+			for _, key := range []string{"base_measure", "conversion_measure"} {
+				conversionParams[key] = normalizeInput(conversionParams[key])
+			}
+			conversionParams["window"] = normalizeWindow(conversionParams["window"])
+		}
+	}
+}
+
+// normalizeInput turns a measure or metric input written as its name into
+// an object, and normalizes its filter.
+func normalizeInput(input interface{}) interface{} {
+	switch v := input.(type) {
+	case string:
+		return map[string]interface{}{"name": v}
+	case map[string]interface{}:
+		v["filter"] = normalizeFilter(v["filter"])
+	}
+	return input
+}
+
+// normalizeFilter turns a filter written as one or more where clauses into
+// a where_filters object.
+func normalizeFilter(filter interface{}) interface{} {
+	var templates []interface{}
+	switch v := filter.(type) {
+	case string:
+		templates = []interface{}{v}
+	case []interface{}:
+		templates = v
+	default:
+		return filter
+	}
+	var whereFilters []interface{}
+	for _, template := range templates {
+		if s, ok := template.(string); ok {
+			whereFilters = append(whereFilters, map[string]interface{}{"where_sql_template": s})
+		}
+	}
+	return map[string]interface{}{"where_filters": whereFilters}
+}
+
+// normalizeWindow turns a window written as "7 days" into an object.
+func normalizeWindow(window interface{}) interface{} {
+	s, ok := window.(string)
+	if !ok {
+		return window
+	}
+	parts := windowRegex.FindStringSubmatch(s)
+	if parts == nil {
+		return window
+	}
+	count, _ := strconv.Atoi(parts[1])
+	return map[string]interface{}{"count": float64(count), "granularity": strings.ToLower(parts[2])}
+}
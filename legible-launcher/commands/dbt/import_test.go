@@ -0,0 +1,276 @@
+package dbt
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// importTestdata imports a project in testdata and checks that the MDL is valid.
+func importTestdata(t *testing.T, format, dir string) *MDLImport {
+	t.Helper()
+	imp, err := ImportMDL(format, filepath.Join("testdata", dir))
+	if err != nil {
+		t.Fatalf("ImportMDL() error = %v", err)
+	}
+	if report := ValidateManifest(imp.Manifest); len(report.Issues) > 0 {
+		t.Errorf("ValidateManifest() = %+v", report.Issues)
+	}
+	return imp
+}
+
+func checkUnmapped(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Unmapped = %q, want %d entries", got, len(want))
+	}
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("Unmapped[%d] = %q, want it to contain %q", i, got[i], want[i])
+		}
+	}
+}
+
+func columnsByName(model LegibleModel) map[string]LegibleColumn {
+	columns := make(map[string]LegibleColumn, len(model.Columns))
+	for _, col := range model.Columns {
+		columns[col.Name] = col
+	}
+	return columns
+}
+
+func TestImportLookML(t *testing.T) {
+	imp := importTestdata(t, "lookml", "lookml")
+	mdl := imp.Manifest
+
+	if len(mdl.Models) != 2 {
+		t.Fatalf("models = %+v, want orders and customers", mdl.Models)
+	}
+	orders := mdl.Models[0]
+	if orders.Name != "orders" || orders.PrimaryKey != "id" || orders.Properties["displayName"] != "Orders" {
+		t.Errorf("orders = %+v", orders)
+	}
+	if want := (TableReference{Catalog: "analytics", Schema: "public", Table: "orders"}); orders.TableReference != want {
+		t.Errorf("orders table = %+v, want %+v", orders.TableReference, want)
+	}
+
+	columns := columnsByName(orders)
+	wantColumns := []struct {
+		name, columnType, expression string
+	}{
+		{"id", doubleType, ""},
+		{"customer_id", doubleType, `"CUSTOMER_ID"`},
+		{"status", varcharType, ""},
+		{"is_returned", booleanType, "status = 'returned'"},
+		{"amount", doubleType, "amount_cents / 100.0"},
+		{"created", timestampType, "created_at"},
+	}
+	if len(columns) != len(wantColumns) {
+		t.Errorf("orders columns = %+v, want %d", orders.Columns, len(wantColumns))
+	}
+	for _, want := range wantColumns {
+		col, ok := columns[want.name]
+		if !ok {
+			t.Errorf("orders has no column %s", want.name)
+			continue
+		}
+		var expression string
+		if col.Expression != nil {
+			expression = *col.Expression
+		}
+		if col.Type != want.columnType || expression != want.expression {
+			t.Errorf("%s = %s %q, want %s %q", want.name, col.Type, expression, want.columnType, want.expression)
+		}
+	}
+	if columns["status"].Properties["description"] == "" || mdl.Models[1].Columns[1].DisplayName != "Customer Name" {
+		t.Errorf("descriptions and labels were not imported: %+v", mdl.Models)
+	}
+
+	wantRelationships := []Relationship{{
+		Name:      "orders_to_customers_by_customer_id",
+		Models:    []string{"orders", "customers"},
+		JoinType:  "MANY_TO_ONE",
+		Condition: `"orders"."customer_id" = "customers"."id"`,
+	}}
+	if !reflect.DeepEqual(mdl.Relationships, wantRelationships) {
+		t.Errorf("relationships = %+v, want %+v", mdl.Relationships, wantRelationships)
+	}
+
+	wantMetrics := map[string]string{
+		"orders_count":        "COUNT(*)",
+		"orders_total_amount": "SUM(amount_cents / 100.0)",
+		"orders_customers":    `COUNT(DISTINCT "CUSTOMER_ID")`,
+	}
+	if len(mdl.Metrics) != len(wantMetrics) {
+		t.Errorf("metrics = %+v, want %d", mdl.Metrics, len(wantMetrics))
+	}
+	for _, m := range mdl.Metrics {
+		if m.Aggregation != wantMetrics[m.Name] || !reflect.DeepEqual(m.Models, []string{"orders"}) {
+			t.Errorf("metric %s = %+v, want aggregation %q", m.Name, m, wantMetrics[m.Name])
+		}
+	}
+	if mdl.Metrics[1].DisplayName != "Revenue" || len(mdl.Metrics[1].Dimensions) != 6 {
+		t.Errorf("orders_total_amount = %+v", mdl.Metrics[1])
+	}
+
+	if len(mdl.Views) != 1 || mdl.Views[0].Name != "order_facts" ||
+		mdl.Views[0].Statement != "SELECT order_id, COUNT(*) AS items\n      FROM order_items\n      GROUP BY 1" {
+		t.Errorf("views = %+v", mdl.Views)
+	}
+
+	checkUnmapped(t, imp.Unmapped, []string{
+		"orders.amount_tier: dimension of type tier",
+		"orders.returned_count: measures with filters",
+		"orders.average_order: number measures",
+		"view order_facts: imported as a view",
+		"explore orders: filters",
+		"join order_facts: order_facts is not a view over a table",
+		"join products: joins on foreign_key",
+	})
+}
+
+func TestParseLookMLErrors(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"missing brace", "view: orders {\n  dimension: id {}\n", "orders.lkml:3: missing '}'"},
+		{"missing semicolons", "view: orders {\n  sql_table_name: orders\n}", "missing ';;' after sql_table_name"},
+		{"missing colon", "view orders {}", "missing ':' after view"},
+		{"unterminated string", `view: orders { label: "Orders }`, "unterminated string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseLookML("orders.lkml", tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseLookML() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportCube(t *testing.T) {
+	imp := importTestdata(t, "cube", "cube")
+	mdl := imp.Manifest
+
+	if len(mdl.Models) != 2 {
+		t.Fatalf("models = %+v, want orders and customers", mdl.Models)
+	}
+	orders, customers := mdl.Models[0], mdl.Models[1]
+	if orders.TableReference != (TableReference{Schema: "public", Table: "orders"}) || orders.PrimaryKey != "id" {
+		t.Errorf("orders = %+v", orders)
+	}
+	// A cube over SELECT * FROM a table is a model over the table.
+	if customers.TableReference != (TableReference{Schema: "public", Table: "customers"}) {
+		t.Errorf("customers table = %+v", customers.TableReference)
+	}
+	if got := len(orders.Columns); got != 4 {
+		t.Errorf("orders columns = %+v, want 4", orders.Columns)
+	}
+	if col := columnsByName(customers)["full_name"]; col.Expression == nil || *col.Expression != "first_name || ' ' || last_name" {
+		t.Errorf("full_name = %+v", col)
+	}
+
+	wantRelationships := []Relationship{{
+		Name:      "orders_to_customers_by_customer_id",
+		Models:    []string{"orders", "customers"},
+		JoinType:  "MANY_TO_ONE",
+		Condition: `"orders"."customer_id" = "customers"."id"`,
+	}}
+	if !reflect.DeepEqual(mdl.Relationships, wantRelationships) {
+		t.Errorf("relationships = %+v, want %+v", mdl.Relationships, wantRelationships)
+	}
+	if len(mdl.Metrics) != 2 || mdl.Metrics[0].Aggregation != "COUNT(*)" || mdl.Metrics[1].Name != "orders_revenue" || mdl.Metrics[1].Aggregation != "SUM(amount)" {
+		t.Errorf("metrics = %+v", mdl.Metrics)
+	}
+	if len(mdl.Views) != 1 || mdl.Views[0].Name != "order_totals" || !strings.HasPrefix(mdl.Views[0].Statement, "SELECT order_id") {
+		t.Errorf("views = %+v", mdl.Views)
+	}
+
+	checkUnmapped(t, imp.Unmapped, []string{
+		"legacy.js: JavaScript data model files",
+		"view orders_view: Cube views",
+		"orders.completed: segments",
+		"orders.location: dimension of type geo",
+		"orders.completed_count: measures with filters",
+		"orders.average_revenue: number measures",
+		"cube order_totals: imported as a view",
+		"join order_totals: order_totals is not a cube over a table",
+	})
+}
+
+func TestImportMetricFlowYAML(t *testing.T) {
+	imp := importTestdata(t, "metricflow", "metricflow")
+	mdl := imp.Manifest
+
+	if len(mdl.Models) != 2 {
+		t.Fatalf("models = %+v, want orders and customers", mdl.Models)
+	}
+	orders := mdl.Models[0]
+	var names []string
+	for _, col := range orders.Columns {
+		names = append(names, col.Name)
+	}
+	// Entities and dimensions over a column are named after it.
+	if want := []string{"order_id", "customer_id", "ordered_at", "is_large", "order_total"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("orders columns = %v, want %v", names, want)
+	}
+	if orders.PrimaryKey != "order_id" || orders.Properties["description"] != "One row per order." {
+		t.Errorf("orders = %+v", orders)
+	}
+	if len(mdl.Relationships) != 1 || mdl.Relationships[0].Condition != `"orders"."customer_id" = "customers"."customer_id"` {
+		t.Errorf("relationships = %+v", mdl.Relationships)
+	}
+
+	metrics := make(map[string]Metric)
+	for _, m := range mdl.Metrics {
+		metrics[m.Name] = m
+	}
+	if m := metrics["revenue"]; m.Aggregation != "SUM(order_total)" || m.DisplayName != "Revenue" || m.TimeDimension != "ordered_at" {
+		t.Errorf("revenue = %+v", m)
+	}
+	// Filters written as a string, and expressions YAML reads as numbers
+	if m := metrics["large_orders"]; m.Aggregation != "SUM(CASE WHEN (order_total > 100) THEN 1 END)" {
+		t.Errorf("large_orders = %+v", m)
+	}
+	if m := metrics["revenue_7d"]; m.Window == nil || *m.Window != (MetricWindow{Count: 7, Granularity: "day"}) {
+		t.Errorf("revenue_7d = %+v", m)
+	}
+	checkUnmapped(t, imp.Unmapped, []string{"metric revenue_per_order: denominator: metric 'order_count_metric' is not defined"})
+}
+
+func TestImportMetricFlowSemanticManifest(t *testing.T) {
+	imp := importTestdata(t, "metricflow", filepath.Join("jaffle_shop", "semantic_manifest.json"))
+
+	models := make(map[string]LegibleModel)
+	for _, m := range imp.Manifest.Models {
+		models[m.Name] = m
+	}
+	if orders := models["orders"]; orders.TableReference != (TableReference{Catalog: "warehouse", Schema: "analytics", Table: "orders"}) {
+		t.Errorf("orders table = %+v", orders.TableReference)
+	}
+	var revenue *Metric
+	for i := range imp.Manifest.Metrics {
+		if imp.Manifest.Metrics[i].Name == "revenue" {
+			revenue = &imp.Manifest.Metrics[i]
+		}
+	}
+	if revenue == nil || revenue.Aggregation != "SUM(product_price)" || !reflect.DeepEqual(revenue.Models, []string{"order_items"}) {
+		t.Errorf("revenue = %+v", revenue)
+	}
+	if len(imp.Manifest.Relationships) == 0 {
+		t.Error("expected relationships between the semantic models' entities")
+	}
+}
+
+func TestImportMDLErrors(t *testing.T) {
+	if _, err := ImportMDL("looker", "testdata"); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("ImportMDL(looker) error = %v", err)
+	}
+	if _, err := ImportMDL("lookml", filepath.Join("testdata", "cube")); err == nil || !strings.Contains(err.Error(), "no .lkml files") {
+		t.Errorf("ImportMDL(lookml) error = %v", err)
+	}
+	if _, err := ImportMDL("cube", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing project")
+	}
+}
@@ -0,0 +1,322 @@
+package dbt
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// InferredRelationship is a relationship that was inferred from the dbt
+// project rather than declared by a relationships test or a semantic model,
+// for the user to review before it is added to the MDL.
+type InferredRelationship struct {
+	Relationship
+	// Confidence is between 0 and 1; higher is more likely to be correct.
+	Confidence float64 `json:"confidence"`
+	// Reason explains what the relationship was inferred from.
+	Reason string `json:"reason"`
+}
+
+// modelNamePrefixes are the layer prefixes stripped from model names when
+// matching them to column names, e.g. dim_customers for customer_id.
+var modelNamePrefixes = []string{"dim_", "fct_", "fact_", "stg_", "staging_", "int_"}
+
+// conditionRegex parses the join conditions the converter writes,
+// e.g. "orders"."customer_id" = "customers"."id".
+var conditionRegex = regexp.MustCompile(`^"([^"]+)"\."([^"]+)" = "([^"]+)"\."([^"]+)"$`)
+
+// relationInferrer infers relationships between the converted models.
+type relationInferrer struct {
+	models  []LegibleModel
+	byName  map[string]*LegibleModel
+	keys    map[string]map[string]string // model -> lower-case column -> why it is unique
+	related map[string]bool              // "model.column>model" pairs that are already related
+}
+
+// inferRelationships infers relationships that are missing from existing:
+// from semantic model foreign entities that do not share a name with the
+// entity they refer to, and from column names such as customer_id that name
+// another model. unique tests, primary keys and semantic model keys decide
+// between ONE_TO_ONE and MANY_TO_ONE. The result is sorted by confidence.
+func inferRelationships(models []LegibleModel, existing []Relationship, manifestData, semanticData map[string]interface{}, index dbtNodeIndex) []InferredRelationship {
+	r := &relationInferrer{
+		models:  models,
+		byName:  make(map[string]*LegibleModel, len(models)),
+		keys:    make(map[string]map[string]string),
+		related: make(map[string]bool),
+	}
+	for i := range models {
+		r.byName[models[i].Name] = &models[i]
+		if models[i].PrimaryKey != "" {
+			r.addKey(models[i].Name, models[i].PrimaryKey, "primary key")
+		}
+	}
+	if manifestData != nil {
+		r.addUniqueTests(manifestData, index)
+	}
+	var semantic *semanticGraph
+	if semanticData != nil {
+		semantic = newSemanticGraph(semanticData, models)
+		for _, sm := range semantic.models {
+			for _, entity := range sm.entities {
+				if entity.entityType != "foreign" && sm.modelName != "" {
+					r.addKey(sm.modelName, entity.expr, entity.entityType+" entity")
+				}
+			}
+		}
+	}
+	for _, rel := range existing {
+		if parts := conditionRegex.FindStringSubmatch(rel.Condition); parts != nil {
+			r.related[relatedKey(parts[1], parts[2], parts[3])] = true
+			r.related[relatedKey(parts[3], parts[4], parts[1])] = true
+		}
+	}
+
+	best := make(map[string]InferredRelationship)
+	consider := func(candidate InferredRelationship, fromModel, fromColumn string) {
+		key := strings.ToLower(fromModel + "." + fromColumn)
+		current, ok := best[key]
+		if !ok || candidate.Confidence > current.Confidence ||
+			(candidate.Confidence == current.Confidence && len(candidate.Models[1]) < len(current.Models[1])) {
+			best[key] = candidate
+		}
+	}
+
+	// Foreign entities whose name is not a key of another semantic model,
+	// e.g. a "customer" entity on orders.customer_id, are matched by name.
+	if semantic != nil {
+		for _, sm := range semantic.models {
+			if sm.modelName == "" {
+				continue
+			}
+			for _, entity := range sm.entities {
+				if entity.entityType != "foreign" || !isPlainIdentifier(entity.expr) {
+					continue
+				}
+				for _, to := range r.modelsNamed(entity.name, sm.modelName) {
+					if candidate, ok := r.candidate(sm.modelName, entity.expr, to, 0.9,
+						fmt.Sprintf("foreign entity '%s' of semantic model '%s'", entity.name, sm.name)); ok {
+						consider(candidate, sm.modelName, entity.expr)
+					}
+				}
+			}
+		}
+	}
+
+	// Columns such as customer_id or customer_key that name another model.
+	for _, from := range models {
+		for _, col := range from.Columns {
+			stem := columnStem(col.Name)
+			if stem == "" {
+				continue
+			}
+			for _, to := range r.modelsNamed(stem, from.Name) {
+				if candidate, ok := r.candidate(from.Name, col.Name, to, 0.5,
+					fmt.Sprintf("column name '%s' matches model '%s'", col.Name, to.Name)); ok {
+					consider(candidate, from.Name, col.Name)
+				}
+			}
+		}
+	}
+
+	inferred := make([]InferredRelationship, 0, len(best))
+	for _, candidate := range best {
+		inferred = append(inferred, candidate)
+	}
+	sort.Slice(inferred, func(i, j int) bool {
+		if inferred[i].Confidence != inferred[j].Confidence {
+			return inferred[i].Confidence > inferred[j].Confidence
+		}
+		return inferred[i].Name < inferred[j].Name
+	})
+	return inferred
+}
+
+// candidate builds a relationship from a model's column to another model,
+// joining on the column of the same name or the target's id or primary key.
+// The confidence is raised when the target column is known to be unique and
+// lowered when the column types do not match.
+func (r *relationInferrer) candidate(fromModel, fromColumn string, to *LegibleModel, confidence float64, reason string) (InferredRelationship, bool) {
+	from := r.byName[fromModel]
+	fromCol := findColumn(from, fromColumn)
+	if fromCol == nil || r.related[relatedKey(fromModel, fromCol.Name, to.Name)] {
+		return InferredRelationship{}, false
+	}
+
+	var toCol *LegibleColumn
+	for _, name := range []string{fromCol.Name, "id", to.PrimaryKey} {
+		if toCol = findColumn(to, name); toCol != nil {
+			break
+		}
+	}
+	if toCol == nil {
+		return InferredRelationship{}, false
+	}
+
+	reasons := []string{reason}
+	if why, ok := r.keys[to.Name][strings.ToLower(toCol.Name)]; ok {
+		confidence += 0.3
+		reasons = append(reasons, fmt.Sprintf("%s.%s is unique (%s)", to.Name, toCol.Name, why))
+	}
+	if !typesCompatible(fromCol.Type, toCol.Type) {
+		confidence -= 0.3
+		reasons = append(reasons, fmt.Sprintf("types differ (%s, %s)", fromCol.Type, toCol.Type))
+	}
+	joinType := "MANY_TO_ONE"
+	if why, ok := r.keys[fromModel][strings.ToLower(fromCol.Name)]; ok {
+		joinType = "ONE_TO_ONE"
+		reasons = append(reasons, fmt.Sprintf("%s.%s is unique (%s)", fromModel, fromCol.Name, why))
+	}
+	if confidence > 1 {
+		confidence = 1
+	}
+	if confidence <= 0 {
+		return InferredRelationship{}, false
+	}
+
+	return InferredRelationship{
+		Relationship: Relationship{
+			Name:      fmt.Sprintf("%s_to_%s_by_%s", fromModel, to.Name, fromCol.Name),
+			Models:    []string{fromModel, to.Name},
+			JoinType:  joinType,
+			Condition: fmt.Sprintf("\"%s\".\"%s\" = \"%s\".\"%s\"", fromModel, fromCol.Name, to.Name, toCol.Name),
+		},
+		Confidence: float64(int(confidence*100+0.5)) / 100,
+		Reason:     strings.Join(reasons, "; "),
+	}, true
+}
+
+// addUniqueTests records the columns that dbt unique tests cover, from both
+// compiled test nodes and the tests listed on manifest columns.
+func (r *relationInferrer) addUniqueTests(manifestData map[string]interface{}, index dbtNodeIndex) {
+	nodes, _ := manifestData["nodes"].(map[string]interface{})
+	for nodeKey, nodeValue := range nodes {
+		nodeMap, ok := nodeValue.(map[string]interface{})
+		if !ok || !strings.HasPrefix(nodeKey, "test.") {
+			continue
+		}
+		testMeta, ok := nodeMap["test_metadata"].(map[string]interface{})
+		if !ok || getStringFromMap(testMeta, "name", "") != "unique" {
+			continue
+		}
+		column := getStringFromMap(nodeMap, "column_name", "")
+		if column == "" {
+			column = getStringFromMap(getMapFromMap(testMeta, "kwargs", nil), "column_name", "")
+		}
+		if modelName := index.modelName(testedNodeID(nodeMap, testMeta, index)); modelName != "" && column != "" {
+			r.addKey(modelName, column, "unique test")
+		}
+	}
+
+	for name, nodeID := range index.nodeIDs(r.models) {
+		manifestNode := findManifestNode(manifestData, nodeID)
+		columns, _ := manifestNode["columns"].(map[string]interface{})
+		for columnName, colData := range columns {
+			colMap, _ := colData.(map[string]interface{})
+			tests, _ := colMap["tests"].([]interface{})
+			for _, test := range tests {
+				if testStr, ok := test.(string); ok && testStr == "unique" {
+					r.addKey(name, columnName, "unique test")
+				}
+			}
+		}
+	}
+}
+
+func (r *relationInferrer) addKey(model, column, why string) {
+	if r.keys[model] == nil {
+		r.keys[model] = make(map[string]string)
+	}
+	column = strings.ToLower(column)
+	if _, ok := r.keys[model][column]; !ok {
+		r.keys[model][column] = why
+	}
+}
+
+// modelsNamed returns the models, other than exclude, that a singular or
+// plural name refers to, e.g. customer for customers or dim_customer.
+func (r *relationInferrer) modelsNamed(name, exclude string) []*LegibleModel {
+	want := singular(strings.ToLower(name))
+	var matches []*LegibleModel
+	for i := range r.models {
+		model := &r.models[i]
+		if model.Name == exclude {
+			continue
+		}
+		normalized := strings.ToLower(model.Name)
+		for _, prefix := range modelNamePrefixes {
+			if trimmed, ok := strings.CutPrefix(normalized, prefix); ok {
+				normalized = trimmed
+				break
+			}
+		}
+		if singular(normalized) == want {
+			matches = append(matches, model)
+		}
+	}
+	return matches
+}
+
+// columnStem returns the name a key column refers to, e.g. "customer" for
+// customer_id or CustomerKey, or "" if the column does not look like a key.
+func columnStem(column string) string {
+	lower := strings.ToLower(column)
+	for _, suffix := range []string{"_id", "_key", "id", "key"} {
+		if stem, ok := strings.CutSuffix(lower, suffix); ok {
+			return strings.TrimSuffix(stem, "_")
+		}
+	}
+	return ""
+}
+
+// singular returns the singular of an English plural, which is good enough
+// for table names such as customers, addresses and categories.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// typesCompatible reports whether two columns can plausibly be joined.
+// Unknown types are assumed to be compatible.
+func typesCompatible(a, b string) bool {
+	familyA, familyB := typeFamily(a), typeFamily(b)
+	return familyA == "" || familyB == "" || familyA == familyB
+}
+
+func typeFamily(columnType string) string {
+	t := strings.ToLower(columnType)
+	switch {
+	case strings.Contains(t, "int"), strings.Contains(t, "number"), strings.Contains(t, "numeric"), strings.Contains(t, "decimal"):
+		return "number"
+	case strings.Contains(t, "char"), strings.Contains(t, "text"), strings.Contains(t, "string"), strings.Contains(t, "uuid"):
+		return "string"
+	case strings.Contains(t, "date"), strings.Contains(t, "time"):
+		return "time"
+	}
+	return ""
+}
+
+// findColumn returns a model's column by name, matched case-insensitively.
+func findColumn(model *LegibleModel, name string) *LegibleColumn {
+	if name == "" {
+		return nil
+	}
+	for i := range model.Columns {
+		if strings.EqualFold(model.Columns[i].Name, name) {
+			return &model.Columns[i]
+		}
+	}
+	return nil
+}
+
+func relatedKey(fromModel, fromColumn, toModel string) string {
+	return strings.ToLower(fromModel + "." + fromColumn + ">" + toModel)
+}
@@ -0,0 +1,139 @@
+package dbt
+
+import (
+	"strings"
+	"testing"
+)
+
+func uniqueTest(attachedNode, column string) map[string]interface{} {
+	return map[string]interface{}{
+		"attached_node": attachedNode,
+		"column_name":   column,
+		"test_metadata": map[string]interface{}{"name": "unique", "kwargs": map[string]interface{}{"column_name": column}},
+	}
+}
+
+func TestInferRelationships(t *testing.T) {
+	dir := t.TempDir()
+	products := catalogNode("products", "product_id", "name")
+	products["columns"].(map[string]interface{})["product_id"].(map[string]interface{})["type"] = "integer"
+	catalog := map[string]interface{}{
+		"nodes": map[string]interface{}{
+			"model.shop.orders":        catalogNode("orders", "order_id", "customer_id", "store_key", "product_id"),
+			"model.shop.order_details": catalogNode("order_details", "order_id", "gift_note"),
+			"model.shop.payments":      catalogNode("payments", "payment_id", "customer_id"),
+			"model.shop.customers":     catalogNode("customers", "id", "name"),
+			"model.shop.dim_stores":    catalogNode("dim_stores", "store_key", "city"),
+			"model.shop.products":      products,
+		},
+	}
+	manifest := map[string]interface{}{
+		"nodes": map[string]interface{}{
+			"model.shop.customers": map[string]interface{}{
+				"columns": map[string]interface{}{
+					"id": map[string]interface{}{"tests": []interface{}{"unique", "not_null"}},
+				},
+			},
+			"test.shop.unique_order_details_order_id": uniqueTest("model.shop.order_details", "order_id"),
+			"test.shop.rel_orders_customer":           relationshipsTest("model.shop.orders", "{{ get_where_subquery(ref('orders')) }}", "customer_id", "ref('customers')", "id"),
+		},
+	}
+	semanticManifest := map[string]interface{}{
+		"semantic_models": []interface{}{
+			map[string]interface{}{
+				"name":          "orders",
+				"node_relation": map[string]interface{}{"alias": "orders", "schema_name": "analytics"},
+				"entities": []interface{}{
+					map[string]interface{}{"name": "order_id", "type": "primary"},
+					map[string]interface{}{"name": "store", "type": "foreign", "expr": "store_key"},
+				},
+			},
+			map[string]interface{}{
+				"name":          "stores",
+				"node_relation": map[string]interface{}{"alias": "dim_stores", "schema_name": "analytics"},
+				"entities": []interface{}{
+					map[string]interface{}{"name": "store_id", "type": "primary", "expr": "store_key"},
+				},
+			},
+		},
+	}
+	conversion, err := convertDbtCatalog(
+		writeJSONFile(t, dir, "catalog.json", catalog), &DefaultDataSource{},
+		writeJSONFile(t, dir, "manifest.json", manifest), writeJSONFile(t, dir, "semantic_manifest.json", semanticManifest),
+		CatalogConvertOptions{InferRelations: true},
+	)
+	if err != nil {
+		t.Fatalf("convertDbtCatalog() error = %v", err)
+	}
+
+	type want struct {
+		joinType   string
+		condition  string
+		confidence float64
+		reason     string
+	}
+	wants := map[string]want{
+		// A foreign entity named after the model, with the target's primary entity.
+		"orders_to_dim_stores_by_store_key": {"MANY_TO_ONE", `"orders"."store_key" = "dim_stores"."store_key"`, 1, "foreign entity 'store'"},
+		// Unique on both sides.
+		"order_details_to_orders_by_order_id": {"ONE_TO_ONE", `"order_details"."order_id" = "orders"."order_id"`, 0.8, "order_details.order_id is unique (unique test)"},
+		// Falls back to the target's id column.
+		"payments_to_customers_by_customer_id": {"MANY_TO_ONE", `"payments"."customer_id" = "customers"."id"`, 0.8, "customers.id is unique (unique test)"},
+		// The types do not match and the target is not known to be unique.
+		"orders_to_products_by_product_id": {"MANY_TO_ONE", `"orders"."product_id" = "products"."product_id"`, 0.2, "types differ"},
+	}
+
+	if len(conversion.inferred) != len(wants) {
+		t.Fatalf("inferred %d relationships, want %d: %+v", len(conversion.inferred), len(wants), conversion.inferred)
+	}
+	for i, got := range conversion.inferred {
+		w, ok := wants[got.Name]
+		if !ok {
+			t.Errorf("unexpected inferred relationship %+v", got)
+			continue
+		}
+		if got.JoinType != w.joinType || got.Condition != w.condition || got.Confidence != w.confidence {
+			t.Errorf("%s = %s %s (%v), want %s %s (%v)", got.Name, got.JoinType, got.Condition, got.Confidence, w.joinType, w.condition, w.confidence)
+		}
+		if !strings.Contains(got.Reason, w.reason) {
+			t.Errorf("%s reason = %q, want it to mention %q", got.Name, got.Reason, w.reason)
+		}
+		if i > 0 && got.Confidence > conversion.inferred[i-1].Confidence {
+			t.Errorf("inferred relationships are not sorted by confidence: %+v", conversion.inferred)
+		}
+	}
+
+	// Inferred relationships are only suggestions.
+	for _, rel := range conversion.manifest.Relationships {
+		if _, ok := wants[rel.Name]; ok {
+			t.Errorf("inferred relationship %s was added to the manifest", rel.Name)
+		}
+	}
+}
+
+func TestColumnStemAndSingular(t *testing.T) {
+	stems := map[string]string{
+		"customer_id": "customer",
+		"CustomerKey": "customer",
+		"store_key":   "store",
+		"id":          "",
+		"name":        "",
+	}
+	for column, want := range stems {
+		if got := columnStem(column); got != want {
+			t.Errorf("columnStem(%q) = %q, want %q", column, got, want)
+		}
+	}
+	singulars := map[string]string{
+		"customers":  "customer",
+		"categories": "category",
+		"addresses":  "address",
+		"boxes":      "box",
+		"class":      "class",
+	}
+	for plural, want := range singulars {
+		if got := singular(plural); got != want {
+			t.Errorf("singular(%q) = %q, want %q", plural, got, want)
+		}
+	}
+}
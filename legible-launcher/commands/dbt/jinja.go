@@ -0,0 +1,456 @@
+package dbt
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// secretEnvPrefix marks environment variables dbt treats as secrets
+const secretEnvPrefix = "DBT_ENV_SECRET_"
+
+// secretProfileKeys are profile fields whose values are credentials
+var secretProfileKeys = map[string]bool{
+	"password": true, "pass": true, "token": true, "access_token": true,
+	"private_key": true, "private_key_passphrase": true, "keyfile_json": true,
+	"client_secret": true, "azure_client_secret": true,
+	"secret_access_key": true, "aws_secret_access_key": true, "aws_session_token": true,
+}
+
+// profileRenderer evaluates the Jinja subset dbt supports in profiles.yml:
+// {{ ... }} expressions calling env_var, var, as_number, as_bool, as_text
+// and as_native, with simple string filters. Statements ({% ... %}) are
+// not supported.
+type profileRenderer struct {
+	vars      map[string]interface{}
+	lookupEnv func(string) (string, bool)
+	// usedSecretEnv is set when an expression reads a DBT_ENV_SECRET_ variable
+	usedSecretEnv bool
+}
+
+func newProfileRenderer(vars map[string]interface{}) *profileRenderer {
+	return &profileRenderer{vars: vars, lookupEnv: os.LookupEnv}
+}
+
+// render renders a template string. A template that is a single
+// expression yielding a number or bool (such as
+// "{{ env_var('PORT') | as_number }}") renders to that value; anything
+// else renders to a string.
+func (r *profileRenderer) render(tmpl string) (interface{}, error) {
+	if !strings.Contains(tmpl, "{{") && !strings.Contains(tmpl, "{%") {
+		return tmpl, nil
+	}
+	if strings.Contains(tmpl, "{%") {
+		return nil, fmt.Errorf("jinja statements are not supported in profiles: %q", tmpl)
+	}
+
+	var out strings.Builder
+	var single interface{}
+	tags := 0
+	rest := tmpl
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed '{{' in %q", tmpl)
+		}
+		out.WriteString(rest[:start])
+		value, err := r.eval(rest[start+2 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(jinjaString(value))
+		single = value
+		tags++
+		rest = rest[start+end+2:]
+	}
+
+	if tags == 1 && strings.HasPrefix(strings.TrimSpace(tmpl), "{{") && strings.HasSuffix(strings.TrimSpace(tmpl), "}}") {
+		switch single.(type) {
+		case int, float64, bool:
+			return single, nil
+		}
+	}
+	return out.String(), nil
+}
+
+// renderString renders a template that must produce a string
+func (r *profileRenderer) renderString(tmpl string) (string, error) {
+	v, err := r.render(tmpl)
+	if err != nil {
+		return "", err
+	}
+	return jinjaString(v), nil
+}
+
+func (r *profileRenderer) eval(expr string) (interface{}, error) {
+	tokens, err := tokenizeJinja(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &jinjaParser{tokens: tokens, r: r}
+	v, err := p.concat()
+	if err != nil {
+		return nil, fmt.Errorf("in '{{%s}}': %w", expr, err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("in '{{%s}}': unexpected '%s'", expr, p.peek().text)
+	}
+	return v, nil
+}
+
+type jinjaTokenKind int
+
+const (
+	tokIdent jinjaTokenKind = iota
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type jinjaToken struct {
+	kind jinjaTokenKind
+	text string
+}
+
+func tokenizeJinja(expr string) ([]jinjaToken, error) {
+	var tokens []jinjaToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			j := i + 1
+			var b strings.Builder
+			for j < len(runes) && runes[j] != c {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in '{{%s}}'", expr)
+			}
+			tokens = append(tokens, jinjaToken{tokString, b.String()})
+			i = j + 1
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, jinjaToken{tokNumber, string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, jinjaToken{tokIdent, string(runes[i:j])})
+			i = j
+		case strings.ContainsRune("()|,~", c):
+			tokens = append(tokens, jinjaToken{tokPunct, string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("unsupported character '%c' in '{{%s}}'", c, expr)
+		}
+	}
+	return tokens, nil
+}
+
+type jinjaParser struct {
+	tokens []jinjaToken
+	pos    int
+	r      *profileRenderer
+}
+
+func (p *jinjaParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *jinjaParser) peek() jinjaToken {
+	if p.done() {
+		return jinjaToken{kind: tokPunct, text: "end of expression"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *jinjaParser) accept(punct string) bool {
+	if !p.done() && p.tokens[p.pos].kind == tokPunct && p.tokens[p.pos].text == punct {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// concat parses filtered values joined with '~'
+func (p *jinjaParser) concat() (interface{}, error) {
+	v, err := p.filtered()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("~") {
+		next, err := p.filtered()
+		if err != nil {
+			return nil, err
+		}
+		v = jinjaString(v) + jinjaString(next)
+	}
+	return v, nil
+}
+
+// filtered parses a primary value followed by '| filter(args)' applications
+func (p *jinjaParser) filtered() (interface{}, error) {
+	v, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		tok := p.peek()
+		if tok.kind != tokIdent {
+			return nil, fmt.Errorf("expected a filter name after '|'")
+		}
+		p.pos++
+		var args []interface{}
+		if p.accept("(") {
+			if args, err = p.args(); err != nil {
+				return nil, err
+			}
+		}
+		if v, err = p.r.call(tok.text, append([]interface{}{v}, args...)); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (p *jinjaParser) primary() (interface{}, error) {
+	tok := p.peek()
+	switch {
+	case p.done():
+		return nil, fmt.Errorf("unexpected end of expression")
+	case tok.kind == tokString:
+		p.pos++
+		return tok.text, nil
+	case tok.kind == tokNumber:
+		p.pos++
+		return parseJinjaNumber(tok.text)
+	case tok.kind == tokIdent:
+		p.pos++
+		switch tok.text {
+		case "true", "True":
+			return true, nil
+		case "false", "False":
+			return false, nil
+		case "none", "None":
+			return nil, nil
+		}
+		if !p.accept("(") {
+			return nil, fmt.Errorf("unknown name '%s'", tok.text)
+		}
+		args, err := p.args()
+		if err != nil {
+			return nil, err
+		}
+		return p.r.call(tok.text, args)
+	case p.accept("("):
+		v, err := p.concat()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("expected ')'")
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected '%s'", tok.text)
+}
+
+// args parses call arguments after the opening parenthesis
+func (p *jinjaParser) args() ([]interface{}, error) {
+	var args []interface{}
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		v, err := p.concat()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+		if p.accept(")") {
+			return args, nil
+		}
+		if !p.accept(",") {
+			return nil, fmt.Errorf("expected ',' or ')'")
+		}
+	}
+}
+
+// call applies a function or filter; filters receive the filtered value
+// as their first argument
+func (r *profileRenderer) call(name string, args []interface{}) (interface{}, error) {
+	arg := func(i int) interface{} {
+		if i < len(args) {
+			return args[i]
+		}
+		return nil
+	}
+	want := func(min, max int) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("wrong number of arguments to '%s'", name)
+		}
+		return nil
+	}
+
+	switch name {
+	case "env_var":
+		if err := want(1, 2); err != nil {
+			return nil, err
+		}
+		key := jinjaString(args[0])
+		if strings.HasPrefix(key, secretEnvPrefix) {
+			r.usedSecretEnv = true
+		}
+		if v, ok := r.lookupEnv(key); ok {
+			return v, nil
+		}
+		if len(args) == 2 {
+			return jinjaString(args[1]), nil
+		}
+		return nil, fmt.Errorf("env var required but not provided: '%s'", key)
+	case "var":
+		if err := want(1, 2); err != nil {
+			return nil, err
+		}
+		key := jinjaString(args[0])
+		if v, ok := r.vars[key]; ok {
+			return v, nil
+		}
+		if len(args) == 2 {
+			return args[1], nil
+		}
+		return nil, fmt.Errorf("required var '%s' not found; pass it with --vars", key)
+	case "as_text", "string":
+		if err := want(1, 1); err != nil {
+			return nil, err
+		}
+		return jinjaString(args[0]), nil
+	case "as_native":
+		if err := want(1, 1); err != nil {
+			return nil, err
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return args[0], nil
+		}
+		if b, err := parseJinjaBool(s); err == nil {
+			return b, nil
+		}
+		if n, err := parseJinjaNumber(strings.TrimSpace(s)); err == nil {
+			return n, nil
+		}
+		return s, nil
+	case "as_number":
+		if err := want(1, 1); err != nil {
+			return nil, err
+		}
+		return parseJinjaNumber(strings.TrimSpace(jinjaString(args[0])))
+	case "int", "float":
+		if err := want(1, 2); err != nil {
+			return nil, err
+		}
+		n, err := parseJinjaNumber(strings.TrimSpace(jinjaString(args[0])))
+		if err != nil {
+			// Like Jinja, fall back to the default (or zero) on bad input
+			n = arg(1)
+			if n == nil {
+				n = 0
+			}
+		}
+		f, _ := strconv.ParseFloat(jinjaString(n), 64)
+		if name == "int" {
+			return int(f), nil
+		}
+		return f, nil
+	case "as_bool":
+		if err := want(1, 1); err != nil {
+			return nil, err
+		}
+		if b, ok := args[0].(bool); ok {
+			return b, nil
+		}
+		return parseJinjaBool(jinjaString(args[0]))
+	case "lower", "upper", "trim":
+		if err := want(1, 1); err != nil {
+			return nil, err
+		}
+		s := jinjaString(args[0])
+		switch name {
+		case "lower":
+			return strings.ToLower(s), nil
+		case "upper":
+			return strings.ToUpper(s), nil
+		default:
+			return strings.TrimSpace(s), nil
+		}
+	case "replace":
+		if err := want(3, 3); err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(jinjaString(args[0]), jinjaString(args[1]), jinjaString(args[2])), nil
+	case "default", "d":
+		if err := want(1, 2); err != nil {
+			return nil, err
+		}
+		if args[0] == nil || args[0] == "" {
+			return arg(1), nil
+		}
+		return args[0], nil
+	}
+	return nil, fmt.Errorf("unsupported function or filter '%s'", name)
+}
+
+func parseJinjaNumber(s string) (interface{}, error) {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("cannot convert '%s' to a number", s)
+}
+
+func parseJinjaBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "1", "yes", "on":
+		return true, nil
+	case "false", "0", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("cannot convert '%s' to a boolean", s)
+}
+
+// jinjaString formats a value the way Jinja prints it
+func jinjaString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		if val {
+			return "True"
+		}
+		return "False"
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,156 @@
+package dbt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProfileRendererRender(t *testing.T) {
+	env := map[string]string{
+		"DBT_HOST":     "db.internal",
+		"DBT_PORT":     "5433",
+		"DBT_SSL":      "True",
+		"DBT_SCHEMA":   "  Analytics ",
+		"DBT_PASSWORD": "s3cret",
+	}
+	r := newProfileRenderer(map[string]interface{}{"schema_suffix": "dev", "threads": 4})
+	r.lookupEnv = func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	tests := []struct {
+		tmpl    string
+		want    interface{}
+		wantErr string
+	}{
+		{tmpl: "plain", want: "plain"},
+		{tmpl: "{{ env_var('DBT_HOST') }}", want: "db.internal"},
+		{tmpl: `{{ env_var("DBT_USER", "analyst") }}`, want: "analyst"},
+		{tmpl: "{{ env_var('DBT_PORT') | as_number }}", want: 5433},
+		{tmpl: "{{ env_var('DBT_PORT') }}", want: "5433"},
+		{tmpl: "{{ env_var('DBT_PORT') | int }}", want: 5433},
+		{tmpl: "{{ env_var('DBT_SSL') | as_bool }}", want: true},
+		{tmpl: "{{ as_native('false') }}", want: false},
+		{tmpl: "{{ env_var('DBT_SCHEMA') | trim | lower }}", want: "analytics"},
+		{tmpl: "analytics_{{ var('schema_suffix') }}", want: "analytics_dev"},
+		{tmpl: "{{ var('threads') }}", want: 4},
+		{tmpl: "{{ var('missing', 'fallback') | upper }}", want: "FALLBACK"},
+		{tmpl: "{{ env_var('DBT_HOST') ~ ':' ~ env_var('DBT_PORT') }}", want: "db.internal:5433"},
+		{tmpl: "{{ env_var('DBT_HOST') | replace('.internal', '.example.com') }}", want: "db.example.com"},
+		{tmpl: "port {{ env_var('DBT_PORT') | as_number }}", want: "port 5433"},
+		{tmpl: "{{ env_var('DBT_MISSING') }}", wantErr: "env var required but not provided: 'DBT_MISSING'"},
+		{tmpl: "{{ var('missing') }}", wantErr: "required var 'missing' not found"},
+		{tmpl: "{{ env_var('DBT_HOST') | unknown_filter }}", wantErr: "unsupported function or filter 'unknown_filter'"},
+		{tmpl: "{{ env_var('DBT_HOST'", wantErr: "unclosed"},
+		{tmpl: "{% if true %}x{% endif %}", wantErr: "statements are not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := r.render(tt.tmpl)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("render(%q) = %#v; want %#v", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeDbtProfilesRendersJinja(t *testing.T) {
+	t.Setenv("DBT_PG_HOST", "pg.internal")
+	t.Setenv("DBT_PG_PORT", "5433")
+	t.Setenv("DBT_PG_PASSWORD", "pg-secret")
+	t.Setenv("DBT_ENV_SECRET_PG_USER", "svc_user")
+
+	profilesPath := filepath.Join(t.TempDir(), "profiles.yml")
+	content := `analytics:
+  target: "{{ env_var('DBT_TARGET', 'dev') }}"
+  outputs:
+    dev:
+      type: postgres
+      host: "{{ env_var('DBT_PG_HOST') }}"
+      port: "{{ env_var('DBT_PG_PORT') | as_number }}"
+      user: "{{ env_var('DBT_ENV_SECRET_PG_USER') }}"
+      password: "{{ env_var('DBT_PG_PASSWORD') }}"
+      dbname: "analytics_{{ var('env', 'dev') }}"
+      schema: public
+    prod:
+      type: postgres
+      host: prod-db
+      password: "{{ env_var('DBT_PROD_PASSWORD') }}"
+`
+	if err := os.WriteFile(profilesPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := AnalyzeDbtProfiles(profilesPath, map[string]interface{}{"env": "ci"})
+	if err != nil {
+		t.Fatalf("AnalyzeDbtProfiles failed: %v", err)
+	}
+	profile := profiles.Profiles["analytics"]
+	if profile.Target != "dev" {
+		t.Errorf("Expected target 'dev', got '%s'", profile.Target)
+	}
+
+	dev := profile.Outputs["dev"]
+	if dev.Host != "pg.internal" || dev.Port != 5433 || dev.Password != "pg-secret" || dev.User != "svc_user" || dev.DbName != "analytics_ci" {
+		t.Errorf("Unexpected rendered connection: %+v", dev)
+	}
+
+	wantRefs := map[string]string{
+		"password": "{{ env_var('DBT_PG_PASSWORD') }}",
+		"user":     "{{ env_var('DBT_ENV_SECRET_PG_USER') }}",
+	}
+	if !reflect.DeepEqual(dev.SecretRefs, wantRefs) {
+		t.Errorf("Expected secret refs %v, got %v", wantRefs, dev.SecretRefs)
+	}
+
+	// The prod output needs an env var that is not set; it only fails when used
+	if _, err := GetActiveDataSources(profiles, "", "analytics", "dev"); err != nil {
+		t.Errorf("Expected the dev target to convert, got %v", err)
+	}
+	if _, err := GetActiveDataSources(profiles, "", "analytics", "prod"); err == nil || !strings.Contains(err.Error(), "DBT_PROD_PASSWORD") {
+		t.Errorf("Expected an error naming DBT_PROD_PASSWORD, got %v", err)
+	}
+}
+
+func TestKeepSecretRefs(t *testing.T) {
+	props := map[string]interface{}{
+		"user":      "analyst",
+		"password":  "s3cret",
+		"warehouse": "s3cret",
+		"kwargs":    map[string]interface{}{"token": "tok"},
+	}
+	keepSecretRefs("snowflake", props, map[string]string{
+		"password": "{{ env_var('DBT_PASSWORD') }}",
+		"token":    "{{ env_var('DBT_ENV_SECRET_TOKEN') }}",
+	})
+
+	// Only the properties converted from the secret fields are replaced,
+	// not others that happen to have the same value
+	if props["password"] != "{{ env_var('DBT_PASSWORD') }}" || props["user"] != "analyst" || props["warehouse"] != "s3cret" {
+		t.Errorf("Unexpected properties: %v", props)
+	}
+	if props["kwargs"].(map[string]interface{})["token"] != "{{ env_var('DBT_ENV_SECRET_TOKEN') }}" {
+		t.Errorf("Expected nested secrets to be replaced, got %v", props["kwargs"])
+	}
+
+	// Properties converted from a field of another name
+	props = map[string]interface{}{"access_key_id": "AKIA", "access_key_secret": "secret"}
+	keepSecretRefs("redshift", props, map[string]string{"secret_access_key": "{{ env_var('AWS_SECRET') }}"})
+	if props["access_key_secret"] != "{{ env_var('AWS_SECRET') }}" || props["access_key_id"] != "AKIA" {
+		t.Errorf("Unexpected redshift properties: %v", props)
+	}
+}
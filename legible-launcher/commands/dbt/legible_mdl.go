@@ -71,6 +71,32 @@ type Metric struct {
 	Aggregation string   `json:"aggregation"`
 	DisplayName string   `json:"displayName"`
 	Description string   `json:"description,omitempty"`
+	// Type is the MetricFlow metric type: simple, ratio, derived, cumulative or conversion.
+	Type string `json:"type,omitempty"`
+	// TimeDimension is the dimension the metric is aggregated over time by.
+	TimeDimension string `json:"timeDimension,omitempty"`
+	// Window and GrainToDate bound a cumulative metric; a cumulative metric
+	// with neither accumulates over all time.
+	Window      *MetricWindow     `json:"window,omitempty"`
+	GrainToDate string            `json:"grainToDate,omitempty"`
+	Conversion  *MetricConversion `json:"conversion,omitempty"`
+}
+
+// MetricWindow is a time window, such as 7 days.
+type MetricWindow struct {
+	Count       int    `json:"count"`
+	Granularity string `json:"granularity"`
+}
+
+// MetricConversion describes a conversion metric: the share of entities
+// counted by the base aggregation that go on to be counted by the conversion
+// aggregation, optionally within a time window.
+type MetricConversion struct {
+	BaseAggregation       string        `json:"baseAggregation"`
+	ConversionAggregation string        `json:"conversionAggregation"`
+	Entity                string        `json:"entity"`
+	Calculation           string        `json:"calculation"`
+	Window                *MetricWindow `json:"window,omitempty"`
 }
 
 // View represents a view in the Legible MDL format
@@ -0,0 +1,248 @@
+package dbt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MetadataMapping configures how the metadata of dbt nodes and columns in
+// manifest.json, such as meta, config, tags and docs, is copied into MDL
+// models and columns.
+//
+// Each value is a dotted path into the manifest node (or column), e.g.
+// "meta.owner", "config.materialized", "tags" or "docs.show".
+type MetadataMapping struct {
+	Models  NodeMetadataMapping `yaml:"models"`
+	Columns NodeMetadataMapping `yaml:"columns"`
+}
+
+// NodeMetadataMapping maps metadata into a model or a column.
+type NodeMetadataMapping struct {
+	// DisplayName lists paths to take the display name from; the first one
+	// that is set wins. A model's display name is its displayName property.
+	DisplayName []string `yaml:"display_name"`
+	// Properties maps property names to paths. A name ending in "*" copies
+	// every key of the map at the path, e.g. "meta_*: meta" copies meta.owner
+	// to the property meta_owner. An empty path disables a property.
+	Properties map[string]string `yaml:"properties"`
+	// Cached and RefreshTime set a model's cached and refreshTime.
+	Cached      string `yaml:"cached"`
+	RefreshTime string `yaml:"refresh_time"`
+}
+
+// DefaultMetadataMapping returns the mapping used when none is configured:
+// display names come from meta.label, every meta key becomes a property,
+// and models can be cached with meta.cached and meta.refresh_time.
+func DefaultMetadataMapping() *MetadataMapping {
+	return &MetadataMapping{
+		Models: NodeMetadataMapping{
+			DisplayName: []string{"meta.label", "meta.display_name"},
+			Properties:  map[string]string{"*": "meta"},
+			Cached:      "meta.cached",
+			RefreshTime: "meta.refresh_time",
+		},
+		Columns: NodeMetadataMapping{
+			DisplayName: []string{"meta.label", "meta.display_name"},
+			Properties:  map[string]string{"*": "meta"},
+		},
+	}
+}
+
+// LoadMetadataMapping reads a metadata mapping from a YAML file. The file is
+// applied over the default mapping: a display_name, cached or refresh_time
+// that is set replaces the default, and properties are added to the default
+// properties, which can be removed by mapping them to an empty path.
+func LoadMetadataMapping(path string) (*MetadataMapping, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata mapping %s: %w", path, err)
+	}
+	mapping := DefaultMetadataMapping()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata mapping %s: %w", path, err)
+	}
+	if mapping.Columns.Cached != "" || mapping.Columns.RefreshTime != "" {
+		return nil, fmt.Errorf("invalid metadata mapping %s: cached and refresh_time only apply to models", path)
+	}
+	return mapping, nil
+}
+
+// applyToModel sets a model's display name, properties and caching from its
+// manifest node, and its columns' from the node's columns. Mapped properties
+// do not replace properties the converter has already set, such as description.
+func (m *MetadataMapping) applyToModel(model *LegibleModel, manifestNode map[string]interface{}) {
+	if manifestNode == nil {
+		return
+	}
+
+	properties := model.Properties
+	if properties == nil {
+		properties = make(map[string]string)
+	}
+	if displayName := m.Models.displayName(manifestNode); displayName != "" {
+		properties["displayName"] = displayName
+	}
+	m.Models.addProperties(manifestNode, properties)
+	if len(properties) > 0 {
+		model.Properties = properties
+	}
+
+	if value, ok := lookupMetadata(manifestNode, m.Models.Cached); ok {
+		switch v := value.(type) {
+		case bool:
+			model.Cached = v
+		case string:
+			model.Cached = strings.EqualFold(v, "true") || strings.EqualFold(v, "yes")
+		}
+	}
+	if value, ok := lookupMetadata(manifestNode, m.Models.RefreshTime); ok {
+		model.RefreshTime = metadataString(value)
+	}
+
+	manifestColumns, _ := manifestNode["columns"].(map[string]interface{})
+	for i := range model.Columns {
+		colMap := findManifestColumn(manifestColumns, model.Columns[i].Name)
+		if colMap == nil {
+			continue
+		}
+		if displayName := m.Columns.displayName(colMap); displayName != "" {
+			model.Columns[i].DisplayName = displayName
+		}
+		columnProperties := model.Columns[i].Properties
+		if columnProperties == nil {
+			columnProperties = make(map[string]string)
+		}
+		m.Columns.addProperties(colMap, columnProperties)
+		if len(columnProperties) > 0 {
+			model.Columns[i].Properties = columnProperties
+		}
+	}
+}
+
+// findManifestColumn returns a column's manifest.json entry. Warehouses
+// such as Snowflake report column names in the catalog in upper case, so
+// the name is matched case-insensitively if there is no exact match.
+func findManifestColumn(columns map[string]interface{}, name string) map[string]interface{} {
+	if colMap, ok := columns[name].(map[string]interface{}); ok {
+		return colMap
+	}
+	for colName, colData := range columns {
+		if strings.EqualFold(colName, name) {
+			colMap, _ := colData.(map[string]interface{})
+			return colMap
+		}
+	}
+	return nil
+}
+
+// displayName returns the value at the first display name path that is set.
+func (m NodeMetadataMapping) displayName(node map[string]interface{}) string {
+	for _, path := range m.DisplayName {
+		if value, ok := lookupMetadata(node, path); ok {
+			if s := metadataString(value); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// addProperties adds the mapped properties that are set on node and not
+// already present in properties.
+func (m NodeMetadataMapping) addProperties(node map[string]interface{}, properties map[string]string) {
+	// Sort the names so that explicitly named properties are added before wildcards.
+	names := make([]string, 0, len(m.Properties))
+	for name := range m.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		wildcardI, wildcardJ := strings.HasSuffix(names[i], "*"), strings.HasSuffix(names[j], "*")
+		if wildcardI != wildcardJ {
+			return !wildcardI
+		}
+		return names[i] < names[j]
+	})
+
+	set := func(name string, value interface{}) {
+		if _, exists := properties[name]; exists {
+			return
+		}
+		if s := metadataString(value); s != "" {
+			properties[name] = s
+		}
+	}
+
+	for _, name := range names {
+		value, ok := lookupMetadata(node, m.Properties[name])
+		if !ok {
+			continue
+		}
+		prefix, isWildcard := strings.CutSuffix(name, "*")
+		if !isWildcard {
+			set(name, value)
+			continue
+		}
+		if values, ok := value.(map[string]interface{}); ok {
+			for key, v := range values {
+				set(prefix+key, v)
+			}
+		}
+	}
+}
+
+// lookupMetadata returns the value at a dotted path in a manifest node, such
+// as "meta.owner". An empty path is never set.
+func lookupMetadata(node map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var current interface{} = node
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok || current == nil {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// metadataString formats a metadata value as a property value: lists of
+// scalars, such as tags or synonyms, are joined with commas and maps are
+// written as JSON.
+func metadataString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+			items = append(items, metadataString(item))
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
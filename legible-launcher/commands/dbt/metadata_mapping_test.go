@@ -0,0 +1,155 @@
+package dbt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testManifestNode() map[string]interface{} {
+	return map[string]interface{}{
+		"tags": []interface{}{"finance", "daily"},
+		"meta": map[string]interface{}{
+			"label":        "Orders",
+			"owner":        "finance-team",
+			"cached":       true,
+			"refresh_time": "30m",
+			"description":  "from meta",
+		},
+		"config": map[string]interface{}{"materialized": "table"},
+		"docs":   map[string]interface{}{"show": false},
+		"columns": map[string]interface{}{
+			"amount": map[string]interface{}{
+				"meta": map[string]interface{}{
+					"display_name": "Order Amount",
+					"unit":         "USD",
+					"contains_pii": false,
+					"synonyms":     []interface{}{"total", "value"},
+					"precision":    float64(2),
+				},
+			},
+		},
+	}
+}
+
+func TestMetadataMappingDefault(t *testing.T) {
+	model := &LegibleModel{
+		Name:       "orders",
+		Properties: map[string]string{"description": "All orders."},
+		Columns:    []LegibleColumn{{Name: "id"}, {Name: "AMOUNT"}},
+	}
+	DefaultMetadataMapping().applyToModel(model, testManifestNode())
+
+	if model.Properties["displayName"] != "Orders" {
+		t.Errorf("displayName = %q, want Orders", model.Properties["displayName"])
+	}
+	if model.Properties["owner"] != "finance-team" {
+		t.Errorf("owner = %q, want finance-team", model.Properties["owner"])
+	}
+	// Mapped properties never replace the ones set by the converter.
+	if model.Properties["description"] != "All orders." {
+		t.Errorf("description = %q, want the dbt description", model.Properties["description"])
+	}
+	if !model.Cached || model.RefreshTime != "30m" {
+		t.Errorf("cached = %v, refreshTime = %q, want true, 30m", model.Cached, model.RefreshTime)
+	}
+
+	if model.Columns[0].Properties != nil || model.Columns[0].DisplayName != "" {
+		t.Errorf("column without metadata changed: %+v", model.Columns[0])
+	}
+	amount := model.Columns[1]
+	if amount.DisplayName != "Order Amount" {
+		t.Errorf("column displayName = %q, want Order Amount", amount.DisplayName)
+	}
+	want := map[string]string{
+		"display_name": "Order Amount",
+		"unit":         "USD",
+		"contains_pii": "false",
+		"synonyms":     "total, value",
+		"precision":    "2",
+	}
+	for k, v := range want {
+		if amount.Properties[k] != v {
+			t.Errorf("column property %s = %q, want %q", k, amount.Properties[k], v)
+		}
+	}
+}
+
+func TestLoadMetadataMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legible-metadata.yml")
+	content := `models:
+  display_name: [meta.owner]
+  properties:
+    "*": ""
+    materialized: config.materialized
+    tags: tags
+    in_docs: docs.show
+    "meta_*": meta
+  cached: ""
+columns:
+  properties:
+    pii: meta.contains_pii
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mapping, err := LoadMetadataMapping(path)
+	if err != nil {
+		t.Fatalf("LoadMetadataMapping() error = %v", err)
+	}
+	// Settings that are not in the file keep their defaults.
+	if mapping.Models.RefreshTime != "meta.refresh_time" || len(mapping.Columns.DisplayName) != 2 {
+		t.Errorf("defaults not kept: %+v", mapping)
+	}
+
+	model := &LegibleModel{Name: "orders", Columns: []LegibleColumn{{Name: "amount"}}}
+	mapping.applyToModel(model, testManifestNode())
+
+	want := map[string]string{
+		"displayName":       "finance-team",
+		"materialized":      "table",
+		"tags":              "finance, daily",
+		"in_docs":           "false",
+		"meta_owner":        "finance-team",
+		"meta_refresh_time": "30m",
+	}
+	for k, v := range want {
+		if model.Properties[k] != v {
+			t.Errorf("property %s = %q, want %q", k, model.Properties[k], v)
+		}
+	}
+	if _, ok := model.Properties["owner"]; ok {
+		t.Errorf("disabled wildcard still copied meta: %v", model.Properties)
+	}
+	if model.Cached {
+		t.Error("cached should be disabled")
+	}
+	if model.RefreshTime != "30m" {
+		t.Errorf("refreshTime = %q, want 30m", model.RefreshTime)
+	}
+	amount := model.Columns[0]
+	if amount.Properties["pii"] != "false" || amount.Properties["unit"] != "USD" {
+		t.Errorf("unexpected column properties: %v", amount.Properties)
+	}
+}
+
+func TestLoadMetadataMappingErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":    "models:\n  labels: [meta.label]\n",
+		"column caching":   "columns:\n  cached: meta.cached\n",
+		"malformed yaml":   "models: [",
+		"wrong value type": "models:\n  properties: [meta]\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mapping.yml")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadMetadataMapping(path); err == nil || !strings.Contains(err.Error(), "metadata mapping") {
+				t.Errorf("LoadMetadataMapping() error = %v, want a metadata mapping error", err)
+			}
+		})
+	}
+}
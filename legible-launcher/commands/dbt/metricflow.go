@@ -0,0 +1,568 @@
+package dbt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+// semanticModel is a MetricFlow semantic model from semantic_manifest.json.
+type semanticModel struct {
+	name string
+	// modelName is the converted MDL model the semantic model is defined on,
+	// or empty if that model is not converted.
+	modelName               string
+	defaultAggTimeDimension string
+	entities                []semanticEntity
+	dimensions              []semanticDimension
+}
+
+// semanticEntity is an entity of a semantic model: a primary, unique,
+// natural or foreign key.
+type semanticEntity struct {
+	name       string
+	entityType string
+	expr       string
+}
+
+// semanticDimension is a categorical or time dimension of a semantic model.
+type semanticDimension struct {
+	name          string
+	dimensionType string
+	expr          string
+}
+
+// semanticMeasure is a measure of a semantic model.
+type semanticMeasure struct {
+	name             string
+	agg              string
+	expr             string
+	aggTimeDimension string
+	percentile       float64
+	discrete         bool
+	model            *semanticModel
+}
+
+// semanticGraph indexes the semantic models, measures and metrics of a
+// semantic manifest, so that metrics can be expanded into aggregations over
+// the measures they are built from.
+type semanticGraph struct {
+	models        []*semanticModel
+	measures      map[string]*semanticMeasure
+	metrics       []map[string]interface{}
+	metricsByName map[string]map[string]interface{}
+	// skipMetric is called for each metric that cannot be converted.
+	skipMetric func(name string, err error)
+}
+
+// metricExpansion is a metric expanded into an aggregation over its models.
+type metricExpansion struct {
+	aggregation   string
+	models        []*semanticModel
+	timeDimension string
+}
+
+// newSemanticGraph reads the semantic models and metrics of a semantic
+// manifest. Semantic models are matched to the converted models by the
+// relation they are defined on.
+func newSemanticGraph(semanticData map[string]interface{}, models []LegibleModel) *semanticGraph {
+	g := &semanticGraph{
+		measures:      make(map[string]*semanticMeasure),
+		metricsByName: make(map[string]map[string]interface{}),
+		skipMetric: func(name string, err error) {
+			pterm.Warning.Printf("Skipping metric '%s': %v\n", name, err)
+		},
+	}
+
+	for _, smMap := range mapsInList(semanticData["semantic_models"]) {
+		name := getStringFromMap(smMap, "name", "")
+		if name == "" {
+			continue
+		}
+		model := &semanticModel{
+			name:                    name,
+			modelName:               findSemanticModelTable(getMapFromMap(smMap, "node_relation", nil), models),
+			defaultAggTimeDimension: getStringFromMap(getMapFromMap(smMap, "defaults", nil), "agg_time_dimension", ""),
+		}
+		for _, entityMap := range mapsInList(smMap["entities"]) {
+			entityName := getStringFromMap(entityMap, "name", "")
+			model.entities = append(model.entities, semanticEntity{
+				name:       entityName,
+				entityType: getStringFromMap(entityMap, "type", ""),
+				expr:       getStringFromMap(entityMap, "expr", entityName),
+			})
+		}
+		for _, dimMap := range mapsInList(smMap["dimensions"]) {
+			dimName := getStringFromMap(dimMap, "name", "")
+			model.dimensions = append(model.dimensions, semanticDimension{
+				name:          dimName,
+				dimensionType: getStringFromMap(dimMap, "type", ""),
+				expr:          getStringFromMap(dimMap, "expr", dimName),
+			})
+		}
+		for _, measureMap := range mapsInList(smMap["measures"]) {
+			measureName := getStringFromMap(measureMap, "name", "")
+			if measureName == "" {
+				continue
+			}
+			measure := &semanticMeasure{
+				name:             measureName,
+				agg:              strings.ToLower(getStringFromMap(measureMap, "agg", "sum")),
+				expr:             getStringFromMap(measureMap, "expr", measureName),
+				aggTimeDimension: getStringFromMap(measureMap, "agg_time_dimension", model.defaultAggTimeDimension),
+				model:            model,
+			}
+			if aggParams, ok := measureMap["agg_params"].(map[string]interface{}); ok {
+				measure.percentile, _ = aggParams["percentile"].(float64)
+				measure.discrete, _ = aggParams["use_discrete_percentile"].(bool)
+			}
+			g.measures[measureName] = measure
+		}
+		g.models = append(g.models, model)
+	}
+
+	for _, metricMap := range mapsInList(semanticData["metrics"]) {
+		if name := getStringFromMap(metricMap, "name", ""); name != "" {
+			g.metrics = append(g.metrics, metricMap)
+			g.metricsByName[name] = metricMap
+		}
+	}
+	return g
+}
+
+// findSemanticModelTable returns the name of the converted model whose table
+// is a semantic model's node_relation.
+func findSemanticModelTable(nodeRelation map[string]interface{}, models []LegibleModel) string {
+	alias := getStringFromMap(nodeRelation, "alias", "")
+	if alias == "" {
+		return ""
+	}
+	schema := getStringFromMap(nodeRelation, "schema_name", "")
+	for _, model := range models {
+		if strings.EqualFold(model.TableReference.Table, alias) &&
+			(schema == "" || model.TableReference.Schema == "" || strings.EqualFold(model.TableReference.Schema, schema)) {
+			return model.Name
+		}
+	}
+	for _, model := range models {
+		if model.Name == alias {
+			return model.Name
+		}
+	}
+	return ""
+}
+
+// mapsInList returns the maps in a JSON list, skipping any other values.
+func mapsInList(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	maps := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// convertMetrics converts the semantic manifest's metrics into Legible MDL
+// metrics. Metrics that cannot be expressed as an aggregation over the
+// converted models are skipped with a warning.
+func (g *semanticGraph) convertMetrics() []Metric {
+	var metrics []Metric
+	for _, metricMap := range g.metrics {
+		metricName := getStringFromMap(metricMap, "name", "")
+		expansion, err := g.expandMetric(metricName, nil, make(map[string]bool))
+		if err != nil {
+			g.skipMetric(metricName, err)
+			continue
+		}
+
+		metric := Metric{
+			Name:          metricName,
+			DisplayName:   getStringFromMap(metricMap, "label", metricName),
+			Description:   getStringFromMap(metricMap, "description", ""),
+			Type:          getStringFromMap(metricMap, "type", ""),
+			Aggregation:   expansion.aggregation,
+			TimeDimension: expansion.timeDimension,
+		}
+		seenDimensions := make(map[string]bool)
+		for _, model := range expansion.models {
+			metric.Models = append(metric.Models, model.modelName)
+			for _, dim := range model.dimensions {
+				if !seenDimensions[dim.name] {
+					seenDimensions[dim.name] = true
+					metric.Dimensions = append(metric.Dimensions, dim.name)
+				}
+			}
+		}
+
+		typeParams := getMapFromMap(metricMap, "type_params", nil)
+		switch metric.Type {
+		case "cumulative":
+			// dbt 1.9 moved window and grain_to_date into cumulative_type_params.
+			cumulativeParams := getMapFromMap(typeParams, "cumulative_type_params", typeParams)
+			metric.Window = parseMetricWindow(cumulativeParams["window"])
+			if metric.Window == nil {
+				metric.Window = parseMetricWindow(typeParams["window"])
+			}
+			metric.GrainToDate = getStringFromMap(cumulativeParams, "grain_to_date", getStringFromMap(typeParams, "grain_to_date", ""))
+		case "conversion":
+			metric.Conversion, err = g.conversion(metricMap)
+			if err != nil {
+				g.skipMetric(metricName, err)
+				continue
+			}
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+// expandMetric expands a metric into an aggregation over its measures.
+// Ratio and derived metrics are expanded from the metrics they refer to, and
+// filters are applied to every measure the metric is built from.
+func (g *semanticGraph) expandMetric(name string, filters []interface{}, visiting map[string]bool) (metricExpansion, error) {
+	metricMap, ok := g.metricsByName[name]
+	if !ok {
+		return metricExpansion{}, fmt.Errorf("metric '%s' is not defined", name)
+	}
+	if visiting[name] {
+		return metricExpansion{}, fmt.Errorf("metric '%s' refers to itself", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	filters = append(filters[:len(filters):len(filters)], metricMap["filter"])
+	typeParams := getMapFromMap(metricMap, "type_params", nil)
+
+	switch metricType := getStringFromMap(metricMap, "type", ""); metricType {
+	case "simple", "cumulative":
+		return g.expandMeasure(getMapFromMap(typeParams, "measure", nil), filters)
+
+	case "ratio":
+		numerator, err := g.expandMetricInput(getMapFromMap(typeParams, "numerator", nil), filters, visiting)
+		if err != nil {
+			return metricExpansion{}, fmt.Errorf("numerator: %w", err)
+		}
+		denominator, err := g.expandMetricInput(getMapFromMap(typeParams, "denominator", nil), filters, visiting)
+		if err != nil {
+			return metricExpansion{}, fmt.Errorf("denominator: %w", err)
+		}
+		return combineExpansions(fmt.Sprintf("(%s) / (%s)", numerator.aggregation, denominator.aggregation), numerator, denominator), nil
+
+	case "derived":
+		expr := getStringFromMap(typeParams, "expr", "")
+		if expr == "" {
+			return metricExpansion{}, fmt.Errorf("derived metric has no expr")
+		}
+		inputs := make(map[string]string)
+		var expansions []metricExpansion
+		for _, input := range mapsInList(typeParams["metrics"]) {
+			inputName := getStringFromMap(input, "name", "")
+			if input["offset_window"] != nil || input["offset_to_grain"] != nil {
+				return metricExpansion{}, fmt.Errorf("input metric '%s' uses an offset, which has no MDL equivalent", inputName)
+			}
+			expansion, err := g.expandMetricInput(input, filters, visiting)
+			if err != nil {
+				return metricExpansion{}, err
+			}
+			inputs[getStringFromMap(input, "alias", inputName)] = expansion.aggregation
+			expansions = append(expansions, expansion)
+		}
+		// Replace the input names in a single pass, so that an input's
+		// aggregation is never rewritten by another input's name.
+		aggregation := identifierRegex.ReplaceAllStringFunc(expr, func(identifier string) string {
+			if aggregation, ok := inputs[identifier]; ok {
+				return "(" + aggregation + ")"
+			}
+			return identifier
+		})
+		return combineExpansions(aggregation, expansions...), nil
+
+	case "conversion":
+		conversionParams := getMapFromMap(typeParams, "conversion_type_params", nil)
+		base, err := g.expandMeasure(getMapFromMap(conversionParams, "base_measure", nil), filters)
+		if err != nil {
+			return metricExpansion{}, fmt.Errorf("base measure: %w", err)
+		}
+		conversion, err := g.expandMeasure(getMapFromMap(conversionParams, "conversion_measure", nil), filters)
+		if err != nil {
+			return metricExpansion{}, fmt.Errorf("conversion measure: %w", err)
+		}
+		aggregation := conversion.aggregation
+		if getStringFromMap(conversionParams, "calculation", "conversion_rate") == "conversion_rate" {
+			aggregation = fmt.Sprintf("(%s) / (%s)", conversion.aggregation, base.aggregation)
+		}
+		return combineExpansions(aggregation, base, conversion), nil
+
+	default:
+		return metricExpansion{}, fmt.Errorf("unsupported metric type '%s'", metricType)
+	}
+}
+
+// expandMetricInput expands a metric that a ratio or derived metric refers
+// to. Older semantic manifests refer to measures in ratio metrics, so a name
+// that is not a metric is looked up as a measure.
+func (g *semanticGraph) expandMetricInput(input map[string]interface{}, filters []interface{}, visiting map[string]bool) (metricExpansion, error) {
+	name := getStringFromMap(input, "name", "")
+	filters = append(filters[:len(filters):len(filters)], input["filter"])
+	if _, ok := g.metricsByName[name]; !ok {
+		if _, ok := g.measures[name]; ok {
+			return g.expandMeasure(input, filters)
+		}
+	}
+	return g.expandMetric(name, filters, visiting)
+}
+
+// expandMeasure aggregates a measure input, such as a simple metric's
+// measure, applying the input's filter along with filters.
+func (g *semanticGraph) expandMeasure(input map[string]interface{}, filters []interface{}) (metricExpansion, error) {
+	name := getStringFromMap(input, "name", "")
+	measure, ok := g.measures[name]
+	if !ok {
+		return metricExpansion{}, fmt.Errorf("measure '%s' is not defined", name)
+	}
+	if measure.model.modelName == "" {
+		return metricExpansion{}, fmt.Errorf("the model of semantic model '%s' is not converted", measure.model.name)
+	}
+
+	var conditions []string
+	for _, filter := range append(filters[:len(filters):len(filters)], input["filter"]) {
+		condition, err := g.renderFilter(filter, measure)
+		if err != nil {
+			return metricExpansion{}, err
+		}
+		if condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	condition := strings.Join(conditions, " AND ")
+
+	return metricExpansion{
+		aggregation:   measure.aggregation(condition),
+		models:        []*semanticModel{measure.model},
+		timeDimension: measure.aggTimeDimension,
+	}, nil
+}
+
+// combineExpansions combines the models of the expansions an aggregation is
+// built from. The time dimension is the first expansion's.
+func combineExpansions(aggregation string, expansions ...metricExpansion) metricExpansion {
+	combined := metricExpansion{aggregation: aggregation}
+	seen := make(map[*semanticModel]bool)
+	for _, expansion := range expansions {
+		if combined.timeDimension == "" {
+			combined.timeDimension = expansion.timeDimension
+		}
+		for _, model := range expansion.models {
+			if !seen[model] {
+				seen[model] = true
+				combined.models = append(combined.models, model)
+			}
+		}
+	}
+	return combined
+}
+
+// aggregation returns the SQL aggregation of the measure over the rows that
+// match condition, or over every row when condition is empty.
+func (m *semanticMeasure) aggregation(condition string) string {
+	if m.agg == "sum_boolean" {
+		// sum_boolean counts the rows where the expression is true.
+		if condition != "" {
+			return fmt.Sprintf("SUM(CASE WHEN (%s) AND (%s) THEN 1 ELSE 0 END)", condition, m.expr)
+		}
+		return fmt.Sprintf("SUM(CASE WHEN %s THEN 1 ELSE 0 END)", m.expr)
+	}
+
+	expr := m.expr
+	if condition != "" {
+		// Rows that do not match are NULL, which aggregate functions ignore.
+		expr = fmt.Sprintf("CASE WHEN %s THEN %s END", condition, m.expr)
+	}
+	switch m.agg {
+	case "count_distinct":
+		return fmt.Sprintf("COUNT(DISTINCT %s)", expr)
+	case "average":
+		return fmt.Sprintf("AVG(%s)", expr)
+	case "median":
+		return fmt.Sprintf("PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %s)", expr)
+	case "percentile":
+		function := "PERCENTILE_CONT"
+		if m.discrete {
+			function = "PERCENTILE_DISC"
+		}
+		return fmt.Sprintf("%s(%s) WITHIN GROUP (ORDER BY %s)", function, strconv.FormatFloat(m.percentile, 'f', -1, 64), expr)
+	default:
+		return fmt.Sprintf("%s(%s)", strings.ToUpper(m.agg), expr)
+	}
+}
+
+// whereFilterRegex matches the Jinja calls in a MetricFlow where filter,
+// e.g. "{{ Dimension('order_id__is_food_order') }}", "{{ TimeDimension('metric_time', 'day') }}"
+// or "{{ Dimension('customer__region').grain('day') }}".
+var whereFilterRegex = regexp.MustCompile(`\{\{\s*(\w+)\s*\(\s*['"]([^'"]+)['"][^)]*\)(?:\s*\.\s*\w+\s*\([^)]*\))*\s*\}\}`)
+
+// identifierRegex matches SQL identifiers, such as the metric names in a
+// derived metric's expression.
+var identifierRegex = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// renderFilter renders a metric filter as a SQL condition on a measure's
+// model. Filters can only refer to the dimensions and entities of that model,
+// as a metric's aggregation cannot join other models.
+func (g *semanticGraph) renderFilter(filter interface{}, measure *semanticMeasure) (string, error) {
+	filterMap, ok := filter.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+	var conditions []string
+	for _, whereFilter := range mapsInList(filterMap["where_filters"]) {
+		template := getStringFromMap(whereFilter, "where_sql_template", "")
+		if template == "" {
+			continue
+		}
+		var renderErr error
+		condition := whereFilterRegex.ReplaceAllStringFunc(template, func(call string) string {
+			parts := whereFilterRegex.FindStringSubmatch(call)
+			expr, err := g.resolveFilterReference(parts[1], parts[2], measure)
+			if err != nil && renderErr == nil {
+				renderErr = err
+			}
+			return expr
+		})
+		if renderErr != nil {
+			return "", renderErr
+		}
+		conditions = append(conditions, "("+strings.TrimSpace(condition)+")")
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// resolveFilterReference returns the column expression for a Dimension,
+// TimeDimension or Entity in a where filter. Names may be prefixed with an
+// entity path, e.g. "order_id__is_food_order".
+func (g *semanticGraph) resolveFilterReference(function, name string, measure *semanticMeasure) (string, error) {
+	model := measure.model
+	path := strings.Split(name, "__")
+	name = path[len(path)-1]
+
+	switch function {
+	case "Dimension", "TimeDimension":
+		if name == "metric_time" {
+			name = measure.aggTimeDimension
+		}
+		if len(path) > 1 && !model.hasKey(path[len(path)-2]) {
+			return "", fmt.Errorf("filter on '%s' needs a join to another semantic model", strings.Join(path, "__"))
+		}
+		if dim, ok := model.dimension(name); ok {
+			return dim.expr, nil
+		}
+		return "", fmt.Errorf("filter dimension '%s' is not in semantic model '%s'", strings.Join(path, "__"), model.name)
+	case "Entity":
+		for _, entity := range model.entities {
+			if entity.name == name {
+				return entity.expr, nil
+			}
+		}
+		return "", fmt.Errorf("filter entity '%s' is not in semantic model '%s'", name, model.name)
+	default:
+		return "", fmt.Errorf("filters on %s() are not supported", function)
+	}
+}
+
+// dimension returns the semantic model's dimension with the given name.
+func (m *semanticModel) dimension(name string) (semanticDimension, bool) {
+	for _, dim := range m.dimensions {
+		if dim.name == name {
+			return dim, true
+		}
+	}
+	return semanticDimension{}, false
+}
+
+// hasKey reports whether the semantic model is keyed by the entity, i.e. the
+// entity is its primary, unique or natural key.
+func (m *semanticModel) hasKey(entityName string) bool {
+	for _, entity := range m.entities {
+		if entity.name == entityName && entity.entityType != "foreign" {
+			return true
+		}
+	}
+	return false
+}
+
+// conversion returns the conversion settings of a conversion metric.
+func (g *semanticGraph) conversion(metricMap map[string]interface{}) (*MetricConversion, error) {
+	conversionParams := getMapFromMap(getMapFromMap(metricMap, "type_params", nil), "conversion_type_params", nil)
+	base, err := g.expandMeasure(getMapFromMap(conversionParams, "base_measure", nil), []interface{}{metricMap["filter"]})
+	if err != nil {
+		return nil, err
+	}
+	conversion, err := g.expandMeasure(getMapFromMap(conversionParams, "conversion_measure", nil), []interface{}{metricMap["filter"]})
+	if err != nil {
+		return nil, err
+	}
+	return &MetricConversion{
+		BaseAggregation:       base.aggregation,
+		ConversionAggregation: conversion.aggregation,
+		Entity:                getStringFromMap(conversionParams, "entity", ""),
+		Calculation:           getStringFromMap(conversionParams, "calculation", "conversion_rate"),
+		Window:                parseMetricWindow(conversionParams["window"]),
+	}, nil
+}
+
+// parseMetricWindow parses a window such as {"count": 7, "granularity": "day"}.
+func parseMetricWindow(value interface{}) *MetricWindow {
+	windowMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	count, _ := windowMap["count"].(float64)
+	granularity := getStringFromMap(windowMap, "granularity", "")
+	if count <= 0 || granularity == "" {
+		return nil
+	}
+	return &MetricWindow{Count: int(count), Granularity: granularity}
+}
+
+// relationships relates the converted models through their semantic models'
+// entities: a foreign entity refers to the model keyed by that entity.
+func (g *semanticGraph) relationships() []Relationship {
+	var relationships []Relationship
+	for _, from := range g.models {
+		if from.modelName == "" {
+			continue
+		}
+		for _, foreign := range from.entities {
+			if foreign.entityType != "foreign" || !isPlainIdentifier(foreign.expr) {
+				continue
+			}
+			for _, to := range g.models {
+				if to == from || to.modelName == "" {
+					continue
+				}
+				for _, key := range to.entities {
+					if key.name != foreign.name || key.entityType == "foreign" || !isPlainIdentifier(key.expr) {
+						continue
+					}
+					relationships = append(relationships, Relationship{
+						Name:      fmt.Sprintf("%s_to_%s_by_%s", from.modelName, to.modelName, foreign.expr),
+						Models:    []string{from.modelName, to.modelName},
+						JoinType:  "MANY_TO_ONE",
+						Condition: fmt.Sprintf("\"%s\".\"%s\" = \"%s\".\"%s\"", from.modelName, foreign.expr, to.modelName, key.expr),
+					})
+				}
+			}
+		}
+	}
+	return relationships
+}
+
+// isPlainIdentifier reports whether an entity expression is a column name
+// rather than a SQL expression, which cannot be quoted in a join condition.
+func isPlainIdentifier(expr string) bool {
+	return expr != "" && identifierRegex.FindString(expr) == expr
+}
@@ -0,0 +1,164 @@
+package dbt
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// convertJaffleShop converts the jaffle-shop semantic manifest in testdata
+// over a catalog of its marts models.
+func convertJaffleShop(t *testing.T) *LegibleMDLManifest {
+	t.Helper()
+	dir := t.TempDir()
+	catalog := map[string]interface{}{
+		"nodes": map[string]interface{}{
+			"model.jaffle_shop.orders":      catalogNode("orders", "order_id", "customer_id", "location_id", "ordered_at", "order_total", "is_food_order"),
+			"model.jaffle_shop.order_items": catalogNode("order_items", "order_item_id", "order_id", "product_id", "product_price"),
+			"model.jaffle_shop.customers":   catalogNode("customers", "customer_id", "customer_name", "customer_type"),
+			"model.jaffle_shop.locations":   catalogNode("locations", "location_id", "location_name", "tax_rate"),
+		},
+	}
+	manifest := map[string]interface{}{
+		"nodes": map[string]interface{}{
+			"test.jaffle_shop.rel_orders_customer": relationshipsTest("model.jaffle_shop.orders", "{{ get_where_subquery(ref('orders')) }}", "customer_id", "ref('customers')", "customer_id"),
+		},
+	}
+	catalogPath := writeJSONFile(t, dir, "catalog.json", catalog)
+	manifestPath := writeJSONFile(t, dir, "manifest.json", manifest)
+	semanticManifestPath := filepath.Join("testdata", "jaffle_shop", "semantic_manifest.json")
+
+	mdl, err := ConvertDbtCatalogToLegibleMDL(catalogPath, &DefaultDataSource{}, manifestPath, semanticManifestPath, CatalogConvertOptions{})
+	if err != nil {
+		t.Fatalf("ConvertDbtCatalogToLegibleMDL() error = %v", err)
+	}
+	return mdl
+}
+
+func TestConvertMetricFlowMetrics(t *testing.T) {
+	mdl := convertJaffleShop(t)
+	metrics := make(map[string]Metric)
+	for _, m := range mdl.Metrics {
+		metrics[m.Name] = m
+	}
+
+	tests := []struct {
+		name          string
+		aggregation   string
+		models        []string
+		timeDimension string
+	}{
+		{"revenue", "SUM(product_price)", []string{"order_items"}, "ordered_at"},
+		{"orders", "SUM(1)", []string{"orders"}, "ordered_at"},
+		{"food_orders", "SUM(CASE WHEN (is_food_order = true) THEN 1 END)", []string{"orders"}, "ordered_at"},
+		{"large_orders", "SUM(CASE WHEN (ordered_at >= '2024-01-01') AND (order_total >= 20) THEN 1 END)", []string{"orders"}, "ordered_at"},
+		{"food_order_count", "SUM(CASE WHEN is_food_order THEN 1 ELSE 0 END)", []string{"orders"}, "ordered_at"},
+		{"customers_with_orders", "COUNT(DISTINCT customer_id)", []string{"orders"}, "ordered_at"},
+		{"order_value_p95", "PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY order_total)", []string{"orders"}, "ordered_at"},
+		{"median_revenue", "PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY product_price)", []string{"order_items"}, "ordered_at"},
+		{"average_tax_rate", "AVG(tax_rate)", []string{"locations"}, "opened_at"},
+		{"new_customers", "COUNT(DISTINCT CASE WHEN (customer_type = 'new') THEN customer_id END)", []string{"customers"}, "first_ordered_at"},
+		{"food_revenue_pct", "(SUM(case when is_food_item then product_price else 0 end)) / (SUM(product_price))", []string{"order_items"}, "ordered_at"},
+		{"order_gross_profit", "(SUM(product_price)) - (SUM(order_cost))", []string{"order_items", "orders"}, "ordered_at"},
+		{"food_order_pct", "(SUM(CASE WHEN (is_food_order = true) THEN 1 END)) / (SUM(1))", []string{"orders"}, "ordered_at"},
+		{"cumulative_revenue", "SUM(product_price)", []string{"order_items"}, "ordered_at"},
+		{"customer_order_conversion_7d", "(COUNT(DISTINCT customer_id)) / (COUNT(DISTINCT customer_id))", []string{"customers", "orders"}, "first_ordered_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric, ok := metrics[tt.name]
+			if !ok {
+				t.Fatalf("metric %s was not converted", tt.name)
+			}
+			if metric.Aggregation != tt.aggregation {
+				t.Errorf("aggregation = %q, want %q", metric.Aggregation, tt.aggregation)
+			}
+			if !reflect.DeepEqual(metric.Models, tt.models) {
+				t.Errorf("models = %v, want %v", metric.Models, tt.models)
+			}
+			if metric.TimeDimension != tt.timeDimension {
+				t.Errorf("timeDimension = %q, want %q", metric.TimeDimension, tt.timeDimension)
+			}
+		})
+	}
+
+	// Filters that need a join, and derived metrics with offsets, have no MDL equivalent.
+	for _, name := range []string{"philadelphia_orders", "revenue_growth_mom"} {
+		if _, ok := metrics[name]; ok {
+			t.Errorf("metric %s should be skipped", name)
+		}
+	}
+
+	revenue := metrics["revenue"]
+	if revenue.Type != "simple" || revenue.DisplayName != "Revenue" {
+		t.Errorf("unexpected revenue metric: %+v", revenue)
+	}
+	if want := []string{"ordered_at", "is_food_item", "is_drink_item"}; !reflect.DeepEqual(revenue.Dimensions, want) {
+		t.Errorf("revenue dimensions = %v, want %v", revenue.Dimensions, want)
+	}
+
+	if m := metrics["cumulative_revenue"]; m.Type != "cumulative" || m.Window != nil || m.GrainToDate != "" {
+		t.Errorf("cumulative_revenue should accumulate over all time: %+v", m)
+	}
+	if m := metrics["revenue_7d"]; !reflect.DeepEqual(m.Window, &MetricWindow{Count: 7, Granularity: "day"}) {
+		t.Errorf("revenue_7d window = %+v, want 7 days", m.Window)
+	}
+	if m := metrics["revenue_mtd"]; m.GrainToDate != "month" || m.Window != nil {
+		t.Errorf("revenue_mtd = %+v, want grain to date month", m)
+	}
+
+	wantConversion := &MetricConversion{
+		BaseAggregation:       "COUNT(DISTINCT customer_id)",
+		ConversionAggregation: "COUNT(DISTINCT customer_id)",
+		Entity:                "customer",
+		Calculation:           "conversion_rate",
+		Window:                &MetricWindow{Count: 7, Granularity: "day"},
+	}
+	if got := metrics["customer_order_conversion_7d"].Conversion; !reflect.DeepEqual(got, wantConversion) {
+		t.Errorf("conversion = %+v, want %+v", got, wantConversion)
+	}
+}
+
+func TestConvertMetricFlowRelationships(t *testing.T) {
+	mdl := convertJaffleShop(t)
+
+	// orders.customer_id is both tested in dbt and a foreign entity, but is
+	// only related once; there is no products model for order_items.product.
+	want := []string{
+		"order_items_to_orders_by_order_id",
+		"orders_to_customers_by_customer_id",
+		"orders_to_locations_by_location_id",
+	}
+	if got := relationshipNames(mdl); !reflect.DeepEqual(got, want) {
+		t.Fatalf("relationships = %v, want %v", got, want)
+	}
+	for _, r := range mdl.Relationships {
+		if r.Name == "orders_to_locations_by_location_id" {
+			if r.JoinType != "MANY_TO_ONE" || r.Condition != `"orders"."location_id" = "locations"."location_id"` {
+				t.Errorf("unexpected relationship: %+v", r)
+			}
+		}
+	}
+}
+
+func TestExpandMetricCycle(t *testing.T) {
+	semanticData := map[string]interface{}{
+		"metrics": []interface{}{
+			map[string]interface{}{
+				"name": "a", "type": "derived",
+				"type_params": map[string]interface{}{"expr": "b + 1", "metrics": []interface{}{map[string]interface{}{"name": "b"}}},
+			},
+			map[string]interface{}{
+				"name": "b", "type": "derived",
+				"type_params": map[string]interface{}{"expr": "a * 2", "metrics": []interface{}{map[string]interface{}{"name": "a"}}},
+			},
+		},
+	}
+	g := newSemanticGraph(semanticData, nil)
+	if _, err := g.expandMetric("a", nil, make(map[string]bool)); err == nil {
+		t.Error("expandMetric() should fail on a cycle")
+	}
+	if metrics := g.convertMetrics(); len(metrics) != 0 {
+		t.Errorf("convertMetrics() = %+v, want no metrics", metrics)
+	}
+}
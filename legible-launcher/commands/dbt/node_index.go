@@ -0,0 +1,167 @@
+package dbt
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dbtNodeIndex names the dbt nodes that are converted to models and
+// resolves the ref() and source() calls that point at them.
+type dbtNodeIndex struct {
+	names     map[string]string // node unique_id -> model name, for converted nodes
+	sourceIDs map[string]string // "source_name.table_name" -> source unique_id
+}
+
+// newDbtNodeIndex selects the catalog nodes to convert. Models, seeds and
+// snapshots are named after the node; sources are named after their table,
+// or "<source>_<table>" when another converted node has that name, with a
+// number appended if that is taken too.
+func newDbtNodeIndex(nodes, sources, manifestData map[string]interface{}, opts CatalogConvertOptions) dbtNodeIndex {
+	index := dbtNodeIndex{
+		names:     make(map[string]string),
+		sourceIDs: make(map[string]string),
+	}
+
+	taken := make(map[string]bool)
+	for nodeKey := range nodes {
+		name := getModelNameFromNodeKey(nodeKey)
+		switch {
+		case strings.HasPrefix(nodeKey, "model."):
+			// Skip staging models if the user has opted to exclude them.
+			if !opts.IncludeStagingModels && (strings.HasPrefix(name, "stg_") || strings.HasPrefix(name, "staging_")) {
+				continue
+			}
+		case strings.HasPrefix(nodeKey, "seed."):
+			if !opts.IncludeSeeds {
+				continue
+			}
+		case strings.HasPrefix(nodeKey, "snapshot."):
+			if !opts.IncludeSnapshots {
+				continue
+			}
+		default:
+			continue
+		}
+		index.names[nodeKey] = name
+		taken[name] = true
+	}
+
+	if manifestSources, ok := manifestData["sources"].(map[string]interface{}); ok {
+		for sourceID, sourceValue := range manifestSources {
+			if sourceMap, ok := sourceValue.(map[string]interface{}); ok {
+				sourceName := getStringFromMap(sourceMap, "source_name", "")
+				tableName := getStringFromMap(sourceMap, "name", "")
+				if sourceName != "" && tableName != "" {
+					index.sourceIDs[sourceName+"."+tableName] = sourceID
+				}
+			}
+		}
+	}
+
+	if !opts.IncludeSources {
+		return index
+	}
+
+	// Sources are named in a fixed order, so that names are the same on
+	// every conversion: first the tables whose name is free, then the others.
+	var sourceIDs []string
+	tableCounts := make(map[string]int)
+	for sourceID := range sources {
+		// e.g., "source.jaffle_shop.raw.orders"
+		parts := strings.Split(sourceID, ".")
+		if len(parts) < 4 || parts[0] != "source" {
+			continue
+		}
+		sourceIDs = append(sourceIDs, sourceID)
+		tableCounts[parts[len(parts)-1]]++
+	}
+	sort.Strings(sourceIDs)
+	var renamed []string
+	for _, sourceID := range sourceIDs {
+		parts := strings.Split(sourceID, ".")
+		sourceName, tableName := parts[len(parts)-2], parts[len(parts)-1]
+		// The manifest is optional, so fall back to the catalog's source IDs.
+		if _, ok := index.sourceIDs[sourceName+"."+tableName]; !ok {
+			index.sourceIDs[sourceName+"."+tableName] = sourceID
+		}
+		if taken[tableName] || tableCounts[tableName] > 1 {
+			renamed = append(renamed, sourceID)
+			continue
+		}
+		index.names[sourceID] = tableName
+		taken[tableName] = true
+	}
+	for _, sourceID := range renamed {
+		parts := strings.Split(sourceID, ".")
+		base := parts[len(parts)-2] + "_" + parts[len(parts)-1]
+		name := base
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		index.names[sourceID] = name
+		taken[name] = true
+	}
+	return index
+}
+
+// nodeIDs maps the names of converted models to their node's unique_id.
+func (index dbtNodeIndex) nodeIDs(models []LegibleModel) map[string]string {
+	converted := make(map[string]bool, len(models))
+	for _, m := range models {
+		converted[m.Name] = true
+	}
+	ids := make(map[string]string, len(models))
+	for nodeKey, name := range index.names {
+		if converted[name] {
+			ids[name] = nodeKey
+		}
+	}
+	return ids
+}
+
+// modelName returns the model name of a node. Sources only have a name when
+// they are converted; other nodes are named after the node, even when they
+// are filtered out, as relationships to them have always been generated.
+func (index dbtNodeIndex) modelName(nodeKey string) string {
+	if name, ok := index.names[nodeKey]; ok {
+		return name
+	}
+	if strings.HasPrefix(nodeKey, "source.") {
+		return ""
+	}
+	return getModelNameFromNodeKey(nodeKey)
+}
+
+// resolve returns the model name for a ref('x') or source('x', 'y') call.
+func (index dbtNodeIndex) resolve(expr string) string {
+	if name := parseRef(expr); name != "" {
+		return name
+	}
+	if sourceID := index.sourceID(expr); sourceID != "" {
+		return index.modelName(sourceID)
+	}
+	return ""
+}
+
+// sourceID returns the unique_id of the source that a source('x', 'y') call points at.
+func (index dbtNodeIndex) sourceID(expr string) string {
+	sourceName, tableName := parseSource(expr)
+	if sourceName == "" {
+		return ""
+	}
+	return index.sourceIDs[sourceName+"."+tableName]
+}
+
+var sourceRegex = regexp.MustCompile(`source\s*\(\s*['"]([^'"]+)['"]\s*,\s*['"]([^'"]+)['"]\s*\)`)
+
+// parseSource extracts the source and table names from a dbt source string.
+// e.g., "source('raw', 'orders')" -> "raw", "orders"
+func parseSource(sourceStr string) (string, string) {
+	matches := sourceRegex.FindStringSubmatch(sourceStr)
+	if len(matches) > 2 {
+		return matches[1], matches[2]
+	}
+	return "", ""
+}
@@ -0,0 +1,40 @@
+package dbt
+
+import "testing"
+
+func TestNewDbtNodeIndexSourceNames(t *testing.T) {
+	nodes := map[string]interface{}{
+		"model.shop.orders":     map[string]interface{}{},
+		"model.shop.raw_orders": map[string]interface{}{},
+	}
+	sources := map[string]interface{}{
+		"source.shop.raw.orders":     map[string]interface{}{},
+		"source.shop.raw.customers":  map[string]interface{}{},
+		"source.shop.erp.customers":  map[string]interface{}{},
+		"source.shop.erp.raw_orders": map[string]interface{}{},
+	}
+	index := newDbtNodeIndex(nodes, sources, nil, CatalogConvertOptions{IncludeSources: true})
+
+	// raw.orders is renamed after the orders model, and raw_orders is taken
+	// by a model too; both sources of customers are renamed.
+	want := map[string]string{
+		"model.shop.orders":          "orders",
+		"model.shop.raw_orders":      "raw_orders",
+		"source.shop.raw.orders":     "raw_orders_2",
+		"source.shop.raw.customers":  "raw_customers",
+		"source.shop.erp.customers":  "erp_customers",
+		"source.shop.erp.raw_orders": "erp_raw_orders",
+	}
+	for nodeKey, name := range want {
+		if got := index.modelName(nodeKey); got != name {
+			t.Errorf("modelName(%q) = %q, want %q", nodeKey, got, name)
+		}
+	}
+	seen := make(map[string]string)
+	for nodeKey, name := range index.names {
+		if other, ok := seen[name]; ok {
+			t.Errorf("%s and %s are both named %q", other, nodeKey, name)
+		}
+		seen[name] = nodeKey
+	}
+}
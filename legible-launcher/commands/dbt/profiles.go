@@ -41,4 +41,10 @@ type DbtConnection struct {
 	Path string `yaml:"path,omitempty" json:"path,omitempty"` // DuckDB
 	// Flexible additional properties
 	Additional map[string]interface{} `yaml:",inline" json:"additional,omitempty"`
+	// RenderError is set when the output's Jinja expressions could not be
+	// rendered, such as for a missing env_var; converting it fails
+	RenderError error `yaml:"-" json:"-"`
+	// SecretRefs maps each field whose secret was rendered from a Jinja
+	// expression to the expression, so that it can be written as a reference
+	SecretRefs map[string]string `yaml:"-" json:"-"`
 }
@@ -10,8 +10,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// AnalyzeDbtProfiles reads and analyzes a dbt profiles.yml file
-func AnalyzeDbtProfiles(profilesPath string) (*DbtProfiles, error) {
+// AnalyzeDbtProfiles reads and analyzes a dbt profiles.yml file, rendering
+// Jinja expressions such as env_var('DBT_PASSWORD') in its values. vars are
+// the values available to var(), as given to dbt with --vars.
+func AnalyzeDbtProfiles(profilesPath string, vars map[string]interface{}) (*DbtProfiles, error) {
 	// Read the profiles.yml file
 	data, err := os.ReadFile(profilesPath) // #nosec G304 -- profilesPath is controlled by application
 	if err != nil {
@@ -28,6 +30,7 @@ func AnalyzeDbtProfiles(profilesPath string) (*DbtProfiles, error) {
 	profiles := &DbtProfiles{
 		Profiles: make(map[string]DbtProfile),
 	}
+	renderer := newProfileRenderer(vars)
 
 	// Extract config if present
 	if config, exists := rawProfiles["config"]; exists {
@@ -40,7 +43,7 @@ func AnalyzeDbtProfiles(profilesPath string) (*DbtProfiles, error) {
 	// Process each profile
 	for profileName, profileData := range rawProfiles {
 		if profileMap, ok := profileData.(map[string]interface{}); ok {
-			profile, err := parseProfile(profileMap)
+			profile, err := parseProfile(profileMap, renderer)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse profile %s: %w", profileName, err)
 			}
@@ -52,7 +55,7 @@ func AnalyzeDbtProfiles(profilesPath string) (*DbtProfiles, error) {
 }
 
 // parseProfile converts a raw profile map to DbtProfile struct
-func parseProfile(profileMap map[string]interface{}) (*DbtProfile, error) {
+func parseProfile(profileMap map[string]interface{}, renderer *profileRenderer) (*DbtProfile, error) {
 	profile := &DbtProfile{
 		Outputs: make(map[string]DbtConnection),
 	}
@@ -60,7 +63,11 @@ func parseProfile(profileMap map[string]interface{}) (*DbtProfile, error) {
 	// Extract target
 	if target, exists := profileMap["target"]; exists {
 		if targetStr, ok := target.(string); ok {
-			profile.Target = targetStr
+			rendered, err := renderer.renderString(targetStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render target: %w", err)
+			}
+			profile.Target = rendered
 		}
 	}
 
@@ -69,10 +76,21 @@ func parseProfile(profileMap map[string]interface{}) (*DbtProfile, error) {
 		if outputsMap, ok := outputs.(map[string]interface{}); ok {
 			for outputName, outputData := range outputsMap {
 				if outputMap, ok := outputData.(map[string]interface{}); ok {
-					connection, err := parseConnection(outputMap)
+					rendered, secretRefs, renderErr := renderConnectionMap(outputMap, renderer)
+					if renderErr != nil {
+						// Like dbt, only fail when this output is actually used, since
+						// other targets may reference variables that are not set locally
+						profile.Outputs[outputName] = DbtConnection{
+							Type:        fmt.Sprint(outputMap["type"]),
+							RenderError: renderErr,
+						}
+						continue
+					}
+					connection, err := parseConnection(rendered)
 					if err != nil {
 						return nil, fmt.Errorf("failed to parse output %s: %w", outputName, err)
 					}
+					connection.SecretRefs = secretRefs
 					profile.Outputs[outputName] = *connection
 				}
 			}
@@ -82,6 +100,59 @@ func parseProfile(profileMap map[string]interface{}) (*DbtProfile, error) {
 	return profile, nil
 }
 
+// renderConnectionMap renders the Jinja expressions in a raw connection
+// map. It also returns the template behind each field rendered into a
+// secret (a credential field, or any field that reads a DBT_ENV_SECRET_
+// variable), by field name.
+func renderConnectionMap(connectionMap map[string]interface{}, renderer *profileRenderer) (map[string]interface{}, map[string]string, error) {
+	rendered := make(map[string]interface{}, len(connectionMap))
+	secretRefs := make(map[string]string)
+	for key, value := range connectionMap {
+		renderer.usedSecretEnv = false
+		v, err := renderProfileValue(value, renderer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if tmpl, ok := value.(string); ok && tmpl != v {
+			if str, ok := v.(string); ok && str != "" && (secretProfileKeys[key] || renderer.usedSecretEnv) {
+				secretRefs[key] = tmpl
+			}
+		}
+		rendered[key] = v
+	}
+	return rendered, secretRefs, nil
+}
+
+// renderProfileValue renders the strings in a YAML value, including those
+// nested in maps and lists
+func renderProfileValue(value interface{}, renderer *profileRenderer) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return renderer.render(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := renderProfileValue(item, renderer)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = r
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := renderProfileValue(item, renderer)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
 // parseConnection converts a raw connection map to DbtConnection struct
 func parseConnection(connectionMap map[string]interface{}) (*DbtConnection, error) {
 	connection := &DbtConnection{
@@ -0,0 +1,3 @@
+cube(`legacy`, {
+  sql_table: `public.legacy`,
+});
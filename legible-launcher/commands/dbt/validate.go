@@ -0,0 +1,379 @@
+package dbt
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+//go:generate cp ../../../wren-mdl/mdl.schema.json mdl.schema.json
+
+// mdlSchemaJSON is a copy of wren-mdl/mdl.schema.json; go:embed cannot reach
+// outside the module, so keep it in sync with go generate.
+//
+//go:embed mdl.schema.json
+var mdlSchemaJSON []byte
+
+var (
+	mdlSchemaOnce     sync.Once
+	mdlSchema         *jsonschema.Schema
+	mdlSchemaErr      error
+	validationPrinter = message.NewPrinter(language.English)
+)
+
+// Validation issue severities. Errors make the manifest unusable; warnings
+// point at references that are probably unintended, such as a metric over
+// a model that was filtered out.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue is a problem found in an MDL manifest.
+type ValidationIssue struct {
+	Severity string `json:"severity"`
+	// Path is the JSON pointer of the offending value, e.g. /models/0/primaryKey.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationReport lists the issues found in an MDL manifest, schema
+// violations first.
+type ValidationReport struct {
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// Errors returns the number of error issues.
+func (r *ValidationReport) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of warning issues.
+func (r *ValidationReport) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+// HasErrors reports whether the manifest is invalid.
+func (r *ValidationReport) HasErrors() bool {
+	return r.Errors() > 0
+}
+
+func (r *ValidationReport) count(severity string) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *ValidationReport) add(severity, path, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, ValidationIssue{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateManifest checks a converted manifest against the MDL JSON schema
+// and for references that the schema cannot express: duplicate names,
+// relationship conditions on missing columns, undefined enums and
+// relationships, and relationships or metrics over models that are not in
+// the manifest.
+func ValidateManifest(manifest *LegibleMDLManifest) *ValidationReport {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		report := &ValidationReport{}
+		report.add(SeverityError, "/", "failed to marshal manifest: %v", err)
+		return report
+	}
+	report, err := ValidateMDL(data)
+	if err != nil {
+		report = &ValidationReport{}
+		report.add(SeverityError, "/", "%v", err)
+	}
+	return report
+}
+
+// ValidateMDL validates an MDL file's contents like ValidateManifest, so
+// hand-written fields that the converter never produces are checked against
+// the schema as well. It returns an error if data is not a JSON object.
+func ValidateMDL(data []byte) (*ValidationReport, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MDL: %w", err)
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to parse MDL: not a JSON object")
+	}
+	var manifest LegibleMDLManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse MDL: %w", err)
+	}
+
+	report := &ValidationReport{}
+	if err := validateSchema(obj, report); err != nil {
+		return nil, err
+	}
+	validateReferences(&manifest, report)
+	return report, nil
+}
+
+// validateSchema validates doc against the MDL JSON schema, without the
+// Legible extensions to it: the $schema URL, data sources the schema does
+// not list, MetricFlow-style metrics and column display names.
+func validateSchema(doc map[string]interface{}, report *ValidationReport) error {
+	mdlSchemaOnce.Do(func() {
+		var schemaDoc interface{}
+		if schemaDoc, mdlSchemaErr = jsonschema.UnmarshalJSON(bytes.NewReader(mdlSchemaJSON)); mdlSchemaErr != nil {
+			return
+		}
+		compiler := jsonschema.NewCompiler()
+		if mdlSchemaErr = compiler.AddResource("mdl.schema.json", schemaDoc); mdlSchemaErr != nil {
+			return
+		}
+		mdlSchema, mdlSchemaErr = compiler.Compile("mdl.schema.json")
+	})
+	if mdlSchemaErr != nil {
+		return fmt.Errorf("failed to load MDL schema: %w", mdlSchemaErr)
+	}
+
+	err := mdlSchema.Validate(withoutLegibleExtensions(doc))
+	if err == nil {
+		return nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return fmt.Errorf("failed to validate MDL: %w", err)
+	}
+	seen := make(map[string]bool)
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		path := jsonPointer(e.InstanceLocation)
+		message := e.ErrorKind.LocalizedString(validationPrinter)
+		if key := path + "\x00" + message; !seen[key] {
+			seen[key] = true
+			report.add(SeverityError, path, "%s", message)
+		}
+	}
+	walk(validationErr)
+	return nil
+}
+
+// withoutLegibleExtensions returns a shallow copy of doc without the
+// fields that Legible adds to the MDL schema.
+func withoutLegibleExtensions(doc map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		out[k] = v
+	}
+	delete(out, "$schema")
+	delete(out, "dataSource")
+
+	// Metrics in the schema's own format have a base object; the converter's
+	// MetricFlow metrics are checked by validateReferences instead.
+	if metrics, ok := out["metrics"].([]interface{}); ok {
+		kept := make([]interface{}, 0, len(metrics))
+		for _, m := range metrics {
+			if metric, ok := m.(map[string]interface{}); ok {
+				if _, ok := metric["baseObject"]; !ok {
+					continue
+				}
+			}
+			kept = append(kept, m)
+		}
+		out["metrics"] = kept
+	}
+
+	if models, ok := out["models"].([]interface{}); ok {
+		copied := make([]interface{}, len(models))
+		for i, m := range models {
+			copied[i] = m
+			model, ok := m.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			columns, ok := model["columns"].([]interface{})
+			if !ok {
+				continue
+			}
+			modelCopy := make(map[string]interface{}, len(model))
+			for k, v := range model {
+				modelCopy[k] = v
+			}
+			columnsCopy := make([]interface{}, len(columns))
+			for j, c := range columns {
+				columnsCopy[j] = c
+				if column, ok := c.(map[string]interface{}); ok {
+					if _, ok := column["displayName"]; ok {
+						columnCopy := make(map[string]interface{}, len(column))
+						for k, v := range column {
+							columnCopy[k] = v
+						}
+						delete(columnCopy, "displayName")
+						columnsCopy[j] = columnCopy
+					}
+				}
+			}
+			modelCopy["columns"] = columnsCopy
+			copied[i] = modelCopy
+		}
+		out["models"] = copied
+	}
+	return out
+}
+
+// stringLiteralRegex matches SQL string literals, which are removed from
+// conditions before looking for column references.
+var stringLiteralRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// columnReferenceRegex matches model.column references in a condition,
+// quoted or not, e.g. "orders"."customer_id" or orders.customer_id.
+var columnReferenceRegex = regexp.MustCompile(`(?:"([^"]+)"|\b([A-Za-z_]\w*))\s*\.\s*(?:"([^"]+)"|([A-Za-z_]\w*))`)
+
+// validateReferences checks the names and references in a manifest.
+func validateReferences(manifest *LegibleMDLManifest, report *ValidationReport) {
+	models := make(map[string]*LegibleModel, len(manifest.Models))
+	for i := range manifest.Models {
+		model := &manifest.Models[i]
+		path := fmt.Sprintf("/models/%d", i)
+		if _, ok := models[model.Name]; ok {
+			report.add(SeverityError, path+"/name", "model %q is defined more than once", model.Name)
+			continue
+		}
+		models[model.Name] = model
+	}
+
+	enums := make(map[string]bool, len(manifest.EnumDefinitions))
+	for i, enum := range manifest.EnumDefinitions {
+		path := fmt.Sprintf("/enumDefinitions/%d", i)
+		if enums[enum.Name] {
+			report.add(SeverityError, path+"/name", "enum %q is defined more than once", enum.Name)
+		}
+		enums[enum.Name] = true
+		values := make(map[string]bool, len(enum.Values))
+		for j, value := range enum.Values {
+			if values[value.Name] {
+				report.add(SeverityWarning, fmt.Sprintf("%s/values/%d", path, j), "enum %q lists %q more than once", enum.Name, value.Name)
+			}
+			values[value.Name] = true
+		}
+	}
+
+	relationships := make(map[string]bool, len(manifest.Relationships))
+	for i, rel := range manifest.Relationships {
+		path := fmt.Sprintf("/relationships/%d", i)
+		if relationships[rel.Name] {
+			report.add(SeverityError, path+"/name", "relationship %q is defined more than once", rel.Name)
+		}
+		relationships[rel.Name] = true
+
+		missing := make(map[string]bool)
+		for j, name := range rel.Models {
+			if _, ok := models[name]; !ok {
+				missing[name] = true
+				report.add(SeverityWarning, fmt.Sprintf("%s/models/%d", path, j), "relationship %q refers to model %q, which is not in the manifest", rel.Name, name)
+			}
+		}
+		condition := stringLiteralRegex.ReplaceAllString(rel.Condition, "''")
+		for _, ref := range columnReferenceRegex.FindAllStringSubmatch(condition, -1) {
+			modelName, columnName := ref[1]+ref[2], ref[3]+ref[4]
+			if !containsString(rel.Models, modelName) {
+				report.add(SeverityError, path+"/condition", "condition of relationship %q refers to model %q, which is not one of its models", rel.Name, modelName)
+				continue
+			}
+			if missing[modelName] {
+				continue
+			}
+			if findColumn(models[modelName], columnName) == nil {
+				report.add(SeverityError, path+"/condition", "condition of relationship %q refers to missing column %s.%s", rel.Name, modelName, columnName)
+			}
+		}
+	}
+
+	for i := range manifest.Models {
+		model := &manifest.Models[i]
+		path := fmt.Sprintf("/models/%d", i)
+		if model.PrimaryKey != "" && findColumn(model, model.PrimaryKey) == nil {
+			report.add(SeverityError, path+"/primaryKey", "primary key %q of model %q is not one of its columns", model.PrimaryKey, model.Name)
+		}
+		columns := make(map[string]bool, len(model.Columns))
+		for j, col := range model.Columns {
+			colPath := fmt.Sprintf("%s/columns/%d", path, j)
+			lower := strings.ToLower(col.Name)
+			if columns[lower] {
+				report.add(SeverityError, colPath+"/name", "column %q of model %q is defined more than once", col.Name, model.Name)
+			}
+			columns[lower] = true
+			if col.Relationship != "" && !relationships[col.Relationship] {
+				report.add(SeverityError, colPath+"/relationship", "column %s.%s refers to undefined relationship %q", model.Name, col.Name, col.Relationship)
+			}
+			if enum := col.Properties["enumDefinition"]; enum != "" && !enums[enum] {
+				report.add(SeverityError, colPath+"/properties/enumDefinition", "column %s.%s refers to undefined enum %q", model.Name, col.Name, enum)
+			}
+		}
+	}
+
+	views := make(map[string]bool, len(manifest.Views))
+	for i, view := range manifest.Views {
+		path := fmt.Sprintf("/views/%d/name", i)
+		switch {
+		case views[view.Name]:
+			report.add(SeverityError, path, "view %q is defined more than once", view.Name)
+		case models[view.Name] != nil:
+			report.add(SeverityError, path, "view %q has the same name as a model", view.Name)
+		}
+		views[view.Name] = true
+	}
+
+	metrics := make(map[string]bool, len(manifest.Metrics))
+	for i, metric := range manifest.Metrics {
+		path := fmt.Sprintf("/metrics/%d", i)
+		if metrics[metric.Name] {
+			report.add(SeverityError, path+"/name", "metric %q is defined more than once", metric.Name)
+		}
+		metrics[metric.Name] = true
+		for j, name := range metric.Models {
+			if _, ok := models[name]; !ok {
+				report.add(SeverityWarning, fmt.Sprintf("%s/models/%d", path, j), "metric %q refers to model %q, which is not in the manifest", metric.Name, name)
+			}
+		}
+		if len(metric.Models) > 0 && strings.TrimSpace(metric.Aggregation) == "" {
+			report.add(SeverityError, path+"/aggregation", "metric %q has no aggregation", metric.Name)
+		}
+	}
+}
+
+// jsonPointer renders an instance location as a JSON pointer.
+func jsonPointer(location []string) string {
+	if len(location) == 0 {
+		return "/"
+	}
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	parts := make([]string, len(location))
+	for i, part := range location {
+		parts[i] = escaper.Replace(part)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
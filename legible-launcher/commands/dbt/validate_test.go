@@ -0,0 +1,120 @@
+package dbt
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMDLSchemaInSync(t *testing.T) {
+	upstream, err := os.ReadFile("../../../wren-mdl/mdl.schema.json")
+	if os.IsNotExist(err) {
+		t.Skip("wren-mdl/mdl.schema.json not found outside the repository")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(mdlSchemaJSON, upstream) {
+		t.Error("mdl.schema.json differs from wren-mdl/mdl.schema.json; run go generate ./commands/dbt")
+	}
+}
+
+func TestValidateManifestConverted(t *testing.T) {
+	report := ValidateManifest(convertJaffleShop(t))
+	if len(report.Issues) != 0 {
+		t.Errorf("converted manifest has issues: %+v", report.Issues)
+	}
+}
+
+func TestValidateManifestIssues(t *testing.T) {
+	manifest := &LegibleMDLManifest{
+		JsonSchema: "https://raw.githubusercontent.com/Canner/Legible/main/legible-mdl/mdl.schema.json",
+		Catalog:    "legible",
+		Schema:     "public",
+		DataSource: "databricks",
+		EnumDefinitions: []EnumDefinition{
+			{Name: "status", Values: []EnumValue{{Name: "open"}}},
+			{Name: "status", Values: []EnumValue{{Name: "closed"}}},
+		},
+		Models: []LegibleModel{
+			{
+				Name:           "orders",
+				TableReference: TableReference{Table: "orders"},
+				PrimaryKey:     "id",
+				Columns: []LegibleColumn{
+					{Name: "order_id", Type: "integer", DisplayName: "Order"},
+					{Name: "customer_id", Type: "integer", Properties: map[string]string{"enumDefinition": "kind"}},
+				},
+			},
+			{Name: "orders", TableReference: TableReference{Table: "orders_v2"}, Columns: []LegibleColumn{}},
+			{Name: "customers", TableReference: TableReference{Table: "customers"}, RefreshTime: "soon",
+				Columns: []LegibleColumn{{Name: "id", Type: "integer", Relationship: "missing"}}},
+		},
+		Relationships: []Relationship{
+			{Name: "orders_customers", Models: []string{"orders", "customers"}, JoinType: "MANY_TO_ONE",
+				Condition: `"orders"."customer_id" = "customers"."customer_id"`},
+			{Name: "orders_stores", Models: []string{"orders", "stores"}, JoinType: "MANY_TO_ONE",
+				Condition: `orders.store_id = stores.id AND orders.order_id <> 'a.b'`},
+		},
+		Metrics: []Metric{
+			{Name: "revenue", Models: []string{"orders", "payments"}, Aggregation: "SUM(amount)"},
+		},
+		Views: []View{{Name: "customers", Statement: "SELECT 1"}},
+	}
+	report := ValidateManifest(manifest)
+
+	want := []ValidationIssue{
+		{SeverityError, "/models/2/refreshTime", "does not match pattern"},
+		{SeverityError, "/models/1/name", `model "orders" is defined more than once`},
+		{SeverityError, "/enumDefinitions/1/name", `enum "status" is defined more than once`},
+		{SeverityError, "/relationships/0/condition", "missing column customers.customer_id"},
+		{SeverityWarning, "/relationships/1/models/1", `model "stores", which is not in the manifest`},
+		{SeverityError, "/relationships/1/condition", "missing column orders.store_id"},
+		{SeverityError, "/models/0/primaryKey", `primary key "id"`},
+		{SeverityError, "/models/0/columns/1/properties/enumDefinition", `undefined enum "kind"`},
+		{SeverityError, "/models/2/columns/0/relationship", `undefined relationship "missing"`},
+		{SeverityError, "/views/0/name", "same name as a model"},
+		{SeverityWarning, "/metrics/0/models/1", `model "payments", which is not in the manifest`},
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %+v", len(report.Issues), len(want), report.Issues)
+	}
+	for i, w := range want {
+		got := report.Issues[i]
+		if got.Severity != w.Severity || got.Path != w.Path || !strings.Contains(got.Message, w.Message) {
+			t.Errorf("issue %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if !report.HasErrors() || report.Errors() != 9 || report.Warnings() != 2 {
+		t.Errorf("Errors() = %d, Warnings() = %d", report.Errors(), report.Warnings())
+	}
+}
+
+func TestValidateMDL(t *testing.T) {
+	// Hand-written fields are checked against the schema, and metrics in
+	// the schema's own format are validated by it.
+	data := []byte(`{
+		"catalog": "legible",
+		"schema": "public",
+		"models": [{"name": "orders", "refSql": "SELECT 1", "columns": [{"name": "id", "type": "integer", "isHidden": "no"}]}],
+		"metrics": [{"name": "revenue", "baseObject": "orders"}]
+	}`)
+	report, err := ValidateMDL(data)
+	if err != nil {
+		t.Fatalf("ValidateMDL() error = %v", err)
+	}
+	paths := make(map[string]bool)
+	for _, issue := range report.Issues {
+		paths[issue.Path] = true
+	}
+	for _, path := range []string{"/models/0/columns/0/isHidden", "/metrics/0"} {
+		if !paths[path] {
+			t.Errorf("no issue at %s: %+v", path, report.Issues)
+		}
+	}
+
+	if _, err := ValidateMDL([]byte(`[1, 2]`)); err == nil {
+		t.Error("ValidateMDL() should fail on a document that is not an object")
+	}
+}
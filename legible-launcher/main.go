@@ -52,4 +52,7 @@ func showHelp() {
 	pterm.Info.Println("  legible-launcher                                              # Launch Legible")
 	pterm.Info.Println("  legible-launcher dbt-auto-convert --path /path/to/dbt --output ./output    # Auto-convert dbt project")
 	pterm.Info.Println("  legible-launcher dbt-auto-convert --path /path/to/dbt --output ./output --profile my_profile --target dev # Convert with specific profile/target")
+	pterm.Info.Println("  legible-launcher dbt-auto-convert --path /path/to/dbt --output ./output --keep-secret-refs # Keep env_var() secrets out of the data source file")
+	pterm.Info.Println("  legible-launcher dbt-auto-convert --path /path/to/dbt --output ./output --metadata-mapping legible-metadata.yml # Map dbt meta into MDL properties")
+	pterm.Info.Println("  legible-launcher dbt-auto-convert --path /path/to/dbt --output ./output --infer-relations # Also infer untested relationships for review")
 }